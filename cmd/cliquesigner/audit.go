@@ -0,0 +1,185 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/log"
+)
+
+// rejectInterval is the minimum time between two audit entries of requests
+// rejected for the same reason, the ones in between are aggregated.
+const rejectInterval = time.Minute
+
+// auditEntry is a single signing request recorded in the audit log. Rejected
+// requests may be aggregated, in which case the entry is the first of them.
+type auditEntry struct {
+	Time       time.Time   `json:"time"`
+	Number     uint64      `json:"number"`
+	ParentHash common.Hash `json:"parentHash"`
+	SealHash   common.Hash `json:"sealHash,omitempty"`
+	Signed     bool        `json:"signed"`
+	Error      string      `json:"error,omitempty"`
+	Count      int         `json:"count,omitempty"` // Number of rejected requests aggregated into the entry
+}
+
+// rejection tracks the requests rejected for a single reason.
+type rejection struct {
+	written time.Time   // Time the last entry for the reason was written
+	pending *auditEntry // Rejections aggregated since, nil if none
+}
+
+// auditLog is an append-only file of JSON encoded signing requests, one per
+// line. Entries are synced to disk before a signature is handed out, while
+// rejected requests are aggregated per reason to bound the log growth.
+type auditLog struct {
+	file     *os.File
+	signed   []*auditEntry         // Signed entries within signedHistory of the highest one at open
+	rejected map[string]*rejection // Rejected requests by error
+}
+
+// openAuditLog opens the audit log at the given path, creating it if needed,
+// and scans the existing entries for the recently signed blocks.
+func openAuditLog(path string) (*auditLog, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	audit := &auditLog{file: file, rejected: make(map[string]*rejection)}
+
+	var highest uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry := new(auditEntry)
+		if err := json.Unmarshal(scanner.Bytes(), entry); err != nil {
+			continue // Torn write of a crashed signer, the request wasn't answered
+		}
+		if !entry.Signed {
+			continue
+		}
+		if entry.Number > highest {
+			highest = entry.Number
+		}
+		audit.signed = append(audit.signed, entry)
+		if len(audit.signed) > 2*signedHistory {
+			audit.signed = recentEntries(audit.signed, highest)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	audit.signed = recentEntries(audit.signed, highest)
+	return audit, nil
+}
+
+// recentEntries filters the entries within signedHistory of the highest block.
+func recentEntries(entries []*auditEntry, highest uint64) []*auditEntry {
+	recent := entries[:0]
+	for _, entry := range entries {
+		if entry.Number+signedHistory >= highest {
+			recent = append(recent, entry)
+		}
+	}
+	return recent
+}
+
+// history returns the recently signed entries found in the log when opened.
+func (l *auditLog) history() []*auditEntry {
+	return l.signed
+}
+
+// write appends a signed entry to the audit log, along with the aggregated
+// rejections due, and flushes it to disk.
+func (l *auditLog) write(entry *auditEntry) error {
+	if err := l.flushRejected(entry.Time, false); err != nil {
+		return err
+	}
+	if err := l.append(entry); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// reject records a rejected request. The first rejection of a reason within
+// rejectInterval is appended right away, later ones are aggregated into a
+// single entry written once the interval passes. Rejections aren't synced to
+// disk as they don't protect against double signing.
+func (l *auditLog) reject(entry *auditEntry) error {
+	if err := l.flushRejected(entry.Time, false); err != nil {
+		return err
+	}
+	r := l.rejected[entry.Error]
+	if r == nil {
+		r = new(rejection)
+		l.rejected[entry.Error] = r
+	}
+	if entry.Time.Sub(r.written) < rejectInterval {
+		if r.pending == nil {
+			r.pending = entry
+		}
+		r.pending.Count++
+		return nil
+	}
+	entry.Count = 1
+	r.written = entry.Time
+	return l.appendRejected(entry)
+}
+
+// flushRejected appends the aggregated rejections whose interval passed by the
+// given time, or all of them if forced.
+func (l *auditLog) flushRejected(now time.Time, force bool) error {
+	for _, r := range l.rejected {
+		if r.pending == nil || (!force && now.Sub(r.written) < rejectInterval) {
+			continue
+		}
+		if err := l.appendRejected(r.pending); err != nil {
+			return err
+		}
+		r.written, r.pending = now, nil
+	}
+	return nil
+}
+
+// appendRejected appends an entry of rejected requests to the audit log.
+func (l *auditLog) appendRejected(entry *auditEntry) error {
+	log.Warn("Rejected signing requests", "count", entry.Count, "number", entry.Number, "parent", entry.ParentHash, "err", entry.Error)
+	return l.append(entry)
+}
+
+// append encodes an entry as a single line of the audit log.
+func (l *auditLog) append(entry *auditEntry) error {
+	blob, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = l.file.Write(append(blob, '\n'))
+	return err
+}
+
+// close writes the pending aggregated rejections and closes the audit log file.
+func (l *auditLog) close() error {
+	if err := l.flushRejected(time.Now(), true); err != nil {
+		log.Error("Failed to write audit log", "err", err)
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+// cliquesigner is a standalone signer sealing clique blocks on behalf of gokc
+// nodes, keeping the sealing key out of the node process.
+//
+// Signing requests are served on an IPC socket and optionally over HTTP. The
+// endpoints are unauthenticated: anyone able to reach them can have headers
+// signed within the signing rules. The HTTP endpoint therefore only listens on
+// localhost by default, exposing it on other interfaces should be limited to
+// trusted networks.
+package main
+
+import (
+	"errors"
+	"flag"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/okcoin/go-okcoin/accounts"
+	"github.com/okcoin/go-okcoin/accounts/keystore"
+	"github.com/okcoin/go-okcoin/cmd/utils"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+var (
+	errInvalidAccount   = errors.New("invalid account address")
+	errNoAccounts       = errors.New("no accounts in keystore")
+	errAmbiguousAccount = errors.New("multiple accounts in keystore, use -account to select one")
+)
+
+func main() {
+	var (
+		keystoreDir  = flag.String("keystore", "", "directory of the keystore holding the sealing account")
+		accountFlag  = flag.String("account", "", "address of the sealing account (default = only account in the keystore)")
+		passwordFile = flag.String("password", "", "file containing the password of the sealing account")
+		ipcPath      = flag.String("ipcpath", "cliquesigner.ipc", "filename of the IPC socket to serve signing requests on")
+		httpEnabled  = flag.Bool("http", false, "serve signing requests over HTTP (unauthenticated, keep it on trusted interfaces)")
+		httpAddr     = flag.String("httpaddr", "localhost", "HTTP listening interface, anyone reaching it can request signatures")
+		httpPort     = flag.Int("httpport", 8550, "HTTP listening port")
+		auditPath    = flag.String("auditlog", "audit.log", "file to record all signing requests into")
+		allowVotes   = flag.Bool("allowvotes", false, "allow signing headers casting signer votes")
+		maxFuture    = flag.Duration("maxfuture", 15*time.Second, "maximum distance of header timestamps into the future")
+		rateLimit    = flag.Int("ratelimit", 60, "maximum number of signatures per rate period (0 = unlimited)")
+		ratePeriod   = flag.Duration("rateperiod", time.Minute, "period over which the rate limit is enforced")
+		verbosity    = flag.Int("verbosity", int(log.LvlInfo), "log verbosity (0-9)")
+		vmodule      = flag.String("vmodule", "", "log verbosity pattern")
+	)
+	flag.Parse()
+
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(*verbosity))
+	glogger.Vmodule(*vmodule)
+	log.Root().SetHandler(glogger)
+
+	if *keystoreDir == "" {
+		utils.Fatalf("Use -keystore to specify the keystore directory")
+	}
+	if *ipcPath == "" && !*httpEnabled {
+		utils.Fatalf("Signer requires at least one of -ipcpath and -http")
+	}
+	// Unlock the sealing account in the signer's own keystore
+	ks := keystore.NewKeyStore(*keystoreDir, keystore.StandardScryptN, keystore.StandardScryptP)
+
+	account, err := findAccount(ks, *accountFlag)
+	if err != nil {
+		utils.Fatalf("Failed to find sealing account: %v", err)
+	}
+	var password string
+	if *passwordFile != "" {
+		blob, err := ioutil.ReadFile(*passwordFile)
+		if err != nil {
+			utils.Fatalf("Failed to read password file: %v", err)
+		}
+		password = strings.TrimRight(string(blob), "\r\n")
+	}
+	if err := ks.Unlock(account, password); err != nil {
+		utils.Fatalf("Failed to unlock sealing account: %v", err)
+	}
+	audit, err := openAuditLog(*auditPath)
+	if err != nil {
+		utils.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer audit.close()
+
+	signer := newSigner(ks, account, rules{
+		allowVotes: *allowVotes,
+		maxFuture:  *maxFuture,
+		rateLimit:  *rateLimit,
+		ratePeriod: *ratePeriod,
+	}, audit)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("signer", &SignerAPI{signer}); err != nil {
+		utils.Fatalf("Failed to register signer API: %v", err)
+	}
+	defer server.Stop()
+
+	// Start serving signing requests on the configured endpoints
+	if *ipcPath != "" {
+		listener, err := rpc.CreateIPCListener(*ipcPath)
+		if err != nil {
+			utils.Fatalf("Failed to open IPC endpoint: %v", err)
+		}
+		defer listener.Close()
+		go server.ServeListener(listener)
+		log.Info("IPC endpoint opened", "url", *ipcPath)
+	}
+	if *httpEnabled {
+		endpoint := net.JoinHostPort(*httpAddr, strconv.Itoa(*httpPort))
+		listener, err := net.Listen("tcp", endpoint)
+		if err != nil {
+			utils.Fatalf("Failed to open HTTP endpoint: %v", err)
+		}
+		defer listener.Close()
+		go http.Serve(listener, server)
+		log.Info("HTTP endpoint opened", "url", "http://"+endpoint)
+
+		if ip := net.ParseIP(*httpAddr); *httpAddr != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			log.Warn("Unauthenticated HTTP endpoint exposed beyond localhost", "addr", *httpAddr)
+		}
+	}
+	log.Info("Clique signer started", "account", account.Address, "highest", signer.highest)
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	<-sigc
+	log.Info("Clique signer stopping")
+}
+
+// findAccount retrieves the sealing account from the keystore, defaulting to
+// the only account available if none was explicitly requested.
+func findAccount(ks *keystore.KeyStore, address string) (accounts.Account, error) {
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			return accounts.Account{}, errInvalidAccount
+		}
+		return ks.Find(accounts.Account{Address: common.HexToAddress(address)})
+	}
+	switch accs := ks.Accounts(); len(accs) {
+	case 0:
+		return accounts.Account{}, errNoAccounts
+	case 1:
+		return accs[0], nil
+	default:
+		return accounts.Account{}, errAmbiguousAccount
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/accounts"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/consensus/clique"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/log"
+)
+
+const (
+	extraVanity = 32 // Fixed number of extra-data prefix bytes reserved for signer vanity
+	extraSeal   = 65 // Fixed number of extra-data suffix bytes reserved for signer seal
+
+	// signedHistory is the number of blocks below the highest signed one that
+	// are tracked for double signing, deeper headers are refused.
+	signedHistory = 1024
+)
+
+var (
+	errMissingExtra  = errors.New("extra-data missing vanity or seal")
+	errGenesisBlock  = errors.New("genesis block cannot be sealed")
+	errVoteForbidden = errors.New("header casts a signer vote")
+	errRateLimited   = errors.New("signing rate limit exceeded")
+	errDoubleSign    = errors.New("conflicting header already signed on this parent")
+	errStaleHeader   = errors.New("header too far below the highest signed block")
+	errFutureHeader  = errors.New("header timestamp too far in the future")
+)
+
+// hashSigner is the subset of the keystore used to sign seal hashes.
+type hashSigner interface {
+	SignHash(a accounts.Account, hash []byte) ([]byte, error)
+}
+
+// rules are the checks every header has to pass before being signed.
+type rules struct {
+	allowVotes bool          // Whether headers casting signer votes may be signed
+	maxFuture  time.Duration // Maximum distance of the header timestamp into the future
+	rateLimit  int           // Maximum number of signatures within ratePeriod, zero for none
+	ratePeriod time.Duration // Period over which the rate limit is enforced
+}
+
+// signer seals clique headers with a single keystore account, enforcing the
+// configured rules and keeping an audit trail of every request.
+type signer struct {
+	keys    hashSigner
+	account accounts.Account
+	rules   rules
+	audit   *auditLog
+
+	highest uint64                    // Number of the highest header signed
+	signed  map[signedKey]common.Hash // Seal hashes of the recent headers signed
+	recent  []time.Time               // Times of the signatures within the rate limit period
+	lock    sync.Mutex
+}
+
+// signedKey identifies the position of a signed header. Only a single header is
+// signed per position, while competing branches after a reorg may be signed.
+type signedKey struct {
+	number uint64
+	parent common.Hash
+}
+
+// newSigner creates a signer sealing with the given account. The recently signed
+// headers are restored from the audit log to preserve the double signing
+// protection across restarts.
+func newSigner(keys hashSigner, account accounts.Account, rules rules, audit *auditLog) *signer {
+	s := &signer{
+		keys:    keys,
+		account: account,
+		rules:   rules,
+		audit:   audit,
+		signed:  make(map[signedKey]common.Hash),
+	}
+	for _, entry := range audit.history() {
+		s.record(entry)
+	}
+	return s
+}
+
+// record tracks a signed header for the double signing protection, dropping
+// the ones fallen out of the tracked history.
+func (s *signer) record(entry *auditEntry) {
+	s.signed[signedKey{entry.Number, entry.ParentHash}] = entry.SealHash
+	if entry.Number <= s.highest {
+		return
+	}
+	s.highest = entry.Number
+	for key := range s.signed {
+		if key.number+signedHistory < s.highest {
+			delete(s.signed, key)
+		}
+	}
+}
+
+// sign checks the header against the rules and, if accepted, signs its seal
+// hash. Every request is recorded in the audit log, whether signed or not, with
+// repeated rejections aggregated. Signatures only count as handed out once their
+// audit entry is written.
+func (s *signer) sign(header *types.Header) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	entry := &auditEntry{Time: now}
+	if header.Number != nil {
+		entry.Number = header.Number.Uint64()
+	}
+	entry.ParentHash = header.ParentHash
+
+	var signature []byte
+	err := s.check(header, now)
+	if err == nil {
+		entry.SealHash = clique.SealHash(header)
+		signature, err = s.keys.SignHash(s.account, entry.SealHash.Bytes())
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		log.Debug("Rejected signing request", "number", entry.Number, "parent", entry.ParentHash, "err", err)
+		if auditErr := s.audit.reject(entry); auditErr != nil {
+			log.Error("Failed to write audit log", "err", auditErr)
+		}
+		return nil, err
+	}
+	entry.Signed = true
+	if auditErr := s.audit.write(entry); auditErr != nil {
+		// Never hand out signatures that aren't audited
+		log.Error("Failed to write audit log", "err", auditErr)
+		return nil, fmt.Errorf("audit log unavailable: %v", auditErr)
+	}
+	s.record(entry)
+	s.recent = append(s.recent, now)
+	log.Info("Signed clique header", "number", entry.Number, "sealhash", entry.SealHash)
+
+	return signature, nil
+}
+
+// check verifies the header against the signing rules. Headers on a parent
+// already built upon are only accepted if they are the very same header.
+func (s *signer) check(header *types.Header, now time.Time) error {
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingExtra
+	}
+	if header.Number == nil || header.Number.Sign() <= 0 {
+		return errGenesisBlock
+	}
+	if header.Time == nil || !header.Time.IsUint64() {
+		return errFutureHeader
+	}
+	if time.Unix(int64(header.Time.Uint64()), 0).Sub(now) > s.rules.maxFuture {
+		return errFutureHeader
+	}
+	if !s.rules.allowVotes && header.Coinbase != (common.Address{}) {
+		return errVoteForbidden
+	}
+	number := header.Number.Uint64()
+	if number+signedHistory < s.highest {
+		return errStaleHeader
+	}
+	if hash, ok := s.signed[signedKey{number, header.ParentHash}]; ok && hash != clique.SealHash(header) {
+		return errDoubleSign
+	}
+	if s.rules.rateLimit > 0 {
+		cutoff := now.Add(-s.rules.ratePeriod)
+		for len(s.recent) > 0 && !s.recent[0].After(cutoff) {
+			s.recent = s.recent[1:]
+		}
+		if len(s.recent) >= s.rules.rateLimit {
+			return errRateLimited
+		}
+	}
+	return nil
+}
+
+// SignerAPI is the RPC API served to the nodes sealing through the signer.
+type SignerAPI struct {
+	signer *signer
+}
+
+// Account returns the address of the account the signer seals with.
+func (api *SignerAPI) Account() common.Address {
+	return api.signer.account.Address
+}
+
+// SignHeader signs the seal hash of the given clique header, if permitted by
+// the signing rules.
+func (api *SignerAPI) SignHeader(header *types.Header) (hexutil.Bytes, error) {
+	return api.signer.sign(header)
+}
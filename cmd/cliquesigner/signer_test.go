@@ -0,0 +1,273 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/accounts"
+	"github.com/okcoin/go-okcoin/accounts/keystore"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/clique"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// newTestSigner creates a signer backed by a fresh keystore account and an
+// audit log within the given directory.
+func newTestSigner(t *testing.T, dir string, rules rules) *signer {
+	ks := keystore.NewKeyStore(filepath.Join(dir, "keystore"), keystore.LightScryptN, keystore.LightScryptP)
+
+	account, err := findAccount(ks, "")
+	if err == errNoAccounts {
+		account, err = ks.NewAccount("")
+	}
+	if err != nil {
+		t.Fatalf("failed to create sealing account: %v", err)
+	}
+	if err := ks.Unlock(account, ""); err != nil {
+		t.Fatalf("failed to unlock sealing account: %v", err)
+	}
+	audit, err := openAuditLog(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	return newSigner(ks, account, rules, audit)
+}
+
+// testTime is the timestamp of the test headers, fixed so that the same header
+// can be recreated for signing again.
+var testTime = time.Now().Unix()
+
+// testHeader creates a sealable header at the given height, distinguished from
+// other headers of the same height by its parent hash.
+func testHeader(number uint64, parent byte) *types.Header {
+	return &types.Header{
+		ParentHash: common.Hash{parent},
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: big.NewInt(2),
+		Time:       big.NewInt(testTime),
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+}
+
+// Tests that the signer only signs headers passing its rules.
+func TestSignerRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cliquesigner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestSigner(t, dir, rules{maxFuture: 15 * time.Second, rateLimit: 4, ratePeriod: time.Hour})
+	defer s.audit.close()
+
+	short := testHeader(1, 0)
+	short.Extra = short.Extra[:extraSeal]
+
+	future := testHeader(1, 0)
+	future.Time = big.NewInt(time.Now().Add(time.Minute).Unix())
+
+	vote := testHeader(1, 0)
+	vote.Coinbase = common.Address{0xff}
+
+	conflict := testHeader(2, 0)
+	conflict.Extra[0] = 0xff
+
+	tests := []struct {
+		header *types.Header
+		err    error
+	}{
+		{short, errMissingExtra},
+		{testHeader(0, 0), errGenesisBlock},
+		{future, errFutureHeader},
+		{vote, errVoteForbidden},
+		{testHeader(2, 0), nil},
+		{testHeader(2, 0), nil}, // same header may be signed again
+		{conflict, errDoubleSign},
+		{testHeader(1, 1), nil}, // lower branch after a reorg
+		{testHeader(signedHistory+3, 0), nil},
+		{testHeader(2, 2), errStaleHeader},
+		{testHeader(signedHistory+4, 0), errRateLimited},
+	}
+	for i, tt := range tests {
+		signature, err := s.sign(tt.header)
+		if err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+			continue
+		}
+		if err == nil && len(signature) != extraSeal {
+			t.Errorf("test %d: signature length mismatch: have %d, want %d", i, len(signature), extraSeal)
+		}
+	}
+}
+
+// Tests that the double signing protection survives a signer restart via the
+// audit log.
+func TestSignerAuditRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cliquesigner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestSigner(t, dir, rules{maxFuture: 15 * time.Second})
+	for _, number := range []uint64{5, 7, 6} {
+		s.sign(testHeader(number, 0))
+	}
+	s.audit.close()
+
+	s = newTestSigner(t, dir, rules{maxFuture: 15 * time.Second})
+	defer s.audit.close()
+
+	if s.highest != 7 {
+		t.Fatalf("restored highest signed block mismatch: have %d, want 7", s.highest)
+	}
+	for _, number := range []uint64{5, 6, 7} {
+		conflict := testHeader(number, 0)
+		conflict.Extra[0] = 0xff
+		if _, err := s.sign(conflict); err != errDoubleSign {
+			t.Fatalf("block %d: conflicting header error mismatch: have %v, want %v", number, err, errDoubleSign)
+		}
+		if _, err := s.sign(testHeader(number, 0)); err != nil {
+			t.Fatalf("block %d: failed to re-sign same header: %v", number, err)
+		}
+	}
+}
+
+// Tests that repeated rejections are aggregated in the audit log instead of being
+// written one by one.
+func TestSignerAuditRejections(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cliquesigner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestSigner(t, dir, rules{maxFuture: 15 * time.Second})
+	vote := testHeader(1, 0)
+	vote.Coinbase = common.Address{0xff}
+	for i := 0; i < 10; i++ {
+		if _, err := s.sign(vote); err != errVoteForbidden {
+			t.Fatalf("request %d: error mismatch: have %v, want %v", i, err, errVoteForbidden)
+		}
+	}
+	s.audit.close()
+
+	blob, err := ioutil.ReadFile(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	var counts []int
+	for _, line := range bytes.Split(bytes.TrimSpace(blob), []byte("\n")) {
+		entry := new(auditEntry)
+		if err := json.Unmarshal(line, entry); err != nil {
+			t.Fatalf("failed to decode audit entry %q: %v", line, err)
+		}
+		if entry.Signed || entry.Error != errVoteForbidden.Error() {
+			t.Errorf("audit entry mismatch: %+v", entry)
+		}
+		counts = append(counts, entry.Count)
+	}
+	if len(counts) != 2 || counts[0] != 1 || counts[1] != 9 {
+		t.Errorf("aggregated rejection counts mismatch: have %v, want [1 9]", counts)
+	}
+}
+
+// Tests that the signer state is left untouched if a signature can't be audited.
+func TestSignerAuditFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cliquesigner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestSigner(t, dir, rules{maxFuture: 15 * time.Second, rateLimit: 1, ratePeriod: time.Hour})
+	s.audit.close()
+
+	if _, err := s.sign(testHeader(1, 0)); err == nil {
+		t.Fatalf("unaudited header signed")
+	}
+	if s.highest != 0 || len(s.signed) != 0 || len(s.recent) != 0 {
+		t.Fatalf("unaudited signature recorded: highest %d, signed %d, recent %d", s.highest, len(s.signed), len(s.recent))
+	}
+}
+
+// Tests that a clique node can seal headers through the signer served over a
+// Unix socket.
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cliquesigner-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := newTestSigner(t, dir, rules{maxFuture: 15 * time.Second})
+	defer s.audit.close()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	if err := server.RegisterName("signer", &SignerAPI{s}); err != nil {
+		t.Fatalf("failed to register signer API: %v", err)
+	}
+	endpoint := filepath.Join(dir, "signer.ipc")
+	listener, err := rpc.CreateIPCListener(endpoint)
+	if err != nil {
+		t.Fatalf("failed to open IPC endpoint: %v", err)
+	}
+	defer listener.Close()
+	go server.ServeListener(listener)
+
+	remote, err := clique.DialRemoteSigner(endpoint)
+	if err != nil {
+		t.Fatalf("failed to dial remote signer: %v", err)
+	}
+	defer remote.Close()
+
+	if remote.Address() != s.account.Address {
+		t.Fatalf("remote signer account mismatch: have %x, want %x", remote.Address(), s.account.Address)
+	}
+	header := testHeader(1, 0)
+	signature, err := remote.SignHeader(accounts.Account{Address: s.account.Address}, header)
+	if err != nil {
+		t.Fatalf("failed to sign header remotely: %v", err)
+	}
+	pubkey, err := crypto.SigToPub(clique.SealHash(header).Bytes(), signature)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != s.account.Address {
+		t.Errorf("signer mismatch: have %x, want %x", signer, s.account.Address)
+	}
+	// Rejected requests and foreign accounts must surface as errors
+	conflict := testHeader(1, 0)
+	conflict.Extra[0] = 0xff
+	if _, err := remote.SignHeader(accounts.Account{Address: s.account.Address}, conflict); err == nil {
+		t.Errorf("conflicting header signed remotely")
+	}
+	if _, err := remote.SignHeader(accounts.Account{Address: common.Address{1}}, testHeader(2, 0)); err == nil {
+		t.Errorf("header signed for foreign account")
+	}
+}
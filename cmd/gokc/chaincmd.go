@@ -17,10 +17,12 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"strconv"
 	"sync/atomic"
 	"time"
@@ -35,6 +37,7 @@ import (
 	"github.com/okcoin/go-okcoin/okcdb"
 	"github.com/okcoin/go-okcoin/event"
 	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/params"
 	"github.com/okcoin/go-okcoin/trie"
 	"github.com/syndtr/goleveldb/leveldb/util"
 	"gopkg.in/urfave/cli.v1"
@@ -125,6 +128,25 @@ The first argument must be the directory containing the blockchain to download f
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 Remove blockchain and state databases`,
+	}
+	upgradeConfigCommand = cli.Command{
+		Action:    utils.MigrateFlags(upgradeConfig),
+		Name:      "upgradeconfig",
+		Usage:     "Apply an updated chain config to an existing database",
+		ArgsUsage: "<genesisPath>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.LightModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The upgradeconfig command replaces the chain configuration stored in an existing
+database with the one of the given genesis file, without reinitialising the chain.
+
+Only changes to forks above the local head block are accepted; changes that would
+invalidate already imported blocks are rejected. The differences between the old
+and new configuration are shown for confirmation, and every applied upgrade is
+recorded in the database.`,
 	}
 	dumpCommand = cli.Command{
 		Action:    utils.MigrateFlags(dump),
@@ -380,6 +402,148 @@ func removeDB(ctx *cli.Context) error {
 	return nil
 }
 
+// upgradeConfig replaces the chain config of the existing databases with the
+// one from the given genesis file, if it only affects future blocks.
+func upgradeConfig(ctx *cli.Context) error {
+	genesisPath := ctx.Args().First()
+	if len(genesisPath) == 0 {
+		utils.Fatalf("Must supply path to genesis JSON file")
+	}
+	file, err := os.Open(genesisPath)
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	defer file.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+	}
+	stack, _ := makeConfigNode(ctx)
+
+	for _, name := range []string{"chaindata", "lightchaindata"} {
+		logger := log.New("database", name)
+
+		dbdir := stack.ResolvePath(name)
+		if !common.FileExist(dbdir) {
+			logger.Info("Database doesn't exist, skipping", "path", dbdir)
+			continue
+		}
+		chaindb, err := stack.OpenDatabase(name, 0, 0)
+		if err != nil {
+			utils.Fatalf("Failed to open database: %v", err)
+		}
+		// Reject the upgrade if it conflicts with the imported blocks
+		storedcfg, head, err := core.CheckChainConfigUpgrade(chaindb, genesis)
+		if err != nil {
+			chaindb.Close()
+			utils.Fatalf("Chain config upgrade of %s rejected: %v", name, err)
+		}
+		diff := chainConfigDiff(storedcfg, genesis.Config)
+		if len(diff) == 0 {
+			logger.Info("Chain config unchanged, skipping")
+			chaindb.Close()
+			continue
+		}
+		fmt.Printf("Chain config changes for %s (head block #%d):\n", dbdir, head)
+		for _, line := range diff {
+			fmt.Println(line)
+		}
+		confirm, err := console.Stdin.PromptConfirm("Apply these changes?")
+		switch {
+		case err != nil:
+			chaindb.Close()
+			utils.Fatalf("%v", err)
+		case !confirm:
+			logger.Warn("Chain config upgrade aborted")
+		default:
+			if _, err := core.UpgradeChainConfig(chaindb, genesis); err != nil {
+				chaindb.Close()
+				utils.Fatalf("Failed to upgrade chain config: %v", err)
+			}
+			logger.Info("Successfully upgraded chain config", "head", head)
+		}
+		chaindb.Close()
+	}
+	return nil
+}
+
+// chainConfigDiff returns the fields differing between two chain configs, one
+// line per removed or added value, keyed by their JSON paths.
+func chainConfigDiff(stored, updated *params.ChainConfig) []string {
+	oldFields, newFields := flattenConfig(stored), flattenConfig(updated)
+
+	keys := make(map[string]struct{})
+	for key := range oldFields {
+		keys[key] = struct{}{}
+	}
+	for key := range newFields {
+		keys[key] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var diff []string
+	for _, key := range sorted {
+		oldValue, hadOld := oldFields[key]
+		newValue, hasNew := newFields[key]
+		if hadOld && hasNew && oldValue == newValue {
+			continue
+		}
+		if hadOld {
+			diff = append(diff, fmt.Sprintf("- %s: %s", key, oldValue))
+		}
+		if hasNew {
+			diff = append(diff, fmt.Sprintf("+ %s: %s", key, newValue))
+		}
+	}
+	return diff
+}
+
+// flattenConfig converts a chain config into a map of JSON paths to the JSON
+// encoding of the leaf values.
+func flattenConfig(config *params.ChainConfig) map[string]string {
+	blob, err := json.Marshal(config)
+	if err != nil {
+		utils.Fatalf("Failed to encode chain config: %v", err)
+	}
+	var tree interface{}
+	decoder := json.NewDecoder(bytes.NewReader(blob))
+	decoder.UseNumber()
+	if err := decoder.Decode(&tree); err != nil {
+		utils.Fatalf("Failed to decode chain config: %v", err)
+	}
+	fields := make(map[string]string)
+	flattenJSON("", tree, fields)
+	return fields
+}
+
+// flattenJSON collects the leaf values of a decoded JSON tree into fields.
+func flattenJSON(path string, value interface{}, fields map[string]string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if path == "" {
+				flattenJSON(key, child, fields)
+			} else {
+				flattenJSON(path+"."+key, child, fields)
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			flattenJSON(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	case nil:
+		// Unset values are simply omitted from the diff
+	default:
+		blob, _ := json.Marshal(value)
+		fields[path] = string(blob)
+	}
+}
+
 func dump(ctx *cli.Context) error {
 	stack := makeFullNode(ctx)
 	chain, chainDb := utils.MakeChain(ctx, stack)
@@ -0,0 +1,225 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okcoin/go-okcoin/accounts/abi/bind"
+	"github.com/okcoin/go-okcoin/accounts/keystore"
+	"github.com/okcoin/go-okcoin/cmd/utils"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/contracts/checkpointoracle"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okcclient"
+	"github.com/okcoin/go-okcoin/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	checkpointAttachFlag = cli.StringFlag{
+		Name:  "attach",
+		Value: node.DefaultIPCEndpoint(clientIdentifier),
+		Usage: "API endpoint of the light server to attach to",
+	}
+	checkpointOracleFlag = cli.StringFlag{
+		Name:  "oracle",
+		Usage: "Address of the checkpoint oracle contract",
+	}
+	checkpointIndexFlag = cli.Int64Flag{
+		Name:  "index",
+		Value: -1,
+		Usage: "Section index of the checkpoint (default = latest processed by the server)",
+	}
+	checkpointAccountFlag = cli.StringFlag{
+		Name:  "account",
+		Usage: "Account signing the checkpoint or sending the publishing transaction",
+	}
+	checkpointCommand = cli.Command{
+		Name:     "checkpoint",
+		Usage:    "Manage the light client checkpoint oracle",
+		Category: "MISCELLANEOUS COMMANDS",
+		Description: `
+The checkpoint oracle is a contract in which a threshold of trusted admins approve
+the checkpoints (CHT and BloomTrie roots of a section) light clients start syncing
+from. The checkpoints are read from a running light server.`,
+		Subcommands: []cli.Command{
+			{
+				Name:   "sign",
+				Usage:  "Sign a checkpoint as an oracle admin",
+				Action: utils.MigrateFlags(signCheckpoint),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					checkpointAttachFlag,
+					checkpointOracleFlag,
+					checkpointIndexFlag,
+					checkpointAccountFlag,
+				},
+				Description: `
+    gokc checkpoint sign --oracle <address> --account <address> [--index <section>]
+
+retrieves the checkpoint of the section from the attached light server and signs
+it with the admin account for the given oracle, printing the signature.`,
+			},
+			{
+				Name:      "publish",
+				Usage:     "Register a checkpoint approved by the oracle admins",
+				ArgsUsage: "<signature> [<signature>...]",
+				Action:    utils.MigrateFlags(publishCheckpoint),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					checkpointAttachFlag,
+					checkpointOracleFlag,
+					checkpointIndexFlag,
+					checkpointAccountFlag,
+				},
+				Description: `
+    gokc checkpoint publish --oracle <address> --account <address> [--index <section>] <signatures>
+
+retrieves the checkpoint of the section from the attached light server, verifies
+that the admin signatures approve it and registers it in the oracle with a
+transaction sent from the given account.`,
+			},
+		},
+	}
+)
+
+// retrieveCheckpoint attaches to the light server and retrieves the checkpoint
+// requested on the command line.
+func retrieveCheckpoint(ctx *cli.Context) (*rpc.Client, *checkpointoracle.Checkpoint) {
+	client, err := dialRPC(ctx.String(checkpointAttachFlag.Name))
+	if err != nil {
+		utils.Fatalf("Unable to attach to gokc node: %v", err)
+	}
+	checkpoint := new(checkpointoracle.Checkpoint)
+	if index := ctx.Int64(checkpointIndexFlag.Name); index >= 0 {
+		err = client.Call(checkpoint, "les_getCheckpoint", hexutil.Uint64(index))
+	} else {
+		err = client.Call(checkpoint, "les_latestCheckpoint")
+	}
+	if err != nil {
+		utils.Fatalf("Failed to retrieve checkpoint: %v", err)
+	}
+	fmt.Printf("Checkpoint #%d\n", checkpoint.SectionIndex)
+	fmt.Printf("  Section head:    %x\n", checkpoint.SectionHead)
+	fmt.Printf("  CHT root:        %x\n", checkpoint.CHTRoot)
+	fmt.Printf("  BloomTrie root:  %x\n", checkpoint.BloomTrieRoot)
+
+	return client, checkpoint
+}
+
+// checkpointOracle returns the oracle address given on the command line.
+func checkpointOracle(ctx *cli.Context) common.Address {
+	if !common.IsHexAddress(ctx.String(checkpointOracleFlag.Name)) {
+		utils.Fatalf("Invalid checkpoint oracle address %q", ctx.String(checkpointOracleFlag.Name))
+	}
+	return common.HexToAddress(ctx.String(checkpointOracleFlag.Name))
+}
+
+// signCheckpoint signs a checkpoint retrieved from a light server with an oracle
+// admin account.
+func signCheckpoint(ctx *cli.Context) error {
+	oracle := checkpointOracle(ctx)
+
+	client, checkpoint := retrieveCheckpoint(ctx)
+	defer client.Close()
+
+	stack, _ := makeConfigNode(ctx)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, _ := unlockAccount(ctx, ks, ctx.String(checkpointAccountFlag.Name), 0, utils.MakePasswordList(ctx))
+
+	sig, err := ks.SignHash(account, checkpoint.SigHash(oracle).Bytes())
+	if err != nil {
+		utils.Fatalf("Failed to sign checkpoint: %v", err)
+	}
+	fmt.Printf("Signature:         %s\n", hexutil.Encode(sig))
+	return nil
+}
+
+// publishCheckpoint registers a checkpoint retrieved from a light server in the
+// oracle, if approved by the given admin signatures.
+func publishCheckpoint(ctx *cli.Context) error {
+	if len(ctx.Args()) == 0 {
+		utils.Fatalf("No checkpoint signatures specified")
+	}
+	sigs := make([][]byte, len(ctx.Args()))
+	for i, arg := range ctx.Args() {
+		sig, err := hexutil.Decode(arg)
+		if err != nil || len(sig) != 65 {
+			utils.Fatalf("Invalid checkpoint signature %q", arg)
+		}
+		sigs[i] = sig
+	}
+	address := checkpointOracle(ctx)
+
+	client, checkpoint := retrieveCheckpoint(ctx)
+	defer client.Close()
+
+	backend := okcclient.NewClient(client)
+	oracle, err := checkpointoracle.NewCheckpointOracle(address, backend, backend)
+	if err != nil {
+		utils.Fatalf("Failed to bind checkpoint oracle: %v", err)
+	}
+	// Ensure the signatures approve the checkpoint before paying for the transaction
+	admins, err := oracle.Admins(nil)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve oracle admins: %v", err)
+	}
+	threshold, err := oracle.Threshold(nil)
+	if err != nil {
+		utils.Fatalf("Failed to retrieve oracle threshold: %v", err)
+	}
+	if err := checkpoint.Verify(address, sigs, admins, threshold); err != nil {
+		utils.Fatalf("Checkpoint not approved: %v", err)
+	}
+	if latest, _, _, err := oracle.LatestCheckpoint(nil); err != nil {
+		utils.Fatalf("Failed to retrieve latest checkpoint: %v", err)
+	} else if latest != nil && latest.SectionIndex >= checkpoint.SectionIndex {
+		utils.Fatalf("Checkpoint #%d already superseded by #%d", checkpoint.SectionIndex, latest.SectionIndex)
+	}
+	// Register the checkpoint with a transaction signed by the sender account
+	stack, _ := makeConfigNode(ctx)
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, _ := unlockAccount(ctx, ks, ctx.String(checkpointAccountFlag.Name), 0, utils.MakePasswordList(ctx))
+
+	opts := &bind.TransactOpts{
+		From:    account.Address,
+		Context: context.Background(),
+		Signer: func(signer types.Signer, from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			sig, err := ks.SignHash(account, signer.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(signer, sig)
+		},
+	}
+	tx, err := oracle.RegisterCheckpoint(opts, checkpoint, sigs)
+	if err != nil {
+		utils.Fatalf("Failed to publish checkpoint: %v", err)
+	}
+	fmt.Printf("Transaction:       %x\n", tx.Hash())
+	return nil
+}
@@ -152,7 +152,12 @@ func enableWhisper(ctx *cli.Context) bool {
 
 func makeFullNode(ctx *cli.Context) *node.Node {
 	stack, cfg := makeConfigNode(ctx)
+	registerServices(ctx, stack, cfg)
+	return stack
+}
 
+// registerServices registers the services enabled by the configuration on a node.
+func registerServices(ctx *cli.Context, stack *node.Node, cfg gokcConfig) {
 	utils.RegisterOkcService(stack, &cfg.Okc)
 
 	if ctx.GlobalBool(utils.DashboardEnabledFlag.Name) {
@@ -171,11 +176,20 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Add the GraphQL server if requested.
+	if cfg.Node.GraphQLHost != "" {
+		utils.RegisterGraphQLService(stack, cfg.Node.GraphQLEndpoint(), cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts)
+	}
+
+	// Add the gRPC server if requested.
+	if cfg.Node.GRPCHost != "" {
+		utils.RegisterGRPCService(stack, cfg.Node.GRPCEndpoint())
+	}
+
 	// Add the Okcoin Stats daemon if requested.
 	if cfg.Okcstats.URL != "" {
 		utils.RegisterOkcStatsService(stack, cfg.Okcstats.URL)
 	}
-	return stack
 }
 
 // dumpConfig is the dumpconfig command.
@@ -85,6 +85,7 @@ var (
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
+		utils.MaxReorgDepthFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
 		utils.LightKDFFlag,
@@ -121,6 +122,19 @@ var (
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.ExtraDataFlag,
+		utils.MinerGasTargetFlag,
+		utils.MinerGasLimitFlag,
+		utils.MinerRecommitIntervalFlag,
+		utils.MinerNotifyFlag,
+		utils.MinerSignerFlag,
+		utils.MinerOrderingFlag,
+		utils.MinerPrioritySendersFlag,
+		utils.MinerSystemSendersFlag,
+		utils.MinerSystemGasShareFlag,
+		utils.MinerStratumFlag,
+		utils.MinerStratumDiffFlag,
+		utils.MinerStratumMinDiffFlag,
+		utils.MinerStratumExtranonceFlag,
 		configFileFlag,
 	}
 
@@ -134,6 +148,24 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.WSVirtualHostsFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLListenAddrFlag,
+		utils.GraphQLPortFlag,
+		utils.GraphQLCORSDomainFlag,
+		utils.GraphQLVirtualHostsFlag,
+		utils.GRPCEnabledFlag,
+		utils.GRPCListenAddrFlag,
+		utils.GRPCPortFlag,
+		utils.RPCAuthSecretFlag,
+		utils.RPCAuthPolicyFlag,
+		utils.RPCLimitRateFlag,
+		utils.RPCLimitBurstFlag,
+		utils.RPCLimitCostsFlag,
+		utils.RPCLimitRequestSizeFlag,
+		utils.RPCLimitBatchItemsFlag,
+		utils.RPCLimitResponseSizeFlag,
+		utils.RPCLimitTimeoutFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -157,9 +189,16 @@ func init() {
 		exportCommand,
 		copydbCommand,
 		removedbCommand,
+		upgradeConfigCommand,
 		dumpCommand,
+		// See replaycmd.go:
+		replayCommand,
+		// See checkpointcmd.go:
+		checkpointCommand,
 		// See monitorcmd.go:
 		monitorCommand,
+		// See openrpccmd.go:
+		openrpcCommand,
 		// See accountcmd.go:
 		accountCommand,
 		walletCommand,
@@ -170,6 +209,7 @@ func init() {
 		// See misccmd.go:
 		makecacheCommand,
 		makedagCommand,
+		pregendagCommand,
 		versionCommand,
 		bugCommand,
 		licenseCommand,
@@ -19,12 +19,17 @@ package main
 import (
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/okcoin/go-okcoin/cmd/utils"
+	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/log"
 	"github.com/okcoin/go-okcoin/okc"
 	"github.com/okcoin/go-okcoin/params"
 	"gopkg.in/urfave/cli.v1"
@@ -55,6 +60,20 @@ The makedag command generates an okcash DAG in <outputDir>.
 
 This command exists to support the system testing project.
 Regular users do not need to execute it.
+`,
+	}
+	pregendagCommand = cli.Command{
+		Action:    utils.MigrateFlags(pregendag),
+		Name:      "pregendag",
+		Usage:     "Pre-generate the okcash mining DAGs of upcoming epochs",
+		ArgsUsage: "<blockNum> <epochs> [<outputDir>]",
+		Category:  "MISCELLANEOUS COMMANDS",
+		Description: `
+The pregendag command ensures the okcash DAGs of <epochs> epochs, starting with
+the one of <blockNum>, are available in <outputDir> (default = the DAG directory
+of the node). DAGs already present are verified against their expected size and
+a sample of their items, and regenerated if corrupted. The generation runs in the
+background and can be interrupted, stopping once the DAG in progress is written.
 `,
 	}
 	versionCommand = cli.Command{
@@ -86,6 +105,7 @@ func makecache(ctx *cli.Context) error {
 	if err != nil {
 		utils.Fatalf("Invalid block number: %v", err)
 	}
+	defer reportGeneration()()
 	okcash.MakeCache(block, args[1])
 
 	return nil
@@ -101,11 +121,82 @@ func makedag(ctx *cli.Context) error {
 	if err != nil {
 		utils.Fatalf("Invalid block number: %v", err)
 	}
+	defer reportGeneration()()
 	okcash.MakeDataset(block, args[1])
 
 	return nil
 }
 
+// pregendag generates the okcash mining DAGs of upcoming epochs into the provided
+// folder, or the default DAG folder if none given. The generation runs in the
+// background, an interrupt stops it once the DAG in progress is written.
+func pregendag(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 && len(args) != 3 {
+		utils.Fatalf(`Usage: gokc pregendag <block number> <epochs> [<outputdir>]`)
+	}
+	block, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		utils.Fatalf("Invalid block number: %v", err)
+	}
+	epochs, err := strconv.Atoi(args[1])
+	if err != nil || epochs <= 0 {
+		utils.Fatalf("Invalid epoch count: %s", args[1])
+	}
+	dir := okc.DefaultConfig.Okcash.DatasetDir
+	if len(args) == 3 {
+		dir = args[2]
+	}
+	defer reportGeneration()()
+
+	abort, done := make(chan struct{}), make(chan error, 1)
+	go func() {
+		done <- okcash.PregenerateDatasets(block, epochs, dir, abort)
+	}()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	for {
+		select {
+		case <-interrupt:
+			if abort != nil {
+				log.Info("Interrupted, stopping after the DAG in progress")
+				close(abort)
+				abort = nil
+			}
+		case err := <-done:
+			if err != nil && err != okcash.ErrPregenerationAborted {
+				utils.Fatalf("Failed to generate DAGs: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// reportGeneration logs the progress of the okcash generations until the
+// returned function is called.
+func reportGeneration() func() {
+	updates := make(chan okcash.GenerationProgress, 16)
+	sub := okcash.SubscribeGenerationProgress(updates)
+
+	go func() {
+		for {
+			select {
+			case update := <-updates:
+				if update.Done {
+					log.Info("Okcash generation done", "kind", update.Kind, "epoch", update.Epoch, "elapsed", common.PrettyDuration(time.Duration(update.Elapsed)*time.Second))
+				} else {
+					log.Info("Okcash generation in progress", "kind", update.Kind, "epoch", update.Epoch, "percentage", update.Percentage, "eta", common.PrettyDuration(time.Duration(update.ETA)*time.Second))
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub.Unsubscribe
+}
+
 func version(ctx *cli.Context) error {
 	fmt.Println(strings.Title(clientIdentifier))
 	fmt.Println("Version:", params.Version)
@@ -0,0 +1,108 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/okcoin/go-okcoin/cmd/utils"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var openrpcGenesisFlag = cli.StringFlag{
+	Name:  "genesis",
+	Usage: "Genesis JSON file of the chain, to describe the APIs of the consensus engine of custom networks",
+}
+
+var openrpcCommand = cli.Command{
+	Action:    utils.MigrateFlags(writeOpenRPC),
+	Name:      "openrpc",
+	Usage:     "Write the OpenRPC document describing the RPC API to a file",
+	ArgsUsage: "<file>",
+	Flags:     append([]cli.Flag{openrpcGenesisFlag}, nodeFlags...),
+	Category:  "MISCELLANEOUS COMMANDS",
+	Description: `
+The openrpc command builds the services of a node configured by the given flags,
+retrieves the description of all their RPC methods and subscriptions through
+rpc_discover and writes it as an OpenRPC JSON document to the given file, for use
+by client code generators. The document covers every API of the node, regardless
+of the ones exposed over HTTP, websocket or IPC.
+
+The node is not started: it neither connects to the network nor opens or locks
+its data directory, so the command can run alongside a running instance. As the
+chain configuration isn't read from the data directory either, the services are
+built on the genesis of the selected network: the APIs of the consensus engine
+of a custom network (e.g. the clique or bft namespaces) are only described when
+its genesis JSON file is given with --genesis.`,
+}
+
+// writeOpenRPC writes the OpenRPC document of a node to the file given as argument.
+func writeOpenRPC(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	stack, cfg := makeConfigNode(ctx)
+	if path := ctx.String(openrpcGenesisFlag.Name); path != "" {
+		cfg.Okc.Genesis = readGenesis(path)
+	}
+	registerServices(ctx, stack, cfg)
+
+	handler, err := stack.APIServer()
+	if err != nil {
+		utils.Fatalf("Failed to build the RPC APIs: %v", err)
+	}
+	client := rpc.DialInProc(handler)
+	defer client.Close()
+
+	var doc json.RawMessage
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		utils.Fatalf("Failed to retrieve the OpenRPC document: %v", err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, doc, "", "  "); err != nil {
+		utils.Fatalf("Invalid OpenRPC document: %v", err)
+	}
+	out.WriteByte('\n')
+
+	file := ctx.Args().First()
+	if err := ioutil.WriteFile(file, out.Bytes(), 0644); err != nil {
+		utils.Fatalf("Failed to write the OpenRPC document: %v", err)
+	}
+	log.Info("Wrote OpenRPC document", "file", file)
+	return nil
+}
+
+// readGenesis reads a genesis JSON file.
+func readGenesis(path string) *core.Genesis {
+	file, err := os.Open(path)
+	if err != nil {
+		utils.Fatalf("Failed to read genesis file: %v", err)
+	}
+	defer file.Close()
+
+	genesis := new(core.Genesis)
+	if err := json.NewDecoder(file).Decode(genesis); err != nil {
+		utils.Fatalf("Invalid genesis file: %v", err)
+	}
+	return genesis
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Tests that the OpenRPC document describes the APIs of the consensus engine of
+// a custom network only if its genesis is given, the data directory not being read.
+func TestOpenRPCGenesis(t *testing.T) {
+	datadir := tmpdir(t)
+	defer os.RemoveAll(datadir)
+
+	genesis := filepath.Join(datadir, "genesis.json")
+	if err := ioutil.WriteFile(genesis, []byte(`{
+		"config"     : {"chainId": 1337, "clique": {"period": 5, "epoch": 30000}},
+		"alloc"      : {},
+		"difficulty" : "0x1",
+		"extraData"  : "0x00000000000000000000000000000000000000000000000000000000000000008888f1f195afa192cfee860698584c030f4c9db10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+		"gasLimit"   : "0x2fefd8"
+	}`), 0600); err != nil {
+		t.Fatalf("failed to write genesis file: %v", err)
+	}
+	runGokc(t, "--datadir", datadir, initCommand.Name, genesis).WaitExit()
+
+	for _, tt := range []struct {
+		args   []string
+		clique bool
+	}{
+		{args: nil, clique: false},
+		{args: []string{"--genesis", genesis}, clique: true},
+	} {
+		doc := filepath.Join(datadir, "openrpc.json")
+		args := append([]string{"openrpc", "--datadir", datadir}, tt.args...)
+		runGokc(t, append(args, doc)...).WaitExit()
+
+		blob, err := ioutil.ReadFile(doc)
+		if err != nil {
+			t.Fatalf("failed to read OpenRPC document written with %v: %v", tt.args, err)
+		}
+		if have := strings.Contains(string(blob), "clique_getSnapshot"); have != tt.clique {
+			t.Errorf("clique API presence mismatch with %v: have %v, want %v", tt.args, have, tt.clique)
+		}
+		os.Remove(doc)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/okcoin/go-okcoin/cmd/utils"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/misc"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/state"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	replayReexecFlag = cli.Uint64Flag{
+		Name:  "reexec",
+		Value: 128,
+		Usage: "Maximum number of blocks to re-execute to regenerate the starting state",
+	}
+	replayCommand = cli.Command{
+		Action:    utils.MigrateFlags(replay),
+		Name:      "replay",
+		Usage:     "Re-execute a range of blocks and verify their results",
+		ArgsUsage: "<firstBlock> [<lastBlock>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			replayReexecFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The replay command re-executes the given range of blocks on top of the state of
+the block preceding them, regenerated from the nearest available state if it is
+not stored. The resulting state roots and receipts are compared against the ones
+stored in the database, which is not modified.
+
+On the first mismatch the block is re-executed transaction by transaction to find
+the one the execution diverged at. It is reported along with the accounts it
+changed and, if the state of the block is stored, the accounts touched by the
+block whose replayed state differs from it.`,
+	}
+)
+
+// replay re-executes a range of blocks, verifying their state roots and receipts.
+func replay(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 || len(ctx.Args()) > 2 {
+		utils.Fatalf("This command requires one or two arguments.")
+	}
+	first, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	if err != nil {
+		utils.Fatalf("Invalid first block number: %v", err)
+	}
+	last := first
+	if len(ctx.Args()) == 2 {
+		if last, err = strconv.ParseUint(ctx.Args().Get(1), 10, 64); err != nil {
+			utils.Fatalf("Invalid last block number: %v", err)
+		}
+	}
+	if first == 0 || last < first {
+		utils.Fatalf("Invalid block range #%d-#%d", first, last)
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	if err := replayRange(os.Stdout, chain, chainDb, first, last, ctx.Uint64(replayReexecFlag.Name)); err != nil {
+		utils.Fatalf("%v", err)
+	}
+	return nil
+}
+
+// replayRange re-executes a range of blocks on top of the nearest available
+// state, writing the details of the first divergence found to out.
+func replayRange(out io.Writer, chain *core.BlockChain, chainDb okcdb.Database, first, last uint64, reexec uint64) error {
+	if head := chain.CurrentBlock().NumberU64(); last > head {
+		return fmt.Errorf("block #%d above the current head #%d", last, head)
+	}
+	// Find the nearest available state to start re-executing from
+	database := state.NewDatabase(chainDb)
+
+	base, statedb, err := replayBase(chain, database, first-1, reexec)
+	if err != nil {
+		return fmt.Errorf("failed to find starting state: %v", err)
+	}
+	if base.NumberU64() < first-1 {
+		log.Info("Regenerating starting state", "base", base.NumberU64(), "first", first)
+	}
+	var (
+		start  = time.Now()
+		logged time.Time
+		proot  common.Hash
+	)
+	for number := base.NumberU64() + 1; number <= last; number++ {
+		// Print progress logs if long enough time elapsed
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Replaying blocks", "block", number, "last", last, "elapsed", time.Since(start))
+			logged = time.Now()
+		}
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block #%d not found", number)
+		}
+		// Re-execute the block and report the divergence in detail on mismatch
+		pre := statedb.Copy()
+		if err := replayBlock(chain, chainDb, block, statedb); err != nil {
+			fmt.Fprintf(out, "Block #%d [%x] diverged: %v\n", number, block.Hash(), err)
+			reportDivergence(out, chain, chainDb, database, block, pre, statedb)
+			return fmt.Errorf("replay diverged at block #%d", number)
+		}
+		// Finalize the state so any modifications are written to the trie
+		root, err := statedb.Commit(chain.Config().IsEIP158(block.Number()))
+		if err != nil {
+			return fmt.Errorf("failed to commit state of block #%d: %v", number, err)
+		}
+		if err := statedb.Reset(root); err != nil {
+			return fmt.Errorf("failed to reset state of block #%d: %v", number, err)
+		}
+		database.TrieDB().Reference(root, common.Hash{})
+		database.TrieDB().Dereference(proot, common.Hash{})
+		proot = root
+	}
+	log.Info("Replayed blocks without divergence", "first", first, "last", last, "elapsed", time.Since(start))
+	return nil
+}
+
+// replayBase returns the nearest block at or below the given number whose state
+// is available, searching at most reexec blocks back.
+func replayBase(chain *core.BlockChain, database state.Database, number uint64, reexec uint64) (*types.Block, *state.StateDB, error) {
+	block := chain.GetBlockByNumber(number)
+	if block == nil {
+		return nil, nil, fmt.Errorf("block #%d not found", number)
+	}
+	for i := uint64(0); ; i++ {
+		if statedb, err := state.New(block.Root(), database); err == nil {
+			return block, statedb, nil
+		}
+		if i == reexec || block.NumberU64() == 0 {
+			return nil, nil, fmt.Errorf("no state available within %d blocks of #%d", reexec, number)
+		}
+		if block = chain.GetBlock(block.ParentHash(), block.NumberU64()-1); block == nil {
+			return nil, nil, fmt.Errorf("ancestor of block #%d missing", number)
+		}
+	}
+}
+
+// replayBlock re-executes a block on top of the state of its parent, verifying
+// the results against the block header and the stored receipts.
+func replayBlock(chain *core.BlockChain, db okcdb.Database, block *types.Block, statedb *state.StateDB) error {
+	receipts, _, usedGas, err := chain.Processor().Process(block, statedb, vm.Config{})
+	if err != nil {
+		return err
+	}
+	parent := chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if err := chain.Validator().ValidateState(block, parent, statedb, receipts, usedGas); err != nil {
+		return err
+	}
+	stored := core.GetBlockReceipts(db, block.Hash(), block.NumberU64())
+	if len(stored) != len(receipts) {
+		return fmt.Errorf("stored receipt count mismatch: have %d, want %d", len(receipts), len(stored))
+	}
+	for i, receipt := range receipts {
+		if err := compareReceipts(receipt, stored[i]); err != nil {
+			return fmt.Errorf("receipt %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// compareReceipts returns an error describing the first difference between the
+// replayed and stored receipts.
+func compareReceipts(have, want *types.Receipt) error {
+	switch {
+	case have.Status != want.Status:
+		return fmt.Errorf("status mismatch: have %d, want %d", have.Status, want.Status)
+	case !bytes.Equal(have.PostState, want.PostState):
+		return fmt.Errorf("post state mismatch: have %x, want %x", have.PostState, want.PostState)
+	case have.CumulativeGasUsed != want.CumulativeGasUsed:
+		return fmt.Errorf("cumulative gas used mismatch: have %d, want %d", have.CumulativeGasUsed, want.CumulativeGasUsed)
+	case have.GasUsed != want.GasUsed:
+		return fmt.Errorf("gas used mismatch: have %d, want %d", have.GasUsed, want.GasUsed)
+	case have.ContractAddress != want.ContractAddress:
+		return fmt.Errorf("contract address mismatch: have %x, want %x", have.ContractAddress, want.ContractAddress)
+	case len(have.Logs) != len(want.Logs):
+		return fmt.Errorf("log count mismatch: have %d, want %d", len(have.Logs), len(want.Logs))
+	case have.Bloom != want.Bloom:
+		return fmt.Errorf("bloom mismatch: have %x, want %x", have.Bloom, want.Bloom)
+	}
+	return nil
+}
+
+// reportDivergence re-executes a diverging block transaction by transaction on
+// top of the given parent state, reporting the first transaction whose receipt
+// differs from the stored one along with the accounts it changed. If the state
+// of the block is stored, the accounts touched by the block whose replayed
+// state differs from it are reported too.
+func reportDivergence(out io.Writer, chain *core.BlockChain, db okcdb.Database, database state.Database, block *types.Block, statedb, post *state.StateDB) {
+	var (
+		config   = chain.Config()
+		header   = block.Header()
+		stored   = core.GetBlockReceipts(db, block.Hash(), block.NumberU64())
+		receipts types.Receipts
+		usedGas  = new(uint64)
+		gp       = new(core.GasPool).AddGas(block.GasLimit())
+		touched  = newTouchTracer()
+	)
+	if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(block.Number()) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+
+		touched.touch(params.DAORefundContract)
+		for _, addr := range params.DAODrainList() {
+			touched.touch(addr)
+		}
+	}
+	diverged := false
+	for i, tx := range block.Transactions() {
+		pre, tracer := statedb.Copy(), newTouchTracer()
+		tracer.touch(header.Coinbase)
+
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		receipt, _, err := core.ApplyTransaction(config, chain, nil, gp, statedb, header, tx, usedGas, vm.Config{Debug: true, Tracer: tracer})
+		touched.merge(tracer)
+
+		switch {
+		case err != nil:
+		case i >= len(stored):
+			err = fmt.Errorf("stored receipt missing")
+		default:
+			err = compareReceipts(receipt, stored[i])
+		}
+		if err != nil {
+			fmt.Fprintf(out, "Transaction %d [%x] diverged: %v\n", i, tx.Hash(), err)
+			fmt.Fprintln(out, "Accounts changed by the transaction (pre, post):")
+			printAccountDiff(out, tracer, pre, statedb)
+			diverged = true
+			break
+		}
+		receipts = append(receipts, receipt)
+	}
+	if !diverged {
+		fmt.Fprintln(out, "All transactions match the stored receipts, block finalization diverged")
+
+		pre, tracer := statedb.Copy(), newTouchTracer()
+		tracer.touch(header.Coinbase)
+		for _, uncle := range block.Uncles() {
+			tracer.touch(uncle.Coinbase)
+		}
+		chain.Engine().Finalize(chain, header, statedb, block.Transactions(), block.Uncles(), receipts)
+		touched.merge(tracer)
+
+		fmt.Fprintln(out, "Accounts changed by the finalization (pre, post):")
+		printAccountDiff(out, tracer, pre, statedb)
+	}
+	// Compare the replayed post state against the stored one, if available
+	want, err := state.New(block.Root(), database)
+	if err != nil {
+		fmt.Fprintf(out, "State of block #%d not available, skipping post state comparison\n", block.NumberU64())
+		return
+	}
+	fmt.Fprintln(out, "Accounts differing from the stored post state (replayed, stored):")
+	printAccountDiff(out, touched, post, want)
+}
+
+// touchTracer is an EVM tracer collecting the accounts a transaction may have
+// changed.
+type touchTracer struct {
+	accounts map[common.Address]struct{}
+}
+
+func newTouchTracer() *touchTracer {
+	return &touchTracer{accounts: make(map[common.Address]struct{})}
+}
+
+// touch marks an account as possibly changed.
+func (t *touchTracer) touch(addr common.Address) {
+	t.accounts[addr] = struct{}{}
+}
+
+// merge adds the accounts touched according to another tracer.
+func (t *touchTracer) merge(other *touchTracer) {
+	for addr := range other.accounts {
+		t.touch(addr)
+	}
+}
+
+func (t *touchTracer) CaptureStart(from common.Address, to common.Address, call bool, input []byte, gas uint64, value *big.Int) error {
+	t.touch(from)
+	t.touch(to)
+	return nil
+}
+
+func (t *touchTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	self := contract.Address()
+	t.touch(self)
+
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		t.touch(common.BigToAddress(stack.Back(1)))
+	case vm.CREATE:
+		t.touch(crypto.CreateAddress(self, env.StateDB.GetNonce(self)))
+	case vm.SELFDESTRUCT:
+		t.touch(common.BigToAddress(stack.Back(0)))
+	}
+	return nil
+}
+
+func (t *touchTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+func (t *touchTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// printAccountDiff prints the touched accounts differing between two states,
+// with their full dumps from both of them.
+func printAccountDiff(out io.Writer, touched *touchTracer, a, b *state.StateDB) {
+	addrs := make([]common.Address, 0, len(touched.accounts))
+	for addr := range touched.accounts {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	haveDump, wantDump := a.RawDumpAccounts(addrs), b.RawDumpAccounts(addrs)
+	encode := func(account state.DumpAccount, ok bool) string {
+		if !ok {
+			return "missing"
+		}
+		blob, _ := json.Marshal(account)
+		return string(blob)
+	}
+	changed := 0
+	for _, addr := range addrs {
+		key := common.Bytes2Hex(addr[:])
+		have, haveOk := haveDump.Accounts[key]
+		want, wantOk := wantDump.Accounts[key]
+		if haveOk == wantOk && reflect.DeepEqual(have, want) {
+			continue
+		}
+		fmt.Fprintf(out, "  %x\n    %s\n    %s\n", addr, encode(have, haveOk), encode(want, wantOk))
+		changed++
+	}
+	if changed == 0 {
+		fmt.Fprintln(out, "  none")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of go-okcoin.
+//
+// go-okcoin is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-okcoin is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-okcoin. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// Tests that replaying a chain with a corrupted receipt reports the transaction
+// it belongs to, diffing only the accounts the transaction touched.
+func TestReplayCorruptedReceipt(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		sender     = crypto.PubkeyToAddress(key.PublicKey)
+		bystander  = common.HexToAddress("0xb1")
+		recipients = []common.Address{common.HexToAddress("0xa1"), common.HexToAddress("0xa2")}
+		db, _      = okcdb.NewMemDatabase()
+		gspec      = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				sender:    {Balance: big.NewInt(1000000000000000000)},
+				bystander: {Balance: big.NewInt(1)},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+	)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, okcash.NewFaker(), db, 3, func(i int, block *core.BlockGen) {
+		for _, recipient := range recipients {
+			tx, err := types.SignTx(types.NewTransaction(block.TxNonce(sender), recipient, big.NewInt(1000), params.TxGas, big.NewInt(1), nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			block.AddTx(tx)
+		}
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, okcash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// An intact chain replays without divergence
+	out := new(bytes.Buffer)
+	if err := replayRange(out, chain, db, 1, 3, 128); err != nil {
+		t.Fatalf("failed to replay intact chain: %v\n%s", err, out)
+	}
+	// Corrupt the receipt of the second transaction of the second block
+	block := blocks[1]
+	receipts := core.GetBlockReceipts(db, block.Hash(), block.NumberU64())
+	receipts[1].GasUsed++
+	if err := core.WriteBlockReceipts(db, block.Hash(), block.NumberU64(), receipts); err != nil {
+		t.Fatalf("failed to corrupt receipts: %v", err)
+	}
+	out.Reset()
+	if err := replayRange(out, chain, db, 1, 3, 128); err == nil {
+		t.Fatalf("corrupted receipt replayed without divergence")
+	}
+	report := out.String()
+
+	if want := fmt.Sprintf("Block #2 [%x] diverged", block.Hash()); !strings.Contains(report, want) {
+		t.Errorf("diverging block not reported, want %q in:\n%s", want, report)
+	}
+	if want := fmt.Sprintf("Transaction 1 [%x] diverged: gas used mismatch", block.Transactions()[1].Hash()); !strings.Contains(report, want) {
+		t.Errorf("diverging transaction not reported, want %q in:\n%s", want, report)
+	}
+	// Only the accounts touched by the transaction are diffed
+	if want := fmt.Sprintf("%x", recipients[1]); !strings.Contains(report, want) {
+		t.Errorf("recipient of diverging transaction not reported:\n%s", report)
+	}
+	for _, addr := range []common.Address{recipients[0], bystander} {
+		if strings.Contains(report, fmt.Sprintf("%x", addr)) {
+			t.Errorf("untouched account %x reported:\n%s", addr, report)
+		}
+	}
+}
@@ -70,6 +70,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RinkebyFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.MaxReorgDepthFlag,
 			utils.OkcStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightServFlag,
@@ -147,6 +148,24 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.WSVirtualHostsFlag,
+			utils.GraphQLEnabledFlag,
+			utils.GraphQLListenAddrFlag,
+			utils.GraphQLPortFlag,
+			utils.GraphQLCORSDomainFlag,
+			utils.GraphQLVirtualHostsFlag,
+			utils.GRPCEnabledFlag,
+			utils.GRPCListenAddrFlag,
+			utils.GRPCPortFlag,
+			utils.RPCAuthSecretFlag,
+			utils.RPCAuthPolicyFlag,
+			utils.RPCLimitRateFlag,
+			utils.RPCLimitBurstFlag,
+			utils.RPCLimitCostsFlag,
+			utils.RPCLimitRequestSizeFlag,
+			utils.RPCLimitBatchItemsFlag,
+			utils.RPCLimitResponseSizeFlag,
+			utils.RPCLimitTimeoutFlag,
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
@@ -182,6 +201,19 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TargetGasLimitFlag,
 			utils.GasPriceFlag,
 			utils.ExtraDataFlag,
+			utils.MinerGasTargetFlag,
+			utils.MinerGasLimitFlag,
+			utils.MinerRecommitIntervalFlag,
+			utils.MinerNotifyFlag,
+			utils.MinerSignerFlag,
+			utils.MinerOrderingFlag,
+			utils.MinerPrioritySendersFlag,
+			utils.MinerSystemSendersFlag,
+			utils.MinerSystemGasShareFlag,
+			utils.MinerStratumFlag,
+			utils.MinerStratumDiffFlag,
+			utils.MinerStratumMinDiffFlag,
+			utils.MinerStratumExtranonceFlag,
 		},
 	},
 	{
@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -33,6 +34,7 @@ import (
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/common/fdlimit"
 	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/consensus/bft"
 	"github.com/okcoin/go-okcoin/consensus/clique"
 	"github.com/okcoin/go-okcoin/consensus/okcash"
 	"github.com/okcoin/go-okcoin/core"
@@ -40,14 +42,17 @@ import (
 	"github.com/okcoin/go-okcoin/core/vm"
 	"github.com/okcoin/go-okcoin/crypto"
 	"github.com/okcoin/go-okcoin/dashboard"
+	"github.com/okcoin/go-okcoin/graphql"
 	"github.com/okcoin/go-okcoin/okc"
 	"github.com/okcoin/go-okcoin/okc/downloader"
 	"github.com/okcoin/go-okcoin/okc/gasprice"
 	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/okcgrpc"
 	"github.com/okcoin/go-okcoin/okcstats"
 	"github.com/okcoin/go-okcoin/les"
 	"github.com/okcoin/go-okcoin/log"
 	"github.com/okcoin/go-okcoin/metrics"
+	"github.com/okcoin/go-okcoin/miner"
 	"github.com/okcoin/go-okcoin/node"
 	"github.com/okcoin/go-okcoin/p2p"
 	"github.com/okcoin/go-okcoin/p2p/discover"
@@ -55,6 +60,7 @@ import (
 	"github.com/okcoin/go-okcoin/p2p/nat"
 	"github.com/okcoin/go-okcoin/p2p/netutil"
 	"github.com/okcoin/go-okcoin/params"
+	"github.com/okcoin/go-okcoin/rpc"
 	whisper "github.com/okcoin/go-okcoin/whisper/whisperv5"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -175,6 +181,10 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	MaxReorgDepthFlag = cli.Uint64Flag{
+		Name:  "maxreorgdepth",
+		Usage: "Maximum number of blocks a chain reorganisation may drop (0 = unlimited)",
+	}
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
 		Usage: "Maximum percentage of time allowed for serving LES requests (0-90)",
@@ -322,7 +332,7 @@ var (
 	}
 	TargetGasLimitFlag = cli.Uint64Flag{
 		Name:  "targetgaslimit",
-		Usage: "Target gas limit sets the artificial target gas floor for the blocks to mine",
+		Usage: "Target gas floor for mined blocks (deprecated, use --miner.gastarget)",
 		Value: params.GenesisGasLimit,
 	}
 	OkcerbaseFlag = cli.StringFlag{
@@ -339,6 +349,66 @@ var (
 		Name:  "extradata",
 		Usage: "Block extra data set by the miner (default = client version)",
 	}
+	MinerGasTargetFlag = cli.Uint64Flag{
+		Name:  "miner.gastarget",
+		Usage: "Target gas floor for mined blocks",
+		Value: okc.DefaultConfig.MinerGasFloor,
+	}
+	MinerGasLimitFlag = cli.Uint64Flag{
+		Name:  "miner.gaslimit",
+		Usage: "Target gas ceiling for mined blocks (0 = no ceiling)",
+		Value: okc.DefaultConfig.MinerGasCeil,
+	}
+	MinerRecommitIntervalFlag = cli.DurationFlag{
+		Name:  "miner.recommit",
+		Usage: "Minimum time interval for recreating the block being mined",
+		Value: okc.DefaultConfig.MinerRecommit,
+	}
+	MinerNotifyFlag = cli.StringFlag{
+		Name:  "miner.notify",
+		Usage: "Comma separated HTTP URL list to notify of new work packages",
+	}
+	MinerSignerFlag = cli.StringFlag{
+		Name:  "miner.signer",
+		Usage: "IPC path or URL of an external signer to seal clique blocks with",
+	}
+	MinerOrderingFlag = cli.StringFlag{
+		Name:  "miner.ordering",
+		Usage: "Transaction ordering strategy of mined blocks (price, fifo, priority, reserved)",
+		Value: okc.DefaultConfig.TxOrdering.Strategy,
+	}
+	MinerPrioritySendersFlag = cli.StringFlag{
+		Name:  "miner.prioritysenders",
+		Usage: "Comma separated accounts whose transactions are mined first by the priority ordering",
+	}
+	MinerSystemSendersFlag = cli.StringFlag{
+		Name:  "miner.systemsenders",
+		Usage: "Comma separated accounts whose transactions have block gas reserved by the reserved ordering",
+	}
+	MinerStratumFlag = cli.StringFlag{
+		Name:  "miner.stratum",
+		Usage: "Stratum server listening address for external miners (disabled if empty, requires mining)",
+	}
+	MinerStratumDiffFlag = cli.Uint64Flag{
+		Name:  "miner.stratum.diff",
+		Usage: "Initial share difficulty of Stratum connections",
+		Value: okc.DefaultConfig.Stratum.Difficulty,
+	}
+	MinerStratumMinDiffFlag = cli.Uint64Flag{
+		Name:  "miner.stratum.mindiff",
+		Usage: "Minimum share difficulty Stratum miners may suggest",
+		Value: okc.DefaultConfig.Stratum.MinDifficulty,
+	}
+	MinerStratumExtranonceFlag = cli.IntFlag{
+		Name:  "miner.stratum.extranonce",
+		Usage: "Nonce bytes partitioning the search space between Stratum connections (0-4)",
+		Value: okc.DefaultConfig.Stratum.ExtranonceSize,
+	}
+	MinerSystemGasShareFlag = cli.Uint64Flag{
+		Name:  "miner.systemgasshare",
+		Usage: "Percentage of the block gas limit reserved for system senders by the reserved ordering",
+		Value: okc.DefaultConfig.TxOrdering.SystemGasShare,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -421,7 +491,7 @@ var (
 	}
 	WSPortFlag = cli.IntFlag{
 		Name:  "wsport",
-		Usage: "WS-RPC server listening port",
+		Usage: "WS-RPC server listening port (shared with the HTTP-RPC server if the same)",
 		Value: node.DefaultWSPort,
 	}
 	WSApiFlag = cli.StringFlag{
@@ -434,6 +504,91 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	WSVirtualHostsFlag = cli.StringFlag{
+		Name:  "wsvhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept websockets requests (server enforced). Accepts '*' wildcard.",
+		Value: "",
+	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server, sharing the access control and limits of HTTP-RPC (queries are the graphql_query method, each block range they resolve the graphql_range one)",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept GraphQL requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
+	GRPCEnabledFlag = cli.BoolFlag{
+		Name:  "grpc",
+		Usage: "Enable the gRPC server, sharing the access control and limits of HTTP-RPC (calls are grpc_<Method> methods)",
+	}
+	GRPCListenAddrFlag = cli.StringFlag{
+		Name:  "grpc.addr",
+		Usage: "gRPC server listening interface",
+		Value: node.DefaultGRPCHost,
+	}
+	GRPCPortFlag = cli.IntFlag{
+		Name:  "grpc.port",
+		Usage: "gRPC server listening port",
+		Value: node.DefaultGRPCPort,
+	}
+	RPCAuthSecretFlag = cli.StringFlag{
+		Name:  "rpcauth.secret",
+		Usage: "File holding the hex encoded secret of the JWT tokens authenticating HTTP-RPC and WS-RPC clients",
+		Value: "",
+	}
+	RPCAuthPolicyFlag = cli.StringFlag{
+		Name:  "rpcauth.policy",
+		Usage: "JSON file granting namespaces and methods to RPC clients by credentials (IPC clients get the \"local\" entry)",
+		Value: "",
+	}
+	RPCLimitRateFlag = cli.Float64Flag{
+		Name:  "rpclimit.rate",
+		Usage: "Request cost units per second granted to each HTTP-RPC and WS-RPC client (0 = unlimited)",
+	}
+	RPCLimitBurstFlag = cli.Float64Flag{
+		Name:  "rpclimit.burst",
+		Usage: "Maximum request cost units an HTTP-RPC or WS-RPC client can accumulate (default = rate)",
+	}
+	RPCLimitCostsFlag = cli.StringFlag{
+		Name:  "rpclimit.costs",
+		Usage: "Comma separated request costs of methods or namespaces (e.g. okc_getLogs=10,debug=50)",
+		Value: "",
+	}
+	RPCLimitRequestSizeFlag = cli.IntFlag{
+		Name:  "rpclimit.requestsize",
+		Usage: "Maximum size of an HTTP-RPC or WS-RPC request in bytes, of IPC ones too if set (-1 = unlimited)",
+		Value: rpc.DefaultLimits.MaxRequestSize,
+	}
+	RPCLimitBatchItemsFlag = cli.IntFlag{
+		Name:  "rpclimit.batchitems",
+		Usage: "Maximum number of calls in an HTTP-RPC or WS-RPC batch request, in IPC ones too if set (-1 = unlimited)",
+		Value: rpc.DefaultLimits.MaxBatchItems,
+	}
+	RPCLimitResponseSizeFlag = cli.IntFlag{
+		Name:  "rpclimit.responsesize",
+		Usage: "Maximum size of an HTTP-RPC or WS-RPC response in bytes, of IPC ones too if set (-1 = unlimited)",
+		Value: rpc.DefaultLimits.MaxResponseSize,
+	}
+	RPCLimitTimeoutFlag = cli.DurationFlag{
+		Name:  "rpclimit.timeout",
+		Usage: "Maximum duration of an IPC, HTTP-RPC or WS-RPC call (0 = unlimited)",
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -715,6 +870,101 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(WSApiFlag.Name) {
 		cfg.WSModules = splitAndTrim(ctx.GlobalString(WSApiFlag.Name))
 	}
+	if ctx.GlobalIsSet(WSVirtualHostsFlag.Name) {
+		cfg.WSVirtualHosts = splitAndTrim(ctx.GlobalString(WSVirtualHostsFlag.Name))
+	}
+}
+
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
+// setGRPC creates the gRPC listener interface string from the set command
+// line flags, returning empty if the gRPC endpoint is disabled.
+func setGRPC(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GRPCEnabledFlag.Name) && cfg.GRPCHost == "" {
+		cfg.GRPCHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GRPCListenAddrFlag.Name) {
+			cfg.GRPCHost = ctx.GlobalString(GRPCListenAddrFlag.Name)
+		}
+	}
+
+	if ctx.GlobalIsSet(GRPCPortFlag.Name) {
+		cfg.GRPCPort = ctx.GlobalInt(GRPCPortFlag.Name)
+	}
+}
+
+// setRPCAuth creates the access control configuration of the HTTP and WebSocket
+// RPC interfaces from the set command line flags.
+func setRPCAuth(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCAuthSecretFlag.Name) {
+		cfg.RPCAuthSecret = ctx.GlobalString(RPCAuthSecretFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCAuthPolicyFlag.Name) {
+		cfg.RPCAuthPolicy = ctx.GlobalString(RPCAuthPolicyFlag.Name)
+	}
+}
+
+// setRPCRateLimit creates the client budgets of the HTTP and WebSocket RPC
+// interfaces from the set command line flags.
+func setRPCRateLimit(ctx *cli.Context, cfg *node.Config) {
+	if !ctx.GlobalIsSet(RPCLimitRateFlag.Name) {
+		return
+	}
+	cfg.RPCRateLimit = &rpc.RateLimitConfig{
+		Rate:  ctx.GlobalFloat64(RPCLimitRateFlag.Name),
+		Burst: ctx.GlobalFloat64(RPCLimitBurstFlag.Name),
+		Costs: make(map[string]float64),
+	}
+	if cfg.RPCRateLimit.Rate == 0 {
+		cfg.RPCRateLimit = nil
+		return
+	}
+	for _, entry := range splitAndTrim(ctx.GlobalString(RPCLimitCostsFlag.Name)) {
+		parts := strings.Split(entry, "=")
+		if len(parts) != 2 {
+			Fatalf("Invalid request cost %q, want <method>=<cost>", entry)
+		}
+		cost, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || cost < 0 {
+			Fatalf("Invalid request cost %q", entry)
+		}
+		cfg.RPCRateLimit.Costs[parts[0]] = cost
+	}
+}
+
+// setRPCLimits creates the size and time limits of the RPC interfaces from the
+// set command line flags.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCLimitRequestSizeFlag.Name) {
+		cfg.RPCMaxRequestSize = ctx.GlobalInt(RPCLimitRequestSizeFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCLimitBatchItemsFlag.Name) {
+		cfg.RPCMaxBatchItems = ctx.GlobalInt(RPCLimitBatchItemsFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCLimitResponseSizeFlag.Name) {
+		cfg.RPCMaxResponseSize = ctx.GlobalInt(RPCLimitResponseSizeFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCLimitTimeoutFlag.Name) {
+		cfg.RPCTimeout = ctx.GlobalDuration(RPCLimitTimeoutFlag.Name)
+	}
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -875,6 +1125,11 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setGraphQL(ctx, cfg)
+	setGRPC(ctx, cfg)
+	setRPCAuth(ctx, cfg)
+	setRPCRateLimit(ctx, cfg)
+	setRPCLimits(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -941,6 +1196,52 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	}
 }
 
+func setTxOrdering(ctx *cli.Context, cfg *miner.OrderingConfig) {
+	if ctx.GlobalIsSet(MinerOrderingFlag.Name) {
+		cfg.Strategy = ctx.GlobalString(MinerOrderingFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerPrioritySendersFlag.Name) {
+		cfg.PrioritySenders = makeAddressList(MinerPrioritySendersFlag.Name, ctx.GlobalString(MinerPrioritySendersFlag.Name))
+	}
+	if ctx.GlobalIsSet(MinerSystemSendersFlag.Name) {
+		cfg.SystemSenders = makeAddressList(MinerSystemSendersFlag.Name, ctx.GlobalString(MinerSystemSendersFlag.Name))
+	}
+	if ctx.GlobalIsSet(MinerSystemGasShareFlag.Name) {
+		cfg.SystemGasShare = ctx.GlobalUint64(MinerSystemGasShareFlag.Name)
+	}
+}
+
+func setStratum(ctx *cli.Context, cfg *miner.StratumConfig) {
+	if ctx.GlobalIsSet(MinerStratumFlag.Name) {
+		cfg.Addr = ctx.GlobalString(MinerStratumFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumDiffFlag.Name) {
+		cfg.Difficulty = ctx.GlobalUint64(MinerStratumDiffFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumMinDiffFlag.Name) {
+		cfg.MinDifficulty = ctx.GlobalUint64(MinerStratumMinDiffFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumExtranonceFlag.Name) {
+		cfg.ExtranonceSize = ctx.GlobalInt(MinerStratumExtranonceFlag.Name)
+	}
+}
+
+// makeAddressList parses a comma separated list of hex addresses given to the
+// named flag.
+func makeAddressList(flag string, input string) []common.Address {
+	var addrs []common.Address
+	for _, account := range strings.Split(input, ",") {
+		if account = strings.TrimSpace(account); account == "" {
+			continue
+		}
+		if !common.IsHexAddress(account) {
+			Fatalf("Option %q: invalid account address %q", flag, account)
+		}
+		addrs = append(addrs, common.HexToAddress(account))
+	}
+	return addrs
+}
+
 func setOkcash(ctx *cli.Context, cfg *okc.Config) {
 	if ctx.GlobalIsSet(OkcashCacheDirFlag.Name) {
 		cfg.Okcash.CacheDir = ctx.GlobalString(OkcashCacheDirFlag.Name)
@@ -1017,11 +1318,14 @@ func SetOkcConfig(ctx *cli.Context, stack *node.Node, cfg *okc.Config) {
 	checkExclusive(ctx, FastSyncFlag, LightModeFlag, SyncModeFlag)
 	checkExclusive(ctx, LightServFlag, LightModeFlag)
 	checkExclusive(ctx, LightServFlag, SyncModeFlag, "light")
+	checkExclusive(ctx, TargetGasLimitFlag, MinerGasTargetFlag)
 
 	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
 	setOkcerbase(ctx, ks, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	setTxOrdering(ctx, &cfg.TxOrdering)
+	setStratum(ctx, &cfg.Stratum)
 	setOkcash(ctx, cfg)
 
 	switch {
@@ -1052,6 +1356,10 @@ func SetOkcConfig(ctx *cli.Context, stack *node.Node, cfg *okc.Config) {
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
 
+	if ctx.GlobalIsSet(MaxReorgDepthFlag.Name) {
+		cfg.MaxReorgDepth = ctx.GlobalUint64(MaxReorgDepthFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
@@ -1067,6 +1375,28 @@ func SetOkcConfig(ctx *cli.Context, stack *node.Node, cfg *okc.Config) {
 	if ctx.GlobalIsSet(GasPriceFlag.Name) {
 		cfg.GasPrice = GlobalBig(ctx, GasPriceFlag.Name)
 	}
+	if ctx.GlobalIsSet(TargetGasLimitFlag.Name) {
+		log.Warn("The --targetgaslimit flag is deprecated and sets the miner gas floor, use --miner.gastarget instead")
+		cfg.MinerGasFloor = ctx.GlobalUint64(TargetGasLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerGasTargetFlag.Name) {
+		cfg.MinerGasFloor = ctx.GlobalUint64(MinerGasTargetFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerGasLimitFlag.Name) {
+		cfg.MinerGasCeil = ctx.GlobalUint64(MinerGasLimitFlag.Name)
+	}
+	if cfg.MinerGasCeil != 0 && cfg.MinerGasFloor > cfg.MinerGasCeil {
+		Fatalf("Miner gas floor %d (--%s) is above the gas ceiling %d (--%s)", cfg.MinerGasFloor, MinerGasTargetFlag.Name, cfg.MinerGasCeil, MinerGasLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerRecommitIntervalFlag.Name) {
+		cfg.MinerRecommit = ctx.GlobalDuration(MinerRecommitIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerNotifyFlag.Name) {
+		cfg.MinerNotify = strings.Split(ctx.GlobalString(MinerNotifyFlag.Name), ",")
+	}
+	if ctx.GlobalIsSet(MinerSignerFlag.Name) {
+		cfg.MinerSigner = ctx.GlobalString(MinerSignerFlag.Name)
+	}
 	if ctx.GlobalIsSet(VMEnableDebugFlag.Name) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
@@ -1159,6 +1489,48 @@ func RegisterShhService(stack *node.Node, cfg *whisper.Config) {
 	}
 }
 
+// RegisterGraphQLService is a utility function to construct a new service and
+// add it to a gokc node, serving the chain data of the full or light Okcoin service.
+// The endpoint shares the access control and limits of the HTTP-RPC one.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Try to construct the GraphQL service backed by a full node
+		var okcServ *okc.Okcoin
+		if err := ctx.Service(&okcServ); err == nil {
+			return graphql.New(okcServ.ApiBackend, endpoint, cors, vhosts, ctx.RPCGuard())
+		}
+		// Try to construct the GraphQL service backed by a light node
+		var lesServ *les.LightOkcoin
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, endpoint, cors, vhosts, ctx.RPCGuard())
+		}
+		return nil, errors.New("no Okcoin service")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
+// RegisterGRPCService is a utility function to construct a new service and add
+// it to a gokc node, serving the chain data of the full or light Okcoin service.
+// The endpoint shares the access control and limits of the HTTP-RPC one.
+func RegisterGRPCService(stack *node.Node, endpoint string) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Try to construct the gRPC service backed by a full node
+		var okcServ *okc.Okcoin
+		if err := ctx.Service(&okcServ); err == nil {
+			return okcgrpc.New(okcServ.ApiBackend, okcServ.NetVersion(), false, endpoint, ctx.RPCGuard()), nil
+		}
+		// Try to construct the gRPC service backed by a light node
+		var lesServ *les.LightOkcoin
+		if err := ctx.Service(&lesServ); err == nil {
+			return okcgrpc.New(lesServ.ApiBackend, lesServ.NetVersion(), true, endpoint, ctx.RPCGuard()), nil
+		}
+		return nil, errors.New("no Okcoin service")
+	}); err != nil {
+		Fatalf("Failed to register the gRPC service: %v", err)
+	}
+}
+
 // RegisterOkcStatsService configures the Okcoin Stats daemon and adds it to
 // th egiven node.
 func RegisterOkcStatsService(stack *node.Node, url string) {
@@ -1224,6 +1596,8 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	var engine consensus.Engine
 	if config.Clique != nil {
 		engine = clique.New(config.Clique, chainDb)
+	} else if config.BFT != nil {
+		engine = bft.New(config.BFT)
 	} else {
 		engine = okcash.NewFaker()
 		if !ctx.GlobalBool(FakePoWFlag.Name) {
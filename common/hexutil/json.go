@@ -67,6 +67,17 @@ func (b *Bytes) UnmarshalText(input []byte) error {
 	return err
 }
 
+// ImplementsGraphQLType returns true if Bytes implements the specified GraphQL type.
+func (Bytes) ImplementsGraphQLType(name string) bool { return name == "Bytes" }
+
+// UnmarshalGraphQL parses hex encoded bytes from a GraphQL query.
+func (b *Bytes) UnmarshalGraphQL(input interface{}) error {
+	if input, ok := input.(string); ok {
+		return b.UnmarshalText([]byte(input))
+	}
+	return fmt.Errorf("unexpected type %T for Bytes", input)
+}
+
 // String returns the hex encoding of b.
 func (b Bytes) String() string {
 	return Encode(b)
@@ -177,6 +188,23 @@ func (b *Big) UnmarshalText(input []byte) error {
 	return nil
 }
 
+// ImplementsGraphQLType returns true if Big implements the specified GraphQL type.
+func (Big) ImplementsGraphQLType(name string) bool { return name == "BigInt" }
+
+// UnmarshalGraphQL parses a big integer from a GraphQL query, given either as a
+// hex string or as a number.
+func (b *Big) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		return b.UnmarshalText([]byte(input))
+	case int32:
+		*b = Big(*big.NewInt(int64(input)))
+		return nil
+	default:
+		return fmt.Errorf("unexpected type %T for BigInt", input)
+	}
+}
+
 // ToInt converts b to a big.Int.
 func (b *Big) ToInt() *big.Int {
 	return (*big.Int)(b)
@@ -88,6 +88,17 @@ func (h Hash) MarshalText() ([]byte, error) {
 	return hexutil.Bytes(h[:]).MarshalText()
 }
 
+// ImplementsGraphQLType returns true if Hash implements the specified GraphQL type.
+func (Hash) ImplementsGraphQLType(name string) bool { return name == "Bytes32" }
+
+// UnmarshalGraphQL parses a hash in hex syntax from a GraphQL query.
+func (h *Hash) UnmarshalGraphQL(input interface{}) error {
+	if input, ok := input.(string); ok {
+		return h.UnmarshalText([]byte(input))
+	}
+	return fmt.Errorf("unexpected type %T for Bytes32", input)
+}
+
 // Sets the hash to the value of b. If b is larger than len(h), 'b' will be cropped (from the left).
 func (h *Hash) SetBytes(b []byte) {
 	if len(b) > len(h) {
@@ -228,6 +239,17 @@ func (a *Address) UnmarshalJSON(input []byte) error {
 	return hexutil.UnmarshalFixedJSON(addressT, input, a[:])
 }
 
+// ImplementsGraphQLType returns true if Address implements the specified GraphQL type.
+func (Address) ImplementsGraphQLType(name string) bool { return name == "Address" }
+
+// UnmarshalGraphQL parses an address in hex syntax from a GraphQL query.
+func (a *Address) UnmarshalGraphQL(input interface{}) error {
+	if input, ok := input.(string); ok {
+		return a.UnmarshalText([]byte(input))
+	}
+	return fmt.Errorf("unexpected type %T for Address", input)
+}
+
 // UnprefixedHash allows marshaling an Address without 0x prefix.
 type UnprefixedAddress Address
 
@@ -0,0 +1,835 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/state"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/rlp"
+)
+
+const (
+	msgChanSize        = 256              // Number of consensus messages waiting to be processed
+	maxFutureHeights   = 16               // Maximum number of heights ahead to buffer consensus messages for
+	maxBacklog         = 4096             // Maximum number of consensus messages buffered for future heights
+	maxSenderBacklog   = 256              // Maximum number of future messages buffered from a single validator
+	maxBacklogSize     = 64 * 1024 * 1024 // Maximum total size of the future messages buffered
+	maxTimeoutExponent = 6                // Maximum doubling of the request timeout in later rounds
+)
+
+var (
+	// errLockedProposal is returned if a proposal differs from the block the
+	// local validator locked on in an earlier round, without being justified by
+	// a later prepared certificate.
+	errLockedProposal = errors.New("proposal differs from locked block")
+
+	// errInvalidCertificate is returned if the prepares of a round change or the
+	// round changes justifying a pre-prepare aren't signed by a quorum of
+	// validators for the claimed block and round.
+	errInvalidCertificate = errors.New("invalid consensus certificate")
+
+	// errUnjustifiedProposal is returned if a proposal of a later round isn't
+	// the highest block prepared according to its round change certificate.
+	errUnjustifiedProposal = errors.New("proposal differs from highest prepared block")
+)
+
+// Chain is the blockchain the validators agree on blocks for, used to verify
+// the proposals and to import the blocks committed.
+type Chain interface {
+	consensus.ChainReader
+
+	// CurrentBlock retrieves the current head block of the chain.
+	CurrentBlock() *types.Block
+
+	// InsertChain imports a batch of blocks into the chain.
+	InsertChain(chain types.Blocks) (int, error)
+
+	// SubscribeChainHeadEvent subscribes to the changes of the head block.
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+
+	// StateAt retrieves the state with the given root.
+	StateAt(root common.Hash) (*state.StateDB, error)
+
+	// Validator retrieves the block validator of the chain.
+	Validator() core.Validator
+
+	// Processor retrieves the block processor of the chain.
+	Processor() core.Processor
+}
+
+// sealRequest is a local block waiting to be proposed, and to be returned to
+// the sealer once committed.
+type sealRequest struct {
+	block  *types.Block
+	result chan *types.Block
+}
+
+// agreement is the consensus state machine of the local node, agreeing on the
+// block at each height with the other validators. The state is only accessed
+// by the loop goroutine.
+//
+// Each height is decided in rounds. The proposer of a round sends a pre-prepare
+// with its block, validators accepting it send a prepare, and a quorum of those
+// locks the block for the height and triggers commits. A quorum of commits, in
+// any round, finalizes the block.
+//
+// Round changes carry the block the sender locked on along with the prepares
+// proving it, and the pre-prepares of later rounds carry the quorum of round
+// changes that started the round. The proposer has to re-propose the highest
+// prepared block among them, so a block committed in an earlier round is never
+// replaced. Validators only prepare a block different from their locked one if
+// it's justified by a prepared certificate of the same or a later round.
+type agreement struct {
+	engine *BFT
+	chain  Chain
+
+	headCh  chan core.ChainHeadEvent
+	headSub event.Subscription
+	msgCh   chan *message
+	sealCh  chan *sealRequest
+	abortCh chan *sealRequest
+	quit    chan struct{}
+	wg      sync.WaitGroup
+
+	head   *types.Header // Current head block the height is built on
+	snap   *Snapshot     // Validators agreeing on the current height
+	prev   *Snapshot     // Validators of the previous height, nil at startup
+	height uint64        // Block number being agreed on
+	round  uint64        // Current round of the height
+
+	proposal    *types.Block // Proposal accepted in the current round
+	locked      *types.Block // Block prepared by a quorum, the only one to prepare unless justified
+	lockedRound uint64       // Round the locked block was prepared in
+	lockedCert  [][]byte     // Prepares of a quorum of validators proving the lock
+	commitSent  bool         // Whether the local commit of the round was sent
+	committed   bool         // Whether the height was decided, waiting for the import
+
+	justification [][]byte     // Round changes of a quorum of validators starting the current round
+	justified     *types.Block // Highest prepared block among the justification, to propose again
+
+	lastRoundChange uint64 // Highest round the local validator asked to change to
+
+	prepares     map[uint64]map[common.Address]*message    // Prepares by round and validator
+	commits      map[common.Hash]map[common.Address][]byte // Commit seals by digest and validator
+	roundChanges map[uint64]map[common.Address]*message    // Round changes asking for each future round
+	backlog      []*message                                // Messages of future heights
+	backlogSize  int                                       // Total size of the messages of future heights
+	backlogs     map[common.Address]int                    // Number of messages of future heights by validator
+
+	pending  *sealRequest                 // Local block to propose when it's our turn
+	proposed map[common.Hash]*sealRequest // Local proposals waiting to be committed
+
+	timeout <-chan time.Time // Timer of the current round, nil once committed
+}
+
+// newAgreement creates the consensus state machine on top of the given chain
+// and starts its event loop.
+func newAgreement(engine *BFT, chain Chain) (*agreement, error) {
+	a := &agreement{
+		engine:  engine,
+		chain:   chain,
+		headCh:  make(chan core.ChainHeadEvent, 16),
+		msgCh:   make(chan *message, msgChanSize),
+		sealCh:  make(chan *sealRequest),
+		abortCh: make(chan *sealRequest),
+		quit:    make(chan struct{}),
+	}
+	if err := a.newHeight(chain.CurrentBlock().Header()); err != nil {
+		return nil, err
+	}
+	a.headSub = chain.SubscribeChainHeadEvent(a.headCh)
+
+	a.wg.Add(1)
+	go a.loop()
+	return a, nil
+}
+
+// stop terminates the event loop and waits for all pending imports.
+func (a *agreement) stop() {
+	close(a.quit)
+	a.wg.Wait()
+}
+
+// deliver hands a consensus message received from the network to the loop.
+func (a *agreement) deliver(msg *message) {
+	select {
+	case a.msgCh <- msg:
+	case <-a.quit:
+	}
+}
+
+// seal hands a local block to the loop to be proposed, waiting until it's
+// committed or the sealing is aborted.
+func (a *agreement) seal(block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	req := &sealRequest{block: block, result: make(chan *types.Block, 1)}
+
+	select {
+	case a.sealCh <- req:
+	case <-stop:
+		return nil, nil
+	case <-a.quit:
+		return nil, errNotStarted
+	}
+	select {
+	case committed := <-req.result:
+		return committed, nil
+	case <-stop:
+		select {
+		case a.abortCh <- req:
+		case <-a.quit:
+		}
+		return nil, nil
+	case <-a.quit:
+		return nil, nil
+	}
+}
+
+// loop is the event loop of the agreement, processing the chain head changes,
+// consensus messages, local proposals and round timeouts.
+func (a *agreement) loop() {
+	defer a.wg.Done()
+	defer a.headSub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-a.headCh:
+			if head := ev.Block.Header(); head.Number.Uint64() >= a.height {
+				if err := a.newHeight(head); err != nil {
+					log.Error("Failed to start new consensus height", "number", head.Number.Uint64()+1, "err", err)
+				}
+			}
+
+		case msg := <-a.msgCh:
+			a.handleMessage(msg)
+
+		case req := <-a.sealCh:
+			a.handleSeal(req)
+
+		case req := <-a.abortCh:
+			if a.pending == req {
+				a.pending = nil
+			}
+			for hash, proposed := range a.proposed {
+				if proposed == req {
+					delete(a.proposed, hash)
+				}
+			}
+
+		case <-a.timeout:
+			a.handleTimeout()
+
+		case <-a.headSub.Err():
+			return
+
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// newHeight resets the consensus state to agree on the block following the
+// given head.
+func (a *agreement) newHeight(head *types.Header) error {
+	snap, err := a.engine.snapshot(a.chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		return err
+	}
+	a.head, a.snap, a.prev = head, snap, a.snap
+	a.height, a.round = head.Number.Uint64()+1, 0
+
+	a.proposal, a.locked, a.lockedRound, a.lockedCert = nil, nil, 0, nil
+	a.commitSent, a.committed = false, false
+	a.justification, a.justified = nil, nil
+	a.lastRoundChange = 0
+
+	a.prepares = make(map[uint64]map[common.Address]*message)
+	a.commits = make(map[common.Hash]map[common.Address][]byte)
+	a.roundChanges = make(map[uint64]map[common.Address]*message)
+	a.proposed = make(map[common.Hash]*sealRequest)
+
+	if a.pending != nil && a.pending.block.NumberU64() != a.height {
+		a.pending = nil
+	}
+	// The first round only starts once the proposer may produce the block
+	delay := time.Unix(head.Time.Int64()+int64(a.engine.config.Period), 0).Sub(time.Now()) // nolint: gosimple
+	if delay < 0 {
+		delay = 0
+	}
+	a.timeout = time.After(delay + a.roundTimeout(0))
+
+	// Replay the messages buffered for the new height
+	backlog := a.backlog
+	a.backlog, a.backlogSize, a.backlogs = nil, 0, make(map[common.Address]int)
+	for _, msg := range backlog {
+		a.handleMessage(msg)
+	}
+	a.propose()
+	return nil
+}
+
+// roundTimeout returns the time to wait for the given round to complete.
+func (a *agreement) roundTimeout(round uint64) time.Duration {
+	if round > maxTimeoutExponent {
+		round = maxTimeoutExponent
+	}
+	return time.Duration(a.engine.config.RequestTimeout) * time.Millisecond << round
+}
+
+// handleSeal accepts a local block to propose once it's our turn.
+func (a *agreement) handleSeal(req *sealRequest) {
+	if req.block.NumberU64() < a.height {
+		return // Stale block, the sealer will be stopped
+	}
+	a.pending = req
+	if req.block.NumberU64() == a.height {
+		a.propose()
+	}
+}
+
+// propose sends a pre-prepare for the current round if the local validator is
+// its proposer. Later rounds re-propose the highest block prepared according
+// to the round changes starting them, or the locked block if none was.
+func (a *agreement) propose() {
+	if a.committed || a.proposal != nil {
+		return
+	}
+	if a.snap.proposer(a.height, a.round) != a.engine.validator() {
+		return
+	}
+	if a.round > 0 && a.justification == nil {
+		return
+	}
+	var block *types.Block
+	switch {
+	case a.justified != nil:
+		block = a.justified
+
+	case a.locked != nil:
+		block = a.locked
+
+	case a.pending != nil && a.pending.block.ParentHash() == a.head.Hash():
+		sealed, err := a.sealProposal(a.pending.block)
+		if err != nil {
+			log.Warn("Failed to seal block proposal", "number", a.height, "round", a.round, "err", err)
+			return
+		}
+		a.proposed[sealed.Hash()] = a.pending
+		block = sealed
+
+	default:
+		return
+	}
+	payload, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		log.Error("Failed to encode block proposal", "err", err)
+		return
+	}
+	log.Debug("Proposing block", "number", a.height, "round", a.round, "hash", block.Hash())
+	a.broadcast(&message{
+		Code:        msgPreprepare,
+		Height:      a.height,
+		Round:       a.round,
+		Digest:      block.Hash(),
+		Proposal:    payload,
+		Certificate: a.justification,
+		block:       block,
+	})
+}
+
+// sealProposal fills the round into a local block and signs it as its proposer.
+func (a *agreement) sealProposal(block *types.Block) (*types.Block, error) {
+	header := block.Header()
+
+	extra, err := decodeExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	extra.Round, extra.Seal, extra.CommittedSeals = a.round, nil, nil
+	if err := setExtra(header, extra); err != nil {
+		return nil, err
+	}
+	sighash, err := sigHash(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, extra.Seal, err = a.engine.sign(sighash.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := setExtra(header, extra); err != nil {
+		return nil, err
+	}
+	return block.WithSeal(header), nil
+}
+
+// sortedSeals returns the commit seals ordered by the validators signing them.
+func sortedSeals(seals map[common.Address][]byte) [][]byte {
+	validators := make([]common.Address, 0, len(seals))
+	for validator := range seals {
+		validators = append(validators, validator)
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		return bytes.Compare(validators[i][:], validators[j][:]) < 0
+	})
+	sorted := make([][]byte, len(validators))
+	for i, validator := range validators {
+		sorted[i] = seals[validator]
+	}
+	return sorted
+}
+
+// broadcast signs a consensus message with the local validator key and
+// processes it locally, which sends it to the network. Nodes which aren't
+// validators of the current height stay silent.
+func (a *agreement) broadcast(msg *message) {
+	if !a.snap.isValidator(a.engine.validator()) {
+		return
+	}
+	var err error
+	if msg.Code == msgCommit {
+		if _, msg.CommitSeal, err = a.engine.sign(commitHash(msg.Digest)); err != nil {
+			log.Warn("Failed to sign commit seal", "err", err)
+			return
+		}
+	}
+	if msg.sender, msg.Signature, err = a.engine.sign(msg.sigHash()); err != nil {
+		log.Warn("Failed to sign consensus message", "err", err)
+		return
+	}
+	if msg.payload, err = rlp.EncodeToBytes(msg); err != nil {
+		log.Error("Failed to encode consensus message", "err", err)
+		return
+	}
+	a.handleMessage(msg)
+}
+
+// handleMessage processes a consensus message, relaying it to the network if
+// signed by a validator of the current height. Messages of future heights are
+// buffered until reaching them, past ones are dropped.
+func (a *agreement) handleMessage(msg *message) {
+	switch {
+	case msg.Height < a.height:
+		return
+
+	case msg.Height > a.height:
+		a.bufferMessage(msg)
+		return
+	}
+	if !a.snap.isValidator(msg.sender) {
+		log.Trace("Ignoring message of non-validator", "msg", msg)
+		return
+	}
+	log.Trace("Handling consensus message", "msg", msg)
+	a.engine.broadcast(msg.payload)
+
+	switch msg.Code {
+	case msgPreprepare:
+		a.handlePreprepare(msg)
+	case msgPrepare:
+		a.handlePrepare(msg)
+	case msgCommit:
+		a.handleCommit(msg)
+	case msgRoundChange:
+		a.handleRoundChange(msg)
+	}
+}
+
+// bufferMessage adds a message of a future height to the backlog. Only messages
+// of validators of the current or previous height are buffered, limited per
+// validator and in total size so no sender can exhaust the backlog.
+func (a *agreement) bufferMessage(msg *message) {
+	if msg.Height > a.height+maxFutureHeights {
+		return
+	}
+	if !a.snap.isValidator(msg.sender) && (a.prev == nil || !a.prev.isValidator(msg.sender)) {
+		log.Trace("Ignoring future message of non-validator", "msg", msg)
+		return
+	}
+	size := len(msg.payload)
+	if len(a.backlog) >= maxBacklog || a.backlogSize+size > maxBacklogSize || a.backlogs[msg.sender] >= maxSenderBacklog {
+		log.Trace("Dropping future message, backlog full", "msg", msg)
+		return
+	}
+	a.backlog = append(a.backlog, msg)
+	a.backlogSize += size
+	a.backlogs[msg.sender]++
+}
+
+// handlePreprepare verifies the proposal of a round, preparing it if valid. The
+// proposals of later rounds need to be justified by the round changes of a
+// quorum of validators, which moves the local validator to the round too.
+func (a *agreement) handlePreprepare(msg *message) {
+	if msg.sender != a.snap.proposer(a.height, msg.Round) {
+		return
+	}
+	if msg.Round < a.round || a.committed {
+		return
+	}
+	if msg.Round > 0 {
+		prepared, err := a.justify(msg)
+		if err == nil && prepared != nil && prepared.Digest != msg.Digest {
+			err = errUnjustifiedProposal
+		}
+		if err == nil && a.locked != nil && a.locked.Hash() != msg.Digest && (prepared == nil || prepared.PreparedRound < a.lockedRound) {
+			err = errLockedProposal
+		}
+		if err != nil {
+			log.Debug("Rejected block proposal", "number", a.height, "round", msg.Round, "hash", msg.Digest, "err", err)
+			return
+		}
+		a.startRound(msg.Round)
+	}
+	if a.proposal != nil {
+		return
+	}
+	if err := a.verifyProposal(msg.block); err != nil {
+		log.Debug("Rejected block proposal", "number", a.height, "round", a.round, "hash", msg.Digest, "err", err)
+		return
+	}
+	a.proposal = msg.block
+
+	a.broadcast(&message{Code: msgPrepare, Height: a.height, Round: a.round, Digest: msg.Digest})
+	a.checkPrepares()
+	a.checkCommits(msg.Digest)
+}
+
+// verifyProposal checks that a proposed block is valid on top of the head,
+// executing its transactions.
+func (a *agreement) verifyProposal(block *types.Block) error {
+	if block.ParentHash() != a.head.Hash() {
+		return consensus.ErrUnknownAncestor
+	}
+	if a.locked != nil && block.Hash() == a.locked.Hash() {
+		return nil // Already verified when first prepared
+	}
+	extra, err := decodeExtra(block.Header())
+	if err != nil {
+		return err
+	}
+	if extra.Round > a.round {
+		return errInvalidMessageRound
+	}
+	// Proposals are sealed by their proposer, the commit seals come afterwards
+	if err := a.engine.VerifyHeader(a.chain, block.Header(), true); err != nil && err != errEmptyCommits {
+		return err
+	}
+	if err := a.chain.Validator().ValidateBody(block); err != nil {
+		return err
+	}
+	parent := a.chain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	statedb, err := a.chain.StateAt(parent.Root())
+	if err != nil {
+		return err
+	}
+	receipts, _, usedGas, err := a.chain.Processor().Process(block, statedb, vm.Config{})
+	if err != nil {
+		return err
+	}
+	return a.chain.Validator().ValidateState(block, parent, statedb, receipts, usedGas)
+}
+
+// handlePrepare records the prepare of a validator.
+func (a *agreement) handlePrepare(msg *message) {
+	if msg.Round < a.round || a.committed {
+		return
+	}
+	prepares := a.prepares[msg.Round]
+	if prepares == nil {
+		prepares = make(map[common.Address]*message)
+		a.prepares[msg.Round] = prepares
+	}
+	if _, ok := prepares[msg.sender]; ok {
+		return
+	}
+	prepares[msg.sender] = msg
+
+	if msg.Round == a.round {
+		a.checkPrepares()
+	}
+}
+
+// checkPrepares locks the proposal of the current round and commits to it if
+// a quorum of validators prepared it.
+func (a *agreement) checkPrepares() {
+	if a.proposal == nil || a.commitSent || a.committed {
+		return
+	}
+	digest := a.proposal.Hash()
+
+	var cert [][]byte
+	for _, prepare := range a.prepares[a.round] {
+		if prepare.Digest == digest {
+			cert = append(cert, prepare.payload)
+		}
+	}
+	if len(cert) < a.snap.quorum() {
+		return
+	}
+	a.locked, a.lockedRound, a.lockedCert = a.proposal, a.round, cert
+	a.commitSent = true
+
+	a.broadcast(&message{Code: msgCommit, Height: a.height, Round: a.round, Digest: digest})
+}
+
+// handleCommit records the commit seal of a validator.
+func (a *agreement) handleCommit(msg *message) {
+	if a.committed {
+		return
+	}
+	seals := a.commits[msg.Digest]
+	if seals == nil {
+		seals = make(map[common.Address][]byte)
+		a.commits[msg.Digest] = seals
+	}
+	seals[msg.sender] = msg.CommitSeal
+
+	a.checkCommits(msg.Digest)
+}
+
+// checkCommits decides the height if a quorum of validators committed to the
+// block with the given hash.
+func (a *agreement) checkCommits(digest common.Hash) {
+	if a.committed || len(a.commits[digest]) < a.snap.quorum() {
+		return
+	}
+	var block *types.Block
+	switch {
+	case a.proposal != nil && a.proposal.Hash() == digest:
+		block = a.proposal
+	case a.locked != nil && a.locked.Hash() == digest:
+		block = a.locked
+	default:
+		return // Block not yet known, wait for it to be proposed or imported
+	}
+	a.commit(block, a.commits[digest])
+}
+
+// commit finalizes the block of the current height, storing the commit seals in
+// its extra-data and returning it to the local sealer if it proposed it, or
+// importing it otherwise.
+func (a *agreement) commit(block *types.Block, seals map[common.Address][]byte) {
+	a.committed, a.timeout = true, nil
+
+	header := block.Header()
+	extra, err := decodeExtra(header)
+	if err != nil {
+		log.Error("Failed to decode committed block", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return
+	}
+	extra.CommittedSeals = sortedSeals(seals)
+	if err := setExtra(header, extra); err != nil {
+		log.Error("Failed to seal committed block", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return
+	}
+	block = block.WithSeal(header)
+
+	log.Info("Committed new block", "number", block.Number(), "hash", block.Hash(), "round", a.round, "commits", len(seals))
+
+	if req, ok := a.proposed[block.Hash()]; ok {
+		delete(a.proposed, block.Hash())
+		req.result <- block
+		return
+	}
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+
+		if _, err := a.chain.InsertChain(types.Blocks{block}); err != nil {
+			log.Warn("Failed to import committed block", "number", block.Number(), "hash", block.Hash(), "err", err)
+		}
+	}()
+}
+
+// handleRoundChange records a validator asking to change to a later round,
+// joining the change if enough validators ask for it to include an honest one,
+// and starting the round once a quorum asked for it.
+func (a *agreement) handleRoundChange(msg *message) {
+	if msg.Round <= a.round || a.committed {
+		return
+	}
+	if err := a.verifyPrepared(msg); err != nil {
+		log.Debug("Rejected round change", "msg", msg, "err", err)
+		return
+	}
+	votes := a.roundChanges[msg.Round]
+	if votes == nil {
+		votes = make(map[common.Address]*message)
+		a.roundChanges[msg.Round] = votes
+	}
+	votes[msg.sender] = msg
+
+	if len(votes) > a.snap.faulty() && a.lastRoundChange < msg.Round {
+		a.sendRoundChange(msg.Round)
+	}
+	if len(votes) >= a.snap.quorum() {
+		a.startRound(msg.Round)
+	}
+}
+
+// sendRoundChange asks the validators to change to the given round, passing on
+// the locked block along with the prepares proving it.
+func (a *agreement) sendRoundChange(round uint64) {
+	a.lastRoundChange = round
+
+	msg := &message{Code: msgRoundChange, Height: a.height, Round: round}
+	if a.locked != nil {
+		payload, err := rlp.EncodeToBytes(a.locked)
+		if err != nil {
+			log.Error("Failed to encode locked block", "err", err)
+			return
+		}
+		msg.Digest, msg.Proposal, msg.block = a.locked.Hash(), payload, a.locked
+		msg.PreparedRound, msg.Certificate = a.lockedRound, a.lockedCert
+	}
+	a.broadcast(msg)
+}
+
+// verifyPrepared checks that the block a round change was sent with, if any,
+// was prepared by a quorum of validators in the claimed round.
+func (a *agreement) verifyPrepared(msg *message) error {
+	if msg.Digest == (common.Hash{}) {
+		return nil
+	}
+	senders := make(map[common.Address]struct{})
+	for _, payload := range msg.Certificate {
+		prepare, err := decodeMessage(payload)
+		if err != nil {
+			return err
+		}
+		if prepare.Code != msgPrepare || prepare.Height != msg.Height || prepare.Round != msg.PreparedRound || prepare.Digest != msg.Digest {
+			return errInvalidCertificate
+		}
+		if !a.snap.isValidator(prepare.sender) {
+			return errInvalidCertificate
+		}
+		senders[prepare.sender] = struct{}{}
+	}
+	if len(senders) < a.snap.quorum() {
+		return errInvalidCertificate
+	}
+	return nil
+}
+
+// justify checks that a pre-prepare of a later round carries the round changes
+// of a quorum of validators asking for it, returning the one with the highest
+// prepared block, which is the only one that may be proposed.
+func (a *agreement) justify(msg *message) (*message, error) {
+	changes := make([]*message, 0, len(msg.Certificate))
+	senders := make(map[common.Address]struct{})
+	for _, payload := range msg.Certificate {
+		change, err := decodeMessage(payload)
+		if err != nil {
+			return nil, err
+		}
+		if change.Code != msgRoundChange || change.Height != msg.Height || change.Round != msg.Round {
+			return nil, errInvalidCertificate
+		}
+		if !a.snap.isValidator(change.sender) {
+			return nil, errInvalidCertificate
+		}
+		if err := a.verifyPrepared(change); err != nil {
+			return nil, err
+		}
+		senders[change.sender] = struct{}{}
+		changes = append(changes, change)
+	}
+	if len(senders) < a.snap.quorum() {
+		return nil, errInvalidCertificate
+	}
+	return highestPrepared(changes), nil
+}
+
+// highestPrepared returns the first round change with the highest prepared
+// round, or nil if none of them carries a prepared block.
+func highestPrepared(changes []*message) *message {
+	var highest *message
+	for _, change := range changes {
+		if change.Digest == (common.Hash{}) {
+			continue
+		}
+		if highest == nil || change.PreparedRound > highest.PreparedRound {
+			highest = change
+		}
+	}
+	return highest
+}
+
+// handleTimeout asks for a round change if the current round didn't complete
+// in time, or the previously requested change didn't happen.
+func (a *agreement) handleTimeout() {
+	if a.committed {
+		return
+	}
+	round := a.round + 1
+	if a.lastRoundChange >= round {
+		round = a.lastRoundChange + 1
+	}
+	log.Debug("Consensus round timed out", "number", a.height, "round", a.round, "next", round)
+
+	a.sendRoundChange(round)
+	a.timeout = time.After(a.roundTimeout(round))
+}
+
+// startRound moves the agreement to a later round of the current height. The
+// round changes asking for it are kept to justify the local proposal.
+func (a *agreement) startRound(round uint64) {
+	if round <= a.round || a.committed {
+		return
+	}
+	log.Debug("Starting consensus round", "number", a.height, "round", round, "proposer", a.snap.proposer(a.height, round))
+
+	a.round = round
+	a.proposal, a.commitSent = nil, false
+	a.justification, a.justified = nil, nil
+	a.timeout = time.After(a.roundTimeout(round))
+
+	if votes := a.roundChanges[round]; len(votes) >= a.snap.quorum() {
+		changes := make([]*message, 0, len(votes))
+		for _, change := range votes {
+			changes = append(changes, change)
+			a.justification = append(a.justification, change.payload)
+		}
+		if prepared := highestPrepared(changes); prepared != nil {
+			a.justified = prepared.block
+		}
+	}
+	for r := range a.prepares {
+		if r < round {
+			delete(a.prepares, r)
+		}
+	}
+	for r := range a.roundChanges {
+		if r <= round {
+			delete(a.roundChanges, r)
+		}
+	}
+	a.propose()
+}
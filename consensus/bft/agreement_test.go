@@ -0,0 +1,373 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/accounts"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/p2p"
+	"github.com/okcoin/go-okcoin/p2p/discover"
+	"github.com/okcoin/go-okcoin/p2p/simulations"
+	"github.com/okcoin/go-okcoin/p2p/simulations/adapters"
+	"github.com/okcoin/go-okcoin/params"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// testValidator is a simulated node running a validator on a private chain,
+// producing blocks with a minimal miner.
+type testValidator struct {
+	engine *BFT
+	chain  *core.BlockChain
+	key    *ecdsa.PrivateKey
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newTestValidatorService returns a simulation service running a validator of
+// a chain with the given genesis validators, keyed by the node key. The chain
+// databases are kept across restarts of the nodes.
+func newTestValidatorService(validators []common.Address) adapters.ServiceFunc {
+	var (
+		dbs  = make(map[discover.NodeID]*okcdb.MemDatabase)
+		lock sync.Mutex
+	)
+	return func(ctx *adapters.ServiceContext) (node.Service, error) {
+		config := *params.AllCliqueProtocolChanges
+		config.Clique, config.BFT = nil, &params.BFTConfig{Period: 1, RequestTimeout: 500}
+
+		genesis := &core.Genesis{
+			Config:     &config,
+			ExtraData:  GenesisExtra(nil, validators),
+			GasLimit:   params.GenesisGasLimit,
+			Difficulty: big.NewInt(1),
+			Alloc:      core.GenesisAlloc{},
+		}
+		lock.Lock()
+		db := dbs[ctx.Config.ID]
+		if db == nil {
+			db, _ = okcdb.NewMemDatabase()
+			dbs[ctx.Config.ID] = db
+		}
+		lock.Unlock()
+
+		if _, _, err := core.SetupGenesisBlock(db, genesis); err != nil {
+			return nil, err
+		}
+
+		engine := New(config.BFT)
+		chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		return &testValidator{
+			engine: engine,
+			chain:  chain,
+			key:    ctx.Config.PrivateKey,
+			quit:   make(chan struct{}),
+		}, nil
+	}
+}
+
+func (v *testValidator) Protocols() []p2p.Protocol { return v.engine.Protocols() }
+func (v *testValidator) APIs() []rpc.API           { return nil }
+func (v *testValidator) Start(*p2p.Server) error   { return nil }
+
+func (v *testValidator) Stop() error {
+	close(v.quit)
+	v.wg.Wait()
+
+	v.engine.Stop()
+	v.chain.Stop()
+	return nil
+}
+
+// startMining authorizes the node key and starts agreeing on blocks.
+func (v *testValidator) startMining() error {
+	v.engine.Authorize(crypto.PubkeyToAddress(v.key.PublicKey), func(account accounts.Account, hash []byte) ([]byte, error) {
+		return crypto.Sign(hash, v.key)
+	})
+	if err := v.engine.Start(v.chain); err != nil {
+		return err
+	}
+	v.wg.Add(1)
+	go v.mine()
+	return nil
+}
+
+// peerCount returns the number of peers connected over the bft protocol.
+func (v *testValidator) peerCount() int {
+	v.engine.peers.lock.RLock()
+	defer v.engine.peers.lock.RUnlock()
+
+	return len(v.engine.peers.peers)
+}
+
+// mine keeps sealing empty blocks on top of the current head, importing the
+// ones committed.
+func (v *testValidator) mine() {
+	defer v.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := v.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		stop := make(chan struct{})
+		result := make(chan *types.Block, 1)
+
+		block, err := v.assemble()
+		if err != nil {
+			return
+		}
+		go func() {
+			sealed, _ := v.engine.Seal(v.chain, block, stop)
+			result <- sealed
+		}()
+		select {
+		case <-heads:
+		case sealed := <-result:
+			if sealed == nil {
+				select {
+				case <-heads:
+				case <-v.quit:
+					return
+				}
+				continue
+			}
+			v.chain.InsertChain(types.Blocks{sealed})
+		case <-v.quit:
+			close(stop)
+			return
+		}
+		close(stop)
+	}
+}
+
+// assemble creates an empty block on top of the current head.
+func (v *testValidator) assemble() (*types.Block, error) {
+	parent := v.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+		Time:       big.NewInt(time.Now().Unix()),
+	}
+	if err := v.engine.Prepare(v.chain, header); err != nil {
+		return nil, err
+	}
+	statedb, err := v.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+	return v.engine.Finalize(v.chain, header, statedb, nil, nil, nil)
+}
+
+// Tests that a fully connected set of validators agrees on a single chain.
+func TestAgreement(t *testing.T) {
+	testAgreement(t, 4, 0, 5)
+}
+
+// Tests that the validators keep agreeing on blocks through round changes if
+// a faulty validator never proposes.
+func TestAgreementOfflineValidator(t *testing.T) {
+	testAgreement(t, 4, 1, 6)
+}
+
+func testAgreement(t *testing.T, validators int, offline int, height uint64) {
+	network, ids := newTestNetwork(t, validators, offline)
+	defer network.Shutdown()
+
+	nodes := startValidators(t, network, ids)
+	waitHeight(t, nodes, height)
+	checkAgreement(t, nodes, height)
+}
+
+// Tests that the validators resume agreeing on blocks after all of them were
+// restarted, the commit seals of the head being persisted in the chain.
+func TestAgreementRestart(t *testing.T) {
+	network, ids := newTestNetwork(t, 4, 0)
+	defer network.Shutdown()
+
+	waitHeight(t, startValidators(t, network, ids), 3)
+
+	for _, id := range ids {
+		if err := network.Stop(id); err != nil {
+			t.Fatalf("failed to stop node: %v", err)
+		}
+	}
+	for _, id := range ids {
+		if err := network.Start(id); err != nil {
+			t.Fatalf("failed to restart node: %v", err)
+		}
+	}
+	nodes := startValidators(t, network, ids)
+
+	height := nodes[0].chain.CurrentBlock().NumberU64() + 3
+	waitHeight(t, nodes, height)
+	checkAgreement(t, nodes, height)
+}
+
+// newTestNetwork creates a simulated network of validators, starting all but
+// the given number of offline ones.
+func newTestNetwork(t *testing.T, validators int, offline int) (*simulations.Network, []discover.NodeID) {
+	configs := make([]*adapters.NodeConfig, validators)
+	addrs := make([]common.Address, validators)
+	for i := range configs {
+		configs[i] = adapters.RandomNodeConfig()
+		configs[i].Services = []string{"bft"}
+		addrs[i] = crypto.PubkeyToAddress(configs[i].PrivateKey.PublicKey)
+	}
+	network := simulations.NewNetwork(adapters.NewSimAdapter(adapters.Services{
+		"bft": newTestValidatorService(addrs),
+	}), &simulations.NetworkConfig{DefaultService: "bft"})
+
+	ids := make([]discover.NodeID, 0, validators-offline)
+	for _, config := range configs[offline:] {
+		node, err := network.NewNodeWithConfig(config)
+		if err != nil {
+			network.Shutdown()
+			t.Fatalf("failed to create node: %v", err)
+		}
+		if err := network.Start(node.ID()); err != nil {
+			network.Shutdown()
+			t.Fatalf("failed to start node: %v", err)
+		}
+		ids = append(ids, node.ID())
+	}
+	return network, ids
+}
+
+// startValidators connects the running validators in a full mesh and starts
+// mining once all are up.
+func startValidators(t *testing.T, network *simulations.Network, ids []discover.NodeID) []*testValidator {
+	nodes := make([]*testValidator, len(ids))
+	for i, id := range ids {
+		nodes[i] = network.GetNode(id).Node.(*adapters.SimNode).Services()[0].(*testValidator)
+	}
+	for i := range ids {
+		client, err := network.GetNode(ids[i]).Client()
+		if err != nil {
+			t.Fatalf("failed to dial node: %v", err)
+		}
+		for j := i + 1; j < len(ids); j++ {
+			if err := client.Call(nil, "admin_addPeer", string(network.GetNode(ids[j]).Addr())); err != nil {
+				t.Fatalf("failed to connect nodes: %v", err)
+			}
+		}
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for _, node := range nodes {
+		for node.peerCount() < len(ids)-1 {
+			if time.Now().After(deadline) {
+				t.Fatalf("validators failed to connect")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	for _, node := range nodes {
+		if err := node.startMining(); err != nil {
+			t.Fatalf("failed to start mining: %v", err)
+		}
+	}
+	return nodes
+}
+
+// waitHeight waits for all validators to reach the requested height.
+func waitHeight(t *testing.T, nodes []*testValidator, height uint64) {
+	deadline := time.Now().Add(time.Minute)
+	for _, node := range nodes {
+		for node.chain.CurrentBlock().NumberU64() < height {
+			if time.Now().After(deadline) {
+				t.Fatalf("chain stuck at height %d, want %d", node.chain.CurrentBlock().NumberU64(), height)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
+
+// checkAgreement ensures the validators finalized the very same blocks, each
+// carrying the commit seals of a quorum.
+func checkAgreement(t *testing.T, nodes []*testValidator, height uint64) {
+	for number := uint64(1); number <= height; number++ {
+		want := nodes[0].chain.GetBlockByNumber(number)
+		for i, node := range nodes[1:] {
+			if have := node.chain.GetBlockByNumber(number); have.Hash() != want.Hash() {
+				t.Fatalf("node %d: block %d mismatch: have %x, want %x", i+1, number, have.Hash(), want.Hash())
+			}
+		}
+		extra, err := decodeExtra(want.Header())
+		if err != nil {
+			t.Fatalf("block %d: failed to decode extra: %v", number, err)
+		}
+		if quorum := len(nodes) - (len(nodes)-1)/3; len(extra.CommittedSeals) < quorum {
+			t.Errorf("block %d: commit seals missing: have %d", number, len(extra.CommittedSeals))
+		}
+	}
+}
+
+// Tests that messages of future heights are only buffered for validators, and
+// within the per validator and total size limits.
+func TestAgreementBacklogLimits(t *testing.T) {
+	var (
+		validator = common.Address{0x01}
+		outsider  = common.Address{0x02}
+	)
+	a := &agreement{
+		snap:     newSnapshot(&params.BFTConfig{}, nil, 0, common.Hash{}, []common.Address{validator}),
+		height:   1,
+		backlogs: make(map[common.Address]int),
+	}
+	a.bufferMessage(&message{Height: 2, sender: outsider})
+	if len(a.backlog) != 0 {
+		t.Fatalf("message of non-validator buffered")
+	}
+	a.bufferMessage(&message{Height: 2 + maxFutureHeights, sender: validator})
+	if len(a.backlog) != 0 {
+		t.Fatalf("message beyond the future heights buffered")
+	}
+	for i := 0; i < 2*maxSenderBacklog; i++ {
+		a.bufferMessage(&message{Height: 2, Round: uint64(i), sender: validator, payload: make([]byte, 1)})
+	}
+	if len(a.backlog) != maxSenderBacklog {
+		t.Fatalf("validator backlog mismatch: have %d, want %d", len(a.backlog), maxSenderBacklog)
+	}
+	// Drop a previous validator's message exceeding the total size
+	a.prev, a.snap = a.snap, newSnapshot(&params.BFTConfig{}, nil, 1, common.Hash{}, []common.Address{outsider})
+	a.backlogs[validator] = 0
+
+	a.bufferMessage(&message{Height: 2, sender: validator, payload: make([]byte, maxBacklogSize)})
+	if len(a.backlog) != maxSenderBacklog {
+		t.Fatalf("message exceeding the backlog size buffered")
+	}
+	a.bufferMessage(&message{Height: 2, sender: validator, payload: make([]byte, 1)})
+	if len(a.backlog) != maxSenderBacklog+1 {
+		t.Fatalf("message of previous validator not buffered")
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"errors"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// errNoFinality is returned if the finalized block is requested from a chain not
+// tracking finality, such as the one of a light client.
+var errNoFinality = errors.New("finalized block not tracked")
+
+// finalizedChain is implemented by the chains tracking the finalized block.
+type finalizedChain interface {
+	// FinalizedBlock retrieves the highest block that may not be reorganised.
+	FinalizedBlock() *types.Block
+}
+
+// API is a user facing RPC API to allow controlling the validator voting of the
+// BFT consensus scheme.
+type API struct {
+	chain consensus.ChainReader
+	bft   *BFT
+}
+
+// header resolves the requested block number to a canonical header, defaulting
+// to the current head. The pending block resolves to the head too, as its
+// validators are decided by it, while the finalized block needs a chain tracking it.
+func (api *API) header(number *rpc.BlockNumber) (*types.Header, error) {
+	if number == nil || *number == rpc.LatestBlockNumber || *number == rpc.PendingBlockNumber {
+		return api.chain.CurrentHeader(), nil
+	}
+	if *number == rpc.FinalizedBlockNumber {
+		chain, ok := api.chain.(finalizedChain)
+		if !ok {
+			return nil, errNoFinality
+		}
+		return chain.FinalizedBlock().Header(), nil
+	}
+	if *number < 0 {
+		return nil, errUnknownBlock
+	}
+	header := api.chain.GetHeaderByNumber(uint64(*number))
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return header, nil
+}
+
+// GetSnapshot retrieves the state snapshot at a given block.
+func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.bft.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetSnapshotAtHash retrieves the state snapshot at a given block.
+func (api *API) GetSnapshotAtHash(hash common.Hash) (*Snapshot, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.bft.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetValidators retrieves the list of validators at the specified block.
+func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := api.bft.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.validators(), nil
+}
+
+// GetValidatorsAtHash retrieves the list of validators at the given block.
+func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.bft.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.validators(), nil
+}
+
+// Proposals returns the current proposals the node tries to uphold and vote on.
+func (api *API) Proposals() map[common.Address]bool {
+	api.bft.lock.RLock()
+	defer api.bft.lock.RUnlock()
+
+	proposals := make(map[common.Address]bool)
+	for address, auth := range api.bft.proposals {
+		proposals[address] = auth
+	}
+	return proposals
+}
+
+// Propose injects a new authorization proposal that the validator will attempt
+// to push through.
+func (api *API) Propose(address common.Address, auth bool) {
+	api.bft.lock.Lock()
+	defer api.bft.lock.Unlock()
+
+	api.bft.proposals[address] = auth
+}
+
+// Discard drops a currently running proposal, stopping the validator from
+// casting further votes (either for or against).
+func (api *API) Discard(address common.Address) {
+	api.bft.lock.Lock()
+	defer api.bft.lock.Unlock()
+
+	delete(api.bft.proposals, address)
+}
@@ -0,0 +1,739 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bft implements a Byzantine fault tolerant consensus engine with
+// immediate finality.
+//
+// A fixed set of validators, changed by votes in the header extra-data, agrees
+// on every block in a pre-prepare/prepare/commit message exchange over the
+// dedicated bft p2p protocol. Each height is decided in rounds with a round
+// robin proposer; rounds failing to commit in time are changed by a quorum of
+// validators. A block is final as soon as a quorum of validators committed to
+// it, the commit seals proving it are stored in its own extra-data. They are
+// excluded from the block hash, which is thus independent of the set of seals
+// collected by each node.
+package bft
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/accounts"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/consensus/misc"
+	"github.com/okcoin/go-okcoin/core/state"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/crypto/sha3"
+	"github.com/okcoin/go-okcoin/params"
+	"github.com/okcoin/go-okcoin/rlp"
+	"github.com/okcoin/go-okcoin/rpc"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	inmemorySnapshots  = 128  // Number of recent validator snapshots to keep in memory
+	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+)
+
+// BFT protocol constants.
+var (
+	epochLength    = uint64(30000) // Default number of blocks after which to checkpoint and reset the pending votes
+	requestTimeout = uint64(10000) // Default milliseconds to wait for a round to complete
+
+	extraVanity = types.BFTExtraVanity // Fixed number of extra-data prefix bytes reserved for validator vanity
+
+	bftDigest = types.BFTDigest // Magic mix digest identifying blocks sealed by the BFT engine
+
+	uncleHash = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
+
+	difficulty = big.NewInt(1) // Block difficulty, fork choice is decided by the validators
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when the list of validators is requested for a
+	// block that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errMissingVanity is returned if a block's extra-data section is shorter than
+	// 32 bytes, which is required to store the validator vanity.
+	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
+
+	// errInvalidExtra is returned if a block's extra-data section following the
+	// vanity can't be decoded.
+	errInvalidExtra = errors.New("invalid extra-data consensus fields")
+
+	// errExtraValidators is returned if a non-checkpoint block contains validator
+	// data in its extra-data fields.
+	errExtraValidators = errors.New("non-checkpoint block contains extra validator list")
+
+	// errInvalidCheckpointValidators is returned if a checkpoint block contains an
+	// invalid list of validators.
+	errInvalidCheckpointValidators = errors.New("invalid validator list on checkpoint block")
+
+	// errInvalidCheckpointVote is returned if a checkpoint/epoch transition block
+	// casts a validator vote.
+	errInvalidCheckpointVote = errors.New("vote in checkpoint block")
+
+	// errInvalidMixDigest is returned if a block's mix digest isn't the BFT one.
+	errInvalidMixDigest = errors.New("invalid mix digest")
+
+	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
+	errInvalidUncleHash = errors.New("non empty uncle hash")
+
+	// errInvalidDifficulty is returned if the difficulty of a block is not 1.
+	errInvalidDifficulty = errors.New("invalid difficulty")
+
+	// ErrInvalidTimestamp is returned if the timestamp of a block is lower than
+	// the previous block's timestamp + the minimum block period.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+	// errInvalidVotingChain is returned if a validator list is attempted to be
+	// modified via out-of-range or non-contiguous headers.
+	errInvalidVotingChain = errors.New("invalid voting chain")
+
+	// errUnauthorized is returned if a header is signed by a non-validator.
+	errUnauthorized = errors.New("unauthorized")
+
+	// errInvalidProposer is returned if a header is signed by a validator which
+	// isn't the proposer of the round the header was proposed in.
+	errInvalidProposer = errors.New("invalid proposer for round")
+
+	// errInvalidCommits is returned if the commit seals of a block are not signed
+	// by a quorum of distinct validators.
+	errInvalidCommits = errors.New("invalid committed seals")
+
+	// errEmptyCommits is returned if a block carries no commit seals, which is
+	// only valid for proposals not yet agreed on.
+	errEmptyCommits = errors.New("empty committed seals")
+
+	// errWaitTransactions is returned if an empty block is attempted to be sealed
+	// on an instant chain (0 second period).
+	errWaitTransactions = errors.New("waiting for transactions")
+
+	// errNotStarted is returned if a block is attempted to be sealed before the
+	// engine was started.
+	errNotStarted = errors.New("bft engine not started")
+)
+
+// SignerFn is a signer callback function to request a hash to be signed by a
+// backing account.
+type SignerFn func(accounts.Account, []byte) ([]byte, error)
+
+// decodeExtra extracts the consensus fields from a header's extra-data.
+func decodeExtra(header *types.Header) (*types.BFTExtra, error) {
+	if len(header.Extra) < extraVanity {
+		return nil, errMissingVanity
+	}
+	extra, err := types.ExtractBFTExtra(header)
+	if err != nil {
+		return nil, errInvalidExtra
+	}
+	return extra, nil
+}
+
+// setExtra replaces the consensus fields in a header's extra-data, retaining
+// the vanity prefix.
+func setExtra(header *types.Header, extra *types.BFTExtra) error {
+	return types.SetBFTExtra(header, extra)
+}
+
+// GenesisExtra assembles the extra-data of a genesis block starting the chain
+// with the given validators.
+func GenesisExtra(vanity []byte, validators []common.Address) []byte {
+	header := &types.Header{Extra: common.CopyBytes(vanity)}
+	if len(header.Extra) > extraVanity {
+		header.Extra = header.Extra[:extraVanity]
+	}
+	if err := setExtra(header, &types.BFTExtra{Validators: validators}); err != nil {
+		panic(err) // Addresses can always be encoded
+	}
+	return header.Extra
+}
+
+// sigHash returns the hash which is used as input for the proposer signature.
+// It is the hash of the entire header apart from the proposer and commit seals
+// contained in the extra-data.
+func sigHash(header *types.Header) (hash common.Hash, err error) {
+	if _, err := decodeExtra(header); err != nil {
+		return common.Hash{}, err
+	}
+	unsealed := types.BFTFilteredHeader(header, false)
+	hasher := sha3.NewKeccak256()
+
+	rlp.Encode(hasher, []interface{}{
+		unsealed.ParentHash,
+		unsealed.UncleHash,
+		unsealed.Coinbase,
+		unsealed.Root,
+		unsealed.TxHash,
+		unsealed.ReceiptHash,
+		unsealed.Bloom,
+		unsealed.Difficulty,
+		unsealed.Number,
+		unsealed.GasLimit,
+		unsealed.GasUsed,
+		unsealed.Time,
+		unsealed.Extra,
+		unsealed.MixDigest,
+		unsealed.Nonce,
+	})
+	hasher.Sum(hash[:0])
+	return hash, nil
+}
+
+// commitHash returns the hash validators sign to commit to the block with the
+// given hash. Block hashes exclude the commit seals, but cover the proposer one.
+func commitHash(hash common.Hash) []byte {
+	return crypto.Keccak256(hash.Bytes(), []byte{byte(msgCommit)})
+}
+
+// ecrecover extracts the Okcoin account address from a signed header.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// If the signature's already cached, return that
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Retrieve the signature from the header extra-data
+	extra, err := decodeExtra(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	sighash, err := sigHash(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	signer, err := recoverAddress(sighash.Bytes(), extra.Seal)
+	if err != nil {
+		return common.Address{}, err
+	}
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// recoverAddress returns the address of the account which signed the hash.
+func recoverAddress(hash []byte, signature []byte) (common.Address, error) {
+	pubkey, err := crypto.Ecrecover(hash, signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+	return signer, nil
+}
+
+// BFT is the Byzantine fault tolerant consensus engine.
+type BFT struct {
+	config *params.BFTConfig // Consensus engine configuration parameters
+
+	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
+	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
+
+	proposals map[common.Address]bool // Current list of proposals we are pushing
+
+	signer common.Address // Okcoin address of the signing key
+	signFn SignerFn       // Signer function to authorize hashes with
+	lock   sync.RWMutex   // Protects the signer fields
+
+	peers     *peerSet      // Peers connected over the bft protocol
+	agreement *agreement    // Consensus state machine, nil until started
+	startLock sync.Mutex    // Protects the agreement field
+	known     *lru.ARCCache // Hashes of consensus messages already seen
+}
+
+// New creates a BFT consensus engine with the initial validators set to the
+// ones in the genesis block.
+func New(config *params.BFTConfig) *BFT {
+	// Set any missing consensus parameters to their defaults
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	if conf.RequestTimeout == 0 {
+		conf.RequestTimeout = requestTimeout
+	}
+	// Allocate the caches and create the engine
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+	known, _ := lru.NewARC(inmemoryMessages)
+
+	return &BFT{
+		config:     &conf,
+		recents:    recents,
+		signatures: signatures,
+		proposals:  make(map[common.Address]bool),
+		peers:      newPeerSet(),
+		known:      known,
+	}
+}
+
+// Author implements consensus.Engine, returning the Okcoin address recovered
+// from the proposer seal in the header's extra-data section.
+func (b *BFT) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, b.signatures)
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules.
+func (b *BFT) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return b.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// method returns a quit channel to abort the operations and a results channel to
+// retrieve the async verifications (the order is that of the input slice).
+func (b *BFT) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := b.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules. The
+// caller may optionally pass in a batch of parents (ascending order) to avoid
+// looking those up from the database. This is useful for concurrently verifying
+// a batch of new headers.
+func (b *BFT) verifyHeader(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Don't waste time checking blocks from the future
+	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
+		return consensus.ErrFutureBlock
+	}
+	// Ensure that the extra-data contains a validator list on checkpoint, but none otherwise
+	extra, err := decodeExtra(header)
+	if err != nil {
+		return err
+	}
+	checkpoint := (number % b.config.Epoch) == 0
+	if !checkpoint && len(extra.Validators) > 0 {
+		return errExtraValidators
+	}
+	if checkpoint && len(extra.Validators) == 0 {
+		return errInvalidCheckpointValidators
+	}
+	if checkpoint && extra.Vote != nil {
+		return errInvalidCheckpointVote
+	}
+	// Ensure that the mix digest identifies the block as BFT sealed
+	if number > 0 && header.MixDigest != bftDigest {
+		return errInvalidMixDigest
+	}
+	// Ensure that the block doesn't contain any uncles which are meaningless in BFT
+	if header.UncleHash != uncleHash {
+		return errInvalidUncleHash
+	}
+	if number > 0 && (header.Difficulty == nil || header.Difficulty.Cmp(difficulty) != 0) {
+		return errInvalidDifficulty
+	}
+	// If all checks passed, validate any special fields for hard forks
+	if err := misc.VerifyForkHashes(chain.Config(), header, false); err != nil {
+		return err
+	}
+	// All basic checks passed, verify cascading fields
+	return b.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyCascadingFields verifies all the header fields that are not standalone,
+// rather depend on a batch of previous headers. The caller may optionally pass
+// in a batch of parents (ascending order) to avoid looking those up from the
+// database. This is useful for concurrently verifying a batch of new headers.
+func (b *BFT) verifyCascadingFields(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	// The genesis block is the always valid dead-end
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	// Ensure that the block's timestamp isn't too close to it's parent
+	parent, err := parentHeader(chain, header, parents)
+	if err != nil {
+		return err
+	}
+	if parent.Time.Uint64()+b.config.Period > header.Time.Uint64() {
+		return ErrInvalidTimestamp
+	}
+	// If the block is a checkpoint block, verify the validator list
+	if number%b.config.Epoch == 0 {
+		snap, err := b.snapshot(chain, number-1, header.ParentHash, parents)
+		if err != nil {
+			return err
+		}
+		extra, err := decodeExtra(header)
+		if err != nil {
+			return err
+		}
+		validators := snap.validators()
+		if len(extra.Validators) != len(validators) {
+			return errInvalidCheckpointValidators
+		}
+		for i, validator := range validators {
+			if extra.Validators[i] != validator {
+				return errInvalidCheckpointValidators
+			}
+		}
+	}
+	// All basic checks passed, verify the seal and return
+	return b.verifySeal(chain, header, parents)
+}
+
+// parentHeader retrieves the parent of a header, either from the batch of
+// parents being verified or the database.
+func parentHeader(chain consensus.ChainReader, header *types.Header, parents []*types.Header) (*types.Header, error) {
+	number := header.Number.Uint64()
+
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	return parent, nil
+}
+
+// snapshot retrieves the validator snapshot at a given point in time.
+func (b *BFT) snapshot(chain consensus.ChainReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
+	// Search for a snapshot in memory or a checkpoint header
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// If an in-memory snapshot was found, use that
+		if s, ok := b.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// Gather the header from the explicit parents or the database
+		var header *types.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		// Checkpoint headers carry the full validator list, start from there
+		if number%b.config.Epoch == 0 {
+			extra, err := decodeExtra(header)
+			if err != nil {
+				return nil, err
+			}
+			if len(extra.Validators) == 0 {
+				return nil, errInvalidCheckpointValidators
+			}
+			snap = newSnapshot(b.config, b.signatures, number, hash, extra.Validators)
+			break
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Previous snapshot found, apply any pending headers on top of it
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	snap, err := snap.apply(headers)
+	if err != nil {
+		return nil, err
+	}
+	b.recents.Add(snap.Hash, snap)
+	return snap, nil
+}
+
+// VerifyUncles implements consensus.Engine, always returning an error for any
+// uncles as this consensus mechanism doesn't permit uncles.
+func (b *BFT) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errors.New("uncles not allowed")
+	}
+	return nil
+}
+
+// VerifySeal implements consensus.Engine, checking whether the proposer seal and
+// the commit seals contained in the header satisfy the consensus protocol
+// requirements.
+func (b *BFT) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	return b.verifySeal(chain, header, nil)
+}
+
+// verifySeal checks whether the proposer seal and the commit seals contained in
+// the header satisfy the consensus protocol requirements. The method accepts an
+// optional list of parent headers that aren't yet part of the local blockchain
+// to generate the snapshots from.
+//
+// Every block needs the commit seals of a quorum of its validators, so a block
+// proposed by a single validator is never accepted. The commit seals are checked
+// last, errEmptyCommits thus marks proposals valid apart from the agreement.
+func (b *BFT) verifySeal(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	// Verifying the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// Retrieve the snapshot needed to verify this header and cache it
+	snap, err := b.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	extra, err := decodeExtra(header)
+	if err != nil {
+		return err
+	}
+	// Resolve the proposer and check it against the validators
+	proposer, err := ecrecover(header, b.signatures)
+	if err != nil {
+		return err
+	}
+	if !snap.isValidator(proposer) {
+		return errUnauthorized
+	}
+	if proposer != snap.proposer(number, extra.Round) {
+		return errInvalidProposer
+	}
+	return verifyCommits(snap, header.Hash(), extra.CommittedSeals)
+}
+
+// verifyCommits checks that the commit seals of a block are signed by a quorum
+// of distinct validators of the snapshot the block was agreed on.
+func verifyCommits(snap *Snapshot, hash common.Hash, commits [][]byte) error {
+	if len(commits) == 0 {
+		return errEmptyCommits
+	}
+	signers := make(map[common.Address]struct{})
+
+	sighash := commitHash(hash)
+	for _, seal := range commits {
+		signer, err := recoverAddress(sighash, seal)
+		if err != nil {
+			return errInvalidCommits
+		}
+		if _, ok := signers[signer]; ok || !snap.isValidator(signer) {
+			return errInvalidCommits
+		}
+		signers[signer] = struct{}{}
+	}
+	if len(signers) < snap.quorum() {
+		return errInvalidCommits
+	}
+	return nil
+}
+
+// Prepare implements consensus.Engine, preparing all the consensus fields of the
+// header for running the transactions on top.
+func (b *BFT) Prepare(chain consensus.ChainReader, header *types.Header) error {
+	header.Nonce = types.BlockNonce{}
+
+	number := header.Number.Uint64()
+	// Assemble the voting snapshot to check which votes make sense
+	snap, err := b.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	extra := new(types.BFTExtra)
+	if number%b.config.Epoch == 0 {
+		extra.Validators = snap.validators()
+	} else {
+		b.lock.RLock()
+
+		// Gather all the proposals that make sense voting on
+		addresses := make([]common.Address, 0, len(b.proposals))
+		for address, authorize := range b.proposals {
+			if snap.validVote(address, authorize) {
+				addresses = append(addresses, address)
+			}
+		}
+		// If there's pending proposals, cast a vote on them
+		if len(addresses) > 0 {
+			address := addresses[rand.Intn(len(addresses))]
+			extra.Vote = &types.BFTVote{Address: address, Authorize: b.proposals[address]}
+		}
+		b.lock.RUnlock()
+	}
+	if err := setExtra(header, extra); err != nil {
+		return err
+	}
+	header.Difficulty = new(big.Int).Set(difficulty)
+	header.MixDigest = bftDigest
+
+	// Ensure the timestamp has the correct delay
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(b.config.Period))
+	if header.Time.Int64() < time.Now().Unix() {
+		header.Time = big.NewInt(time.Now().Unix())
+	}
+	return nil
+}
+
+// Finalize implements consensus.Engine, ensuring no uncles are set, nor block
+// rewards given, and returns the final block.
+func (b *BFT) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	// No block rewards in BFT, so the state remains as is and uncles are dropped
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+
+	// Assemble and return the final block for sealing
+	return types.NewBlock(header, txs, nil, receipts), nil
+}
+
+// Authorize injects a private key into the consensus engine to propose blocks
+// and take part in the agreement on them.
+func (b *BFT) Authorize(signer common.Address, signFn SignerFn) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.signer = signer
+	b.signFn = signFn
+}
+
+// sign signs the given hash with the authorized validator key.
+func (b *BFT) sign(hash []byte) (common.Address, []byte, error) {
+	b.lock.RLock()
+	signer, signFn := b.signer, b.signFn
+	b.lock.RUnlock()
+
+	if signFn == nil {
+		return common.Address{}, nil, errUnauthorized
+	}
+	signature, err := signFn(accounts.Account{Address: signer}, hash)
+	return signer, signature, err
+}
+
+// validator returns the address of the authorized validator key.
+func (b *BFT) validator() common.Address {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.signer
+}
+
+// Seal implements consensus.Engine, handing the block to the consensus state
+// machine to be proposed once it's the local validator's turn. The sealed block
+// is returned only if it was committed by a quorum of validators.
+func (b *BFT) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	header := block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
+	if b.config.Period == 0 && len(block.Transactions()) == 0 {
+		return nil, errWaitTransactions
+	}
+	// Bail out if we're not a validator or unable to agree on blocks
+	snap, err := b.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !snap.isValidator(b.validator()) {
+		return nil, errUnauthorized
+	}
+	b.startLock.Lock()
+	agreement := b.agreement
+	b.startLock.Unlock()
+
+	if agreement == nil {
+		return nil, errNotStarted
+	}
+	// Wait for the block's time slot and hand it over for proposing
+	delay := time.Unix(header.Time.Int64(), 0).Sub(time.Now()) // nolint: gosimple
+	select {
+	case <-stop:
+		return nil, nil
+	case <-time.After(delay):
+	}
+	return agreement.seal(block, stop)
+}
+
+// CalcDifficulty is the difficulty adjustment algorithm. It returns the difficulty
+// that a new block should have, which is constant for BFT.
+func (b *BFT) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(difficulty)
+}
+
+// Start starts the consensus state machine agreeing on blocks on top of the
+// given chain.
+func (b *BFT) Start(chain Chain) error {
+	b.startLock.Lock()
+	defer b.startLock.Unlock()
+
+	if b.agreement != nil {
+		return errors.New("bft engine already started")
+	}
+	agreement, err := newAgreement(b, chain)
+	if err != nil {
+		return err
+	}
+	b.agreement = agreement
+	return nil
+}
+
+// Stop terminates the consensus state machine.
+func (b *BFT) Stop() {
+	b.startLock.Lock()
+	defer b.startLock.Unlock()
+
+	if b.agreement != nil {
+		b.agreement.stop()
+		b.agreement = nil
+	}
+}
+
+// APIs implements consensus.Engine, returning the user facing RPC API to allow
+// controlling the validator voting.
+func (b *BFT) APIs(chain consensus.ChainReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "bft",
+		Version:   "1.0",
+		Service:   &API{chain: chain, bft: b},
+		Public:    false,
+	}}
+}
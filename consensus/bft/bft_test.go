@@ -0,0 +1,375 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/params"
+	"github.com/okcoin/go-okcoin/rlp"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// newTestKeys generates n validator keys, returning them ordered by address.
+func newTestKeys(t *testing.T, n int) ([]*ecdsa.PrivateKey, []common.Address) {
+	keys := make([]*ecdsa.PrivateKey, 0, n)
+	for i := 0; i < n; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	snap := newSnapshot(&params.BFTConfig{Epoch: epochLength}, nil, 0, common.Hash{}, nil)
+	for _, key := range keys {
+		snap.Validators[crypto.PubkeyToAddress(key.PublicKey)] = struct{}{}
+	}
+	addrs := snap.validators()
+
+	sorted := make([]*ecdsa.PrivateKey, n)
+	for _, key := range keys {
+		for i, addr := range addrs {
+			if addr == crypto.PubkeyToAddress(key.PublicKey) {
+				sorted[i] = key
+			}
+		}
+	}
+	return sorted, addrs
+}
+
+// Tests that the consensus fields survive a round trip through the extra-data
+// and that the vanity is retained.
+func TestExtraData(t *testing.T) {
+	_, validators := newTestKeys(t, 3)
+
+	vanity := bytes.Repeat([]byte{0x42}, extraVanity)
+	header := &types.Header{Extra: GenesisExtra(vanity, validators)}
+
+	extra, err := decodeExtra(header)
+	if err != nil {
+		t.Fatalf("failed to decode genesis extra: %v", err)
+	}
+	if len(extra.Validators) != len(validators) || extra.Vote != nil || len(extra.Seal) != 0 {
+		t.Fatalf("genesis extra mismatch: %+v", extra)
+	}
+	extra.Validators = nil
+	extra.Vote = &types.BFTVote{Address: validators[0], Authorize: true}
+	extra.Round, extra.Seal = 3, []byte{0x01}
+
+	if err := setExtra(header, extra); err != nil {
+		t.Fatalf("failed to set extra: %v", err)
+	}
+	if !bytes.Equal(header.Extra[:extraVanity], vanity) {
+		t.Errorf("vanity lost: %x", header.Extra[:extraVanity])
+	}
+	decoded, err := decodeExtra(header)
+	if err != nil {
+		t.Fatalf("failed to decode extra: %v", err)
+	}
+	if decoded.Vote == nil || *decoded.Vote != *extra.Vote || decoded.Round != 3 || !bytes.Equal(decoded.Seal, extra.Seal) {
+		t.Errorf("extra mismatch: have %+v, want %+v", decoded, extra)
+	}
+	if _, err := decodeExtra(&types.Header{Extra: vanity[:10]}); err != errMissingVanity {
+		t.Errorf("short extra error mismatch: have %v, want %v", err, errMissingVanity)
+	}
+}
+
+// Tests that the proposer seal is recovered and that it doesn't cover itself.
+func TestProposerSeal(t *testing.T) {
+	keys, validators := newTestKeys(t, 1)
+
+	header := &types.Header{
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+		Time:       big.NewInt(1),
+		Extra:      GenesisExtra(nil, nil),
+	}
+	hash, err := sigHash(header)
+	if err != nil {
+		t.Fatalf("failed to hash header: %v", err)
+	}
+	extra, _ := decodeExtra(header)
+	if extra.Seal, err = crypto.Sign(hash.Bytes(), keys[0]); err != nil {
+		t.Fatalf("failed to sign header: %v", err)
+	}
+	setExtra(header, extra)
+
+	if sealed, _ := sigHash(header); sealed != hash {
+		t.Fatalf("seal hash changed by sealing: have %x, want %x", sealed, hash)
+	}
+	sigcache, _ := lru.NewARC(inmemorySignatures)
+	proposer, err := ecrecover(header, sigcache)
+	if err != nil {
+		t.Fatalf("failed to recover proposer: %v", err)
+	}
+	if proposer != validators[0] {
+		t.Errorf("proposer mismatch: have %x, want %x", proposer, validators[0])
+	}
+}
+
+// Tests that the hash of a block doesn't change when its commit seals are
+// filled in after being proposed.
+func TestCommittedSealsHash(t *testing.T) {
+	keys, _ := newTestKeys(t, 2)
+
+	header := &types.Header{
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(1),
+		Time:       big.NewInt(1),
+		MixDigest:  bftDigest,
+		Extra:      GenesisExtra(nil, nil),
+	}
+	extra, _ := decodeExtra(header)
+	extra.Seal = bytes.Repeat([]byte{0x01}, 65)
+	setExtra(header, extra)
+	hash := header.Hash()
+
+	for _, key := range keys {
+		seal, err := crypto.Sign(commitHash(hash), key)
+		if err != nil {
+			t.Fatalf("failed to sign commit: %v", err)
+		}
+		extra.CommittedSeals = append(extra.CommittedSeals, seal)
+	}
+	setExtra(header, extra)
+
+	if sealed := header.Hash(); sealed != hash {
+		t.Errorf("hash changed by commit seals: have %x, want %x", sealed, hash)
+	}
+	extra.Seal = bytes.Repeat([]byte{0x02}, 65)
+	setExtra(header, extra)
+
+	if resealed := header.Hash(); resealed == hash {
+		t.Errorf("hash not covering proposer seal")
+	}
+}
+
+// Tests the quorum and fault tolerance for various validator set sizes.
+func TestQuorum(t *testing.T) {
+	tests := []struct {
+		validators int
+		faulty     int
+		quorum     int
+	}{
+		{1, 0, 1}, {2, 0, 2}, {3, 0, 2}, {4, 1, 3}, {5, 1, 4}, {6, 1, 4}, {7, 2, 5}, {10, 3, 7},
+	}
+	for _, tt := range tests {
+		_, validators := newTestKeys(t, tt.validators)
+		snap := newSnapshot(&params.BFTConfig{Epoch: epochLength}, nil, 0, common.Hash{}, validators)
+
+		if faulty := snap.faulty(); faulty != tt.faulty {
+			t.Errorf("validators %d: faulty mismatch: have %d, want %d", tt.validators, faulty, tt.faulty)
+		}
+		if quorum := snap.quorum(); quorum != tt.quorum {
+			t.Errorf("validators %d: quorum mismatch: have %d, want %d", tt.validators, quorum, tt.quorum)
+		}
+		if proposer := snap.proposer(1, 1); proposer != validators[2%tt.validators] {
+			t.Errorf("validators %d: proposer mismatch: have %x, want %x", tt.validators, proposer, validators[2%tt.validators])
+		}
+	}
+}
+
+// Tests that commit seals are only accepted from a quorum of distinct
+// validators.
+func TestVerifyCommits(t *testing.T) {
+	keys, validators := newTestKeys(t, 4)
+	outsider, _ := newTestKeys(t, 1)
+
+	snap := newSnapshot(&params.BFTConfig{Epoch: epochLength}, nil, 0, common.Hash{}, validators)
+	hash := common.HexToHash("0xdeadbeef")
+
+	seal := func(key *ecdsa.PrivateKey) []byte {
+		sig, err := crypto.Sign(commitHash(hash), key)
+		if err != nil {
+			t.Fatalf("failed to sign commit: %v", err)
+		}
+		return sig
+	}
+	tests := []struct {
+		commits [][]byte
+		err     error
+	}{
+		{[][]byte{seal(keys[0]), seal(keys[1]), seal(keys[2])}, nil},
+		{[][]byte{seal(keys[0]), seal(keys[1]), seal(keys[2]), seal(keys[3])}, nil},
+		{[][]byte{seal(keys[0]), seal(keys[1])}, errInvalidCommits},
+		{[][]byte{seal(keys[0]), seal(keys[1]), seal(keys[1])}, errInvalidCommits},
+		{[][]byte{seal(keys[0]), seal(keys[1]), seal(outsider[0])}, errInvalidCommits},
+		{[][]byte{seal(keys[0]), seal(keys[1]), []byte{0x01}}, errInvalidCommits},
+		{nil, errEmptyCommits},
+	}
+	for i, tt := range tests {
+		if err := verifyCommits(snap, hash, tt.commits); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if err := verifyCommits(snap, common.HexToHash("0xbad"), tests[0].commits); err != errInvalidCommits {
+		t.Errorf("commits of other block accepted: %v", err)
+	}
+}
+
+// encodeTestMessage signs a consensus message with the given key and encodes it.
+func encodeTestMessage(t *testing.T, msg *message, key *ecdsa.PrivateKey) []byte {
+	var err error
+	if msg.Signature, err = crypto.Sign(msg.sigHash(), key); err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	payload, err := rlp.EncodeToBytes(msg)
+	if err != nil {
+		t.Fatalf("failed to encode message: %v", err)
+	}
+	return payload
+}
+
+// Tests that consensus messages are authenticated and their contents checked
+// when decoded.
+func TestDecodeMessage(t *testing.T) {
+	keys, validators := newTestKeys(t, 2)
+
+	encode := func(msg *message, key *ecdsa.PrivateKey) []byte {
+		return encodeTestMessage(t, msg, key)
+	}
+	digest := common.HexToHash("0x01")
+
+	// A valid prepare recovers its sender
+	msg, err := decodeMessage(encode(&message{Code: msgPrepare, Height: 1, Digest: digest}, keys[0]))
+	if err != nil {
+		t.Fatalf("failed to decode prepare: %v", err)
+	}
+	if msg.sender != validators[0] {
+		t.Errorf("sender mismatch: have %x, want %x", msg.sender, validators[0])
+	}
+	// A commit needs to carry a seal of its own sender
+	seal, _ := crypto.Sign(commitHash(digest), keys[1])
+	if _, err := decodeMessage(encode(&message{Code: msgCommit, Height: 1, Digest: digest, CommitSeal: seal}, keys[0])); err != errInvalidCommitSeal {
+		t.Errorf("foreign commit seal error mismatch: have %v, want %v", err, errInvalidCommitSeal)
+	}
+	if _, err := decodeMessage(encode(&message{Code: msgCommit, Height: 1, Digest: digest, CommitSeal: seal}, keys[1])); err != nil {
+		t.Errorf("failed to decode commit: %v", err)
+	}
+	// A pre-prepare needs to carry the block it announces
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), Time: big.NewInt(1)})
+	proposal, _ := rlp.EncodeToBytes(block)
+
+	if _, err := decodeMessage(encode(&message{Code: msgPreprepare, Height: 1, Digest: block.Hash(), Proposal: proposal}, keys[0])); err != nil {
+		t.Errorf("failed to decode pre-prepare: %v", err)
+	}
+	if _, err := decodeMessage(encode(&message{Code: msgPreprepare, Height: 1, Digest: digest, Proposal: proposal}, keys[0])); err != errInvalidProposal {
+		t.Errorf("mismatching pre-prepare error mismatch: have %v, want %v", err, errInvalidProposal)
+	}
+	// Round changes to the first round and unknown codes are rejected
+	if _, err := decodeMessage(encode(&message{Code: msgRoundChange, Height: 1}, keys[0])); err != errInvalidMessageRound {
+		t.Errorf("round change error mismatch: have %v, want %v", err, errInvalidMessageRound)
+	}
+	if _, err := decodeMessage(encode(&message{Code: msgRoundChange, Height: 1, Round: 1, Digest: block.Hash(), Proposal: proposal}, keys[0])); err != errInvalidMessageRound {
+		t.Errorf("uncertified round change error mismatch: have %v, want %v", err, errInvalidMessageRound)
+	}
+	if _, err := decodeMessage(encode(&message{Code: msgPrepare, Height: 1, Digest: digest, Certificate: [][]byte{{0x01}}}, keys[0])); err != errUnexpectedFields {
+		t.Errorf("certified prepare error mismatch: have %v, want %v", err, errUnexpectedFields)
+	}
+	if _, err := decodeMessage(encode(&message{Code: 42, Height: 1}, keys[0])); err != errInvalidMessageCode {
+		t.Errorf("unknown code error mismatch: have %v, want %v", err, errInvalidMessageCode)
+	}
+	// Tampering with a message changes its sender
+	payload := encode(&message{Code: msgPrepare, Height: 1, Digest: digest}, keys[0])
+	tampered := new(message)
+	rlp.DecodeBytes(payload, tampered)
+	tampered.Height = 2
+	payload, _ = rlp.EncodeToBytes(tampered)
+
+	if msg, err := decodeMessage(payload); err == nil && msg.sender == validators[0] {
+		t.Errorf("tampered message attributed to original sender")
+	}
+}
+
+// Tests that round changes are only accepted with the prepares of a quorum for
+// their locked block, and that pre-prepares of later rounds are bound to the
+// highest block prepared among the round changes justifying them.
+func TestRoundChangeCertificates(t *testing.T) {
+	keys, validators := newTestKeys(t, 4)
+	a := &agreement{snap: newSnapshot(&params.BFTConfig{Epoch: epochLength}, nil, 0, common.Hash{}, validators)}
+
+	newBlock := func(extra byte) (*types.Block, []byte) {
+		block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), Time: big.NewInt(1), Extra: []byte{extra}})
+		payload, _ := rlp.EncodeToBytes(block)
+		return block, payload
+	}
+	prepares := func(block *types.Block, round uint64, signers ...int) [][]byte {
+		var cert [][]byte
+		for _, i := range signers {
+			cert = append(cert, encodeTestMessage(t, &message{Code: msgPrepare, Height: 1, Round: round, Digest: block.Hash()}, keys[i]))
+		}
+		return cert
+	}
+	roundChange := func(signer int, round uint64, block *types.Block, payload []byte, prepared uint64, cert [][]byte) []byte {
+		msg := &message{Code: msgRoundChange, Height: 1, Round: round}
+		if block != nil {
+			msg.Digest, msg.Proposal, msg.PreparedRound, msg.Certificate = block.Hash(), payload, prepared, cert
+		}
+		return encodeTestMessage(t, msg, keys[signer])
+	}
+	low, lowPayload := newBlock(1)
+	high, highPayload := newBlock(2)
+
+	// Prepared certificates need a quorum of distinct validators of the round
+	tests := []struct {
+		cert [][]byte
+		err  error
+	}{
+		{prepares(low, 0, 0, 1, 2), nil},
+		{prepares(low, 0, 0, 1), errInvalidCertificate},
+		{prepares(low, 0, 0, 1, 1), errInvalidCertificate},
+		{prepares(low, 1, 0, 1, 2), errInvalidCertificate},
+		{prepares(high, 0, 0, 1, 2), errInvalidCertificate},
+	}
+	for i, tt := range tests {
+		msg, err := decodeMessage(roundChange(3, 2, low, lowPayload, 0, tt.cert))
+		if err != nil {
+			t.Fatalf("test %d: failed to decode round change: %v", i, err)
+		}
+		if err := a.verifyPrepared(msg); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Pre-prepares need a quorum of round changes, reporting the highest lock
+	justification := [][]byte{
+		roundChange(0, 2, nil, nil, 0, nil),
+		roundChange(1, 2, low, lowPayload, 0, prepares(low, 0, 0, 1, 2)),
+		roundChange(2, 2, high, highPayload, 1, prepares(high, 1, 1, 2, 3)),
+	}
+	preprepare := &message{Code: msgPreprepare, Height: 1, Round: 2, Certificate: justification}
+	prepared, err := a.justify(preprepare)
+	if err != nil {
+		t.Fatalf("failed to justify pre-prepare: %v", err)
+	}
+	if prepared == nil || prepared.Digest != high.Hash() {
+		t.Errorf("highest prepared block mismatch: have %v, want %x", prepared, high.Hash())
+	}
+	preprepare.Certificate = justification[:2]
+	if _, err := a.justify(preprepare); err != errInvalidCertificate {
+		t.Errorf("minority justification error mismatch: have %v, want %v", err, errInvalidCertificate)
+	}
+	preprepare.Round, preprepare.Certificate = 3, justification
+	if _, err := a.justify(preprepare); err != errInvalidCertificate {
+		t.Errorf("other round justification error mismatch: have %v, want %v", err, errInvalidCertificate)
+	}
+}
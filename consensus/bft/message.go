@@ -0,0 +1,160 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/rlp"
+)
+
+// Consensus message codes of the agreement on a block.
+const (
+	msgPreprepare  = iota // Proposer announcing the block of a round
+	msgPrepare            // Validator accepting the proposal of a round
+	msgCommit             // Validator committing to a block prepared by a quorum
+	msgRoundChange        // Validator asking to move on to a later round
+)
+
+var (
+	errInvalidMessageCode  = errors.New("invalid message code")
+	errInvalidProposal     = errors.New("invalid proposal")
+	errInvalidCommitSeal   = errors.New("commit seal not signed by sender")
+	errInvalidMessageRound = errors.New("invalid message round")
+	errUnexpectedFields    = errors.New("unexpected message fields")
+)
+
+// message is a signed consensus message exchanged between the validators.
+type message struct {
+	Code          uint64
+	Height        uint64      // Block number being agreed on
+	Round         uint64      // Round of the height the message belongs to
+	Digest        common.Hash // Hash of the block proposed, prepared, committed to or locked on
+	Proposal      []byte      // RLP encoded block of pre-prepares and locked round changes
+	CommitSeal    []byte      // Signature over the commit hash of the block in commits
+	PreparedRound uint64      // Round the block of a round change was prepared in
+	Certificate   [][]byte    // Prepares proving a round change, or round changes justifying a pre-prepare
+	Signature     []byte      // Sender signature over all the other fields
+
+	sender  common.Address // Validator the message was signed by
+	block   *types.Block   // Decoded block of pre-prepares and locked round changes
+	payload []byte         // Signed RLP encoding of the message, relayed and certified
+}
+
+// String implements fmt.Stringer.
+func (msg *message) String() string {
+	var code string
+	switch msg.Code {
+	case msgPreprepare:
+		code = "pre-prepare"
+	case msgPrepare:
+		code = "prepare"
+	case msgCommit:
+		code = "commit"
+	case msgRoundChange:
+		code = "round-change"
+	default:
+		code = fmt.Sprintf("unknown(%d)", msg.Code)
+	}
+	return fmt.Sprintf("%s{height: %d, round: %d, sender: %x}", code, msg.Height, msg.Round, msg.sender[:4])
+}
+
+// sigHash returns the hash the sender of the message signs.
+func (msg *message) sigHash() []byte {
+	blob, _ := rlp.EncodeToBytes([]interface{}{msg.Code, msg.Height, msg.Round, msg.Digest, msg.Proposal, msg.CommitSeal, msg.PreparedRound, msg.Certificate})
+	return crypto.Keccak256(blob)
+}
+
+// decodeMessage decodes a consensus message received from the network, and
+// validates its self contained fields, recovering the sender.
+func decodeMessage(payload []byte) (*message, error) {
+	msg := new(message)
+	if err := rlp.DecodeBytes(payload, msg); err != nil {
+		return nil, err
+	}
+	sender, err := recoverAddress(msg.sigHash(), msg.Signature)
+	if err != nil {
+		return nil, err
+	}
+	msg.sender, msg.payload = sender, payload
+
+	switch msg.Code {
+	case msgPreprepare:
+		if len(msg.CommitSeal) > 0 || msg.PreparedRound != 0 || (msg.Round == 0) != (len(msg.Certificate) == 0) {
+			return nil, errUnexpectedFields
+		}
+		if msg.block, err = decodeBlock(msg); err != nil {
+			return nil, err
+		}
+
+	case msgPrepare:
+		if len(msg.Proposal) > 0 || len(msg.CommitSeal) > 0 || msg.PreparedRound != 0 || len(msg.Certificate) > 0 {
+			return nil, errUnexpectedFields
+		}
+
+	case msgCommit:
+		if len(msg.Proposal) > 0 || msg.PreparedRound != 0 || len(msg.Certificate) > 0 {
+			return nil, errUnexpectedFields
+		}
+		signer, err := recoverAddress(commitHash(msg.Digest), msg.CommitSeal)
+		if err != nil || signer != msg.sender {
+			return nil, errInvalidCommitSeal
+		}
+
+	case msgRoundChange:
+		if msg.Round == 0 {
+			return nil, errInvalidMessageRound
+		}
+		if len(msg.CommitSeal) > 0 {
+			return nil, errUnexpectedFields
+		}
+		// Round changes without a locked block carry nothing else
+		if msg.Digest == (common.Hash{}) {
+			if len(msg.Proposal) > 0 || msg.PreparedRound != 0 || len(msg.Certificate) > 0 {
+				return nil, errUnexpectedFields
+			}
+			break
+		}
+		if msg.PreparedRound >= msg.Round || len(msg.Certificate) == 0 {
+			return nil, errInvalidMessageRound
+		}
+		if msg.block, err = decodeBlock(msg); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, errInvalidMessageCode
+	}
+	return msg, nil
+}
+
+// decodeBlock decodes the block carried by a message, checking that it's the
+// one of the message height and digest.
+func decodeBlock(msg *message) (*types.Block, error) {
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(msg.Proposal, block); err != nil {
+		return nil, err
+	}
+	if block.NumberU64() != msg.Height || block.Hash() != msg.Digest {
+		return nil, errInvalidProposal
+	}
+	return block, nil
+}
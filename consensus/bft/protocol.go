@@ -0,0 +1,205 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package bft
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/p2p"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Constants of the bft consensus message protocol.
+const (
+	protocolName    = "bft"
+	protocolVersion = 1
+	protocolLength  = 1
+
+	consensusMsg = 0x00 // Message code of the consensus messages
+
+	maxMessageSize   = 10 * 1024 * 1024 // Maximum cap on the size of a consensus message (with proposal)
+	inmemoryMessages = 4096             // Number of recent consensus messages to remember as seen
+	maxQueuedMsgs    = 256              // Maximum number of consensus messages queued to a peer
+)
+
+// peer is a remote node connected over the bft protocol.
+type peer struct {
+	*p2p.Peer
+	rw p2p.MsgReadWriter
+
+	known *lru.ARCCache // Hashes of the consensus messages known to the peer
+	queue chan []byte   // Consensus messages waiting to be sent to the peer
+	term  chan struct{} // Termination channel to stop the broadcaster
+}
+
+// newPeer wraps a remote node connected over the bft protocol.
+func newPeer(p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
+	known, _ := lru.NewARC(inmemoryMessages)
+	return &peer{
+		Peer:  p,
+		rw:    rw,
+		known: known,
+		queue: make(chan []byte, maxQueuedMsgs),
+		term:  make(chan struct{}),
+	}
+}
+
+// broadcast is a write loop that sends the queued consensus messages to the
+// remote peer, keeping slow peers from blocking the agreement.
+func (p *peer) broadcast() {
+	for {
+		select {
+		case payload := <-p.queue:
+			if err := p2p.Send(p.rw, consensusMsg, payload); err != nil {
+				return
+			}
+		case <-p.term:
+			return
+		}
+	}
+}
+
+// send queues a consensus message to be sent to the peer unless it's already
+// known to have it. Messages are dropped if the peer can't keep up.
+func (p *peer) send(hash common.Hash, payload []byte) {
+	if p.known.Contains(hash) {
+		return
+	}
+	p.known.Add(hash, struct{}{})
+	select {
+	case p.queue <- payload:
+	default:
+		p.Log().Debug("Dropping consensus message", "hash", hash)
+	}
+}
+
+// peerSet is the set of peers connected over the bft protocol.
+type peerSet struct {
+	peers map[string]*peer
+	lock  sync.RWMutex
+}
+
+// newPeerSet creates an empty set of peers.
+func newPeerSet() *peerSet {
+	return &peerSet{peers: make(map[string]*peer)}
+}
+
+// register adds a new peer to the set.
+func (ps *peerSet) register(p *peer) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	ps.peers[p.ID().String()] = p
+}
+
+// unregister removes a peer from the set.
+func (ps *peerSet) unregister(p *peer) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	delete(ps.peers, p.ID().String())
+}
+
+// broadcast queues a consensus message to all the peers not yet knowing it.
+func (ps *peerSet) broadcast(hash common.Hash, payload []byte) {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	for _, p := range ps.peers {
+		p.send(hash, payload)
+	}
+}
+
+// Protocols returns the p2p protocols exchanging the consensus messages between
+// the validators. Messages are only relayed by nodes running the agreement,
+// once verified to be signed by a validator of the height being agreed on.
+func (b *BFT) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{{
+		Name:    protocolName,
+		Version: protocolVersion,
+		Length:  protocolLength,
+		Run:     b.runPeer,
+	}}
+}
+
+// runPeer handles the consensus messages of a remote peer until the connection
+// is torn down.
+func (b *BFT) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	peer := newPeer(p, rw)
+
+	b.peers.register(peer)
+	defer b.peers.unregister(peer)
+
+	go peer.broadcast()
+	defer close(peer.term)
+
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if msg.Size > maxMessageSize {
+			msg.Discard()
+			return fmt.Errorf("message too large: %v > %v", msg.Size, maxMessageSize)
+		}
+		if msg.Code != consensusMsg {
+			msg.Discard()
+			return fmt.Errorf("invalid message code: %v", msg.Code)
+		}
+		var payload []byte
+		if err := msg.Decode(&payload); err != nil {
+			return fmt.Errorf("invalid message %v: %v", msg, err)
+		}
+		b.deliver(peer, payload)
+	}
+}
+
+// deliver validates a consensus message received from a peer and hands it to
+// the agreement, which relays it to the other peers if sent by a validator.
+func (b *BFT) deliver(p *peer, payload []byte) {
+	hash := crypto.Keccak256Hash(payload)
+	p.known.Add(hash, struct{}{})
+
+	if b.known.Contains(hash) {
+		return
+	}
+	b.known.Add(hash, struct{}{})
+
+	msg, err := decodeMessage(payload)
+	if err != nil {
+		p.Log().Debug("Invalid consensus message", "err", err)
+		return
+	}
+	b.startLock.Lock()
+	agreement := b.agreement
+	b.startLock.Unlock()
+
+	if agreement != nil {
+		agreement.deliver(msg)
+	}
+}
+
+// broadcast sends a locally created consensus message to all the peers.
+func (b *BFT) broadcast(payload []byte) {
+	hash := crypto.Keccak256Hash(payload)
+
+	b.known.Add(hash, struct{}{})
+	b.peers.broadcast(hash, payload)
+}
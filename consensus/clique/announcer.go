@@ -0,0 +1,204 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"sync"
+
+	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/log"
+)
+
+// Chain is the blockchain whose canonical signer changes are announced.
+type Chain interface {
+	consensus.ChainReader
+
+	// SubscribeChainHeadEvent subscribes to the changes of the head block.
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// announcer follows the head of the canonical chain and announces the signer
+// changes enacted by the blocks becoming canonical.
+//
+// The head events are consumed by a goroutine of their own, which only records
+// the latest head, so that slow change subscribers never stall the chain.
+type announcer struct {
+	engine *Clique
+	chain  Chain
+
+	headCh  chan core.ChainHeadEvent
+	headSub event.Subscription
+
+	latest *types.Header // Latest head of the chain not yet processed
+	lock   sync.Mutex    // Protects the latest head
+	wake   chan struct{} // Notifies the announce loop of a new head
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newAnnouncer creates an announcer on top of the given chain, announcing the
+// changes of the blocks imported after the current head.
+func newAnnouncer(engine *Clique, chain Chain) *announcer {
+	a := &announcer{
+		engine: engine,
+		chain:  chain,
+		headCh: make(chan core.ChainHeadEvent, 16),
+		wake:   make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+	}
+	a.headSub = chain.SubscribeChainHeadEvent(a.headCh)
+
+	a.wg.Add(2)
+	go a.headLoop()
+	go a.announceLoop(chain.CurrentHeader())
+	return a
+}
+
+// stop terminates the announcer, waiting for the delivery of the changes being
+// announced.
+func (a *announcer) stop() {
+	a.headSub.Unsubscribe()
+	close(a.quit)
+	a.wg.Wait()
+}
+
+// headLoop records the latest head reported by the chain.
+func (a *announcer) headLoop() {
+	defer a.wg.Done()
+
+	for {
+		select {
+		case ev := <-a.headCh:
+			a.lock.Lock()
+			a.latest = ev.Block.Header()
+			a.lock.Unlock()
+
+			select {
+			case a.wake <- struct{}{}:
+			default:
+			}
+		case <-a.headSub.Err():
+			return
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// announceLoop announces the changes between the last processed head and the
+// latest one whenever the chain advances.
+func (a *announcer) announceLoop(last *types.Header) {
+	defer a.wg.Done()
+
+	for {
+		select {
+		case <-a.wake:
+			a.lock.Lock()
+			head := a.latest
+			a.latest = nil
+			a.lock.Unlock()
+
+			if head == nil {
+				continue
+			}
+			changes, err := a.changes(last, head)
+			if err != nil {
+				log.Warn("Failed to collect signer changes", "number", head.Number, "hash", head.Hash(), "err", err)
+			}
+			for _, change := range changes {
+				select {
+				case <-a.quit:
+					return
+				default:
+					a.engine.changeFeed.Send(change)
+				}
+			}
+			last = head
+
+		case <-a.quit:
+			return
+		}
+	}
+}
+
+// changes collects the signer changes enacted by the blocks of the new head's
+// chain after its common ancestor with the last head, oldest first.
+func (a *announcer) changes(last, head *types.Header) ([]SignerChange, error) {
+	var headers []*types.Header
+	for head.Number.Uint64() > last.Number.Uint64() {
+		headers = append(headers, head)
+		if head = a.chain.GetHeader(head.ParentHash, head.Number.Uint64()-1); head == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+	}
+	for last.Number.Uint64() > head.Number.Uint64() {
+		if last = a.chain.GetHeader(last.ParentHash, last.Number.Uint64()-1); last == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+	}
+	for head.Hash() != last.Hash() {
+		headers = append(headers, head)
+		head = a.chain.GetHeader(head.ParentHash, head.Number.Uint64()-1)
+		last = a.chain.GetHeader(last.ParentHash, last.Number.Uint64()-1)
+		if head == nil || last == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+	}
+	// Compare the signers authorized before and after each new block
+	parent, err := a.engine.snapshot(a.chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var changes []SignerChange
+	for i := len(headers) - 1; i >= 0; i-- {
+		header := headers[i]
+
+		snap, err := a.engine.snapshot(a.chain, header.Number.Uint64(), header.Hash(), nil)
+		if err != nil {
+			return changes, err
+		}
+		if change := signerChange(parent, snap); change != nil {
+			changes = append(changes, *change)
+		}
+		parent = snap
+	}
+	return changes, nil
+}
+
+// signerChange returns the change of the authorized signers between a snapshot
+// and the one of its parent block, or nil if the signers are the same. A block
+// changes the authorization of at most one account.
+func signerChange(parent, snap *Snapshot) *SignerChange {
+	change := &SignerChange{Block: snap.Number, Hash: snap.Hash, Signers: snap.signers()}
+	for signer := range snap.Signers {
+		if _, ok := parent.Signers[signer]; !ok {
+			change.Address, change.Authorized = signer, true
+			return change
+		}
+	}
+	for signer := range parent.Signers {
+		if _, ok := snap.Signers[signer]; !ok {
+			change.Address = signer
+			return change
+		}
+	}
+	return nil
+}
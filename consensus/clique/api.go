@@ -17,12 +17,40 @@
 package clique
 
 import (
+	"bytes"
+	"context"
+	"errors"
+
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/consensus"
 	"github.com/okcoin/go-okcoin/core/types"
 	"github.com/okcoin/go-okcoin/rpc"
 )
 
+// maxVoteHistory is the maximum number of blocks whose votes may be retrieved
+// in a single request.
+const maxVoteHistory = 32768
+
+var (
+	// errInvalidVoteRange is returned if the requested vote history range is
+	// reversed or extends beyond the current head.
+	errInvalidVoteRange = errors.New("invalid block range")
+
+	// errVoteRangeTooLarge is returned if the requested vote history range spans
+	// more blocks than allowed.
+	errVoteRangeTooLarge = errors.New("block range too large")
+
+	// errNoFinality is returned if the finalized block is requested from a chain
+	// not tracking finality, such as the one of a light client.
+	errNoFinality = errors.New("finalized block not tracked")
+)
+
+// finalizedChain is implemented by the chains tracking the finalized block.
+type finalizedChain interface {
+	// FinalizedBlock retrieves the highest block that may not be reorganised.
+	FinalizedBlock() *types.Block
+}
+
 // API is a user facing RPC API to allow controlling the signer and voting
 // mechanisms of the proof-of-authority scheme.
 type API struct {
@@ -30,19 +58,36 @@ type API struct {
 	clique *Clique
 }
 
-// GetSnapshot retrieves the state snapshot at a given block.
-func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
-	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
-	} else {
-		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
-	}
-	// Ensure we have an actually valid block and return its snapshot
+// header resolves the requested block number to a canonical header, defaulting
+// to the current head. The pending block resolves to the head too, as its
+// signers are decided by it, while the finalized block needs a chain tracking it.
+func (api *API) header(number *rpc.BlockNumber) (*types.Header, error) {
+	if number == nil || *number == rpc.LatestBlockNumber || *number == rpc.PendingBlockNumber {
+		return api.chain.CurrentHeader(), nil
+	}
+	if *number == rpc.FinalizedBlockNumber {
+		chain, ok := api.chain.(finalizedChain)
+		if !ok {
+			return nil, errNoFinality
+		}
+		return chain.FinalizedBlock().Header(), nil
+	}
+	if *number < 0 {
+		return nil, errUnknownBlock
+	}
+	header := api.chain.GetHeaderByNumber(uint64(*number))
 	if header == nil {
 		return nil, errUnknownBlock
 	}
+	return header, nil
+}
+
+// GetSnapshot retrieves the state snapshot at a given block.
+func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
+	}
 	return api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
 }
 
@@ -57,16 +102,9 @@ func (api *API) GetSnapshotAtHash(hash common.Hash) (*Snapshot, error) {
 
 // GetSigners retrieves the list of authorized signers at the specified block.
 func (api *API) GetSigners(number *rpc.BlockNumber) ([]common.Address, error) {
-	// Retrieve the requested block number (or current if none requested)
-	var header *types.Header
-	if number == nil || *number == rpc.LatestBlockNumber {
-		header = api.chain.CurrentHeader()
-	} else {
-		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
-	}
-	// Ensure we have an actually valid block and return the signers from its snapshot
-	if header == nil {
-		return nil, errUnknownBlock
+	header, err := api.header(number)
+	if err != nil {
+		return nil, err
 	}
 	snap, err := api.clique.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
 	if err != nil {
@@ -117,3 +155,101 @@ func (api *API) Discard(address common.Address) {
 
 	delete(api.clique.proposals, address)
 }
+
+// Status returns the sealing activity of the signers over the given number of
+// most recent blocks (default 64): blocks sealed in and out of turn and turns
+// missed.
+func (api *API) Status(blocks *uint64) (*Status, error) {
+	window := uint64(healthWindow)
+	if blocks != nil {
+		window = *blocks
+	}
+	return api.clique.status(api.chain, api.chain.CurrentHeader(), window)
+}
+
+// GetVotes retrieves every vote cast within the given range of canonical blocks,
+// inclusive. If no end block is given, the range extends up to the current head.
+// Votes are reported as cast, regardless whokcer they counted towards a tally.
+func (api *API) GetVotes(from rpc.BlockNumber, to *rpc.BlockNumber) ([]*Vote, error) {
+	head := api.chain.CurrentHeader().Number.Uint64()
+
+	resolve := func(number rpc.BlockNumber) (uint64, error) {
+		if number >= 0 {
+			return uint64(number), nil // May be beyond the head, rejected below
+		}
+		header, err := api.header(&number)
+		if err != nil {
+			return 0, err
+		}
+		return header.Number.Uint64(), nil
+	}
+	start, err := resolve(from)
+	if err != nil {
+		return nil, err
+	}
+	end := head
+	if to != nil {
+		if end, err = resolve(*to); err != nil {
+			return nil, err
+		}
+	}
+	if start > end || end > head {
+		return nil, errInvalidVoteRange
+	}
+	if end-start >= maxVoteHistory {
+		return nil, errVoteRangeTooLarge
+	}
+	votes := make([]*Vote, 0)
+	for number := start; number <= end; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		// Blocks without a beneficiary (including checkpoints) carry no vote
+		if header.Coinbase == (common.Address{}) {
+			continue
+		}
+		signer, err := ecrecover(header, api.clique.signatures)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, &Vote{
+			Signer:    signer,
+			Block:     number,
+			Address:   header.Coinbase,
+			Authorize: bytes.Equal(header.Nonce[:], nonceAuthVote),
+		})
+	}
+	return votes, nil
+}
+
+// SignerChanges creates a subscription that is notified whenever a vote passes,
+// changing the list of authorized signers.
+func (api *API) SignerChanges(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		changes := make(chan SignerChange, 16)
+		sub := api.clique.SubscribeSignerChanges(changes)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case change := <-changes:
+				notifier.Notify(rpcSub.ID, change)
+			case <-sub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
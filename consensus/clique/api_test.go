@@ -0,0 +1,214 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/params"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// testerHeaderChain implements consensus.ChainReader over a canonical list of
+// headers. All other methods and requests will panic.
+type testerHeaderChain struct {
+	headers []*types.Header
+}
+
+func (c *testerHeaderChain) Config() *params.ChainConfig               { return params.AllCliqueProtocolChanges }
+func (c *testerHeaderChain) CurrentHeader() *types.Header              { return c.headers[len(c.headers)-1] }
+func (c *testerHeaderChain) GetBlock(common.Hash, uint64) *types.Block { panic("not supported") }
+func (c *testerHeaderChain) GetHeaderByHash(common.Hash) *types.Header { panic("not supported") }
+func (c *testerHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+func (c *testerHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number]
+}
+
+// Tests that the vote history reports every vote cast in the requested range.
+func TestGetVotes(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	votes := []testerVote{
+		{signer: "A", voted: "B", auth: true},
+		{signer: "B"},
+		{signer: "A", voted: "C", auth: true},
+		{signer: "B", voted: "A", auth: false},
+		{signer: "A", voted: "C", auth: true},
+	}
+	headers := []*types.Header{{Number: big.NewInt(0)}}
+	for i, vote := range votes {
+		header := &types.Header{
+			Number:     big.NewInt(int64(i) + 1),
+			ParentHash: headers[i].Hash(),
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		if vote.voted != "" {
+			header.Coinbase = accounts.address(vote.voted)
+		}
+		if vote.auth {
+			copy(header.Nonce[:], nonceAuthVote)
+		}
+		accounts.sign(header, vote.signer)
+		headers = append(headers, header)
+	}
+	db, _ := okcdb.NewMemDatabase()
+	api := &API{chain: &testerHeaderChain{headers: headers}, clique: New(&params.CliqueConfig{}, db)}
+
+	// Retrieve the full history and check it against the votes cast
+	history, err := api.GetVotes(0, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve votes: %v", err)
+	}
+	var want []*Vote
+	for i, vote := range votes {
+		if vote.voted != "" {
+			want = append(want, &Vote{Signer: accounts.address(vote.signer), Block: uint64(i) + 1, Address: accounts.address(vote.voted), Authorize: vote.auth})
+		}
+	}
+	if len(history) != len(want) {
+		t.Fatalf("vote count mismatch: have %d, want %d", len(history), len(want))
+	}
+	for i := range want {
+		if *history[i] != *want[i] {
+			t.Errorf("vote %d mismatch: have %+v, want %+v", i, history[i], want[i])
+		}
+	}
+	// Retrieve a sub-range and check invalid ranges
+	to := rpc.BlockNumber(4)
+	if history, err := api.GetVotes(3, &to); err != nil || len(history) != 2 || history[0].Block != 3 {
+		t.Errorf("sub-range mismatch: have %v (err %v), want 2 votes from block 3", history, err)
+	}
+	genesis := rpc.BlockNumber(0)
+	if _, err := api.GetVotes(4, &genesis); err != errInvalidVoteRange {
+		t.Errorf("reversed range error mismatch: have %v, want %v", err, errInvalidVoteRange)
+	}
+	beyond := rpc.BlockNumber(10)
+	if _, err := api.GetVotes(0, &beyond); err != errInvalidVoteRange {
+		t.Errorf("future range error mismatch: have %v, want %v", err, errInvalidVoteRange)
+	}
+	// Resolve the finalized block only on chains tracking it
+	finalized := rpc.FinalizedBlockNumber
+	if _, err := api.GetVotes(0, &finalized); err != errNoFinality {
+		t.Errorf("untracked finality error mismatch: have %v, want %v", err, errNoFinality)
+	}
+	api.chain = &testerFinalizedChain{testerHeaderChain{headers: headers}, 2}
+	if history, err := api.GetVotes(0, &finalized); err != nil || len(history) != 1 || history[0].Block != 1 {
+		t.Errorf("finalized range mismatch: have %v (err %v), want 1 vote from block 1", history, err)
+	}
+}
+
+// testerFinalizedChain is a testerHeaderChain tracking a finalized block.
+type testerFinalizedChain struct {
+	testerHeaderChain
+	finalized uint64
+}
+
+func (c *testerFinalizedChain) FinalizedBlock() *types.Block {
+	return types.NewBlockWithHeader(c.headers[c.finalized])
+}
+
+// Tests that the signer status reports the in-turn, out-of-turn and missed
+// blocks of every signer, including ones that stopped sealing.
+func TestStatus(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	signers := []common.Address{accounts.address("A"), accounts.address("B"), accounts.address("C")}
+	for i := 0; i < len(signers); i++ {
+		for j := i + 1; j < len(signers); j++ {
+			if bytes.Compare(signers[i][:], signers[j][:]) > 0 {
+				signers[i], signers[j] = signers[j], signers[i]
+			}
+		}
+	}
+	names := make(map[common.Address]string)
+	for _, name := range []string{"A", "B", "C"} {
+		names[accounts.address(name)] = name
+	}
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Time:       big.NewInt(0),
+		Difficulty: big.NewInt(1),
+		UncleHash:  uncleHash,
+		Extra:      make([]byte, extraVanity+len(signers)*common.AddressLength+extraSeal),
+	}
+	for i, signer := range signers {
+		copy(genesis.Extra[extraVanity+i*common.AddressLength:], signer[:])
+	}
+	// The last signer stopped sealing, the two others alternate on its turns
+	headers := []*types.Header{genesis}
+	for number := int64(1); number <= 6; number++ {
+		header := &types.Header{
+			Number:     big.NewInt(number),
+			ParentHash: headers[number-1].Hash(),
+			Difficulty: diffNoTurn,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		signer := signers[number%2]
+		if signer == signers[number%3] {
+			header.Difficulty = diffInTurn
+		}
+		accounts.sign(header, names[signer])
+		headers = append(headers, header)
+	}
+	db, _ := okcdb.NewMemDatabase()
+	api := &API{chain: &testerHeaderChain{headers: headers}, clique: New(&params.CliqueConfig{}, db)}
+
+	status, err := api.Status(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve status: %v", err)
+	}
+	if status.From != 1 || status.To != 6 {
+		t.Errorf("window mismatch: have [%d, %d], want [1, 6]", status.From, status.To)
+	}
+	want := map[common.Address]SignerHealth{
+		signers[0]: {InTurn: 1, OutOfTurn: 2, Missed: 1, LastBlock: 6},
+		signers[1]: {InTurn: 1, OutOfTurn: 2, Missed: 1, LastBlock: 5},
+		signers[2]: {Missed: 2},
+	}
+	for signer, health := range want {
+		if have := status.Signers[signer]; have == nil || *have != health {
+			t.Errorf("signer %x health mismatch: have %+v, want %+v", signer, have, health)
+		}
+	}
+	if have, want := status.InTurnPercent, float64(2)*100/6; have != want {
+		t.Errorf("in-turn percentage mismatch: have %v, want %v", have, want)
+	}
+	// A shorter window only accounts for the recent blocks
+	blocks := uint64(2)
+	if status, err = api.Status(&blocks); err != nil {
+		t.Fatalf("failed to retrieve windowed status: %v", err)
+	}
+	if have := status.Signers[signers[2]]; have == nil || have.Missed != 1 {
+		t.Errorf("windowed health mismatch: have %+v, want 1 missed", have)
+	}
+	if _, err := api.Status(new(uint64)); err == nil {
+		t.Errorf("empty window accepted")
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/okcoin/go-okcoin/core/types"
 	"github.com/okcoin/go-okcoin/crypto"
 	"github.com/okcoin/go-okcoin/crypto/sha3"
+	"github.com/okcoin/go-okcoin/event"
 	"github.com/okcoin/go-okcoin/okcdb"
 	"github.com/okcoin/go-okcoin/log"
 	"github.com/okcoin/go-okcoin/params"
@@ -136,6 +137,11 @@ var (
 // backing account.
 type SignerFn func(accounts.Account, []byte) ([]byte, error)
 
+// HeaderSignerFn is a signer callback function to request a header to be signed
+// by a backing account. Contrary to SignerFn, the signer receives the entire
+// header, allowing it to validate what it's signing.
+type HeaderSignerFn func(accounts.Account, *types.Header) ([]byte, error)
+
 // sigHash returns the hash which is used as input for the proof-of-authority
 // signing. It is the hash of the entire header apart from the 65 byte signature
 // contained at the end of the extra data.
@@ -167,6 +173,12 @@ func sigHash(header *types.Header) (hash common.Hash) {
 	return hash
 }
 
+// SealHash returns the hash of a block prior to it being sealed, i.e. the hash
+// the signer of the block has to sign.
+func SealHash(header *types.Header) common.Hash {
+	return sigHash(header)
+}
+
 // ecrecover extracts the Okcoin account address from a signed header.
 func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
 	// If the signature's already cached, return that
@@ -201,10 +213,17 @@ type Clique struct {
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
 
+	changeFeed  event.Feed              // Feed announcing the signer changes
+	changeScope event.SubscriptionScope // Subscription scope tracking the change subscribers
+	announcer   *announcer              // Announcer of the canonical signer changes, nil if not started
+	startLock   sync.Mutex              // Protects the announcer during start and stop
+
+	health *healthTracker // Signer health metrics of the recent blocks
+
 	proposals map[common.Address]bool // Current list of proposals we are pushing
 
 	signer common.Address // Okcoin address of the signing key
-	signFn SignerFn       // Signer function to authorize hashes with
+	signFn HeaderSignerFn // Signer function to authorize headers with
 	lock   sync.RWMutex   // Protects the signer fields
 }
 
@@ -225,6 +244,7 @@ func New(config *params.CliqueConfig, db okcdb.Database) *Clique {
 		db:         db,
 		recents:    recents,
 		signatures: signatures,
+		health:     newHealthTracker(),
 		proposals:  make(map[common.Address]bool),
 	}
 }
@@ -444,6 +464,38 @@ func (c *Clique) snapshot(chain consensus.ChainReader, number uint64, hash commo
 	return snap, err
 }
 
+// Start starts announcing the signer changes enacted by the blocks becoming
+// canonical in the given chain.
+func (c *Clique) Start(chain Chain) error {
+	c.startLock.Lock()
+	defer c.startLock.Unlock()
+
+	if c.announcer != nil {
+		return errors.New("clique engine already started")
+	}
+	c.announcer = newAnnouncer(c, chain)
+	return nil
+}
+
+// Stop terminates the announcement of the signer changes.
+func (c *Clique) Stop() {
+	c.startLock.Lock()
+	defer c.startLock.Unlock()
+
+	if c.announcer != nil {
+		c.announcer.stop()
+		c.announcer = nil
+	}
+}
+
+// SubscribeSignerChanges subscribes to the changes of the authorized signers.
+// Changes are announced once the blocks enacting them become canonical, which
+// requires the engine to be started. Changes of blocks reorged out of the chain
+// are not retracted.
+func (c *Clique) SubscribeSignerChanges(ch chan<- SignerChange) event.Subscription {
+	return c.changeScope.Track(c.changeFeed.Subscribe(ch))
+}
+
 // VerifyUncles implements consensus.Engine, always returning an error for any
 // uncles as this consensus mechanism doesn't permit uncles.
 func (c *Clique) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
@@ -499,6 +551,7 @@ func (c *Clique) verifySeal(chain consensus.ChainReader, header *types.Header, p
 	if !inturn && header.Difficulty.Cmp(diffNoTurn) != 0 {
 		return errInvalidDifficulty
 	}
+	c.health.record(snap, header, signer)
 	return nil
 }
 
@@ -581,6 +634,14 @@ func (c *Clique) Finalize(chain consensus.ChainReader, header *types.Header, sta
 // Authorize injects a private key into the consensus engine to mint new blocks
 // with.
 func (c *Clique) Authorize(signer common.Address, signFn SignerFn) {
+	c.AuthorizeHeaderSigner(signer, func(account accounts.Account, header *types.Header) ([]byte, error) {
+		return signFn(account, sigHash(header).Bytes())
+	})
+}
+
+// AuthorizeHeaderSigner injects a header signer into the consensus engine to
+// mint new blocks with, allowing the signing key to be kept outside the node.
+func (c *Clique) AuthorizeHeaderSigner(signer common.Address, signFn HeaderSignerFn) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -643,11 +704,12 @@ func (c *Clique) Seal(chain consensus.ChainReader, block *types.Block, stop <-ch
 	case <-time.After(delay):
 	}
 	// Sign all the things!
-	sighash, err := signFn(accounts.Account{Address: signer}, sigHash(header).Bytes())
+	sighash, err := signFn(accounts.Account{Address: signer}, header)
 	if err != nil {
 		return nil, err
 	}
 	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+	c.health.record(snap, header, signer)
 
 	return block.WithSeal(header), nil
 }
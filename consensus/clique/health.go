@@ -0,0 +1,200 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/metrics"
+)
+
+const (
+	healthWindow    = 64   // Number of recent blocks the signer health metrics are computed over
+	maxStatusWindow = 4096 // Maximum number of blocks a signer status may be computed over
+)
+
+// SignerHealth is the sealing activity of a single signer over a window of blocks.
+type SignerHealth struct {
+	InTurn    uint64 `json:"inTurn"`    // Number of blocks sealed in the signer's turn
+	OutOfTurn uint64 `json:"outOfTurn"` // Number of blocks sealed out of the signer's turn
+	Missed    uint64 `json:"missed"`    // Number of the signer's turns sealed by others
+	LastBlock uint64 `json:"lastBlock"` // Last block sealed by the signer in the window (0 = none)
+}
+
+// Status is the sealing activity of the signers over a window of blocks.
+type Status struct {
+	From          uint64                           `json:"from"`          // First block of the window
+	To            uint64                           `json:"to"`            // Last block of the window
+	InTurnPercent float64                          `json:"inTurnPercent"` // Percentage of blocks sealed in-turn
+	Signers       map[common.Address]*SignerHealth `json:"signers"`       // Activity of the individual signers
+}
+
+// account adds a block sealed by signer on top of the parent snapshot to the
+// status.
+func (s *Status) account(snap *Snapshot, header *types.Header, signer common.Address) {
+	number := header.Number.Uint64()
+
+	health := s.health(signer)
+	health.LastBlock = number
+
+	if header.Difficulty.Cmp(diffInTurn) == 0 {
+		health.InTurn++
+		return
+	}
+	health.OutOfTurn++
+	if expected := snap.inturnSigner(number); expected != signer {
+		s.health(expected).Missed++
+	}
+}
+
+// health retrieves the activity of a signer, creating it if not yet tracked.
+func (s *Status) health(signer common.Address) *SignerHealth {
+	health, ok := s.Signers[signer]
+	if !ok {
+		health = new(SignerHealth)
+		s.Signers[signer] = health
+	}
+	return health
+}
+
+// status computes the sealing activity of the signers over the given number of
+// blocks ending with head. All current signers are included, even if inactive.
+func (c *Clique) status(chain consensus.ChainReader, head *types.Header, blocks uint64) (*Status, error) {
+	if blocks == 0 || blocks > maxStatusWindow {
+		return nil, fmt.Errorf("invalid status window %d, must be in [1, %d]", blocks, maxStatusWindow)
+	}
+	// Gather the headers of the window, the genesis block is not signed
+	number := head.Number.Uint64()
+	if blocks > number {
+		blocks = number
+	}
+	headers := make([]*types.Header, blocks)
+	for i := len(headers) - 1; i >= 0; i-- {
+		headers[i] = head
+		if head = chain.GetHeader(head.ParentHash, head.Number.Uint64()-1); head == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+	}
+	status := &Status{
+		From:    number - blocks + 1,
+		To:      number,
+		Signers: make(map[common.Address]*SignerHealth),
+	}
+	if len(headers) == 0 {
+		status.From = number
+	}
+	// Replay the window on top of the snapshot preceding it
+	snap, err := c.snapshot(chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var inturn uint64
+	for _, header := range headers {
+		signer, err := ecrecover(header, c.signatures)
+		if err != nil {
+			return nil, err
+		}
+		status.account(snap, header, signer)
+		if header.Difficulty.Cmp(diffInTurn) == 0 {
+			inturn++
+		}
+		if snap, err = snap.apply([]*types.Header{header}); err != nil {
+			return nil, err
+		}
+	}
+	for signer := range snap.Signers {
+		status.health(signer)
+	}
+	if len(headers) > 0 {
+		status.InTurnPercent = float64(inturn) * 100 / float64(len(headers))
+	}
+	return status, nil
+}
+
+// healthRecord is the sealing outcome of a single block.
+type healthRecord struct {
+	number   uint64
+	signer   common.Address // Signer that sealed the block
+	expected common.Address // Signer whose turn the block was
+	inturn   bool
+}
+
+// healthTracker maintains the signer health metrics over the most recent blocks
+// sealed or verified locally.
+type healthTracker struct {
+	records [healthWindow]*healthRecord // Ring of recent blocks, indexed by number
+	known   map[common.Address]struct{} // Signers with metrics already reported
+	lock    sync.Mutex
+}
+
+// newHealthTracker creates a tracker for the signer health metrics.
+func newHealthTracker() *healthTracker {
+	return &healthTracker{known: make(map[common.Address]struct{})}
+}
+
+// record adds the sealing outcome of a block on top of the parent snapshot and
+// updates the metrics of all signers in the window.
+func (t *healthTracker) record(snap *Snapshot, header *types.Header, signer common.Address) {
+	number := header.Number.Uint64()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.records[number%healthWindow] = &healthRecord{
+		number:   number,
+		signer:   signer,
+		expected: snap.inturnSigner(number),
+		inturn:   header.Difficulty.Cmp(diffInTurn) == 0,
+	}
+	// Aggregate the window ending with this block (reorgs may leave stale ones)
+	status := &Status{Signers: make(map[common.Address]*SignerHealth)}
+	for _, record := range t.records {
+		if record == nil || record.number > number || record.number+healthWindow <= number {
+			continue
+		}
+		health := status.health(record.signer)
+		if record.inturn {
+			health.InTurn++
+		} else {
+			health.OutOfTurn++
+			if record.expected != record.signer {
+				status.health(record.expected).Missed++
+			}
+		}
+	}
+	for signer := range snap.Signers {
+		status.health(signer)
+	}
+	// Report all signers, zeroing the ones that left the window
+	for signer := range t.known {
+		if _, ok := status.Signers[signer]; !ok {
+			status.Signers[signer] = new(SignerHealth)
+		}
+	}
+	for signer, health := range status.Signers {
+		t.known[signer] = struct{}{}
+
+		prefix := fmt.Sprintf("clique/signer/%x/", signer)
+		metrics.GetOrRegisterGauge(prefix+"inturn", nil).Update(int64(health.InTurn))
+		metrics.GetOrRegisterGauge(prefix+"outofturn", nil).Update(int64(health.OutOfTurn))
+		metrics.GetOrRegisterGauge(prefix+"missed", nil).Update(int64(health.Missed))
+	}
+}
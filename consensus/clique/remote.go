@@ -0,0 +1,112 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/okcoin/go-okcoin/accounts"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// remoteSignTimeout is the maximum time to wait for an external signer to
+// answer a request.
+const remoteSignTimeout = 10 * time.Second
+
+// RemoteSigner is a client of an external signer holding the sealing key outside
+// of the node. The signer may be reached over any JSON-RPC transport and has to
+// serve the signer_account and signer_signHeader methods. As the signer gets the
+// full header instead of just its seal hash, it can check every request against
+// its own rules before signing.
+type RemoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// DialRemoteSigner connects to an external signer listening on the given IPC
+// path or HTTP/WebSocket URL.
+func DialRemoteSigner(endpoint string) (*RemoteSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := NewRemoteSigner(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return signer, nil
+}
+
+// NewRemoteSigner creates a remote signer using an already established RPC
+// connection, retrieving the account the signer seals with.
+func NewRemoteSigner(client *rpc.Client) (*RemoteSigner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSignTimeout)
+	defer cancel()
+
+	var address common.Address
+	if err := client.CallContext(ctx, &address, "signer_account"); err != nil {
+		return nil, err
+	}
+	return &RemoteSigner{client: client, address: address}, nil
+}
+
+// Address returns the account the remote signer seals blocks with.
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignHeader requests the remote signer to seal the header, implementing the
+// HeaderSignerFn callback. The returned signature is verified to be made over
+// the seal hash of the header by the expected account.
+func (s *RemoteSigner) SignHeader(account accounts.Account, header *types.Header) ([]byte, error) {
+	if account.Address != s.address {
+		return nil, fmt.Errorf("remote signer account mismatch: have %x, want %x", s.address, account.Address)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSignTimeout)
+	defer cancel()
+
+	var signature hexutil.Bytes
+	if err := s.client.CallContext(ctx, &signature, "signer_signHeader", header); err != nil {
+		return nil, err
+	}
+	if len(signature) != extraSeal {
+		return nil, fmt.Errorf("invalid remote signature length: have %d, want %d", len(signature), extraSeal)
+	}
+	pubkey, err := crypto.Ecrecover(SealHash(header).Bytes(), signature)
+	if err != nil {
+		return nil, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	if signer != s.address {
+		return nil, fmt.Errorf("remote signature by wrong account: have %x, want %x", signer, s.address)
+	}
+	return signature, nil
+}
+
+// Close terminates the connection to the remote signer.
+func (s *RemoteSigner) Close() {
+	s.client.Close()
+}
@@ -43,6 +43,16 @@ type Tally struct {
 	Votes     int  `json:"votes"`     // Number of votes until now wanting to pass the proposal
 }
 
+// SignerChange is the event of a vote passing, changing the list of authorized
+// signers.
+type SignerChange struct {
+	Block      uint64           `json:"block"`      // Block number the change took effect in
+	Hash       common.Hash      `json:"hash"`       // Hash of the block the change took effect in
+	Address    common.Address   `json:"address"`    // Account whose authorization was changed
+	Authorized bool             `json:"authorized"` // Whokcer the account was authorized or deauthorized
+	Signers    []common.Address `json:"signers"`    // List of authorized signers after the change
+}
+
 // Snapshot is the state of the authorization voting at a given point in time.
 type Snapshot struct {
 	config   *params.CliqueConfig // Consensus engine parameters to fine tune behavior
@@ -300,6 +310,12 @@ func (s *Snapshot) signers() []common.Address {
 	return signers
 }
 
+// inturnSigner returns the signer whose turn it is at a given block height.
+func (s *Snapshot) inturnSigner(number uint64) common.Address {
+	signers := s.signers()
+	return signers[number%uint64(len(signers))]
+}
+
 // inturn returns if a signer at a given block height is in-turn or not.
 func (s *Snapshot) inturn(number uint64, signer common.Address) bool {
 	signers, offset := s.signers(), 0
@@ -20,12 +20,15 @@ import (
 	"bytes"
 	"crypto/ecdsa"
 	"math/big"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/core"
 	"github.com/okcoin/go-okcoin/core/types"
 	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/event"
 	"github.com/okcoin/go-okcoin/okcdb"
 	"github.com/okcoin/go-okcoin/params"
 )
@@ -403,3 +406,141 @@ func TestVoting(t *testing.T) {
 		}
 	}
 }
+
+// testerHeadChain implements Chain over a set of headers, reporting the head of
+// the canonical list as set by the test.
+type testerHeadChain struct {
+	canonical []*types.Header
+	headers   map[common.Hash]*types.Header
+	lock      sync.Mutex
+
+	feed event.Feed
+}
+
+func (c *testerHeadChain) Config() *params.ChainConfig               { return params.AllCliqueProtocolChanges }
+func (c *testerHeadChain) GetBlock(common.Hash, uint64) *types.Block { panic("not supported") }
+func (c *testerHeadChain) GetHeaderByHash(common.Hash) *types.Header { panic("not supported") }
+func (c *testerHeadChain) CurrentHeader() *types.Header {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.canonical[len(c.canonical)-1]
+}
+func (c *testerHeadChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+func (c *testerHeadChain) GetHeaderByNumber(number uint64) *types.Header {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if number >= uint64(len(c.canonical)) {
+		return nil
+	}
+	return c.canonical[number]
+}
+func (c *testerHeadChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return c.feed.Subscribe(ch)
+}
+
+// setHead makes the given headers the canonical chain and reports its head.
+func (c *testerHeadChain) setHead(canonical []*types.Header) {
+	c.lock.Lock()
+	c.canonical = canonical
+	for _, header := range canonical {
+		c.headers[header.Hash()] = header
+	}
+	c.lock.Unlock()
+
+	c.feed.Send(core.ChainHeadEvent{Block: types.NewBlockWithHeader(canonical[len(canonical)-1])})
+}
+
+// Tests that signer changes are announced once the blocks enacting them become
+// canonical, and not for blocks of side chains.
+func TestSignerChanges(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	// Create a genesis with a single signer, voting in a second one
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+common.AddressLength+extraSeal),
+	}
+	copy(genesis.ExtraData[extraVanity:], accounts.address("A").Bytes())
+
+	db, _ := okcdb.NewMemDatabase()
+	genesisHeader := genesis.MustCommit(db).Header()
+
+	// Create the chain enacting the vote and a side chain without it
+	build := func(votes []testerVote) []*types.Header {
+		headers := []*types.Header{genesisHeader}
+		for i, vote := range votes {
+			header := &types.Header{
+				Number:     big.NewInt(int64(i) + 1),
+				Time:       big.NewInt(int64(i) * int64(blockPeriod)),
+				ParentHash: headers[i].Hash(),
+				Coinbase:   accounts.address(vote.voted),
+				Extra:      make([]byte, extraVanity+extraSeal),
+			}
+			if vote.auth {
+				copy(header.Nonce[:], nonceAuthVote)
+			}
+			accounts.sign(header, vote.signer)
+			headers = append(headers, header)
+		}
+		return headers
+	}
+	voting := build([]testerVote{
+		{signer: "A", voted: "B", auth: true},
+		{signer: "B"},
+		{signer: "A"},
+	})
+	side := build([]testerVote{
+		{signer: "A"},
+		{signer: "A"},
+	})
+	chain := &testerHeadChain{canonical: voting[:1], headers: make(map[common.Hash]*types.Header)}
+	chain.headers[genesisHeader.Hash()] = genesisHeader
+
+	engine := New(&params.CliqueConfig{}, db)
+	if err := engine.Start(chain); err != nil {
+		t.Fatalf("failed to start engine: %v", err)
+	}
+	defer engine.Stop()
+
+	changes := make(chan SignerChange, 10)
+	sub := engine.SubscribeSignerChanges(changes)
+	defer sub.Unsubscribe()
+
+	expectChange := func() {
+		select {
+		case change := <-changes:
+			if change.Block != 1 || change.Hash != voting[1].Hash() || change.Address != accounts.address("B") || !change.Authorized {
+				t.Errorf("signer change mismatch: have %+v", change)
+			}
+			if len(change.Signers) != 2 {
+				t.Errorf("signer list mismatch: have %x, want 2 signers", change.Signers)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("signer change not announced")
+		}
+	}
+	expectNone := func() {
+		select {
+		case change := <-changes:
+			t.Errorf("unexpected signer change: %+v", change)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	// Import the voting chain, announcing the change once
+	chain.setHead(voting)
+	expectChange()
+	chain.setHead(voting)
+	expectNone()
+
+	// Reorg to the side chain without the vote and back again
+	chain.setHead(side)
+	expectNone()
+	chain.setHead(voting)
+	expectChange()
+}
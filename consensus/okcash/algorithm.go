@@ -152,6 +152,14 @@ func generateCache(dest []uint32, epoch uint64, seed []byte) {
 	// Start a monitoring goroutine to report progress on low end devices
 	var progress uint32
 
+	total := uint32(rows) * (cacheRounds + 1)
+	percent := total / 100
+	if percent == 0 {
+		percent = 1
+	}
+	reporter := generations.start(cacheGeneration, epoch, uint64(total))
+	defer reporter.finish()
+
 	done := make(chan struct{})
 	defer close(done)
 
@@ -172,7 +180,9 @@ func generateCache(dest []uint32, epoch uint64, seed []byte) {
 	keccak512(cache, seed)
 	for offset := uint64(hashBytes); offset < size; offset += hashBytes {
 		keccak512(cache[offset:], cache[offset-hashBytes:offset])
-		atomic.AddUint32(&progress, 1)
+		if status := atomic.AddUint32(&progress, 1); status%percent == 0 {
+			reporter.report(uint64(status))
+		}
 	}
 	// Use a low-round version of randmemohash
 	temp := make([]byte, hashBytes)
@@ -187,7 +197,9 @@ func generateCache(dest []uint32, epoch uint64, seed []byte) {
 			bitutil.XORBytes(temp, cache[srcOff:srcOff+hashBytes], cache[xorOff:xorOff+hashBytes])
 			keccak512(cache[dstOff:], temp)
 
-			atomic.AddUint32(&progress, 1)
+			if status := atomic.AddUint32(&progress, 1); status%percent == 0 {
+				reporter.report(uint64(status))
+			}
 		}
 	}
 	// Swap the byte order on big endian systems and return
@@ -293,6 +305,9 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 	var pend sync.WaitGroup
 	pend.Add(threads)
 
+	reporter := generations.start(datasetGeneration, epoch, size/hashBytes)
+	defer reporter.finish()
+
 	var progress uint32
 	for i := 0; i < threads; i++ {
 		go func(id int) {
@@ -310,6 +325,9 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 			}
 			// Calculate the dataset segment
 			percent := uint32(size / hashBytes / 100)
+			if percent == 0 {
+				percent = 1
+			}
 			for index := first; index < limit; index++ {
 				item := generateDatasetItem(cache, index, keccak512)
 				if swapped {
@@ -319,6 +337,7 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 
 				if status := atomic.AddUint32(&progress, 1); status%percent == 0 {
 					logger.Info("Generating DAG in progress", "percentage", uint64(status*100)/(size/hashBytes), "elapsed", common.PrettyDuration(time.Since(start)))
+					reporter.report(uint64(status))
 				}
 			}
 		}(i)
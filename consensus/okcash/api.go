@@ -0,0 +1,66 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcash
+
+import (
+	"context"
+
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// API is a user facing RPC API to monitor the generation of the okcash
+// verification caches and mining datasets.
+type API struct {
+	okcash *Okcash
+}
+
+// GenerationProgress returns the progress of the cache and dataset generations
+// currently running.
+func (api *API) GenerationProgress() []GenerationProgress {
+	return ActiveGenerations()
+}
+
+// Generation creates a subscription that is notified of the progress of every
+// cache and dataset generation.
+func (api *API) Generation(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		updates := make(chan GenerationProgress, 16)
+		sub := SubscribeGenerationProgress(updates)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case update := <-updates:
+				notifier.Notify(rpcSub.ID, update)
+			case <-sub.Err():
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -36,10 +36,16 @@ import (
 
 // Okcash proof-of-work protocol constants.
 var (
-	FrontierBlockReward    *big.Int = big.NewInt(5e+18) // Block reward in wei for successfully mining a block
-	ByzantiumBlockReward   *big.Int = big.NewInt(3e+18) // Block reward in wei for successfully mining a block upward from Byzantium
-	maxUncles                       = 2                 // Maximum number of uncles allowed in a single block
-	allowedFutureBlockTime          = 15 * time.Second  // Max time from current time allowed for blocks, before they're considered future blocks
+	FrontierBlockReward    *big.Int = big.NewInt(5e+18)   // Block reward in wei for successfully mining a block
+	ByzantiumBlockReward   *big.Int = big.NewInt(3e+18)   // Block reward in wei for successfully mining a block upward from Byzantium
+	defaultBlockReward              = big.NewInt(1e+18)   // Block reward in wei if none is configured in the chain config
+	byzantiumBombDelay              = big.NewInt(3000000) // Blocks the difficulty bomb is delayed by from Byzantium if none is configured
+	maxUncles                       = 2                   // Maximum number of uncles allowed in a single block
+	allowedFutureBlockTime          = 15 * time.Second    // Max time from current time allowed for blocks, before they're considered future blocks
+
+	// initialSupplyReward is the reward in wei of the first blocks, minting the
+	// initial supply if no block reward is configured in the chain config.
+	initialSupplyReward = new(big.Int).Mul(big.NewInt(1e+11), big.NewInt(1e+18))
 )
 
 // Various error messages to mark blocks invalid. These should be private to
@@ -56,6 +62,10 @@ var (
 	errInvalidDifficulty = errors.New("non-positive difficulty")
 	errInvalidMixDigest  = errors.New("invalid mix digest")
 	errInvalidPoW        = errors.New("invalid proof-of-work")
+
+	// ErrLowShareDifficulty is returned by VerifyShare for nonces not satisfying
+	// the share difficulty.
+	ErrLowShareDifficulty = errors.New("share below difficulty")
 )
 
 // Author implements consensus.Engine, returning the header's coinbase as the
@@ -296,13 +306,31 @@ func (okcash *Okcash) CalcDifficulty(chain consensus.ChainReader, time uint64, p
 // given the parent block's time and difficulty.
 func CalcDifficulty(config *params.ChainConfig, time uint64, parent *types.Header) *big.Int {
 	next := new(big.Int).Add(parent.Number, big1)
+
+	// Resolve the configured parameters, falling back to the protocol defaults
+	rules := config.Okcash.Params(next)
+
+	divisor := rules.DifficultyBoundDivisor
+	if divisor == nil {
+		divisor = params.DifficultyBoundDivisor
+	}
+	delay := rules.BombDelay
 	switch {
 	case config.IsByzantium(next):
-		return calcDifficultyByzantium(time, parent)
+		if delay == nil {
+			delay = byzantiumBombDelay
+		}
+		return calcDifficultyByzantium(time, parent, divisor, delay)
 	case config.IsHomestead(next):
-		return calcDifficultyHomestead(time, parent)
+		if delay == nil {
+			delay = common.Big0
+		}
+		return calcDifficultyHomestead(time, parent, divisor, delay)
 	default:
-		return calcDifficultyFrontier(time, parent)
+		if delay == nil {
+			delay = common.Big0
+		}
+		return calcDifficultyFrontier(time, parent, divisor, delay)
 	}
 }
 
@@ -314,13 +342,23 @@ var (
 	big9          = big.NewInt(9)
 	big10         = big.NewInt(10)
 	bigMinus99    = big.NewInt(-99)
-	big2999999    = big.NewInt(2999999)
 )
 
+// bombPeriod returns the number of periods of the exponential difficulty factor
+// for the block following parent, with the bomb delayed by the given number of
+// blocks.
+func bombPeriod(parent *types.Header, delay *big.Int) *big.Int {
+	period := new(big.Int).Add(parent.Number, big1)
+	if period.Sub(period, delay).Sign() < 0 {
+		period.SetUint64(0)
+	}
+	return period.Div(period, expDiffPeriod)
+}
+
 // calcDifficultyByzantium is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time given the
 // parent block's time and difficulty. The calculation uses the Byzantium rules.
-func calcDifficultyByzantium(time uint64, parent *types.Header) *big.Int {
+func calcDifficultyByzantium(time uint64, parent *types.Header, divisor, delay *big.Int) *big.Int {
 	// https://github.com/okcoin/EIPs/issues/100.
 	// algorithm:
 	// diff = (parent_diff +
@@ -347,7 +385,7 @@ func calcDifficultyByzantium(time uint64, parent *types.Header) *big.Int {
 		x.Set(bigMinus99)
 	}
 	// parent_diff + (parent_diff / 2048 * max((2 if len(parent.uncles) else 1) - ((timestamp - parent.timestamp) // 9), -99))
-	y.Div(parent.Difficulty, params.DifficultyBoundDivisor)
+	y.Div(parent.Difficulty, divisor)
 	x.Mul(y, x)
 	x.Add(parent.Difficulty, x)
 
@@ -355,16 +393,11 @@ func calcDifficultyByzantium(time uint64, parent *types.Header) *big.Int {
 	if x.Cmp(params.MinimumDifficulty) < 0 {
 		x.Set(params.MinimumDifficulty)
 	}
-	// calculate a fake block number for the ice-age delay:
+	// calculate the exponential factor from a fake block number for the ice-age
+	// delay (3_000_000 blocks unless configured otherwise):
 	//   https://github.com/okcoin/EIPs/pull/669
-	//   fake_block_number = min(0, block.number - 3_000_000
-	fakeBlockNumber := new(big.Int)
-	if parent.Number.Cmp(big2999999) >= 0 {
-		fakeBlockNumber = fakeBlockNumber.Sub(parent.Number, big2999999) // Note, parent is 1 less than the actual block number
-	}
-	// for the exponential factor
-	periodCount := fakeBlockNumber
-	periodCount.Div(periodCount, expDiffPeriod)
+	//   fake_block_number = max(0, block.number - bomb_delay)
+	periodCount := bombPeriod(parent, delay)
 
 	// the exponential factor, commonly referred to as "the bomb"
 	// diff = diff + 2^(periodCount - 2)
@@ -379,7 +412,7 @@ func calcDifficultyByzantium(time uint64, parent *types.Header) *big.Int {
 // calcDifficultyHomestead is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time given the
 // parent block's time and difficulty. The calculation uses the Homestead rules.
-func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
+func calcDifficultyHomestead(time uint64, parent *types.Header, divisor, delay *big.Int) *big.Int {
 	// https://github.com/okcoin/EIPs/blob/master/EIPS/eip-2.md
 	// algorithm:
 	// diff = (parent_diff +
@@ -403,7 +436,7 @@ func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
 		x.Set(bigMinus99)
 	}
 	// (parent_diff + parent_diff // 2048 * max(1 - (block_timestamp - parent_timestamp) // 10, -99))
-	y.Div(parent.Difficulty, params.DifficultyBoundDivisor)
+	y.Div(parent.Difficulty, divisor)
 	x.Mul(y, x)
 	x.Add(parent.Difficulty, x)
 
@@ -412,8 +445,7 @@ func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
 		x.Set(params.MinimumDifficulty)
 	}
 	// for the exponential factor
-	periodCount := new(big.Int).Add(parent.Number, big1)
-	periodCount.Div(periodCount, expDiffPeriod)
+	periodCount := bombPeriod(parent, delay)
 
 	// the exponential factor, commonly referred to as "the bomb"
 	// diff = diff + 2^(periodCount - 2)
@@ -428,9 +460,9 @@ func calcDifficultyHomestead(time uint64, parent *types.Header) *big.Int {
 // calcDifficultyFrontier is the difficulty adjustment algorithm. It returns the
 // difficulty that a new block should have when created at time given the parent
 // block's time and difficulty. The calculation uses the Frontier rules.
-func calcDifficultyFrontier(time uint64, parent *types.Header) *big.Int {
+func calcDifficultyFrontier(time uint64, parent *types.Header, divisor, delay *big.Int) *big.Int {
 	diff := new(big.Int)
-	adjust := new(big.Int).Div(parent.Difficulty, params.DifficultyBoundDivisor)
+	adjust := new(big.Int).Div(parent.Difficulty, divisor)
 	bigTime := new(big.Int)
 	bigParentTime := new(big.Int)
 
@@ -446,8 +478,7 @@ func calcDifficultyFrontier(time uint64, parent *types.Header) *big.Int {
 		diff.Set(params.MinimumDifficulty)
 	}
 
-	periodCount := new(big.Int).Add(parent.Number, big1)
-	periodCount.Div(periodCount, expDiffPeriod)
+	periodCount := bombPeriod(parent, delay)
 	if periodCount.Cmp(big1) > 0 {
 		// diff = diff + 2^(periodCount - 2)
 		expDiff := periodCount.Sub(periodCount, big2)
@@ -500,6 +531,41 @@ func (okcash *Okcash) VerifySeal(chain consensus.ChainReader, header *types.Head
 	return nil
 }
 
+// VerifyShare checks whether a nonce is a valid solution of the header's seal at
+// a difficulty usually lower than the header's own, as used by mining pools to
+// account for the work of individual miners. It returns the mix digest of the
+// solution and whether it also satisfies the header's difficulty, i.e. whether
+// the block can be sealed with it. Nonces not meeting the share difficulty are
+// rejected with ErrLowShareDifficulty.
+func (okcash *Okcash) VerifyShare(header *types.Header, nonce types.BlockNonce, difficulty *big.Int) (common.Hash, bool, error) {
+	// If we're running a fake PoW, accept any share as a valid block
+	if okcash.config.PowMode == ModeFake || okcash.config.PowMode == ModeFullFake {
+		return common.Hash{}, true, nil
+	}
+	// If we're running a shared PoW, delegate verification to it
+	if okcash.shared != nil {
+		return okcash.shared.VerifyShare(header, nonce, difficulty)
+	}
+	if difficulty.Sign() <= 0 || header.Difficulty.Sign() <= 0 {
+		return common.Hash{}, false, errInvalidDifficulty
+	}
+	number := header.Number.Uint64()
+
+	cache := okcash.cache(number)
+	size := datasetSize(number)
+	if okcash.config.PowMode == ModeTest {
+		size = 32 * 1024
+	}
+	digest, result := hashimotoLight(size, cache.cache, header.HashNoNonce().Bytes(), nonce.Uint64())
+	runtime.KeepAlive(cache)
+
+	value := new(big.Int).SetBytes(result)
+	if value.Cmp(new(big.Int).Div(maxUint256, difficulty)) > 0 {
+		return common.Hash{}, false, ErrLowShareDifficulty
+	}
+	return common.BytesToHash(digest), value.Cmp(new(big.Int).Div(maxUint256, header.Difficulty)) <= 0, nil
+}
+
 // Prepare implements consensus.Engine, initializing the difficulty field of a
 // header to conform to the okcash protocol. The changes are done inline.
 func (okcash *Okcash) Prepare(chain consensus.ChainReader, header *types.Header) error {
@@ -533,35 +599,36 @@ var (
 // included uncles. The coinbase of each uncle block is also rewarded.
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
 	// Select the correct block reward based on chain progression
-	blockReward := FrontierBlockReward
-	if config.IsByzantium(header.Number) {
-		blockReward = ByzantiumBlockReward
+	rewards := config.Okcash.Params(header.Number)
+
+	blockReward := rewards.BlockReward
+	if blockReward == nil {
+		// The default schedule mints the initial supply on the first blocks
+		if header.Number.Cmp(big1) <= 0 {
+			state.AddBalance(header.Coinbase, initialSupplyReward)
+			return
+		}
+		blockReward = defaultBlockReward
 	}
-
-	if header.Number.Cmp(big.NewInt(1)) > 0 {
-		blockReward = big.NewInt(1e+18)
-	} else {
-
-		bigReward := new(big.Int)
-		bigReward.SetString("100000000000000000000000000000", 10)
-
-		state.AddBalance(header.Coinbase, bigReward)
-
-		return
+	uncleReward := rewards.UncleReward
+	if uncleReward == nil {
+		uncleReward = blockReward
+	}
+	inclusionReward := rewards.InclusionReward
+	if inclusionReward == nil {
+		inclusionReward = new(big.Int).Div(blockReward, big32)
 	}
-
 	// Accumulate the rewards for the miner and any included uncles
 	reward := new(big.Int).Set(blockReward)
 	r := new(big.Int)
 	for _, uncle := range uncles {
 		r.Add(uncle.Number, big8)
 		r.Sub(r, header.Number)
-		r.Mul(r, blockReward)
+		r.Mul(r, uncleReward)
 		r.Div(r, big8)
 		state.AddBalance(uncle.Coinbase, r)
 
-		r.Div(blockReward, big32)
-		reward.Add(reward, r)
+		reward.Add(reward, inclusionReward)
 	}
 	state.AddBalance(header.Coinbase, reward)
 }
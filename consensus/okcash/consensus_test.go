@@ -23,8 +23,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/common/math"
+	"github.com/okcoin/go-okcoin/core/state"
 	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/okcdb"
 	"github.com/okcoin/go-okcoin/params"
 )
 
@@ -84,3 +87,169 @@ func TestCalcDifficulty(t *testing.T) {
 		}
 	}
 }
+
+// testForkConfig returns a chain config activating the given fork from genesis.
+func testForkConfig(fork string, okcash *params.OkcashConfig) *params.ChainConfig {
+	config := &params.ChainConfig{Okcash: okcash}
+	switch fork {
+	case "byzantium":
+		config.ByzantiumBlock = big.NewInt(0)
+		fallthrough
+	case "homestead":
+		config.HomesteadBlock = big.NewInt(0)
+	}
+	return config
+}
+
+// Tests that the difficulty calculation without configured okcash parameters
+// matches the previously hardcoded protocol rules.
+func TestCalcDifficultyDefaults(t *testing.T) {
+	tests := []struct {
+		fork   string
+		number int64
+		delta  uint64
+		uncles bool
+		want   string
+	}{
+		{"frontier", 1, 1, false, "50024414062"},
+		{"frontier", 1, 30, false, "49975585938"},
+		{"frontier", 1, 2000, false, "49975585938"},
+		{"frontier", 3199999, 1, false, "51098155886"},
+		{"frontier", 3199999, 30, false, "51049327762"},
+		{"frontier", 3199999, 2000, false, "51049327762"},
+		{"frontier", 5000000, 1, false, "281525001124718"},
+		{"frontier", 5000000, 30, false, "281524952296594"},
+		{"frontier", 5000000, 2000, false, "281524952296594"},
+		{"homestead", 1, 1, false, "50024414062"},
+		{"homestead", 1, 30, false, "49951171876"},
+		{"homestead", 1, 2000, false, "47583007862"},
+		{"homestead", 3199999, 1, false, "51098155886"},
+		{"homestead", 3199999, 30, false, "51024913700"},
+		{"homestead", 3199999, 2000, false, "48656749686"},
+		{"homestead", 5000000, 1, false, "281525001124718"},
+		{"homestead", 5000000, 30, false, "281524927882532"},
+		{"homestead", 5000000, 2000, false, "281522559718518"},
+		{"byzantium", 1, 1, false, "50024414062"},
+		{"byzantium", 1, 1, true, "50048828124"},
+		{"byzantium", 1, 30, false, "49951171876"},
+		{"byzantium", 1, 30, true, "49975585938"},
+		{"byzantium", 1, 2000, false, "47583007862"},
+		{"byzantium", 3199999, 1, false, "50024414063"},
+		{"byzantium", 3199999, 1, true, "50048828125"},
+		{"byzantium", 3199999, 30, false, "49951171877"},
+		{"byzantium", 3199999, 30, true, "49975585939"},
+		{"byzantium", 3199999, 2000, false, "47583007863"},
+		{"byzantium", 5000000, 1, false, "50024676206"},
+		{"byzantium", 5000000, 1, true, "50049090268"},
+		{"byzantium", 5000000, 30, false, "49951434020"},
+		{"byzantium", 5000000, 30, true, "49975848082"},
+		{"byzantium", 5000000, 2000, false, "47583270006"},
+	}
+	for i, tt := range tests {
+		parent := &types.Header{
+			Number:     big.NewInt(tt.number),
+			Time:       big.NewInt(1000000),
+			Difficulty: big.NewInt(50000000000),
+			UncleHash:  types.EmptyUncleHash,
+		}
+		if tt.uncles {
+			parent.UncleHash = common.Hash{1}
+		}
+		want, _ := new(big.Int).SetString(tt.want, 10)
+		for _, okcash := range []*params.OkcashConfig{nil, new(params.OkcashConfig)} {
+			if have := CalcDifficulty(testForkConfig(tt.fork, okcash), 1000000+tt.delta, parent); have.Cmp(want) != 0 {
+				t.Errorf("test %d (%s, config %v): difficulty mismatch: have %v, want %v", i, tt.fork, okcash, have, want)
+			}
+		}
+	}
+}
+
+// Tests that the configured difficulty bound divisor and bomb delay are applied,
+// following the scheduled changes.
+func TestCalcDifficultyConfigured(t *testing.T) {
+	okcash := &params.OkcashConfig{
+		OkcashParams: params.OkcashParams{DifficultyBoundDivisor: big.NewInt(1024)},
+		Forks: []*params.OkcashFork{
+			{Block: big.NewInt(4000000), OkcashParams: params.OkcashParams{BombDelay: big.NewInt(5000000)}},
+		},
+	}
+	parent := func(number int64) *types.Header {
+		return &types.Header{
+			Number:     big.NewInt(number),
+			Time:       big.NewInt(1000000),
+			Difficulty: big.NewInt(50000000000),
+			UncleHash:  types.EmptyUncleHash,
+		}
+	}
+	tests := []struct {
+		number int64
+		want   int64
+	}{
+		// Divisor halved: twice the adjustment, default bomb delay of 3M blocks
+		{1, 50048828125},
+		{3199999, 50048828126},
+		// Bomb delayed further at the fork, not yet exploding
+		{3999999, 50048828125},
+		{5000000, 50048828125},
+	}
+	for i, tt := range tests {
+		have := CalcDifficulty(testForkConfig("byzantium", okcash), 1000001, parent(tt.number))
+		if have.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("test %d: difficulty mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that the block and uncle rewards without configured okcash parameters
+// match the previously hardcoded schedule, and that configured ones override
+// it from their scheduled block on.
+func TestAccumulateRewards(t *testing.T) {
+	var (
+		miner  = common.Address{1}
+		uncler = common.Address{2}
+		ether  = big.NewInt(1e18)
+	)
+	configured := &params.OkcashConfig{
+		Forks: []*params.OkcashFork{
+			{Block: big.NewInt(100), OkcashParams: params.OkcashParams{BlockReward: big.NewInt(2e18), InclusionReward: big.NewInt(1e17)}},
+			{Block: big.NewInt(200), OkcashParams: params.OkcashParams{UncleReward: big.NewInt(8e17)}},
+		},
+	}
+	tests := []struct {
+		okcash *params.OkcashConfig
+		number int64
+		uncle  int64 // Number of the included uncle, zero for none
+		miner  *big.Int
+		uncler *big.Int
+	}{
+		// Default schedule: initial supply, then 1 ether with uncle rewards
+		{nil, 1, 0, new(big.Int).Mul(big.NewInt(1e11), ether), new(big.Int)},
+		{nil, 2, 0, ether, new(big.Int)},
+		{new(params.OkcashConfig), 10, 0, ether, new(big.Int)},
+		{nil, 10, 9, big.NewInt(1.03125e18), big.NewInt(0.875e18)},
+		{nil, 10, 3, big.NewInt(1.03125e18), big.NewInt(0.125e18)},
+
+		// Configured schedule: defaults until the first fork, then overridden
+		{configured, 99, 98, big.NewInt(1.03125e18), big.NewInt(0.875e18)},
+		{configured, 100, 99, big.NewInt(2.1e18), big.NewInt(1.75e18)},
+		{configured, 200, 199, big.NewInt(2.1e18), big.NewInt(0.7e18)},
+	}
+	for i, tt := range tests {
+		db, _ := okcdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+		header := &types.Header{Number: big.NewInt(tt.number), Coinbase: miner}
+		var uncles []*types.Header
+		if tt.uncle != 0 {
+			uncles = append(uncles, &types.Header{Number: big.NewInt(tt.uncle), Coinbase: uncler})
+		}
+		accumulateRewards(testForkConfig("byzantium", tt.okcash), statedb, header, uncles)
+
+		if have := statedb.GetBalance(miner); have.Cmp(tt.miner) != 0 {
+			t.Errorf("test %d: miner reward mismatch: have %v, want %v", i, have, tt.miner)
+		}
+		if have := statedb.GetBalance(uncler); have.Cmp(tt.uncler) != 0 {
+			t.Errorf("test %d: uncle reward mismatch: have %v, want %v", i, have, tt.uncler)
+		}
+	}
+}
@@ -18,6 +18,7 @@
 package okcash
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"math"
@@ -34,6 +35,7 @@ import (
 
 	mmap "github.com/edsrzf/mmap-go"
 	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/crypto/sha3"
 	"github.com/okcoin/go-okcoin/log"
 	"github.com/okcoin/go-okcoin/metrics"
 	"github.com/okcoin/go-okcoin/rpc"
@@ -42,6 +44,9 @@ import (
 
 var ErrInvalidDumpMagic = errors.New("invalid dump magic")
 
+// ErrPregenerationAborted is returned by PregenerateDatasets if it was aborted.
+var ErrPregenerationAborted = errors.New("dataset pregeneration aborted")
+
 var (
 	// maxUint256 is a big integer representing 2^256-1
 	maxUint256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
@@ -304,13 +309,10 @@ func (d *dataset) generate(dir string, limit int, test bool) {
 
 			d.dataset = make([]uint32, dsize/4)
 			generateDataset(d.dataset, d.epoch, cache)
+			return
 		}
 		// Disk storage is needed, this will get fancy
-		var endian string
-		if !isLittleEndian() {
-			endian = ".be"
-		}
-		path := filepath.Join(dir, fmt.Sprintf("full-R%d-%x%s", algorithmRevision, seed[:8], endian))
+		path := datasetPath(dir, seed)
 		logger := log.New("epoch", d.epoch)
 
 		// We're about to mmap the file, ensure that the mapping is cleaned up when the
@@ -340,8 +342,7 @@ func (d *dataset) generate(dir string, limit int, test bool) {
 		// Iterate over all previous instances and delete old ones
 		for ep := int(d.epoch) - limit; ep >= 0; ep-- {
 			seed := seedHash(uint64(ep)*epochLength + 1)
-			path := filepath.Join(dir, fmt.Sprintf("full-R%d-%x%s", algorithmRevision, seed[:8], endian))
-			os.Remove(path)
+			os.Remove(datasetPath(dir, seed))
 		}
 	})
 }
@@ -367,6 +368,90 @@ func MakeDataset(block uint64, dir string) {
 	d.generate(dir, math.MaxInt32, false)
 }
 
+// datasetSamples is the number of random items checked when verifying a dataset
+// stored on disk.
+const datasetSamples = 64
+
+// VerifyDataset checks that the okcash dataset of the given block stored in dir
+// is intact: it must have the expected size and a random sample of its items
+// must match the ones recomputed from the verification cache.
+func VerifyDataset(block uint64, dir string) error {
+	epoch := block / epochLength
+	seed := seedHash(epoch*epochLength + 1)
+
+	c := &cache{epoch: epoch}
+	c.generate("", 0, false)
+
+	return verifyDataset(datasetPath(dir, seed), datasetSize(epoch*epochLength+1), c.cache, datasetSamples)
+}
+
+// verifyDataset checks the size and a random sample of items of a dataset file
+// against the verification cache it was generated from.
+func verifyDataset(path string, size uint64, cache []uint32, samples int) error {
+	dump, mem, dataset, err := memoryMap(path)
+	if err != nil {
+		return err
+	}
+	defer dump.Close()
+	defer mem.Unmap()
+
+	if have := uint64(len(dataset)) * 4; have != size {
+		return fmt.Errorf("dataset size mismatch: have %d, want %d", have, size)
+	}
+	keccak512 := makeHasher(sha3.NewKeccak512())
+	for i := 0; i < samples; i++ {
+		index := uint32(rand.Int63n(int64(size / hashBytes)))
+		item := generateDatasetItem(cache, index, keccak512)
+		for j := 0; j < hashWords; j++ {
+			if dataset[index*hashWords+uint32(j)] != binary.LittleEndian.Uint32(item[j*4:]) {
+				return fmt.Errorf("dataset item %d corrupted", index)
+			}
+		}
+	}
+	return nil
+}
+
+// PregenerateDatasets ensures that the okcash datasets of the given number of
+// epochs, starting with the one of the given block, are stored intact in dir.
+// Missing datasets are generated and corrupted ones regenerated, existing
+// intact ones are left alone. Closing abort stops the pregeneration once the
+// dataset in progress is done, returning ErrPregenerationAborted.
+func PregenerateDatasets(block uint64, epochs int, dir string, abort <-chan struct{}) error {
+	for i := 0; i < epochs; i++ {
+		select {
+		case <-abort:
+			return ErrPregenerationAborted
+		default:
+		}
+		epoch := block/epochLength + uint64(i)
+		logger := log.New("epoch", epoch)
+
+		path := datasetPath(dir, seedHash(epoch*epochLength+1))
+		if _, err := os.Stat(path); err == nil {
+			err := VerifyDataset(epoch*epochLength, dir)
+			if err == nil {
+				logger.Info("Okcash dataset already generated", "path", path)
+				continue
+			}
+			logger.Warn("Regenerating corrupted okcash dataset", "path", path, "err", err)
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+		MakeDataset(epoch*epochLength, dir)
+	}
+	return nil
+}
+
+// datasetPath returns the file path of the dataset with the given seed.
+func datasetPath(dir string, seed []byte) string {
+	var endian string
+	if !isLittleEndian() {
+		endian = ".be"
+	}
+	return filepath.Join(dir, fmt.Sprintf("full-R%d-%x%s", algorithmRevision, seed[:8], endian))
+}
+
 // Mode defines the type and amount of PoW verification an okcash engine makes.
 type Mode uint
 
@@ -565,10 +650,14 @@ func (okcash *Okcash) Hashrate() float64 {
 	return okcash.hashrate.Rate1()
 }
 
-// APIs implements consensus.Engine, returning the user facing RPC APIs. Currently
-// that is empty.
+// APIs implements consensus.Engine, returning the user facing RPC APIs.
 func (okcash *Okcash) APIs(chain consensus.ChainReader) []rpc.API {
-	return nil
+	return []rpc.API{{
+		Namespace: "okcash",
+		Version:   "1.0",
+		Service:   &API{okcash},
+		Public:    true,
+	}}
 }
 
 // SeedHash is the seed to use for generating a verification cache and the mining
@@ -43,6 +43,94 @@ func TestTestMode(t *testing.T) {
 	}
 }
 
+// Tests that shares are verified against their own difficulty, and that block
+// solutions are recognised among them.
+func TestVerifyShare(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+
+	okcash := NewTester()
+	block, err := okcash.Seal(nil, types.NewBlockWithHeader(head), nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	// A block solution must be a valid share of any lower difficulty
+	digest, solved, err := okcash.VerifyShare(head, types.EncodeNonce(block.Nonce()), big.NewInt(10))
+	if err != nil {
+		t.Fatalf("block solution rejected as share: %v", err)
+	}
+	if !solved {
+		t.Errorf("block solution not recognised")
+	}
+	if digest != block.MixDigest() {
+		t.Errorf("mix digest mismatch: have %x, want %x", digest, block.MixDigest())
+	}
+	// Any nonce satisfies the minimal difficulty, but not an excessive one
+	if _, _, err := okcash.VerifyShare(head, types.EncodeNonce(block.Nonce()+1), big.NewInt(1)); err != nil {
+		t.Errorf("minimal difficulty share rejected: %v", err)
+	}
+	excessive := new(big.Int).Lsh(big.NewInt(1), 250)
+	if _, _, err := okcash.VerifyShare(head, types.EncodeNonce(block.Nonce()+1), excessive); err != ErrLowShareDifficulty {
+		t.Errorf("excessive difficulty share error mismatch: have %v, want %v", err, ErrLowShareDifficulty)
+	}
+}
+
+// Tests that stored datasets are verified against their expected size and
+// content.
+func TestVerifyDataset(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "okcash-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	d := &dataset{epoch: 0}
+	d.generate(tmpdir, 1, true)
+	d.finalizer()
+
+	cache := make([]uint32, 1024/4)
+	generateCache(cache, 0, seedHash(1))
+
+	path := datasetPath(tmpdir, seedHash(1))
+	if err := verifyDataset(path, 32*1024, cache, 16); err != nil {
+		t.Fatalf("intact dataset rejected: %v", err)
+	}
+	if err := verifyDataset(path, 64*1024, cache, 16); err == nil {
+		t.Errorf("dataset of invalid size accepted")
+	}
+	// Corrupt all the items (keeping the magic) and ensure it's detected
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read dataset: %v", err)
+	}
+	for i := len(dumpMagic) * 4; i < len(blob); i++ {
+		blob[i] = ^blob[i]
+	}
+	if err := ioutil.WriteFile(path, blob, 0644); err != nil {
+		t.Fatalf("failed to corrupt dataset: %v", err)
+	}
+	if err := verifyDataset(path, 32*1024, cache, 16); err == nil {
+		t.Errorf("corrupted dataset accepted")
+	}
+}
+
+// Tests that an aborted pregeneration stops without touching the datasets.
+func TestPregenerateDatasetsAbort(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "okcash-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	abort := make(chan struct{})
+	close(abort)
+	if err := PregenerateDatasets(0, 2, tmpdir, abort); err != ErrPregenerationAborted {
+		t.Fatalf("pregeneration error mismatch: have %v, want %v", err, ErrPregenerationAborted)
+	}
+	if files, _ := ioutil.ReadDir(tmpdir); len(files) != 0 {
+		t.Errorf("aborted pregeneration wrote %d files", len(files))
+	}
+}
+
 // This test checks that cache lru logic doesn't crash under load.
 // It reproduces https://github.com/okcoin/go-okcoin/issues/14943
 func TestCacheFileEvict(t *testing.T) {
@@ -0,0 +1,226 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcash
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/metrics"
+)
+
+const (
+	cacheGeneration   = "cache"   // Generation kind of verification caches
+	datasetGeneration = "dataset" // Generation kind of mining datasets
+)
+
+var (
+	cacheProgressGauge   = metrics.NewRegisteredGauge("okcash/cache/progress", nil)
+	cacheEtaGauge        = metrics.NewRegisteredGauge("okcash/cache/eta", nil)
+	datasetProgressGauge = metrics.NewRegisteredGauge("okcash/dataset/progress", nil)
+	datasetEtaGauge      = metrics.NewRegisteredGauge("okcash/dataset/eta", nil)
+
+	// generations tracks all cache and dataset generations of the process. It is
+	// shared as generations are not tied to any engine instance.
+	generations = &generationTracker{
+		active: make(map[generationKey]*GenerationProgress),
+		wake:   make(chan struct{}, 1),
+	}
+)
+
+// GenerationProgress is a snapshot of the state of a verification cache or
+// mining dataset being generated.
+type GenerationProgress struct {
+	Kind       string `json:"kind"`       // Data being generated, cache or dataset
+	Epoch      uint64 `json:"epoch"`      // Epoch the data is generated for
+	Percentage uint64 `json:"percentage"` // Percentage of the data generated
+	Elapsed    uint64 `json:"elapsed"`    // Seconds spent generating so far
+	ETA        uint64 `json:"eta"`        // Estimated seconds until generation finishes
+	Done       bool   `json:"done"`       // Whether the generation finished
+}
+
+// SubscribeGenerationProgress subscribes to the progress updates of all cache
+// and dataset generations. Updates are sent on every percent of progress and
+// on completion.
+func SubscribeGenerationProgress(ch chan<- GenerationProgress) event.Subscription {
+	return generations.scope.Track(generations.feed.Subscribe(ch))
+}
+
+// ActiveGenerations returns the progress of the currently running generations.
+func ActiveGenerations() []GenerationProgress {
+	return generations.snapshot()
+}
+
+// generationKey identifies a single generation.
+type generationKey struct {
+	kind  string
+	epoch uint64
+}
+
+// generationTracker aggregates the progress of running generations, exporting
+// it via metrics and an event feed. Updates are delivered to the feed from a
+// separate goroutine so that slow subscribers never stall a generation.
+type generationTracker struct {
+	active  map[generationKey]*GenerationProgress
+	pending []GenerationProgress // Updates queued for delivery, coalesced per generation
+	lock    sync.Mutex
+
+	wake    chan struct{} // Notifies the delivery loop of queued updates
+	deliver sync.Once     // Starts the delivery loop on the first generation
+
+	feed  event.Feed
+	scope event.SubscriptionScope
+}
+
+// start registers a new generation of the given total number of items and
+// returns the reporter to feed its progress into.
+func (t *generationTracker) start(kind string, epoch uint64, total uint64) *generationReporter {
+	progress := &GenerationProgress{Kind: kind, Epoch: epoch}
+
+	t.lock.Lock()
+	t.active[generationKey{kind, epoch}] = progress
+	t.lock.Unlock()
+
+	t.deliver.Do(func() { go t.loop() })
+	return &generationReporter{tracker: t, progress: progress, total: total, start: time.Now()}
+}
+
+// queue schedules a progress update for delivery without blocking. A pending
+// unfinished update of the same generation is replaced, so a subscriber that
+// falls behind only skips intermediate percentages, never the completion.
+func (t *generationTracker) queue(update GenerationProgress) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	queued := false
+	if !update.Done {
+		for i, pending := range t.pending {
+			if pending.Kind == update.Kind && pending.Epoch == update.Epoch && !pending.Done {
+				t.pending[i], queued = update, true
+				break
+			}
+		}
+	}
+	if !queued {
+		t.pending = append(t.pending, update)
+	}
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop delivers the queued progress updates to the subscribers.
+func (t *generationTracker) loop() {
+	for range t.wake {
+		t.lock.Lock()
+		pending := t.pending
+		t.pending = nil
+		t.lock.Unlock()
+
+		for _, update := range pending {
+			t.feed.Send(update)
+		}
+	}
+}
+
+// snapshot returns the current progress of all running generations, sorted by
+// kind and epoch.
+func (t *generationTracker) snapshot() []GenerationProgress {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snapshot := make([]GenerationProgress, 0, len(t.active))
+	for _, progress := range t.active {
+		snapshot = append(snapshot, *progress)
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if snapshot[i].Kind != snapshot[j].Kind {
+			return snapshot[i].Kind < snapshot[j].Kind
+		}
+		return snapshot[i].Epoch < snapshot[j].Epoch
+	})
+	return snapshot
+}
+
+// generationReporter converts the raw item counts of a single generation into
+// progress updates.
+type generationReporter struct {
+	tracker  *generationTracker
+	progress *GenerationProgress
+	total    uint64
+	start    time.Time
+
+	lock sync.Mutex // Serializes updates reported from multiple threads
+}
+
+// report updates the progress of the generation to the given number of items
+// done. Updates not advancing the percentage are ignored.
+func (r *generationReporter) report(done uint64) {
+	if r.total == 0 || done > r.total {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	percentage := done * 100 / r.total
+	if percentage <= r.progress.Percentage || percentage == 100 {
+		return
+	}
+	elapsed := time.Since(r.start)
+
+	r.tracker.lock.Lock()
+	r.progress.Percentage = percentage
+	r.progress.Elapsed = uint64(elapsed / time.Second)
+	r.progress.ETA = uint64(elapsed.Seconds() * float64(r.total-done) / float64(done))
+	update := *r.progress
+	r.tracker.lock.Unlock()
+
+	r.publish(update)
+}
+
+// finish marks the generation done and stops tracking it.
+func (r *generationReporter) finish() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.tracker.lock.Lock()
+	delete(r.tracker.active, generationKey{r.progress.Kind, r.progress.Epoch})
+
+	r.progress.Percentage, r.progress.ETA, r.progress.Done = 100, 0, true
+	r.progress.Elapsed = uint64(time.Since(r.start) / time.Second)
+	update := *r.progress
+	r.tracker.lock.Unlock()
+
+	r.publish(update)
+}
+
+// publish exports a progress update to the metrics system and queues it for
+// the subscribers.
+func (r *generationReporter) publish(update GenerationProgress) {
+	switch update.Kind {
+	case cacheGeneration:
+		cacheProgressGauge.Update(int64(update.Percentage))
+		cacheEtaGauge.Update(int64(update.ETA))
+	case datasetGeneration:
+		datasetProgressGauge.Update(int64(update.Percentage))
+		datasetEtaGauge.Update(int64(update.ETA))
+	}
+	r.tracker.queue(update)
+}
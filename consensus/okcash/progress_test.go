@@ -0,0 +1,92 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcash
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// Tests that cache and dataset generations report monotonic progress, ending
+// with a completion update.
+func TestGenerationProgress(t *testing.T) {
+	// Use an epoch no other test generates concurrently
+	const epoch = math.MaxUint32
+
+	updates := make(chan GenerationProgress, 1024)
+	sub := SubscribeGenerationProgress(updates)
+	defer sub.Unsubscribe()
+
+	cache := make([]uint32, 1024/4)
+	generateCache(cache, epoch, seedHash(1))
+
+	dataset := make([]uint32, 32*1024/4)
+	generateDataset(dataset, epoch, cache)
+
+	for _, kind := range []string{cacheGeneration, datasetGeneration} {
+		var last uint64
+		for {
+			update := <-updates
+			if update.Epoch != epoch {
+				continue
+			}
+			if update.Kind != kind {
+				t.Fatalf("unexpected update: have %+v, want %s", update, kind)
+			}
+			if update.Done {
+				if update.Percentage != 100 || update.ETA != 0 {
+					t.Errorf("%s: invalid completion: %+v", kind, update)
+				}
+				break
+			}
+			if update.Percentage <= last {
+				t.Errorf("%s: progress not monotonic: %d after %d", kind, update.Percentage, last)
+			}
+			last = update.Percentage
+		}
+		if last < 90 {
+			t.Errorf("%s: progress incomplete before finishing: %d", kind, last)
+		}
+	}
+	for _, active := range ActiveGenerations() {
+		if active.Epoch == epoch {
+			t.Errorf("finished generation still active: %+v", active)
+		}
+	}
+}
+
+// Tests that a subscriber not consuming its updates does not stall generations.
+func TestGenerationProgressStalledSubscriber(t *testing.T) {
+	const epoch = math.MaxUint32 - 1
+
+	updates := make(chan GenerationProgress)
+	sub := SubscribeGenerationProgress(updates)
+	defer sub.Unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		cache := make([]uint32, 1024/4)
+		generateCache(cache, epoch, seedHash(1))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("generation blocked by stalled subscriber")
+	}
+}
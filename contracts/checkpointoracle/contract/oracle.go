@@ -0,0 +1,430 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	okcoin "github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/accounts/abi"
+	"github.com/okcoin/go-okcoin/accounts/abi/bind"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/event"
+)
+
+// CheckpointOracleABI is the input ABI used to generate the binding from.
+const CheckpointOracleABI = "[{\"constant\":true,\"inputs\":[],\"name\":\"GetAllAdmin\",\"outputs\":[{\"name\":\"\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"GetLatestCheckpoint\",\"outputs\":[{\"name\":\"\",\"type\":\"uint64\"},{\"name\":\"\",\"type\":\"bytes32\"},{\"name\":\"\",\"type\":\"bytes32\"},{\"name\":\"\",\"type\":\"bytes32\"},{\"name\":\"\",\"type\":\"uint256\"},{\"name\":\"\",\"type\":\"uint8[]\"},{\"name\":\"\",\"type\":\"bytes32[]\"},{\"name\":\"\",\"type\":\"bytes32[]\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"GetThreshold\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"_sectionIndex\",\"type\":\"uint64\"},{\"name\":\"_sectionHead\",\"type\":\"bytes32\"},{\"name\":\"_chtRoot\",\"type\":\"bytes32\"},{\"name\":\"_bloomTrieRoot\",\"type\":\"bytes32\"},{\"name\":\"_v\",\"type\":\"uint8[]\"},{\"name\":\"_r\",\"type\":\"bytes32[]\"},{\"name\":\"_s\",\"type\":\"bytes32[]\"}],\"name\":\"SetCheckpoint\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"_adminlist\",\"type\":\"address[]\"},{\"name\":\"_threshold\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"index\",\"type\":\"uint64\"},{\"indexed\":false,\"name\":\"sectionHead\",\"type\":\"bytes32\"},{\"indexed\":false,\"name\":\"chtRoot\",\"type\":\"bytes32\"},{\"indexed\":false,\"name\":\"bloomTrieRoot\",\"type\":\"bytes32\"},{\"indexed\":false,\"name\":\"v\",\"type\":\"uint8\"},{\"indexed\":false,\"name\":\"r\",\"type\":\"bytes32\"},{\"indexed\":false,\"name\":\"s\",\"type\":\"bytes32\"}],\"name\":\"NewCheckpoint\",\"type\":\"event\"}]"
+
+// CheckpointOracleBin is the compiled bytecode used for deploying new contracts.
+const CheckpointOracleBin = `60806040523480156200001157600080fd5b5060405162000e1a38038062000e1a8339810160408190526200003491620001be565b60008111801562000046575081518111155b6200005057600080fd5b60005b8251811015620001675760008084838151811062000075576200007562000298565b602090810291909101810151600160a060020a031682528101919091526040016000205460ff1615620000a757600080fd5b6001600080858481518110620000c157620000c162000298565b6020026020010151600160a060020a0316600160a060020a0316815260200190815260200160002060006101000a81548160ff021916908315150217905550600183828151811062000117576200011762000298565b6020908102919091018101518254600181018455600093845291909220018054600160a060020a031916600160a060020a03909216919091179055806200015e81620002c7565b91505062000053565b506002555062000308565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052604160045260246000fd5b8051600160a060020a0381168114620001b957600080fd5b919050565b60008060408385031215620001d257600080fd5b825167ffffffffffffffff80821115620001eb57600080fd5b818501915085601f8301126200020057600080fd5b815160208282111562000217576200021762000172565b808202604051601f19603f830116810181811086821117156200023e576200023e62000172565b6040529283528183019350848101820192898411156200025d57600080fd5b948201945b8386101562000286576200027686620001a1565b8552948201949382019362000262565b97909101519698969750505050505050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052603260045260246000fd5b60006001820162000301577f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b5060010190565b610b0280620003186000396000f3fe608060405234801561001057600080fd5b5060043610610068577c0100000000000000000000000000000000000000000000000000000000600035046345848dfc811461006d5780634d6a304c1461008b5780636889d7b2146100a7578063990c17c3146100ca575b600080fd5b6100756100db565b6040516100829190610712565b60405180910390f35b61009361014a565b6040516100829897969594939291906107a7565b6100ba6100b536600461099e565b6102ae565b6040519015158152602001610082565b600254604051908152602001610082565b6060600180548060200260200160405190810160405280929190818152602001828054801561014057602002820191906000526020600020905b815473ffffffffffffffffffffffffffffffffffffffff168152600190910190602001808311610115575b5050505050905090565b60008060008060006060806060600360009054906101000a900467ffffffffffffffff1660045460055460065460075460086009600a828054806020026020016040519081016040528092919081815260200182805480156101e957602002820191906000526020600020906000905b825461010083900a900460ff168152602060019283018181049485019490930390920291018084116101ba5790505b505050505092508180548060200260200160405190810160405280929190818152602001828054801561023b57602002820191906000526020600020905b815481526020019060010190808311610227575b505050505091508080548060200260200160405190810160405280929190818152602001828054801561028d57602002820191906000526020600020905b815481526020019060010190808311610279575b50505050509050975097509750975097509750975097509091929394959697565b6000825184511480156102c2575081518451145b6102cb57600080fd5b600254845110156102db57600080fd5b60075415806102f9575060035467ffffffffffffffff908116908916115b61030257600080fd5b6040517f190000000000000000000000000000000000000000000000000000000000000060208201526000602182018190526c0100000000000000000000000030026022830152780100000000000000000000000000000000000000000000000067ffffffffffffffff8b16026036830152603e8201899052605e8201889052607e820187905290609e016040516020818303038152906040528051906020012090506000805b865181101561059f5760006001848984815181106103c9576103c9610a5d565b60200260200101518985815181106103e3576103e3610a5d565b60200260200101518986815181106103fd576103fd610a5d565b60200260200101516040516000815260200160405260405161043b949392919093845260ff9290921660208401526040830152606082015260800190565b6020604051602081039080840390855afa15801561045d573d6000803e3d6000fd5b505060408051601f19015173ffffffffffffffffffffffffffffffffffffffff811660009081526020819052919091205490925060ff16905061049f57600080fd5b8273ffffffffffffffffffffffffffffffffffffffff168173ffffffffffffffffffffffffffffffffffffffff16116104d757600080fd5b8092508b67ffffffffffffffff167f72b2834c64b742f4e4dd251be2d1dc7e72a66b2147aaeb6e450a757240a2eafb8c8c8c8c878151811061051b5761051b610a5d565b60200260200101518c888151811061053557610535610a5d565b60200260200101518c898151811061054f5761054f610a5d565b602090810291909101810151604080519788529187019590955285019290925260ff166060840152608083015260a082015260c00160405180910390a2508061059781610a8c565b9150506103a9565b506003805467ffffffffffffffff191667ffffffffffffffff8c161790556004899055600588905560068790554360075585516105e390600890602089019061061c565b5084516105f79060099060208801906106c2565b50835161060b90600a9060208701906106c2565b5060019a9950505050505050505050565b82805482825590600052602060002090601f016020900481019282156106b25791602002820160005b8382111561068357835183826101000a81548160ff021916908360ff1602179055509260200192600101602081600001049283019260010302610645565b80156106b05782816101000a81549060ff0219169055600101602081600001049283019260010302610683565b505b506106be9291506106fd565b5090565b8280548282559060005260206000209081019282156106b2579160200282015b828111156106b25782518255916020019190600101906106e2565b5b808211156106be57600081556001016106fe565b6020808252825182820181905260009190848201906040850190845b8181101561076057835173ffffffffffffffffffffffffffffffffffffffff168352928401929184019160010161072e565b50909695505050505050565b600081518084526020808501945080840160005b8381101561079c57815187529582019590820190600101610780565b509495945050505050565b600061010080830167ffffffffffffffff8c16845260208b818601528a60408601528960608601528860808601528260a0860152819250875180835261012086019350818901925060005b8181101561081157835160ff16855293820193928201926001016107f2565b5050505082810360c0840152610827818661076c565b905082810360e084015261083b818561076c565b9b9a5050505050505050505050565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052604160045260246000fd5b604051601f8201601f1916810167ffffffffffffffff811182821017156108a2576108a261084a565b604052919050565b600067ffffffffffffffff8211156108c4576108c461084a565b5060209081020190565b600082601f8301126108df57600080fd5b813560206108f46108ef836108aa565b610879565b8281529181028401810191818101908684111561091057600080fd5b8286015b8481101561093b57803560ff8116811461092e5760008081fd5b8352918301918301610914565b509695505050505050565b600082601f83011261095757600080fd5b813560206109676108ef836108aa565b8281529181028401810191818101908684111561098357600080fd5b8286015b8481101561093b5780358352918301918301610987565b600080600080600080600060e0888a0312156109b957600080fd5b873567ffffffffffffffff80821682146109d257600080fd5b909750602089013596506040890135955060608901359450608089013590808211156109fd57600080fd5b610a098b838c016108ce565b945060a08a0135915080821115610a1f57600080fd5b610a2b8b838c01610946565b935060c08a0135915080821115610a4157600080fd5b50610a4e8a828b01610946565b91505092959891949750929550565b7f4e487b7100000000000000000000000000000000000000000000000000000000600052603260045260246000fd5b600060018201610ac5577f4e487b7100000000000000000000000000000000000000000000000000000000600052601160045260246000fd5b506001019056fea2646970667358221220d68c7e2dd1ec18a6fb0ee3c11cb7a60a1fddaf6145e7c132cd667a93d1a199c764736f6c63430008150033`
+
+// DeployCheckpointOracle deploys a new Okcoin contract, binding an instance of CheckpointOracle to it.
+func DeployCheckpointOracle(auth *bind.TransactOpts, backend bind.ContractBackend, _adminlist []common.Address, _threshold *big.Int) (common.Address, *types.Transaction, *CheckpointOracle, error) {
+	parsed, err := abi.JSON(strings.NewReader(CheckpointOracleABI))
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	address, tx, contract, err := bind.DeployContract(auth, parsed, common.FromHex(CheckpointOracleBin), backend, _adminlist, _threshold)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &CheckpointOracle{CheckpointOracleCaller: CheckpointOracleCaller{contract: contract}, CheckpointOracleTransactor: CheckpointOracleTransactor{contract: contract}, CheckpointOracleFilterer: CheckpointOracleFilterer{contract: contract}}, nil
+}
+
+// CheckpointOracle is an auto generated Go binding around an Okcoin contract.
+type CheckpointOracle struct {
+	CheckpointOracleCaller     // Read-only binding to the contract
+	CheckpointOracleTransactor // Write-only binding to the contract
+	CheckpointOracleFilterer   // Log filterer for contract events
+}
+
+// CheckpointOracleCaller is an auto generated read-only Go binding around an Okcoin contract.
+type CheckpointOracleCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CheckpointOracleTransactor is an auto generated write-only Go binding around an Okcoin contract.
+type CheckpointOracleTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CheckpointOracleFilterer is an auto generated log filtering Go binding around an Okcoin contract events.
+type CheckpointOracleFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// CheckpointOracleSession is an auto generated Go binding around an Okcoin contract,
+// with pre-set call and transact options.
+type CheckpointOracleSession struct {
+	Contract     *CheckpointOracle // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// CheckpointOracleCallerSession is an auto generated read-only Go binding around an Okcoin contract,
+// with pre-set call options.
+type CheckpointOracleCallerSession struct {
+	Contract *CheckpointOracleCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts           // Call options to use throughout this session
+}
+
+// CheckpointOracleTransactorSession is an auto generated write-only Go binding around an Okcoin contract,
+// with pre-set transact options.
+type CheckpointOracleTransactorSession struct {
+	Contract     *CheckpointOracleTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts           // Transaction auth options to use throughout this session
+}
+
+// CheckpointOracleRaw is an auto generated low-level Go binding around an Okcoin contract.
+type CheckpointOracleRaw struct {
+	Contract *CheckpointOracle // Generic contract binding to access the raw methods on
+}
+
+// CheckpointOracleCallerRaw is an auto generated low-level read-only Go binding around an Okcoin contract.
+type CheckpointOracleCallerRaw struct {
+	Contract *CheckpointOracleCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// CheckpointOracleTransactorRaw is an auto generated low-level write-only Go binding around an Okcoin contract.
+type CheckpointOracleTransactorRaw struct {
+	Contract *CheckpointOracleTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewCheckpointOracle creates a new instance of CheckpointOracle, bound to a specific deployed contract.
+func NewCheckpointOracle(address common.Address, backend bind.ContractBackend) (*CheckpointOracle, error) {
+	contract, err := bindCheckpointOracle(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointOracle{CheckpointOracleCaller: CheckpointOracleCaller{contract: contract}, CheckpointOracleTransactor: CheckpointOracleTransactor{contract: contract}, CheckpointOracleFilterer: CheckpointOracleFilterer{contract: contract}}, nil
+}
+
+// NewCheckpointOracleCaller creates a new read-only instance of CheckpointOracle, bound to a specific deployed contract.
+func NewCheckpointOracleCaller(address common.Address, caller bind.ContractCaller) (*CheckpointOracleCaller, error) {
+	contract, err := bindCheckpointOracle(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointOracleCaller{contract: contract}, nil
+}
+
+// NewCheckpointOracleTransactor creates a new write-only instance of CheckpointOracle, bound to a specific deployed contract.
+func NewCheckpointOracleTransactor(address common.Address, transactor bind.ContractTransactor) (*CheckpointOracleTransactor, error) {
+	contract, err := bindCheckpointOracle(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointOracleTransactor{contract: contract}, nil
+}
+
+// NewCheckpointOracleFilterer creates a new log filterer instance of CheckpointOracle, bound to a specific deployed contract.
+func NewCheckpointOracleFilterer(address common.Address, filterer bind.ContractFilterer) (*CheckpointOracleFilterer, error) {
+	contract, err := bindCheckpointOracle(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointOracleFilterer{contract: contract}, nil
+}
+
+// bindCheckpointOracle binds a generic wrapper to an already deployed contract.
+func bindCheckpointOracle(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(CheckpointOracleABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_CheckpointOracle *CheckpointOracleRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _CheckpointOracle.Contract.CheckpointOracleCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_CheckpointOracle *CheckpointOracleRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _CheckpointOracle.Contract.CheckpointOracleTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_CheckpointOracle *CheckpointOracleRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _CheckpointOracle.Contract.CheckpointOracleTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_CheckpointOracle *CheckpointOracleCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _CheckpointOracle.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_CheckpointOracle *CheckpointOracleTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _CheckpointOracle.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_CheckpointOracle *CheckpointOracleTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _CheckpointOracle.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetAllAdmin is a free data retrieval call binding the contract method 0x45848dfc.
+//
+// Solidity: function GetAllAdmin() constant returns(address[])
+func (_CheckpointOracle *CheckpointOracleCaller) GetAllAdmin(opts *bind.CallOpts) ([]common.Address, error) {
+	var (
+		ret0 = new([]common.Address)
+	)
+	out := ret0
+	err := _CheckpointOracle.contract.Call(opts, out, "GetAllAdmin")
+	return *ret0, err
+}
+
+// GetAllAdmin is a free data retrieval call binding the contract method 0x45848dfc.
+//
+// Solidity: function GetAllAdmin() constant returns(address[])
+func (_CheckpointOracle *CheckpointOracleSession) GetAllAdmin() ([]common.Address, error) {
+	return _CheckpointOracle.Contract.GetAllAdmin(&_CheckpointOracle.CallOpts)
+}
+
+// GetAllAdmin is a free data retrieval call binding the contract method 0x45848dfc.
+//
+// Solidity: function GetAllAdmin() constant returns(address[])
+func (_CheckpointOracle *CheckpointOracleCallerSession) GetAllAdmin() ([]common.Address, error) {
+	return _CheckpointOracle.Contract.GetAllAdmin(&_CheckpointOracle.CallOpts)
+}
+
+// GetLatestCheckpoint is a free data retrieval call binding the contract method 0x4d6a304c.
+//
+// Solidity: function GetLatestCheckpoint() constant returns(uint64, bytes32, bytes32, bytes32, uint256, uint8[], bytes32[], bytes32[])
+func (_CheckpointOracle *CheckpointOracleCaller) GetLatestCheckpoint(opts *bind.CallOpts) (uint64, [32]byte, [32]byte, [32]byte, *big.Int, []uint8, [][32]byte, [][32]byte, error) {
+	var (
+		ret0 = new(uint64)
+		ret1 = new([32]byte)
+		ret2 = new([32]byte)
+		ret3 = new([32]byte)
+		ret4 = new(*big.Int)
+		ret5 = new([]uint8)
+		ret6 = new([][32]byte)
+		ret7 = new([][32]byte)
+	)
+	out := &[]interface{}{
+		ret0,
+		ret1,
+		ret2,
+		ret3,
+		ret4,
+		ret5,
+		ret6,
+		ret7,
+	}
+	err := _CheckpointOracle.contract.Call(opts, out, "GetLatestCheckpoint")
+	return *ret0, *ret1, *ret2, *ret3, *ret4, *ret5, *ret6, *ret7, err
+}
+
+// GetLatestCheckpoint is a free data retrieval call binding the contract method 0x4d6a304c.
+//
+// Solidity: function GetLatestCheckpoint() constant returns(uint64, bytes32, bytes32, bytes32, uint256, uint8[], bytes32[], bytes32[])
+func (_CheckpointOracle *CheckpointOracleSession) GetLatestCheckpoint() (uint64, [32]byte, [32]byte, [32]byte, *big.Int, []uint8, [][32]byte, [][32]byte, error) {
+	return _CheckpointOracle.Contract.GetLatestCheckpoint(&_CheckpointOracle.CallOpts)
+}
+
+// GetLatestCheckpoint is a free data retrieval call binding the contract method 0x4d6a304c.
+//
+// Solidity: function GetLatestCheckpoint() constant returns(uint64, bytes32, bytes32, bytes32, uint256, uint8[], bytes32[], bytes32[])
+func (_CheckpointOracle *CheckpointOracleCallerSession) GetLatestCheckpoint() (uint64, [32]byte, [32]byte, [32]byte, *big.Int, []uint8, [][32]byte, [][32]byte, error) {
+	return _CheckpointOracle.Contract.GetLatestCheckpoint(&_CheckpointOracle.CallOpts)
+}
+
+// GetThreshold is a free data retrieval call binding the contract method 0x990c17c3.
+//
+// Solidity: function GetThreshold() constant returns(uint256)
+func (_CheckpointOracle *CheckpointOracleCaller) GetThreshold(opts *bind.CallOpts) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _CheckpointOracle.contract.Call(opts, out, "GetThreshold")
+	return *ret0, err
+}
+
+// GetThreshold is a free data retrieval call binding the contract method 0x990c17c3.
+//
+// Solidity: function GetThreshold() constant returns(uint256)
+func (_CheckpointOracle *CheckpointOracleSession) GetThreshold() (*big.Int, error) {
+	return _CheckpointOracle.Contract.GetThreshold(&_CheckpointOracle.CallOpts)
+}
+
+// GetThreshold is a free data retrieval call binding the contract method 0x990c17c3.
+//
+// Solidity: function GetThreshold() constant returns(uint256)
+func (_CheckpointOracle *CheckpointOracleCallerSession) GetThreshold() (*big.Int, error) {
+	return _CheckpointOracle.Contract.GetThreshold(&_CheckpointOracle.CallOpts)
+}
+
+// SetCheckpoint is a paid mutator transaction binding the contract method 0x6889d7b2.
+//
+// Solidity: function SetCheckpoint(_sectionIndex uint64, _sectionHead bytes32, _chtRoot bytes32, _bloomTrieRoot bytes32, _v uint8[], _r bytes32[], _s bytes32[]) returns(bool)
+func (_CheckpointOracle *CheckpointOracleTransactor) SetCheckpoint(opts *bind.TransactOpts, _sectionIndex uint64, _sectionHead [32]byte, _chtRoot [32]byte, _bloomTrieRoot [32]byte, _v []uint8, _r [][32]byte, _s [][32]byte) (*types.Transaction, error) {
+	return _CheckpointOracle.contract.Transact(opts, "SetCheckpoint", _sectionIndex, _sectionHead, _chtRoot, _bloomTrieRoot, _v, _r, _s)
+}
+
+// SetCheckpoint is a paid mutator transaction binding the contract method 0x6889d7b2.
+//
+// Solidity: function SetCheckpoint(_sectionIndex uint64, _sectionHead bytes32, _chtRoot bytes32, _bloomTrieRoot bytes32, _v uint8[], _r bytes32[], _s bytes32[]) returns(bool)
+func (_CheckpointOracle *CheckpointOracleSession) SetCheckpoint(_sectionIndex uint64, _sectionHead [32]byte, _chtRoot [32]byte, _bloomTrieRoot [32]byte, _v []uint8, _r [][32]byte, _s [][32]byte) (*types.Transaction, error) {
+	return _CheckpointOracle.Contract.SetCheckpoint(&_CheckpointOracle.TransactOpts, _sectionIndex, _sectionHead, _chtRoot, _bloomTrieRoot, _v, _r, _s)
+}
+
+// SetCheckpoint is a paid mutator transaction binding the contract method 0x6889d7b2.
+//
+// Solidity: function SetCheckpoint(_sectionIndex uint64, _sectionHead bytes32, _chtRoot bytes32, _bloomTrieRoot bytes32, _v uint8[], _r bytes32[], _s bytes32[]) returns(bool)
+func (_CheckpointOracle *CheckpointOracleTransactorSession) SetCheckpoint(_sectionIndex uint64, _sectionHead [32]byte, _chtRoot [32]byte, _bloomTrieRoot [32]byte, _v []uint8, _r [][32]byte, _s [][32]byte) (*types.Transaction, error) {
+	return _CheckpointOracle.Contract.SetCheckpoint(&_CheckpointOracle.TransactOpts, _sectionIndex, _sectionHead, _chtRoot, _bloomTrieRoot, _v, _r, _s)
+}
+
+// CheckpointOracleNewCheckpointIterator is returned from FilterNewCheckpoint and is used to iterate over the raw logs and unpacked data for NewCheckpoint events raised by the CheckpointOracle contract.
+type CheckpointOracleNewCheckpointIterator struct {
+	Event *CheckpointOracleNewCheckpoint // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log      // Log channel receiving the found contract events
+	sub  okcoin.Subscription // Subscription for errors, completion and termination
+	done bool                // Whokcer the subscription completed delivering logs
+	fail error               // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whokcer there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *CheckpointOracleNewCheckpointIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(CheckpointOracleNewCheckpoint)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(CheckpointOracleNewCheckpoint)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *CheckpointOracleNewCheckpointIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *CheckpointOracleNewCheckpointIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// CheckpointOracleNewCheckpoint represents a NewCheckpoint event raised by the CheckpointOracle contract.
+type CheckpointOracleNewCheckpoint struct {
+	Index         uint64
+	SectionHead   [32]byte
+	ChtRoot       [32]byte
+	BloomTrieRoot [32]byte
+	V             uint8
+	R             [32]byte
+	S             [32]byte
+	Raw           types.Log // Blockchain specific contextual infos
+}
+
+// FilterNewCheckpoint is a free log retrieval operation binding the contract event 0x72b2834c64b742f4e4dd251be2d1dc7e72a66b2147aaeb6e450a757240a2eafb.
+//
+// Solidity: event NewCheckpoint(index indexed uint64, sectionHead bytes32, chtRoot bytes32, bloomTrieRoot bytes32, v uint8, r bytes32, s bytes32)
+func (_CheckpointOracle *CheckpointOracleFilterer) FilterNewCheckpoint(opts *bind.FilterOpts, index []uint64) (*CheckpointOracleNewCheckpointIterator, error) {
+
+	var indexRule []interface{}
+	for _, indexItem := range index {
+		indexRule = append(indexRule, indexItem)
+	}
+
+	logs, sub, err := _CheckpointOracle.contract.FilterLogs(opts, "NewCheckpoint", indexRule)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointOracleNewCheckpointIterator{contract: _CheckpointOracle.contract, event: "NewCheckpoint", logs: logs, sub: sub}, nil
+}
+
+// WatchNewCheckpoint is a free log subscription operation binding the contract event 0x72b2834c64b742f4e4dd251be2d1dc7e72a66b2147aaeb6e450a757240a2eafb.
+//
+// Solidity: event NewCheckpoint(index indexed uint64, sectionHead bytes32, chtRoot bytes32, bloomTrieRoot bytes32, v uint8, r bytes32, s bytes32)
+func (_CheckpointOracle *CheckpointOracleFilterer) WatchNewCheckpoint(opts *bind.WatchOpts, sink chan<- *CheckpointOracleNewCheckpoint, index []uint64) (event.Subscription, error) {
+
+	var indexRule []interface{}
+	for _, indexItem := range index {
+		indexRule = append(indexRule, indexItem)
+	}
+
+	logs, sub, err := _CheckpointOracle.contract.WatchLogs(opts, "NewCheckpoint", indexRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(CheckpointOracleNewCheckpoint)
+				if err := _CheckpointOracle.contract.UnpackLog(event, "NewCheckpoint", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
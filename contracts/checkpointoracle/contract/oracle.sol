@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: LGPL-3.0-or-later
+
+pragma solidity ^0.8.0;
+
+/**
+ * @title CheckpointOracle
+ * @dev Registry of the light client checkpoints (CHT and BloomTrie roots of a
+ * section) agreed on by a threshold of trusted admins. The signatures of the
+ * latest checkpoint are retained, so clients can verify it themselves without
+ * trusting the state they read it from.
+ */
+contract CheckpointOracle {
+    /*
+        Events
+    */
+
+    // NewCheckpoint is emitted when a checkpoint is registered, once for every
+    // admin signature approving it.
+    event NewCheckpoint(uint64 indexed index, bytes32 sectionHead, bytes32 chtRoot, bytes32 bloomTrieRoot, uint8 v, bytes32 r, bytes32 s);
+
+    /*
+        Public Functions
+    */
+    constructor(address[] memory _adminlist, uint _threshold) {
+        require(_threshold > 0 && _threshold <= _adminlist.length);
+
+        for (uint i = 0; i < _adminlist.length; i++) {
+            require(!admins[_adminlist[i]]);
+            admins[_adminlist[i]] = true;
+            adminList.push(_adminlist[i]);
+        }
+        threshold = _threshold;
+    }
+
+    /**
+     * @dev Get the latest registered checkpoint along with the admin signatures
+     * approving it.
+     */
+    function GetLatestCheckpoint()
+    view
+    public
+    returns(uint64, bytes32, bytes32, bytes32, uint, uint8[] memory, bytes32[] memory, bytes32[] memory) {
+        return (sectionIndex, sectionHead, chtRoot, bloomTrieRoot, height, sigV, sigR, sigS);
+    }
+
+    /**
+     * @dev Get all the admins of the oracle.
+     */
+    function GetAllAdmin()
+    view
+    public
+    returns(address[] memory) {
+        return adminList;
+    }
+
+    /**
+     * @dev Get the number of admin signatures a checkpoint needs.
+     */
+    function GetThreshold()
+    view
+    public
+    returns(uint) {
+        return threshold;
+    }
+
+    /**
+     * @dev Register a new checkpoint, approved by at least a threshold of admin
+     * signatures ordered by the signing addresses.
+     *
+     * The signed message follows EIP 191 with version 0x00, the oracle address
+     * as the intended validator and the packed checkpoint fields as the data.
+     */
+    function SetCheckpoint(
+        uint64 _sectionIndex,
+        bytes32 _sectionHead,
+        bytes32 _chtRoot,
+        bytes32 _bloomTrieRoot,
+        uint8[] memory _v,
+        bytes32[] memory _r,
+        bytes32[] memory _s
+    )
+    public
+    returns (bool)
+    {
+        // Ensure the checkpoint is approved by enough signatures
+        require(_v.length == _r.length && _v.length == _s.length);
+        require(_v.length >= threshold);
+
+        // Ensure checkpoints are only ever moving forward
+        require(height == 0 || _sectionIndex > sectionIndex);
+
+        bytes32 signedHash = keccak256(abi.encodePacked(bytes1(0x19), bytes1(0), address(this), _sectionIndex, _sectionHead, _chtRoot, _bloomTrieRoot));
+
+        address lastVoter = address(0);
+        for (uint i = 0; i < _v.length; i++) {
+            address signer = ecrecover(signedHash, _v[i], _r[i], _s[i]);
+            require(admins[signer]);
+
+            // Ascending signers prevent a signature from being counted twice
+            require(uint160(signer) > uint160(lastVoter));
+            lastVoter = signer;
+
+            emit NewCheckpoint(_sectionIndex, _sectionHead, _chtRoot, _bloomTrieRoot, _v[i], _r[i], _s[i]);
+        }
+        sectionIndex = _sectionIndex;
+        sectionHead = _sectionHead;
+        chtRoot = _chtRoot;
+        bloomTrieRoot = _bloomTrieRoot;
+        height = block.number;
+
+        sigV = _v;
+        sigR = _r;
+        sigS = _s;
+        return true;
+    }
+
+    /*
+        Fields
+    */
+    // A map of admin users who have the permission to sign checkpoints
+    mapping(address => bool) admins;
+
+    // A list of admin users so that we can obtain all admin users
+    address[] adminList;
+
+    // Number of admin signatures required to register a checkpoint
+    uint threshold;
+
+    // The latest registered checkpoint and the block it was registered in
+    uint64 sectionIndex;
+    bytes32 sectionHead;
+    bytes32 chtRoot;
+    bytes32 bloomTrieRoot;
+    uint height;
+
+    // The admin signatures approving the latest checkpoint
+    uint8[] sigV;
+    bytes32[] sigR;
+    bytes32[] sigS;
+}
@@ -0,0 +1,218 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package checkpointoracle is a wrapper of the checkpoint oracle contract, in
+// which a threshold of trusted admins approve light client checkpoints.
+package checkpointoracle
+
+// The bytecode is the output of solc 0.8.21 for the byzantium EVM with 200
+// optimizer runs, `solc --evm-version byzantium --optimize --bin`. The ABI
+// matches the compiler output, using the legacy "constant" function flags.
+//go:generate abigen --abi contract/oracle.abi --bin contract/oracle.bin --pkg contract --type CheckpointOracle --out contract/oracle.go
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/okcoin/go-okcoin/accounts/abi/bind"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/contracts/checkpointoracle/contract"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+)
+
+var (
+	// errReadOnly is returned if a checkpoint is registered through an oracle
+	// bound without a transactor.
+	errReadOnly = errors.New("oracle bound read only")
+
+	// errMalformedSignatures is returned if the oracle returns signature parts
+	// of different lengths.
+	errMalformedSignatures = errors.New("malformed checkpoint signatures")
+)
+
+// Checkpoint is a set of post-processed trie roots (CHT and BloomTrie) of a
+// light client section, along with the section index and head hash.
+type Checkpoint struct {
+	SectionIndex  uint64      `json:"sectionIndex"`
+	SectionHead   common.Hash `json:"sectionHead"`
+	CHTRoot       common.Hash `json:"chtRoot"`
+	BloomTrieRoot common.Hash `json:"bloomTrieRoot"`
+}
+
+// SigHash returns the hash admins sign to approve the checkpoint in the given
+// oracle. It follows EIP 191 version 0, with the oracle as the validator.
+func (c *Checkpoint) SigHash(oracle common.Address) common.Hash {
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], c.SectionIndex)
+	return crypto.Keccak256Hash([]byte{0x19, 0x00}, oracle.Bytes(), index[:], c.SectionHead.Bytes(), c.CHTRoot.Bytes(), c.BloomTrieRoot.Bytes())
+}
+
+// Signers recovers the accounts that signed the checkpoint in the given oracle.
+func (c *Checkpoint) Signers(oracle common.Address, sigs [][]byte) ([]common.Address, error) {
+	hash := c.SigHash(oracle)
+
+	signers := make([]common.Address, len(sigs))
+	for i, sig := range sigs {
+		pubkey, err := crypto.SigToPub(hash.Bytes(), sig)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = crypto.PubkeyToAddress(*pubkey)
+	}
+	return signers, nil
+}
+
+// Verify checks that the checkpoint is approved in the given oracle by at least
+// threshold distinct accounts of the trusted signers.
+func (c *Checkpoint) Verify(oracle common.Address, sigs [][]byte, trusted []common.Address, threshold uint64) error {
+	signers, err := c.Signers(oracle, sigs)
+	if err != nil {
+		return err
+	}
+	approved := make(map[common.Address]bool)
+	for _, signer := range signers {
+		for _, account := range trusted {
+			if signer == account {
+				approved[signer] = true
+			}
+		}
+	}
+	if uint64(len(approved)) < threshold {
+		return fmt.Errorf("checkpoint #%d approved by %d trusted signers, want %d", c.SectionIndex, len(approved), threshold)
+	}
+	return nil
+}
+
+// CheckpointOracle is a Go wrapper around a deployed checkpoint oracle.
+type CheckpointOracle struct {
+	address    common.Address
+	caller     *contract.CheckpointOracleCaller
+	transactor *contract.CheckpointOracleTransactor
+}
+
+// NewCheckpointOracle binds the oracle deployed at the given address. The
+// transactor may be nil if the oracle is only read.
+func NewCheckpointOracle(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor) (*CheckpointOracle, error) {
+	oracle := &CheckpointOracle{address: address}
+
+	var err error
+	if oracle.caller, err = contract.NewCheckpointOracleCaller(address, caller); err != nil {
+		return nil, err
+	}
+	if transactor != nil {
+		if oracle.transactor, err = contract.NewCheckpointOracleTransactor(address, transactor); err != nil {
+			return nil, err
+		}
+	}
+	return oracle, nil
+}
+
+// DeployCheckpointOracle deploys a checkpoint oracle in which the given admins
+// approve checkpoints with at least threshold signatures, binding it.
+func DeployCheckpointOracle(opts *bind.TransactOpts, backend bind.ContractBackend, admins []common.Address, threshold uint64) (common.Address, *CheckpointOracle, error) {
+	address, _, _, err := contract.DeployCheckpointOracle(opts, backend, admins, new(big.Int).SetUint64(threshold))
+	if err != nil {
+		return address, nil, err
+	}
+	oracle, err := NewCheckpointOracle(address, backend, backend)
+	if err != nil {
+		return address, nil, err
+	}
+	return address, oracle, nil
+}
+
+// Address returns the address of the oracle contract.
+func (o *CheckpointOracle) Address() common.Address {
+	return o.address
+}
+
+// Admins retrieves the accounts allowed to approve checkpoints.
+func (o *CheckpointOracle) Admins(opts *bind.CallOpts) ([]common.Address, error) {
+	return o.caller.GetAllAdmin(opts)
+}
+
+// Threshold retrieves the number of admin signatures a checkpoint needs.
+func (o *CheckpointOracle) Threshold(opts *bind.CallOpts) (uint64, error) {
+	threshold, err := o.caller.GetThreshold(opts)
+	if err != nil {
+		return 0, err
+	}
+	return threshold.Uint64(), nil
+}
+
+// LatestCheckpoint retrieves the latest registered checkpoint along with the
+// number of the block it was registered in and the admin signatures approving
+// it. A nil checkpoint is returned if none was registered yet.
+func (o *CheckpointOracle) LatestCheckpoint(opts *bind.CallOpts) (*Checkpoint, uint64, [][]byte, error) {
+	index, head, chtRoot, bloomTrieRoot, height, v, r, s, err := o.caller.GetLatestCheckpoint(opts)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if height.Sign() == 0 {
+		return nil, 0, nil, nil
+	}
+	if len(v) != len(r) || len(v) != len(s) {
+		return nil, 0, nil, errMalformedSignatures
+	}
+	sigs := make([][]byte, len(v))
+	for i := range v {
+		sigs[i] = make([]byte, 65)
+		copy(sigs[i], r[i][:])
+		copy(sigs[i][32:], s[i][:])
+		sigs[i][64] = v[i] - 27
+	}
+	checkpoint := &Checkpoint{
+		SectionIndex:  index,
+		SectionHead:   head,
+		CHTRoot:       chtRoot,
+		BloomTrieRoot: bloomTrieRoot,
+	}
+	return checkpoint, height.Uint64(), sigs, nil
+}
+
+// RegisterCheckpoint publishes a checkpoint approved by the given admin
+// signatures, which are ordered by signer as the contract requires.
+func (o *CheckpointOracle) RegisterCheckpoint(opts *bind.TransactOpts, checkpoint *Checkpoint, sigs [][]byte) (*types.Transaction, error) {
+	if o.transactor == nil {
+		return nil, errReadOnly
+	}
+	signers, err := checkpoint.Signers(o.address, sigs)
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(sigs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return bytes.Compare(signers[order[i]].Bytes(), signers[order[j]].Bytes()) < 0
+	})
+	var (
+		v    = make([]uint8, len(sigs))
+		r, s = make([][32]byte, len(sigs)), make([][32]byte, len(sigs))
+	)
+	for i, idx := range order {
+		copy(r[i][:], sigs[idx][:32])
+		copy(s[i][:], sigs[idx][32:64])
+		v[i] = sigs[idx][64] + 27
+	}
+	return o.transactor.SetCheckpoint(opts, checkpoint.SectionIndex, checkpoint.SectionHead, checkpoint.CHTRoot, checkpoint.BloomTrieRoot, v, r, s)
+}
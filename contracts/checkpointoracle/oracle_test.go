@@ -0,0 +1,117 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpointoracle
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/accounts/abi/bind"
+	"github.com/okcoin/go-okcoin/accounts/abi/bind/backends"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/crypto"
+)
+
+// Tests that checkpoints signed by the admins are published in the order the
+// contract requires, and that clients can verify them when reading back.
+func TestRegisterCheckpoint(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	admins := make([]common.Address, 3)
+	alloc := make(core.GenesisAlloc)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		admins[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[admins[i]] = core.GenesisAccount{Balance: big.NewInt(1000000000000000000)}
+	}
+	backend := backends.NewSimulatedBackend(alloc)
+	auth := bind.NewKeyedTransactor(keys[0])
+
+	address, oracle, err := DeployCheckpointOracle(auth, backend, admins, 2)
+	if err != nil {
+		t.Fatalf("failed to deploy oracle: %v", err)
+	}
+	backend.Commit()
+
+	if checkpoint, _, _, err := oracle.LatestCheckpoint(nil); checkpoint != nil || err != nil {
+		t.Fatalf("checkpoint before registration: %v, %v", checkpoint, err)
+	}
+	if threshold, err := oracle.Threshold(nil); threshold != 2 || err != nil {
+		t.Fatalf("threshold mismatch: have %d, %v, want 2", threshold, err)
+	}
+	if registered, err := oracle.Admins(nil); len(registered) != len(admins) || err != nil {
+		t.Fatalf("admins mismatch: have %x, %v, want %x", registered, err, admins)
+	}
+	checkpoint := &Checkpoint{
+		SectionIndex:  7,
+		SectionHead:   common.HexToHash("0x01"),
+		CHTRoot:       common.HexToHash("0x02"),
+		BloomTrieRoot: common.HexToHash("0x03"),
+	}
+	sign := func(checkpoint *Checkpoint, key *ecdsa.PrivateKey) []byte {
+		sig, err := crypto.Sign(checkpoint.SigHash(address).Bytes(), key)
+		if err != nil {
+			t.Fatalf("failed to sign checkpoint: %v", err)
+		}
+		return sig
+	}
+	// Checkpoints short of the threshold or signed by outsiders are rejected
+	if _, err := oracle.RegisterCheckpoint(auth, checkpoint, [][]byte{sign(checkpoint, keys[0])}); err == nil {
+		t.Fatalf("checkpoint below threshold registered")
+	}
+	outsider, _ := crypto.GenerateKey()
+	if _, err := oracle.RegisterCheckpoint(auth, checkpoint, [][]byte{sign(checkpoint, keys[0]), sign(checkpoint, outsider)}); err == nil {
+		t.Fatalf("checkpoint signed by outsider registered")
+	}
+	// Approved checkpoints are registered regardless of the signature order
+	sigs := [][]byte{sign(checkpoint, keys[2]), sign(checkpoint, keys[1]), sign(checkpoint, keys[0])}
+	if _, err := oracle.RegisterCheckpoint(auth, checkpoint, sigs); err != nil {
+		t.Fatalf("failed to register checkpoint: %v", err)
+	}
+	backend.Commit()
+
+	latest, height, sigs, err := oracle.LatestCheckpoint(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve checkpoint: %v", err)
+	}
+	if *latest != *checkpoint || height != 2 || len(sigs) != 3 {
+		t.Fatalf("checkpoint mismatch: have %+v at %d with %d signatures, want %+v at 2", latest, height, len(sigs), checkpoint)
+	}
+	if err := latest.Verify(oracle.Address(), sigs, admins, 2); err != nil {
+		t.Errorf("failed to verify checkpoint: %v", err)
+	}
+	if err := latest.Verify(oracle.Address(), sigs, admins[:1], 2); err == nil {
+		t.Errorf("checkpoint verified without enough trusted signers")
+	}
+	if err := latest.Verify(common.Address{0x0d}, sigs, admins, 2); err == nil {
+		t.Errorf("checkpoint verified for another oracle")
+	}
+	// Checkpoints may only move forward
+	stale := &Checkpoint{SectionIndex: 6}
+	if _, err := oracle.RegisterCheckpoint(auth, stale, [][]byte{sign(stale, keys[0]), sign(stale, keys[1])}); err == nil {
+		t.Errorf("stale checkpoint registered")
+	}
+	// Read-only oracles refuse to register checkpoints
+	reader, err := NewCheckpointOracle(address, backend, nil)
+	if err != nil {
+		t.Fatalf("failed to bind oracle: %v", err)
+	}
+	if _, err := reader.RegisterCheckpoint(auth, checkpoint, sigs); err != errReadOnly {
+		t.Errorf("read-only registration error mismatch: have %v, want %v", err, errReadOnly)
+	}
+}
@@ -197,3 +197,30 @@ func testHeaderConcurrentAbortion(t *testing.T, threads int) {
 		t.Errorf("verification count too large: have %d, want below %d", verified, 2*threads)
 	}
 }
+
+// Tests that the gas limit of new blocks moves towards the configured floor and
+// ceiling as fast as the protocol allows, and follows usage in between.
+func TestCalcGasLimit(t *testing.T) {
+	tests := []struct {
+		gasUsed uint64
+		floor   uint64
+		ceil    uint64
+		want    uint64
+	}{
+		// Within bounds the limit follows the usage of the parent
+		{0, 8000000, 12000000, 9990236},
+		{10000000, 8000000, 12000000, 10004884},
+		// Below the floor the limit is raised by the maximal step
+		{0, 12000000, 20000000, 10009764},
+		{10000000, 10005000, 20000000, 10005000},
+		// Above the ceiling the limit is lowered by the maximal step
+		{10000000, 8000000, 9000000, 9990236},
+		{10000000, 8000000, 10000000, 10000000},
+	}
+	for i, tt := range tests {
+		parent := types.NewBlockWithHeader(&types.Header{GasLimit: 10000000, GasUsed: tt.gasUsed})
+		if have := CalcGasLimit(parent, tt.floor, tt.ceil); have != tt.want {
+			t.Errorf("test %d: gas limit mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+}
@@ -93,14 +93,15 @@ type BlockChain struct {
 	triegc *prque.Prque   // Priority queue mapping block numbers to tries to gc
 	gcproc time.Duration  // Accumulates canonical block processing for trie dumping
 
-	hc            *HeaderChain
-	rmLogsFeed    event.Feed
-	chainFeed     event.Feed
-	chainSideFeed event.Feed
-	chainHeadFeed event.Feed
-	logsFeed      event.Feed
-	scope         event.SubscriptionScope
-	genesisBlock  *types.Block
+	hc                *HeaderChain
+	rmLogsFeed        event.Feed
+	chainFeed         event.Feed
+	chainSideFeed     event.Feed
+	chainHeadFeed     event.Feed
+	logsFeed          event.Feed
+	reorgRejectedFeed event.Feed
+	scope             event.SubscriptionScope
+	genesisBlock      *types.Block
 
 	mu      sync.RWMutex // global mutex for locking chain operations
 	chainmu sync.RWMutex // blockchain insertion lock
@@ -109,6 +110,7 @@ type BlockChain struct {
 	checkpoint       int          // checkpoint counts towards the new checkpoint
 	currentBlock     atomic.Value // Current head of the block chain
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
+	finality         atomic.Value // Rules deciding the final blocks of the chain (*finality)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
@@ -1069,6 +1071,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		if err == nil {
 			err = bc.Validator().ValidateBody(block)
 		}
+		if err == nil || err == ErrKnownBlock || err == consensus.ErrPrunedAncestor {
+			if ferr := bc.checkFinality(block); ferr != nil {
+				return i, events, coalescedLogs, ferr
+			}
+		}
 		switch {
 		case err == ErrKnownBlock:
 			// Block and state both already known. However if the current block is below
@@ -1248,6 +1255,8 @@ func countTransactions(chain []*types.Block) (c int) {
 // event about them
 func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 	var (
+		oldHead     = oldBlock
+		newHead     = newBlock
 		newChain    types.Blocks
 		oldChain    types.Blocks
 		commonBlock *types.Block
@@ -1310,6 +1319,10 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 			return fmt.Errorf("Invalid new chain")
 		}
 	}
+	// Refuse dropping blocks which are already final
+	if commonBlock.NumberU64() < bc.finalizedNumber(oldHead.NumberU64()) {
+		return bc.rejectReorg(oldHead, newHead, commonBlock.NumberU64()+1)
+	}
 	// Ensure the user sees large reorgs
 	if len(oldChain) > 0 && len(newChain) > 0 {
 		logFn := log.Debug
@@ -1558,6 +1571,11 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeReorgRejectedEvent registers a subscription of ReorgRejectedEvent.
+func (bc *BlockChain) SubscribeReorgRejectedEvent(ch chan<- ReorgRejectedEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgRejectedFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
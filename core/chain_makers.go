@@ -21,6 +21,7 @@ import (
 	"math/big"
 
 	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/math"
 	"github.com/okcoin/go-okcoin/consensus"
 	"github.com/okcoin/go-okcoin/consensus/misc"
 	"github.com/okcoin/go-okcoin/core/state"
@@ -234,7 +235,7 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 			Difficulty: parent.Difficulty(),
 			UncleHash:  parent.UncleHash(),
 		}),
-		GasLimit: CalcGasLimit(parent),
+		GasLimit: CalcGasLimit(parent, params.TargetGasLimit, math.MaxUint64),
 		Number:   new(big.Int).Add(parent.Number(), common.Big1),
 		Time:     time,
 	}
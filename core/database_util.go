@@ -62,6 +62,9 @@ var (
 	preimagePrefix = "secure-key-"              // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("okcoin-config-") // config prefix for the db
 
+	// Not nested under configPrefix to keep iterations over the configs clean
+	configUpgradesPrefix = []byte("okcoin-upgrades-") // configUpgradesPrefix + hash -> chain config upgrade history
+
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
 
@@ -624,6 +627,39 @@ func GetChainConfig(db DatabaseReader, hash common.Hash) (*params.ChainConfig, e
 	return &config, nil
 }
 
+// ChainConfigUpgrade is a record of a chain config replacement applied to an
+// existing database.
+type ChainConfigUpgrade struct {
+	Time uint64              `json:"time"` // Unix timestamp of the upgrade
+	Head uint64              `json:"head"` // Local head header number at the time of the upgrade
+	Old  *params.ChainConfig `json:"old"`
+	New  *params.ChainConfig `json:"new"`
+}
+
+// WriteChainConfigUpgrades writes the chain config upgrade history of the chain
+// with the given genesis hash to the database.
+func WriteChainConfigUpgrades(db okcdb.Putter, hash common.Hash, history []*ChainConfigUpgrade) error {
+	blob, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return db.Put(append(configUpgradesPrefix, hash[:]...), blob)
+}
+
+// GetChainConfigUpgrades retrieves the chain config upgrade history of the chain
+// with the given genesis hash, oldest first.
+func GetChainConfigUpgrades(db DatabaseReader, hash common.Hash) ([]*ChainConfigUpgrade, error) {
+	blob, _ := db.Get(append(configUpgradesPrefix, hash[:]...))
+	if len(blob) == 0 {
+		return nil, nil
+	}
+	var history []*ChainConfigUpgrade
+	if err := json.Unmarshal(blob, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
 // FindCommonAncestor returns the last common ancestor of two block headers
 func FindCommonAncestor(db DatabaseReader, a, b *types.Header) *types.Header {
 	for bn := b.Number.Uint64(); a.Number.Uint64() > bn; {
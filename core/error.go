@@ -29,6 +29,10 @@ var (
 	// ErrBlacklistedHash is returned if a block to import is on the blacklist.
 	ErrBlacklistedHash = errors.New("blacklisted hash")
 
+	// ErrFinalizedReorg is returned if a block to import is on a branch competing
+	// with the finalized part of the chain.
+	ErrFinalizedReorg = errors.New("reorg below finalized block")
+
 	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than the
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
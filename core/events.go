@@ -52,3 +52,11 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ReorgRejectedEvent is posted when a block is refused as it would reorganise
+// the chain below its finalized block.
+type ReorgRejectedEvent struct {
+	Head  *types.Block // Canonical head at the time of the refusal
+	Block *types.Block // Block on the competing branch
+	Fork  uint64       // Block number at or below which the competing branch diverges
+}
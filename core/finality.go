@@ -0,0 +1,163 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/log"
+)
+
+// FinalityCheckpoint is a canonical block vouched for by the operator of the
+// node, below which the chain may never be reorganised.
+type FinalityCheckpoint struct {
+	Number    uint64        `json:"number"`
+	Hash      common.Hash   `json:"hash"`
+	Signature hexutil.Bytes `json:"signature"` // Operator signature over the SigHash
+}
+
+// SigHash returns the hash the operator signs to vouch for the checkpoint.
+func (c *FinalityCheckpoint) SigHash() common.Hash {
+	var number [8]byte
+	binary.BigEndian.PutUint64(number[:], c.Number)
+	return crypto.Keccak256Hash([]byte("finality checkpoint"), number[:], c.Hash.Bytes())
+}
+
+// Signer recovers the account that signed the checkpoint.
+func (c *FinalityCheckpoint) Signer() (common.Address, error) {
+	pubkey, err := crypto.SigToPub(c.SigHash().Bytes(), c.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// FinalityConfig contains the rules deciding which blocks of the canonical
+// chain are final and may not be reorganised away anymore.
+type FinalityConfig struct {
+	MaxReorgDepth uint64                // Maximum number of canonical blocks a reorg may drop (0 = unlimited)
+	Checkpoints   []*FinalityCheckpoint // Operator signed checkpoints the chain is final up to
+	Signer        common.Address        // Operator account the checkpoints need to be signed by
+}
+
+// finality is the validated form of a FinalityConfig.
+type finality struct {
+	maxDepth    uint64
+	checkpoints map[uint64]common.Hash
+}
+
+// SetFinality sets the rules deciding which blocks are final, verifying the
+// checkpoints against their operator signature and the local chain.
+func (bc *BlockChain) SetFinality(config *FinalityConfig) error {
+	rules := &finality{
+		maxDepth:    config.MaxReorgDepth,
+		checkpoints: make(map[uint64]common.Hash),
+	}
+	for _, checkpoint := range config.Checkpoints {
+		signer, err := checkpoint.Signer()
+		if err != nil {
+			return fmt.Errorf("invalid signature on checkpoint #%d: %v", checkpoint.Number, err)
+		}
+		if signer != config.Signer {
+			return fmt.Errorf("checkpoint #%d signed by %x, want %x", checkpoint.Number, signer, config.Signer)
+		}
+		if hash := GetCanonicalHash(bc.db, checkpoint.Number); hash != (common.Hash{}) && hash != checkpoint.Hash {
+			return fmt.Errorf("local chain conflicts with checkpoint #%d: have %x, want %x", checkpoint.Number, hash, checkpoint.Hash)
+		}
+		rules.checkpoints[checkpoint.Number] = checkpoint.Hash
+	}
+	bc.finality.Store(rules)
+
+	if block := bc.FinalizedBlock(); block.NumberU64() > 0 {
+		log.Info("Loaded chain finality rules", "maxdepth", rules.maxDepth, "checkpoints", len(rules.checkpoints), "finalized", block.Number(), "hash", block.Hash())
+	}
+	return nil
+}
+
+// finalizedNumber returns the number of the highest final block on top of the
+// given canonical head.
+func (bc *BlockChain) finalizedNumber(head uint64) uint64 {
+	rules, _ := bc.finality.Load().(*finality)
+	if rules == nil {
+		return 0
+	}
+	var number uint64
+	if rules.maxDepth > 0 && head > rules.maxDepth {
+		number = head - rules.maxDepth
+	}
+	for checkpoint, hash := range rules.checkpoints {
+		if checkpoint > number && checkpoint <= head && GetCanonicalHash(bc.db, checkpoint) == hash {
+			number = checkpoint
+		}
+	}
+	return number
+}
+
+// FinalizedBlock retrieves the highest block of the canonical chain which may
+// not be reorganised away anymore, the genesis block if no rules are set.
+func (bc *BlockChain) FinalizedBlock() *types.Block {
+	return bc.GetBlockByNumber(bc.finalizedNumber(bc.CurrentBlock().NumberU64()))
+}
+
+// checkFinality returns an error if the given block is on a branch competing
+// with the final part of the canonical chain, or with a checkpoint.
+func (bc *BlockChain) checkFinality(block *types.Block) error {
+	rules, _ := bc.finality.Load().(*finality)
+	if rules == nil {
+		return nil
+	}
+	if hash, ok := rules.checkpoints[block.NumberU64()]; ok && hash != block.Hash() {
+		return bc.rejectReorg(bc.CurrentBlock(), block, block.NumberU64())
+	}
+	head := bc.CurrentBlock()
+	finalized := bc.finalizedNumber(head.NumberU64())
+	if finalized == 0 {
+		return nil
+	}
+	// Walk the ancestry of the block back until it joins the canonical chain
+	header := block.Header()
+	for {
+		number := header.Number.Uint64()
+		if GetCanonicalHash(bc.db, number) == header.Hash() {
+			if number < finalized && number < block.NumberU64() {
+				return bc.rejectReorg(head, block, number+1)
+			}
+			return nil
+		}
+		if number <= finalized {
+			return bc.rejectReorg(head, block, number)
+		}
+		if header = bc.GetHeader(header.ParentHash, number-1); header == nil {
+			return nil // Unknown ancestry, left for the import to reject
+		}
+	}
+}
+
+// rejectReorg reports a refused reorganisation of the chain, with the competing
+// branch diverging at or below the given block number.
+func (bc *BlockChain) rejectReorg(head, block *types.Block, fork uint64) error {
+	log.Warn("Refused reorg below finalized block", "number", block.Number(), "hash", block.Hash(),
+		"fork", fork, "head", head.Number(), "headhash", head.Hash())
+
+	go bc.reorgRejectedFeed.Send(ReorgRejectedEvent{Head: head, Block: block, Fork: fork})
+	return ErrFinalizedReorg
+}
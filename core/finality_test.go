@@ -0,0 +1,140 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// newFinalityTester creates a chain of n canonical blocks, returning the chain
+// along with a generator for competing branches off any of its blocks.
+func newFinalityTester(t *testing.T, n int) (*BlockChain, []*types.Block, func(parent *types.Block, n int) []*types.Block) {
+	var (
+		gendb, _ = okcdb.NewMemDatabase()
+		genesis  = new(Genesis).MustCommit(gendb)
+		blocks   = makeBlockChain(genesis, n, okcash.NewFaker(), gendb, 0)
+	)
+	db, _ := okcdb.NewMemDatabase()
+	new(Genesis).MustCommit(db)
+
+	chain, err := NewBlockChain(db, nil, params.TestChainConfig, okcash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert canonical chain: %v", err)
+	}
+	fork := func(parent *types.Block, n int) []*types.Block {
+		return makeBlockChain(parent, n, okcash.NewFaker(), gendb, 1)
+	}
+	return chain, blocks, fork
+}
+
+// Tests that reorgs dropping more blocks than allowed are refused, with an
+// event reporting them, while shallower ones still go through.
+func TestMaxReorgDepth(t *testing.T) {
+	chain, blocks, fork := newFinalityTester(t, 10)
+	defer chain.Stop()
+
+	if err := chain.SetFinality(&FinalityConfig{MaxReorgDepth: 3}); err != nil {
+		t.Fatalf("failed to set finality: %v", err)
+	}
+	if number := chain.FinalizedBlock().NumberU64(); number != 7 {
+		t.Fatalf("finalized block mismatch: have %d, want %d", number, 7)
+	}
+	rejected := make(chan ReorgRejectedEvent, 1)
+	sub := chain.SubscribeReorgRejectedEvent(rejected)
+	defer sub.Unsubscribe()
+
+	// A heavier branch forking below the finalized block must be refused
+	if _, err := chain.InsertChain(fork(blocks[2], 12)); err != ErrFinalizedReorg {
+		t.Fatalf("deep reorg error mismatch: have %v, want %v", err, ErrFinalizedReorg)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != blocks[9].Hash() {
+		t.Fatalf("head moved by refused reorg: have #%d [%x…]", head.NumberU64(), head.Hash().Bytes()[:4])
+	}
+	select {
+	case ev := <-rejected:
+		if ev.Head.Hash() != blocks[9].Hash() || ev.Fork != 4 {
+			t.Errorf("rejection event mismatch: head #%d, fork %d", ev.Head.NumberU64(), ev.Fork)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("no event for refused reorg")
+	}
+	// A heavier branch forking above the finalized block is accepted
+	branch := fork(blocks[7], 4)
+	if _, err := chain.InsertChain(branch); err != nil {
+		t.Fatalf("failed to reorg within allowed depth: %v", err)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != branch[3].Hash() {
+		t.Fatalf("head mismatch after reorg: have #%d, want #%d", head.NumberU64(), branch[3].NumberU64())
+	}
+}
+
+// Tests that operator signed checkpoints are verified, and that the chain is
+// final up to them.
+func TestFinalityCheckpoints(t *testing.T) {
+	chain, blocks, fork := newFinalityTester(t, 10)
+	defer chain.Stop()
+
+	if number := chain.FinalizedBlock().NumberU64(); number != 0 {
+		t.Fatalf("finalized block without rules: have %d, want 0", number)
+	}
+	key, _ := crypto.GenerateKey()
+	operator := crypto.PubkeyToAddress(key.PublicKey)
+
+	sign := func(number uint64, hash common.Hash) *FinalityCheckpoint {
+		checkpoint := &FinalityCheckpoint{Number: number, Hash: hash}
+		checkpoint.Signature, _ = crypto.Sign(checkpoint.SigHash().Bytes(), key)
+		return checkpoint
+	}
+	// Checkpoints from other signers or conflicting with the chain are rejected
+	checkpoint := sign(5, blocks[4].Hash())
+	if err := chain.SetFinality(&FinalityConfig{Checkpoints: []*FinalityCheckpoint{checkpoint}, Signer: common.Address{1}}); err == nil {
+		t.Fatalf("checkpoint of foreign signer accepted")
+	}
+	if err := chain.SetFinality(&FinalityConfig{Checkpoints: []*FinalityCheckpoint{sign(5, blocks[5].Hash())}, Signer: operator}); err == nil {
+		t.Fatalf("conflicting checkpoint accepted")
+	}
+	// Valid checkpoints finalize the chain up to them
+	if err := chain.SetFinality(&FinalityConfig{Checkpoints: []*FinalityCheckpoint{checkpoint, sign(20, common.Hash{2})}, Signer: operator}); err != nil {
+		t.Fatalf("failed to set finality: %v", err)
+	}
+	if number := chain.FinalizedBlock().NumberU64(); number != 5 {
+		t.Fatalf("finalized block mismatch: have %d, want %d", number, 5)
+	}
+	if _, err := chain.InsertChain(fork(blocks[2], 12)); err != ErrFinalizedReorg {
+		t.Fatalf("reorg below checkpoint error mismatch: have %v, want %v", err, ErrFinalizedReorg)
+	}
+	// Branches above the checkpoint are accepted, unless conflicting with a later one
+	branch := fork(blocks[5], 14)
+	if n, err := chain.InsertChain(branch); err != ErrFinalizedReorg || branch[n].NumberU64() != 20 {
+		t.Fatalf("conflicting branch error mismatch: have %v at #%d, want %v at #20", err, branch[n].NumberU64(), ErrFinalizedReorg)
+	}
+	if head := chain.CurrentBlock(); head.Hash() != branch[12].Hash() {
+		t.Fatalf("head mismatch: have #%d, want #%d", head.NumberU64(), branch[12].NumberU64())
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package forkid implements the fork identifier exchanged during the okc
+// handshake, allowing nodes to reject peers on incompatible chain forks early.
+package forkid
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+var (
+	// ErrRemoteStale is returned by the filter if the remote fork ID is a subset
+	// of the local forks, but the remote doesn't know about the next local fork.
+	ErrRemoteStale = errors.New("remote needs update")
+
+	// ErrLocalIncompatibleOrStale is returned by the filter if the remote fork ID
+	// is not compatible with the local forks, or the local node already passed
+	// a fork the remote one announces but the local one doesn't know about.
+	ErrLocalIncompatibleOrStale = errors.New("local incompatible or needs update")
+)
+
+// ID is a fork identifier, summarizing the chain a node follows.
+type ID struct {
+	Hash [4]byte // CRC32 checksum of the genesis block hash and the passed fork block numbers
+	Next uint64  // Block number of the next upcoming fork, or 0 if no forks are known
+}
+
+// String implements fmt.Stringer.
+func (id ID) String() string {
+	return fmt.Sprintf("%x/%d", id.Hash, id.Next)
+}
+
+// Filter is a fork ID validator, returning an error if a remote fork ID is not
+// compatible with the local chain.
+type Filter func(id ID) error
+
+// NewID calculates the fork ID of a chain with the given config and genesis at
+// the given head block number.
+func NewID(config *params.ChainConfig, genesis common.Hash, head uint64) ID {
+	hash := crc32.ChecksumIEEE(genesis[:])
+
+	for _, fork := range config.ForkBlocks() {
+		if fork > head {
+			return ID{Hash: checksumToBytes(hash), Next: fork}
+		}
+		hash = checksumUpdate(hash, fork)
+	}
+	return ID{Hash: checksumToBytes(hash)}
+}
+
+// NewFilter creates a fork ID validator for a chain with the given config and
+// genesis, retrieving the current head block number via headfn.
+func NewFilter(config *params.ChainConfig, genesis common.Hash, headfn func() uint64) Filter {
+	// Calculate the checksums of all the fork combinations up front
+	forks := config.ForkBlocks()
+
+	sums := make([][4]byte, len(forks)+1) // 0th is the genesis
+	hash := crc32.ChecksumIEEE(genesis[:])
+	sums[0] = checksumToBytes(hash)
+	for i, fork := range forks {
+		hash = checksumUpdate(hash, fork)
+		sums[i+1] = checksumToBytes(hash)
+	}
+	forks = append(forks, math.MaxUint64) // Last fork will never be passed
+
+	return func(id ID) error {
+		head := headfn()
+
+		for i, fork := range forks {
+			// Find the first fork not yet passed, its checksum is our current one
+			if head >= fork {
+				continue
+			}
+			// Remote on the same fork, reject if it knows of a fork we already passed
+			if sums[i] == id.Hash {
+				if id.Next > 0 && head >= id.Next {
+					return ErrLocalIncompatibleOrStale
+				}
+				return nil
+			}
+			// Remote behind, only accept if it's aware of the next fork we passed
+			for j := 0; j < i; j++ {
+				if sums[j] == id.Hash {
+					if forks[j] != id.Next {
+						return ErrRemoteStale
+					}
+					return nil
+				}
+			}
+			// Remote ahead, accept if its forks are all known to us
+			for j := i + 1; j < len(sums); j++ {
+				if sums[j] == id.Hash {
+					return nil
+				}
+			}
+			return ErrLocalIncompatibleOrStale
+		}
+		return nil // Unreachable, the last fork is never passed
+	}
+}
+
+// checksumUpdate folds a fork block number into the running checksum.
+func checksumUpdate(hash uint32, fork uint64) uint32 {
+	var blob [8]byte
+	binary.BigEndian.PutUint64(blob[:], fork)
+	return crc32.Update(hash, crc32.IEEETable, blob[:])
+}
+
+// checksumToBytes converts a checksum into its big endian byte representation.
+func checksumToBytes(hash uint32) [4]byte {
+	var blob [4]byte
+	binary.BigEndian.PutUint32(blob[:], hash)
+	return blob
+}
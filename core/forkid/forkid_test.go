@@ -0,0 +1,110 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkid
+
+import (
+	"hash/crc32"
+	"math/big"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// testConfig schedules forks at blocks 10, 20 and 30.
+var testConfig = &params.ChainConfig{
+	HomesteadBlock: big.NewInt(10),
+	EIP150Block:    big.NewInt(20),
+	ByzantiumBlock: big.NewInt(20),
+	Okcash: &params.OkcashConfig{
+		Forks: []*params.OkcashFork{{Block: big.NewInt(30)}},
+	},
+}
+
+var testGenesis = common.Hash{1}
+
+// checksum calculates the fork hash of the test genesis and the given forks.
+func checksum(genesis common.Hash, forks ...uint64) [4]byte {
+	hash := crc32.ChecksumIEEE(genesis[:])
+	for _, fork := range forks {
+		hash = checksumUpdate(hash, fork)
+	}
+	return checksumToBytes(hash)
+}
+
+// Tests that fork IDs are calculated from the forks passed at the head.
+func TestNewID(t *testing.T) {
+	tests := []struct {
+		head uint64
+		want ID
+	}{
+		{0, ID{Hash: checksum(testGenesis), Next: 10}},
+		{9, ID{Hash: checksum(testGenesis), Next: 10}},
+		{10, ID{Hash: checksum(testGenesis, 10), Next: 20}},
+		{29, ID{Hash: checksum(testGenesis, 10, 20), Next: 30}},
+		{30, ID{Hash: checksum(testGenesis, 10, 20, 30), Next: 0}},
+		{1000, ID{Hash: checksum(testGenesis, 10, 20, 30), Next: 0}},
+	}
+	for i, tt := range tests {
+		if have := NewID(testConfig, testGenesis, tt.head); have != tt.want {
+			t.Errorf("test %d: fork ID mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that remote fork IDs are validated against the local chain.
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		head uint64
+		id   ID
+		err  error
+	}{
+		// Local and remote on the same fork, remote unaware of or agreeing on the next one
+		{15, ID{Hash: checksum(testGenesis, 10), Next: 20}, nil},
+		{15, ID{Hash: checksum(testGenesis, 10), Next: 0}, nil},
+
+		// Remote announcing an unknown future fork, local not yet past it
+		{15, ID{Hash: checksum(testGenesis, 10), Next: 17}, nil},
+
+		// Remote announcing an unknown fork the local node already passed
+		{15, ID{Hash: checksum(testGenesis, 10), Next: 12}, ErrLocalIncompatibleOrStale},
+
+		// Remote behind, aware of the fork the local node passed next
+		{15, ID{Hash: checksum(testGenesis), Next: 10}, nil},
+		{35, ID{Hash: checksum(testGenesis, 10), Next: 20}, nil},
+
+		// Remote behind and unaware of the fork the local node passed next
+		{15, ID{Hash: checksum(testGenesis), Next: 0}, ErrRemoteStale},
+		{35, ID{Hash: checksum(testGenesis, 10), Next: 25}, ErrRemoteStale},
+
+		// Remote ahead on known forks, local still syncing
+		{15, ID{Hash: checksum(testGenesis, 10, 20), Next: 30}, nil},
+		{0, ID{Hash: checksum(testGenesis, 10, 20, 30), Next: 0}, nil},
+
+		// Remote on an unknown fork or a different chain
+		{15, ID{Hash: checksum(testGenesis, 10, 17), Next: 0}, ErrLocalIncompatibleOrStale},
+		{35, ID{Hash: checksum(testGenesis, 10, 20, 30, 40), Next: 0}, ErrLocalIncompatibleOrStale},
+		{15, ID{Hash: checksum(common.Hash{2}, 10), Next: 20}, ErrLocalIncompatibleOrStale},
+	}
+	for i, tt := range tests {
+		head := tt.head
+		filter := NewFilter(testConfig, testGenesis, func() uint64 { return head })
+		if err := filter(tt.id); err != tt.err {
+			t.Errorf("test %d: validation error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
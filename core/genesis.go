@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/common/hexutil"
@@ -39,7 +40,11 @@ import (
 //go:generate gencodec -type Genesis -field-override genesisSpecMarshaling -out gen_genesis.go
 //go:generate gencodec -type GenesisAccount -field-override genesisAccountMarshaling -out gen_genesis_account.go
 
-var errGenesisNoConfig = errors.New("genesis has no chain configuration")
+var (
+	errGenesisNoConfig = errors.New("genesis has no chain configuration")
+	errNoStoredGenesis = errors.New("database contains no genesis block")
+	errEngineChange    = errors.New("consensus engine can't be changed")
+)
 
 // Genesis specifies the header fields, state of a genesis block. It also defines hard
 // fork switch-over blocks through the chain configuration.
@@ -153,6 +158,11 @@ func SetupGenesisBlock(db okcdb.Database, genesis *Genesis) (*params.ChainConfig
 	if genesis != nil && genesis.Config == nil {
 		return params.AllOkcashProtocolChanges, common.Hash{}, errGenesisNoConfig
 	}
+	if genesis != nil {
+		if err := genesis.Config.Validate(); err != nil {
+			return genesis.Config, common.Hash{}, err
+		}
+	}
 
 	// Just commit the new block if there is no stored genesis block.
 	stored := GetCanonicalHash(db, 0)
@@ -218,6 +228,109 @@ func SetupGenesisBlock(db okcdb.Database, genesis *Genesis) (*params.ChainConfig
 	return newcfg, stored, WriteChainConfig(db, stored, newcfg)
 }
 
+// CheckChainConfigUpgrade verifies that the chain configuration of the given
+// genesis can replace the one stored in db without invalidating any of the
+// already imported blocks, i.e. that it only changes forks above the local
+// head header. The consensus engine and its non-scheduled parameters can't be
+// changed at all. It returns the stored configuration and the local head number.
+func CheckChainConfigUpgrade(db okcdb.Database, genesis *Genesis) (*params.ChainConfig, uint64, error) {
+	if genesis.Config == nil {
+		return nil, 0, errGenesisNoConfig
+	}
+	if err := genesis.Config.Validate(); err != nil {
+		return nil, 0, err
+	}
+	stored := GetCanonicalHash(db, 0)
+	if (stored == common.Hash{}) {
+		return nil, 0, errNoStoredGenesis
+	}
+	if hash := genesis.ToBlock(nil).Hash(); hash != stored {
+		return nil, 0, &GenesisMismatchError{stored, hash}
+	}
+	storedcfg, err := GetChainConfig(db, stored)
+	if err != nil {
+		return nil, 0, err
+	}
+	height := GetBlockNumber(db, GetHeadHeaderHash(db))
+	if height == missingNumber {
+		return storedcfg, 0, fmt.Errorf("missing block number for head header hash")
+	}
+	// Unlike SetupGenesisBlock, any conflict is fatal here: even a rewind to
+	// the genesis block would drop imported blocks.
+	if compatErr := storedcfg.CheckCompatible(genesis.Config, height); compatErr != nil {
+		return storedcfg, height, compatErr
+	}
+	if err := checkEngineUpgrade(storedcfg, genesis.Config); err != nil {
+		return storedcfg, height, err
+	}
+	return storedcfg, height, nil
+}
+
+// checkEngineUpgrade verifies that a configuration upgrade keeps the consensus
+// engine and its parameters. Okcash parameters are checked by CheckCompatible,
+// as they may be changed by forks scheduled above the local head.
+func checkEngineUpgrade(oldcfg, newcfg *params.ChainConfig) error {
+	if (oldcfg.Okcash == nil) != (newcfg.Okcash == nil) || (oldcfg.Clique == nil) != (newcfg.Clique == nil) || (oldcfg.BFT == nil) != (newcfg.BFT == nil) {
+		return fmt.Errorf("%v: engine have %v, want %v", errEngineChange, engineName(oldcfg), engineName(newcfg))
+	}
+	if oldcfg.Clique != nil && *oldcfg.Clique != *newcfg.Clique {
+		return fmt.Errorf("%v: clique config have %+v, want %+v", errEngineChange, *oldcfg.Clique, *newcfg.Clique)
+	}
+	if oldcfg.BFT != nil && *oldcfg.BFT != *newcfg.BFT {
+		return fmt.Errorf("%v: bft config have %+v, want %+v", errEngineChange, *oldcfg.BFT, *newcfg.BFT)
+	}
+	return nil
+}
+
+// engineName returns the names of the consensus engines configured.
+func engineName(config *params.ChainConfig) string {
+	var names []string
+	if config.Okcash != nil {
+		names = append(names, config.Okcash.String())
+	}
+	if config.Clique != nil {
+		names = append(names, config.Clique.String())
+	}
+	if config.BFT != nil {
+		names = append(names, config.BFT.String())
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// UpgradeChainConfig replaces the chain configuration stored in db with the one
+// of the given genesis if CheckChainConfigUpgrade permits it, recording the
+// change in the upgrade history of the chain. The replaced configuration is
+// returned.
+func UpgradeChainConfig(db okcdb.Database, genesis *Genesis) (*params.ChainConfig, error) {
+	storedcfg, height, err := CheckChainConfigUpgrade(db, genesis)
+	if err != nil {
+		return storedcfg, err
+	}
+	stored := GetCanonicalHash(db, 0)
+
+	history, err := GetChainConfigUpgrades(db, stored)
+	if err != nil {
+		return storedcfg, err
+	}
+	history = append(history, &ChainConfigUpgrade{
+		Time: uint64(time.Now().Unix()),
+		Head: height,
+		Old:  storedcfg,
+		New:  genesis.Config,
+	})
+	batch := db.NewBatch()
+	if err := WriteChainConfigUpgrades(batch, stored, history); err != nil {
+		return storedcfg, err
+	}
+	if err := WriteChainConfig(batch, stored, genesis.Config); err != nil {
+		return storedcfg, err
+	}
+	return storedcfg, batch.Write()
+}
+
 func (g *Genesis) configOrDefault(ghash common.Hash) *params.ChainConfig {
 	switch {
 	case g != nil:
@@ -161,3 +161,121 @@ func TestSetupGenesis(t *testing.T) {
 		}
 	}
 }
+
+// Tests that a genesis with out of bounds consensus parameters is rejected
+// before anything is written to the database.
+func TestSetupGenesisInvalidConfig(t *testing.T) {
+	db, _ := okcdb.NewMemDatabase()
+	genesis := &Genesis{
+		Config: &params.ChainConfig{
+			Okcash: &params.OkcashConfig{
+				OkcashParams: params.OkcashParams{DifficultyBoundDivisor: big.NewInt(0)},
+			},
+		},
+	}
+	if _, _, err := SetupGenesisBlock(db, genesis); err == nil {
+		t.Fatalf("invalid okcash config accepted")
+	}
+	if hash := GetCanonicalHash(db, 0); hash != (common.Hash{}) {
+		t.Errorf("genesis written despite invalid config: %x", hash)
+	}
+}
+
+// Tests that chain config upgrades are only applied if they don't affect the
+// already imported blocks, and that applied ones are recorded.
+func TestUpgradeChainConfig(t *testing.T) {
+	db, _ := okcdb.NewMemDatabase()
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: big.NewInt(10)},
+		Alloc:  GenesisAlloc{{1}: {Balance: big.NewInt(1)}},
+	}
+	genesis := gspec.MustCommit(db)
+
+	bc, _ := NewBlockChain(db, nil, gspec.Config, okcash.NewFullFaker(), vm.Config{})
+	defer bc.Stop()
+
+	blocks, _ := GenerateChain(gspec.Config, genesis, okcash.NewFaker(), db, 4, nil)
+	if _, err := bc.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Moving a fork below the local head must be rejected
+	conflict := *gspec
+	conflict.Config = &params.ChainConfig{HomesteadBlock: big.NewInt(3)}
+	if _, err := UpgradeChainConfig(db, &conflict); err == nil {
+		t.Fatalf("conflicting config upgrade accepted")
+	} else if _, ok := err.(*params.ConfigCompatError); !ok {
+		t.Fatalf("conflicting config upgrade error mismatch: have %v, want *params.ConfigCompatError", err)
+	}
+	// Upgrading the config of a different chain must be rejected
+	foreign := *gspec
+	foreign.Alloc = GenesisAlloc{{2}: {Balance: big.NewInt(1)}}
+	if _, err := UpgradeChainConfig(db, &foreign); err == nil {
+		t.Fatalf("config upgrade of foreign genesis accepted")
+	}
+	if history, _ := GetChainConfigUpgrades(db, genesis.Hash()); len(history) != 0 {
+		t.Fatalf("rejected upgrades recorded: %v", history)
+	}
+	// Moving a future fork must be accepted and recorded
+	upgrade := *gspec
+	upgrade.Config = &params.ChainConfig{HomesteadBlock: big.NewInt(5), ByzantiumBlock: big.NewInt(20)}
+	old, err := UpgradeChainConfig(db, &upgrade)
+	if err != nil {
+		t.Fatalf("failed to upgrade config: %v", err)
+	}
+	if !reflect.DeepEqual(old, gspec.Config) {
+		t.Errorf("replaced config mismatch: have %v, want %v", old, gspec.Config)
+	}
+	if stored, _ := GetChainConfig(db, genesis.Hash()); !reflect.DeepEqual(stored, upgrade.Config) {
+		t.Errorf("stored config mismatch: have %v, want %v", stored, upgrade.Config)
+	}
+	history, err := GetChainConfigUpgrades(db, genesis.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve upgrade history: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("upgrade history length mismatch: have %d, want 1", len(history))
+	}
+	if history[0].Head != 4 || !reflect.DeepEqual(history[0].Old, gspec.Config) || !reflect.DeepEqual(history[0].New, upgrade.Config) {
+		t.Errorf("upgrade record mismatch: have %+v", history[0])
+	}
+}
+
+// Tests that chain config upgrades changing the consensus engine or its
+// parameters are rejected.
+func TestUpgradeChainConfigEngine(t *testing.T) {
+	var (
+		okcashcfg = &params.OkcashConfig{}
+		cliquecfg = &params.CliqueConfig{Period: 15, Epoch: 30000}
+		bftcfg    = &params.BFTConfig{Period: 5, Epoch: 30000, RequestTimeout: 10000}
+	)
+	tests := []struct {
+		stored, upgrade *params.ChainConfig
+		ok              bool
+	}{
+		{&params.ChainConfig{Okcash: okcashcfg}, &params.ChainConfig{Okcash: okcashcfg, ByzantiumBlock: big.NewInt(10)}, true},
+		{&params.ChainConfig{Clique: cliquecfg}, &params.ChainConfig{Clique: &params.CliqueConfig{Period: 15, Epoch: 30000}}, true},
+		{&params.ChainConfig{Okcash: okcashcfg}, &params.ChainConfig{Clique: cliquecfg}, false},
+		{&params.ChainConfig{Clique: cliquecfg}, &params.ChainConfig{BFT: bftcfg}, false},
+		{&params.ChainConfig{BFT: bftcfg}, &params.ChainConfig{}, false},
+		{&params.ChainConfig{Clique: cliquecfg}, &params.ChainConfig{Clique: cliquecfg, BFT: bftcfg}, false},
+		{&params.ChainConfig{Clique: cliquecfg}, &params.ChainConfig{Clique: &params.CliqueConfig{Period: 5, Epoch: 30000}}, false},
+		{&params.ChainConfig{Clique: cliquecfg}, &params.ChainConfig{Clique: &params.CliqueConfig{Period: 15, Epoch: 100}}, false},
+		{&params.ChainConfig{BFT: bftcfg}, &params.ChainConfig{BFT: &params.BFTConfig{Period: 5, Epoch: 30000, RequestTimeout: 500}}, false},
+		{&params.ChainConfig{Okcash: okcashcfg}, &params.ChainConfig{Okcash: &params.OkcashConfig{OkcashParams: params.OkcashParams{BlockReward: big.NewInt(1)}}}, false},
+	}
+	for i, tt := range tests {
+		db, _ := okcdb.NewMemDatabase()
+		gspec := &Genesis{Config: tt.stored}
+		gspec.MustCommit(db)
+
+		upgrade := *gspec
+		upgrade.Config = tt.upgrade
+		_, _, err := CheckChainConfigUpgrade(db, &upgrade)
+		if tt.ok && err != nil {
+			t.Errorf("test %d: valid upgrade rejected: %v", i, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("test %d: engine changing upgrade accepted", i)
+		}
+	}
+}
@@ -17,6 +17,7 @@
 package state
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -71,6 +72,49 @@ func (self *StateDB) RawDump() Dump {
 	return dump
 }
 
+// RawDumpAccounts dumps the given accounts of the state, including the changes
+// not committed yet. Storage keys without a known preimage are reported hashed,
+// accounts missing from the state are left out.
+func (self *StateDB) RawDumpAccounts(addrs []common.Address) Dump {
+	dump := Dump{
+		Root:     fmt.Sprintf("%x", self.trie.Hash()),
+		Accounts: make(map[string]DumpAccount),
+	}
+	for _, addr := range addrs {
+		obj := self.getStateObject(addr)
+		if obj == nil {
+			continue
+		}
+		account := DumpAccount{
+			Balance:  obj.data.Balance.String(),
+			Nonce:    obj.data.Nonce,
+			Root:     common.Bytes2Hex(obj.data.Root[:]),
+			CodeHash: common.Bytes2Hex(obj.data.CodeHash),
+			Code:     common.Bytes2Hex(obj.Code(self.db)),
+			Storage:  make(map[string]string),
+		}
+		tr := obj.getTrie(self.db)
+		it := trie.NewIterator(tr.NodeIterator(nil))
+		for it.Next() {
+			key := tr.GetKey(it.Key)
+			if key == nil {
+				key = it.Key
+			}
+			account.Storage[common.Bytes2Hex(key)] = common.Bytes2Hex(it.Value)
+		}
+		for key, value := range obj.dirtyStorage {
+			if (value == common.Hash{}) {
+				delete(account.Storage, common.Bytes2Hex(key[:]))
+				continue
+			}
+			enc, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
+			account.Storage[common.Bytes2Hex(key[:])] = common.Bytes2Hex(enc)
+		}
+		dump.Accounts[common.Bytes2Hex(addr[:])] = account
+	}
+	return dump
+}
+
 func (self *StateDB) Dump() []byte {
 	json, err := json.MarshalIndent(self.RawDump(), "", "    ")
 	if err != nil {
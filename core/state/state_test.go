@@ -86,6 +86,31 @@ func (s *StateSuite) TestDump(c *checker.C) {
 	}
 }
 
+func (s *StateSuite) TestDumpAccounts(c *checker.C) {
+	addr1, addr2, missing := toAddr([]byte{0x01}), toAddr([]byte{0x02}), toAddr([]byte{0x03})
+
+	s.state.SetState(addr1, common.Hash{0x01}, common.Hash{0x11})
+	s.state.SetState(addr1, common.Hash{0x02}, common.Hash{0x22})
+	s.state.Commit(false)
+
+	// Change the accounts without committing
+	s.state.SetState(addr1, common.Hash{0x01}, common.Hash{})
+	s.state.SetState(addr1, common.Hash{0x03}, common.Hash{0x33})
+	s.state.AddBalance(addr2, big.NewInt(42))
+
+	dump := s.state.RawDumpAccounts([]common.Address{addr1, addr2, missing})
+	if len(dump.Accounts) != 2 {
+		c.Fatalf("dumped account count mismatch: have %d, want 2", len(dump.Accounts))
+	}
+	storage := dump.Accounts[common.Bytes2Hex(addr1[:])].Storage
+	want := map[string]string{
+		common.Bytes2Hex(common.Hash{0x02}.Bytes()): "a0" + common.Bytes2Hex(common.Hash{0x22}.Bytes()),
+		common.Bytes2Hex(common.Hash{0x03}.Bytes()): "a0" + common.Bytes2Hex(common.Hash{0x33}.Bytes()),
+	}
+	c.Check(storage, checker.DeepEquals, want)
+	c.Check(dump.Accounts[common.Bytes2Hex(addr2[:])].Balance, checker.Equals, "42")
+}
+
 func (s *StateSuite) SetUpTest(c *checker.C) {
 	s.db, _ = okcdb.NewMemDatabase()
 	s.state, _ = New(common.Hash{}, NewDatabase(s.db))
@@ -0,0 +1,100 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/rlp"
+)
+
+var (
+	// BFTDigest is the magic mix digest identifying blocks sealed by the BFT engine.
+	BFTDigest = common.HexToHash("0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365")
+
+	// BFTExtraVanity is the fixed number of extra-data prefix bytes reserved for
+	// the validator vanity of BFT sealed headers.
+	BFTExtraVanity = 32
+
+	errBFTMissingVanity = errors.New("bft extra-data vanity missing")
+)
+
+// BFTVote is a validator vote cast by the proposer of a BFT sealed block.
+type BFTVote struct {
+	Address   common.Address // Account being voted on to change its validator status
+	Authorize bool           // Whether to add or remove the account as a validator
+}
+
+// BFTExtra is the consensus part of the extra-data of BFT sealed headers, RLP
+// encoded after the vanity prefix.
+type BFTExtra struct {
+	Validators     []common.Address // Full validator list on checkpoint blocks, empty otherwise
+	Vote           *BFTVote         `rlp:"nil"`
+	Round          uint64           // Consensus round the block was proposed in
+	Seal           []byte           // Proposer signature over the header without any seals
+	CommittedSeals [][]byte         // Commit seals of a quorum of validators finalizing the block
+}
+
+// ExtractBFTExtra decodes the consensus fields from the extra-data of a BFT
+// sealed header.
+func ExtractBFTExtra(h *Header) (*BFTExtra, error) {
+	if len(h.Extra) < BFTExtraVanity {
+		return nil, errBFTMissingVanity
+	}
+	extra := new(BFTExtra)
+	if err := rlp.DecodeBytes(h.Extra[BFTExtraVanity:], extra); err != nil {
+		return nil, err
+	}
+	return extra, nil
+}
+
+// SetBFTExtra replaces the consensus fields in the extra-data of a header,
+// retaining the vanity prefix.
+func SetBFTExtra(h *Header, extra *BFTExtra) error {
+	blob, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return err
+	}
+	if len(h.Extra) < BFTExtraVanity {
+		h.Extra = append(h.Extra, bytes.Repeat([]byte{0x00}, BFTExtraVanity-len(h.Extra))...)
+	}
+	h.Extra = append(h.Extra[:BFTExtraVanity:BFTExtraVanity], blob...)
+	return nil
+}
+
+// BFTFilteredHeader returns a copy of a BFT sealed header without the commit
+// seals, and without the proposer seal too unless keepSeal is set. The commit
+// seals are collected after the block is agreed on, so they are excluded from
+// the block hash. Nil is returned if the extra-data can't be decoded.
+func BFTFilteredHeader(h *Header, keepSeal bool) *Header {
+	extra, err := ExtractBFTExtra(h)
+	if err != nil {
+		return nil
+	}
+	if !keepSeal {
+		extra.Seal = nil
+	}
+	extra.CommittedSeals = nil
+
+	cpy := CopyHeader(h)
+	if err := SetBFTExtra(cpy, extra); err != nil {
+		return nil
+	}
+	return cpy
+}
@@ -98,8 +98,13 @@ type headerMarshaling struct {
 }
 
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
-// RLP encoding.
+// RLP encoding. The commit seals of BFT sealed headers are excluded.
 func (h *Header) Hash() common.Hash {
+	if h.MixDigest == BFTDigest {
+		if filtered := BFTFilteredHeader(h, true); filtered != nil {
+			return rlpHash(filtered)
+		}
+	}
 	return rlpHash(h)
 }
 
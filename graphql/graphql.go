@@ -0,0 +1,876 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to the chain data of a node.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/bloombits"
+	"github.com/okcoin/go-okcoin/core/state"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/internal/okcapi"
+	"github.com/okcoin/go-okcoin/okc/filters"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+var (
+	errBlockNotFound    = errors.New("block not found")
+	errBlockNumberHash  = errors.New("only one of number or hash may be specified")
+	errBlockRangeTooBig = fmt.Errorf("block range too big, at most %d blocks allowed", maxBlockRange)
+)
+
+// maxBlockRange is the maximum number of blocks a single blocks or logs field may
+// span.
+const maxBlockRange = 1024
+
+// Backend is the chain data access of the GraphQL service: the one of the public
+// Okcoin APIs, extended with the log filtering support of the filters package.
+type Backend interface {
+	okcapi.Backend
+
+	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
+	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
+	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	BloomStatus() (uint64, uint64)
+	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+}
+
+// Long is a 64 bit integer, which unlike the Int type of GraphQL is able to hold
+// block numbers, nonces and gas amounts.
+type Long int64
+
+// ImplementsGraphQLType returns true if Long implements the specified GraphQL type.
+func (Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL parses a Long from a GraphQL query, given either as a number
+// or as a decimal string.
+func (l *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		value, err := strconv.ParseInt(input, 10, 64)
+		*l = Long(value)
+		return err
+	case int32:
+		*l = Long(input)
+	case int64:
+		*l = Long(input)
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return nil
+}
+
+// BlockNumberArgs selects the block an account is looked up at, the latest one
+// if unset.
+type BlockNumberArgs struct {
+	Block *Long
+}
+
+// number returns the selected block number.
+func (a BlockNumberArgs) number() rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return rpc.LatestBlockNumber
+}
+
+// Account represents an Okcoin account at a particular block. Its state is only
+// retrieved once a field needing it is resolved.
+type Account struct {
+	backend     Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+
+	state *state.StateDB
+	lock  sync.Mutex
+}
+
+// resolveState returns the state the account is looked up in, fetching it if needed.
+func (a *Account) resolveState(ctx context.Context) (*state.StateDB, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.state == nil {
+		state, _, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		if state == nil {
+			return nil, errBlockNotFound
+		}
+		a.state = state
+	}
+	return a.state, nil
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, err := a.resolveState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (Long, error) {
+	state, err := a.resolveState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(state.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, err := a.resolveState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(state.GetCode(a.address)), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, err := a.resolveState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) (*Transaction, error) {
+	return l.transaction, nil
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	return &Account{backend: l.backend, address: l.log.Address, blockNumber: args.number()}, nil
+}
+
+func (l *Log) Index(ctx context.Context) (int32, error) {
+	return int32(l.log.Index), nil
+}
+
+func (l *Log) Topics(ctx context.Context) ([]common.Hash, error) {
+	return l.log.Topics, nil
+}
+
+func (l *Log) Data(ctx context.Context) (hexutil.Bytes, error) {
+	return hexutil.Bytes(l.log.Data), nil
+}
+
+// Transaction represents an Okcoin transaction, identified by its hash. The
+// transaction itself is only retrieved once a field needing it is resolved.
+type Transaction struct {
+	backend Backend
+	hash    common.Hash
+
+	tx    *types.Transaction
+	block *Block // Block the transaction was included in, nil if pending
+	index uint64 // Index of the transaction in the block
+	lock  sync.Mutex
+}
+
+// resolve returns the internal transaction object, fetching it from the chain or
+// the transaction pool if needed. It returns nil if the transaction is unknown.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.tx == nil {
+		tx, blockHash, blockNumber, index := core.GetTransaction(t.backend.ChainDb(), t.hash)
+		if tx != nil {
+			number := rpc.BlockNumber(blockNumber)
+			t.tx, t.index = tx, index
+			t.block = &Block{backend: t.backend, hash: blockHash, number: &number}
+		} else {
+			t.tx = t.backend.GetPoolTransaction(t.hash)
+		}
+	}
+	return t.tx, nil
+}
+
+// mustResolve returns the internal transaction object, failing if it is unknown.
+func (t *Transaction) mustResolve(ctx context.Context) (*types.Transaction, error) {
+	tx, err := t.resolve(ctx)
+	if err == nil && tx == nil {
+		err = fmt.Errorf("transaction %x not found", t.hash)
+	}
+	return tx, err
+}
+
+func (t *Transaction) Hash(ctx context.Context) (common.Hash, error) {
+	return t.hash, nil
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (Long, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (Long, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	to := tx.To()
+	if to == nil {
+		return nil, nil
+	}
+	return &Account{backend: t.backend, address: *to, blockNumber: args.number()}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{backend: t.backend, address: from, blockNumber: args.number()}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.mustResolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.mustResolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+// getReceipt returns the receipt of the transaction, nil if it is still pending.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.mustResolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || len(receipt.PostState) != 0 {
+		return nil, err
+	}
+	status := Long(receipt.Status)
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gas := Long(receipt.GasUsed)
+	return &gas, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gas := Long(receipt.CumulativeGasUsed)
+	return &gas, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return &Account{backend: t.backend, address: receipt.ContractAddress, blockNumber: args.number()}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	logs := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		logs = append(logs, &Log{backend: t.backend, transaction: t, log: log})
+	}
+	return &logs, nil
+}
+
+// Block represents an Okcoin block, identified by its hash or number. Its header,
+// body and receipts are each only retrieved once a field needing them is resolved.
+type Block struct {
+	backend Backend
+	number  *rpc.BlockNumber // Number of the block, nil if only the hash is known
+	hash    common.Hash      // Hash of the block, zero if only the number is known
+
+	header   *types.Header
+	block    *types.Block
+	receipts []*types.Receipt
+	lock     sync.Mutex
+}
+
+// resolve returns the internal block object, fetching it if needed.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.resolveBlock(ctx)
+}
+
+// resolveBlock fetches the block if not yet retrieved, with the lock held.
+func (b *Block) resolveBlock(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	var (
+		block *types.Block
+		err   error
+	)
+	if b.hash != (common.Hash{}) {
+		block, err = b.backend.GetBlock(ctx, b.hash)
+	} else {
+		block, err = b.backend.BlockByNumber(ctx, *b.number)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errBlockNotFound
+	}
+	b.block, b.header = block, block.Header()
+	return block, nil
+}
+
+// resolveHeader returns the internal header object, fetching it if needed. Blocks
+// identified by their hash only are retrieved in full.
+func (b *Block) resolveHeader(ctx context.Context) (*types.Header, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.header != nil {
+		return b.header, nil
+	}
+	if b.hash != (common.Hash{}) {
+		if _, err := b.resolveBlock(ctx); err != nil {
+			return nil, err
+		}
+		return b.header, nil
+	}
+	header, err := b.backend.HeaderByNumber(ctx, *b.number)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, errBlockNotFound
+	}
+	b.header = header
+	return header, nil
+}
+
+// resolveReceipts returns the receipts of the transactions of the block, fetching
+// them if needed.
+func (b *Block) resolveReceipts(ctx context.Context) ([]*types.Receipt, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.receipts == nil {
+		receipts, err := b.backend.GetReceipts(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = []*types.Receipt(receipts)
+	}
+	return b.receipts, nil
+}
+
+func (b *Block) Number(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.Number.Uint64()), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	if b.hash != (common.Hash{}) {
+		return b.hash, nil
+	}
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Hash(), nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.GasLimit), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.GasUsed), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.Number.Sign() == 0 {
+		return nil, nil
+	}
+	number := rpc.BlockNumber(header.Number.Uint64() - 1)
+	return &Block{backend: b.backend, hash: header.ParentHash, number: &number}, nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Difficulty), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Time), nil
+}
+
+func (b *Block) Nonce(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(header.Nonce[:]), nil
+}
+
+func (b *Block) MixHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.MixDigest, nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.TxHash, nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Root, nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.ReceiptHash, nil
+}
+
+func (b *Block) OmmerHash(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.UncleHash, nil
+}
+
+func (b *Block) OmmerCount(ctx context.Context) (int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int32(len(block.Uncles())), nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(header.Extra), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Bytes(header.Bloom.Bytes()), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	td := b.backend.GetTd(hash)
+	if td == nil {
+		return hexutil.Big{}, fmt.Errorf("total difficulty of block %x not found", hash)
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{backend: b.backend, address: header.Coinbase, blockNumber: args.number()}, nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int32(len(block.Transactions())), nil
+}
+
+func (b *Block) Transactions(ctx context.Context) ([]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		txs = append(txs, &Transaction{backend: b.backend, hash: tx.Hash(), tx: tx, block: b, index: uint64(i)})
+	}
+	return txs, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	tx := txs[args.Index]
+	return &Transaction{backend: b.backend, hash: tx.Hash(), tx: tx, block: b, index: uint64(args.Index)}, nil
+}
+
+// BlockFilterCriteria are the log filter criteria of a single block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // Restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // Restricts matches to particular event topics
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	number, err := b.Number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runFilter(ctx, b.backend, int64(number), int64(number), args.Filter.Addresses, args.Filter.Topics)
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	number, err := b.Number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{backend: b.backend, address: args.Address, blockNumber: rpc.BlockNumber(number)}, nil
+}
+
+// runFilter runs a log filter over a range of blocks, returning the matching logs.
+func runFilter(ctx context.Context, backend Backend, begin, end int64, addresses *[]common.Address, topicsArg *[][]common.Hash) ([]*Log, error) {
+	var (
+		addrs  []common.Address
+		topics [][]common.Hash
+	)
+	if addresses != nil {
+		addrs = *addresses
+	}
+	if topicsArg != nil {
+		topics = *topicsArg
+	}
+	logs, err := filters.New(backend, begin, end, addrs, topics).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Share the transactions of the logs, along with their blocks
+	var (
+		ret = make([]*Log, 0, len(logs))
+		txs = make(map[common.Hash]*Transaction)
+	)
+	for _, log := range logs {
+		tx, ok := txs[log.TxHash]
+		if !ok {
+			number := rpc.BlockNumber(log.BlockNumber)
+			block := &Block{backend: backend, hash: log.BlockHash, number: &number}
+			tx = &Transaction{backend: backend, hash: log.TxHash, block: block, index: uint64(log.TxIndex)}
+			txs[log.TxHash] = tx
+		}
+		ret = append(ret, &Log{backend: backend, transaction: tx, log: log})
+	}
+	return ret, nil
+}
+
+// Pending represents the pending state of the node.
+type Pending struct {
+	backend Backend
+}
+
+func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	return int32(len(txs)), err
+}
+
+func (p *Pending) Transactions(ctx context.Context) ([]*Transaction, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		ret = append(ret, &Transaction{backend: p.backend, hash: tx.Hash(), tx: tx})
+	}
+	return ret, nil
+}
+
+func (p *Pending) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	return &Account{backend: p.backend, address: args.Address, blockNumber: rpc.PendingBlockNumber}, nil
+}
+
+// Resolver is the root resolver of the GraphQL schema.
+type Resolver struct {
+	backend Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *Long
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *Block
+	switch {
+	case args.Number != nil && args.Hash != nil:
+		return nil, errBlockNumberHash
+	case args.Hash != nil:
+		block = &Block{backend: r.backend, hash: *args.Hash}
+	case args.Number != nil:
+		number := rpc.BlockNumber(*args.Number)
+		block = &Block{backend: r.backend, number: &number}
+	default:
+		number := rpc.LatestBlockNumber
+		block = &Block{backend: r.backend, number: &number}
+	}
+	// Return null for unknown blocks instead of failing the query
+	if _, err := block.resolveHeader(ctx); err != nil {
+		if err == errBlockNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return block, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From Long
+	To   *Long
+}) ([]*Block, error) {
+	var to Long
+	if args.To != nil {
+		to = *args.To
+	} else {
+		header, err := r.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		to = Long(header.Number.Uint64())
+	}
+	if to < args.From {
+		return []*Block{}, nil
+	}
+	if to-args.From >= maxBlockRange {
+		return nil, errBlockRangeTooBig
+	}
+	if err := chargeRange(ctx); err != nil {
+		return nil, err
+	}
+	ret := make([]*Block, 0, to-args.From+1)
+	for i := args.From; i <= to; i++ {
+		number := rpc.BlockNumber(i)
+		block := &Block{backend: r.backend, number: &number}
+		if _, err := block.resolveHeader(ctx); err != nil {
+			if err == errBlockNotFound {
+				break
+			}
+			return nil, err
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Pending(ctx context.Context) (*Pending, error) {
+	return &Pending{backend: r.backend}, nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{backend: r.backend, hash: args.Hash}
+
+	// Return null for unknown transactions instead of failing the query
+	if resolved, err := tx.resolve(ctx); err != nil || resolved == nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// FilterCriteria are the log filter criteria of a range of blocks, equivalent to
+// filters.FilterCriteria.
+type FilterCriteria struct {
+	FromBlock *Long             // Beginning of the queried range, nil means latest block
+	ToBlock   *Long             // End of the range, nil means latest block
+	Addresses *[]common.Address // Restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // Restricts matches to particular event topics
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	// Resolve the open ends of the range to bound its size
+	var begin, end int64
+	if args.Filter.FromBlock == nil || args.Filter.ToBlock == nil {
+		header, err := r.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		begin, end = header.Number.Int64(), header.Number.Int64()
+	}
+	if args.Filter.FromBlock != nil {
+		begin = int64(*args.Filter.FromBlock)
+	}
+	if args.Filter.ToBlock != nil {
+		end = int64(*args.Filter.ToBlock)
+	}
+	if end-begin >= maxBlockRange {
+		return nil, errBlockRangeTooBig
+	}
+	if err := chargeRange(ctx); err != nil {
+		return nil, err
+	}
+	return runFilter(ctx, r.backend, begin, end, args.Filter.Addresses, args.Filter.Topics)
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ProtocolVersion(ctx context.Context) (int32, error) {
+	return int32(r.backend.ProtocolVersion()), nil
+}
@@ -0,0 +1,330 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/internal/okctest"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okc"
+	"github.com/okcoin/go-okcoin/params"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// newTestService starts a node over the test chain of okctest and returns a
+// GraphQL service serving its chain data.
+func newTestService(t *testing.T) (*node.Node, *okc.Okcoin, *Service) {
+	stack, okcServ := okctest.NewChainNode(t, nil)
+
+	service, err := New(okcServ.ApiBackend, "127.0.0.1:0", nil, nil, nil)
+	if err != nil {
+		stack.Stop()
+		t.Fatalf("failed to create GraphQL service: %v", err)
+	}
+	return stack, okcServ, service
+}
+
+// query executes a GraphQL query against the service, returning its decoded data.
+func query(t *testing.T, service *Service, query string) map[string]interface{} {
+	response := service.schema.Exec(context.Background(), query, "", nil)
+	if len(response.Errors) > 0 {
+		t.Fatalf("query %q failed: %v", query, response.Errors)
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(response.Data, &data); err != nil {
+		t.Fatalf("failed to decode response data: %v", err)
+	}
+	return data
+}
+
+// Tests that blocks and the transactions within them can be queried.
+func TestBlockQueries(t *testing.T) {
+	stack, okcServ, service := newTestService(t)
+	defer stack.Stop()
+
+	block := okcServ.BlockChain().GetBlockByNumber(1)
+	tx := block.Transactions()[0]
+
+	data := query(t, service, `{ block(number: 1) { number hash parent { number } transactionCount transactions { hash index from { address } to { address } value status gasUsed } } }`)
+	want := map[string]interface{}{
+		"block": map[string]interface{}{
+			"number":           float64(1),
+			"hash":             block.Hash().Hex(),
+			"parent":           map[string]interface{}{"number": float64(0)},
+			"transactionCount": float64(1),
+			"transactions": []interface{}{
+				map[string]interface{}{
+					"hash":    tx.Hash().Hex(),
+					"index":   float64(0),
+					"from":    map[string]interface{}{"address": strings.ToLower(okctest.Addr.Hex())},
+					"to":      map[string]interface{}{"address": strings.ToLower(okctest.Payee.Hex())},
+					"value":   fmt.Sprintf("%#x", okctest.Payment),
+					"status":  float64(1),
+					"gasUsed": float64(params.TxGas),
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("block query mismatch:\nhave %v\nwant %v", data, want)
+	}
+	// Blocks may also be looked up by hash, unknown ones resolving to null
+	data = query(t, service, fmt.Sprintf(`{ known: block(hash: "%s") { number } unknown: block(number: 100) { number } }`, block.Hash().Hex()))
+	if have := data["known"].(map[string]interface{})["number"]; have != float64(1) {
+		t.Errorf("block by hash number mismatch: have %v, want 1", have)
+	}
+	if data["unknown"] != nil {
+		t.Errorf("unknown block resolved: %v", data["unknown"])
+	}
+	// Ranges of blocks default to ending with the latest block
+	data = query(t, service, `{ blocks(from: 1) { number } }`)
+	if have := len(data["blocks"].([]interface{})); have != 3 {
+		t.Errorf("block range length mismatch: have %d, want 3", have)
+	}
+	// Transactions may be looked up directly
+	data = query(t, service, fmt.Sprintf(`{ transaction(hash: "%s") { nonce block { number } } }`, tx.Hash().Hex()))
+	want = map[string]interface{}{
+		"transaction": map[string]interface{}{
+			"nonce": float64(0),
+			"block": map[string]interface{}{"number": float64(1)},
+		},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("transaction query mismatch:\nhave %v\nwant %v", data, want)
+	}
+}
+
+// Tests that account state can be queried at different blocks.
+func TestAccountQueries(t *testing.T) {
+	stack, _, service := newTestService(t)
+	defer stack.Stop()
+
+	data := query(t, service, fmt.Sprintf(`{ genesis: block(number: 0) { account(address: "%s") { balance transactionCount } } latest: block { account(address: "%s") { balance } } }`, okctest.Payee.Hex(), okctest.Payee.Hex()))
+	want := map[string]interface{}{
+		"genesis": map[string]interface{}{"account": map[string]interface{}{"balance": "0x0", "transactionCount": float64(0)}},
+		"latest":  map[string]interface{}{"account": map[string]interface{}{"balance": fmt.Sprintf("%#x", 2*okctest.Payment.Int64())}},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("account query mismatch:\nhave %v\nwant %v", data, want)
+	}
+}
+
+// Tests that logs can be filtered, both from a single block and from a range.
+func TestLogQueries(t *testing.T) {
+	stack, okcServ, service := newTestService(t)
+	defer stack.Stop()
+
+	contract := crypto.CreateAddress(okctest.Addr, 1)
+	create := okcServ.BlockChain().GetBlockByNumber(2).Transactions()[0]
+
+	data := query(t, service, `{ logs(filter: {fromBlock: 0}) { account { address } transaction { hash createdContract { address } } } }`)
+	want := map[string]interface{}{
+		"logs": []interface{}{
+			map[string]interface{}{
+				"account": map[string]interface{}{"address": strings.ToLower(contract.Hex())},
+				"transaction": map[string]interface{}{
+					"hash":            create.Hash().Hex(),
+					"createdContract": map[string]interface{}{"address": strings.ToLower(contract.Hex())},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("logs query mismatch:\nhave %v\nwant %v", data, want)
+	}
+	data = query(t, service, fmt.Sprintf(`{ match: block(number: 2) { logs(filter: {addresses: ["%s"]}) { index } } miss: block(number: 2) { logs(filter: {addresses: ["%s"]}) { index } } }`, contract.Hex(), okctest.Payee.Hex()))
+	want = map[string]interface{}{
+		"match": map[string]interface{}{"logs": []interface{}{map[string]interface{}{"index": float64(0)}}},
+		"miss":  map[string]interface{}{"logs": []interface{}{}},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("block logs query mismatch:\nhave %v\nwant %v", data, want)
+	}
+}
+
+// Tests that queries are served over HTTP and that invalid ones are reported.
+func TestHTTPHandler(t *testing.T) {
+	stack, _, service := newTestService(t)
+	defer stack.Stop()
+
+	server := httptest.NewServer(&handler{service.schema, service.guard})
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("failed to send GET request: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET request status mismatch: have %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+	for _, tt := range []struct {
+		query string
+		data  string
+		fail  bool
+	}{
+		{query: `{ block(number: 3) { number } }`, data: `{"block":{"number":3}}`},
+		{query: `{ block(number: 1, hash: "0x0000000000000000000000000000000000000000000000000000000000000000") { number } }`, data: `{"block":null}`, fail: true},
+		{query: `{ block { unknownField } }`, data: ``, fail: true},
+	} {
+		body, _ := json.Marshal(request{Query: tt.query})
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("failed to send query %q: %v", tt.query, err)
+		}
+		var result struct {
+			Data   json.RawMessage
+			Errors []interface{}
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to decode response to query %q: %v", tt.query, err)
+		}
+		if string(result.Data) != tt.data {
+			t.Errorf("query %q data mismatch: have %s, want %s", tt.query, result.Data, tt.data)
+		}
+		if (len(result.Errors) > 0) != tt.fail {
+			t.Errorf("query %q errors mismatch: have %v, want failure %v", tt.query, result.Errors, tt.fail)
+		}
+	}
+}
+
+// Tests that log queries spanning too many blocks are rejected, open ranges
+// being resolved against the latest block.
+func TestLogsRangeLimit(t *testing.T) {
+	stack, _, service := newTestService(t)
+	defer stack.Stop()
+
+	for _, tt := range []struct {
+		query string
+		fail  bool
+	}{
+		{query: `{ logs(filter: {}) { index } }`},
+		{query: `{ logs(filter: {fromBlock: 0}) { index } }`},
+		{query: fmt.Sprintf(`{ logs(filter: {fromBlock: 0, toBlock: %d}) { index } }`, maxBlockRange-1)},
+		{query: fmt.Sprintf(`{ logs(filter: {fromBlock: 0, toBlock: %d}) { index } }`, maxBlockRange), fail: true},
+	} {
+		response := service.schema.Exec(context.Background(), tt.query, "", nil)
+		if failed := len(response.Errors) > 0; failed != tt.fail {
+			t.Errorf("query %q errors mismatch: have %v, want failure %v", tt.query, response.Errors, tt.fail)
+		}
+	}
+}
+
+// Tests that the HTTP handler enforces the access control, rate limits and size
+// limits of its guard.
+func TestHTTPHandlerGuard(t *testing.T) {
+	stack, _, service := newTestService(t)
+	defer stack.Stop()
+
+	limiter, err := rpc.NewRateLimiter(rpc.RateLimitConfig{Rate: 0.001, Burst: 5})
+	if err != nil {
+		t.Fatalf("failed to create rate limiter: %v", err)
+	}
+	guard := &rpc.Guard{
+		Auth: rpc.NewAuthenticator(nil, &rpc.Policy{
+			APIKeys: map[string][]string{"reader": {"graphql"}, "other": {"okc"}},
+		}),
+		Limiter: limiter,
+		Limits:  rpc.Limits{MaxResponseSize: 100},
+	}
+	server := httptest.NewServer(&handler{service.schema, guard})
+	defer server.Close()
+
+	post := func(credentials, query string) (int, string) {
+		body, _ := json.Marshal(request{Query: query})
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(string(body)))
+		if credentials != "" {
+			req.Header.Set("Authorization", "Bearer "+credentials)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to send query %q: %v", query, err)
+		}
+		defer resp.Body.Close()
+
+		var result struct{ Errors []struct{ Message string } }
+		json.NewDecoder(resp.Body).Decode(&result)
+		if len(result.Errors) > 0 {
+			return resp.StatusCode, result.Errors[0].Message
+		}
+		return resp.StatusCode, ""
+	}
+	if code, _ := post("", `{ block { number } }`); code != http.StatusForbidden {
+		t.Errorf("anonymous query status mismatch: have %d, want %d", code, http.StatusForbidden)
+	}
+	if code, _ := post("other", `{ block { number } }`); code != http.StatusForbidden {
+		t.Errorf("unauthorized query status mismatch: have %d, want %d", code, http.StatusForbidden)
+	}
+	if code, msg := post("reader", `{ block { number } }`); code != http.StatusOK || msg != "" {
+		t.Errorf("authorized query failed: status %d, error %q", code, msg)
+	}
+	if code, msg := post("reader", `{ blocks(from: 0) { hash } }`); code != http.StatusOK || !strings.Contains(msg, "response too large") {
+		t.Errorf("oversize response not replaced: status %d, error %q", code, msg)
+	}
+	if code, msg := post("reader", `{ a: blocks(from: 0, to: 0) { number } b: blocks(from: 0, to: 0) { number } }`); code != http.StatusOK || msg != rpc.ErrRateLimited.Error() {
+		t.Errorf("ranges over budget not rejected: status %d, error %q", code, msg)
+	}
+	if code, _ := post("reader", `{ block { number } }`); code != http.StatusTooManyRequests {
+		t.Errorf("query over budget status mismatch: have %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
+
+// Tests that queries nesting fields too deeply or resolving too many of them,
+// aliases included, are rejected.
+func TestQueryComplexityLimits(t *testing.T) {
+	stack, okcServ, service := newTestService(t)
+	defer stack.Stop()
+
+	deep := `{ block { parent { parent { parent { parent { parent { parent { parent { parent { parent { parent { parent { number } } } } } } } } } } } } }`
+	if response := service.schema.Exec(context.Background(), deep, "", nil); len(response.Errors) == 0 {
+		t.Errorf("query deeper than %d fields not rejected", maxQueryDepth)
+	}
+	// Check the field budget on a small one, counting the list elements and aliases
+	limited, err := graphql.ParseSchema(schema, &Resolver{okcServ.ApiBackend}, graphql.Tracer(fieldLimiter{max: 5}))
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	for _, tt := range []struct {
+		query string
+		fail  bool
+	}{
+		{query: `{ a: block(number: 0) { number } b: block(number: 1) { number } }`},
+		{query: `{ blocks(from: 0, to: 2) { number } }`},
+		{query: `{ a: block(number: 0) { number hash } b: block(number: 1) { number hash } }`, fail: true},
+		{query: `{ blocks(from: 0, to: 2) { number hash } }`, fail: true},
+	} {
+		response := limited.Exec(context.Background(), tt.query, "", nil)
+		if failed := len(response.Errors) > 0; failed != tt.fail {
+			t.Errorf("query %q errors mismatch: have %v, want failure %v", tt.query, response.Errors, tt.fail)
+		}
+		if tt.fail && len(response.Errors) > 0 && response.Errors[0].Message != errQueryTooComplex.Error() {
+			t.Errorf("query %q error mismatch: have %q, want %q", tt.query, response.Errors[0].Message, errQueryTooComplex)
+		}
+	}
+}
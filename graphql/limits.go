@@ -0,0 +1,102 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/graph-gophers/graphql-go/introspection"
+	"github.com/graph-gophers/graphql-go/trace"
+)
+
+const (
+	// maxQueryDepth is the maximum nesting depth of the fields of a query.
+	maxQueryDepth = 12
+
+	// maxQueryFields is the maximum number of fields a query may resolve, each
+	// element of a list and each alias counting separately.
+	maxQueryFields = 50000
+
+	// rangeMethod is the name the block ranges resolved by queries are priced
+	// by in the rate limits, each of them being charged on top of the query.
+	rangeMethod = "graphql_range"
+)
+
+var errQueryTooComplex = fmt.Errorf("query too complex, at most %d fields allowed", maxQueryFields)
+
+// fieldCountKey is the context key of the number of fields resolved by a query.
+type fieldCountKey struct{}
+
+// fieldLimiter is a tracer bounding the number of fields resolved by a query.
+// The fields beyond the budget are resolved with an expired context, which makes
+// the executor fail them instead of calling their resolvers.
+type fieldLimiter struct {
+	trace.NoopTracer
+	max int64
+}
+
+// TraceQuery implements trace.Tracer, setting up the field count of a query.
+func (l fieldLimiter) TraceQuery(ctx context.Context, queryString string, operationName string, variables map[string]interface{}, varTypes map[string]*introspection.Type) (context.Context, trace.TraceQueryFinishFunc) {
+	return context.WithValue(ctx, fieldCountKey{}, new(int64)), func([]*errors.QueryError) {}
+}
+
+// TraceField implements trace.Tracer, counting a field against the budget of
+// its query.
+func (l fieldLimiter) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	if count, ok := ctx.Value(fieldCountKey{}).(*int64); ok && atomic.AddInt64(count, 1) > l.max {
+		return exhaustedContext{ctx}, func(*errors.QueryError) {}
+	}
+	return ctx, func(*errors.QueryError) {}
+}
+
+// exhaustedContext is the context of the fields exceeding the budget of their
+// query.
+type exhaustedContext struct {
+	context.Context
+}
+
+// closedChan is the always ready done channel of exhausted contexts.
+var closedChan = make(chan struct{})
+
+func init() {
+	close(closedChan)
+}
+
+func (exhaustedContext) Done() <-chan struct{} { return closedChan }
+func (exhaustedContext) Err() error            { return errQueryTooComplex }
+
+// rangeChargerKey is the context key of the function charging the block ranges
+// resolved by a query to the budget of its client.
+type rangeChargerKey struct{}
+
+// withRangeCharger returns a context charging the block ranges resolved under it
+// with the given function.
+func withRangeCharger(ctx context.Context, charge func() error) context.Context {
+	return context.WithValue(ctx, rangeChargerKey{}, charge)
+}
+
+// chargeRange charges a block range about to be resolved to the budget of the
+// client of the query, if any.
+func chargeRange(ctx context.Context) error {
+	if charge, ok := ctx.Value(rangeChargerKey{}).(func() error); ok {
+		return charge()
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/errors"
+	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/p2p"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// Service encapsulates a GraphQL service, serving chain data queries over HTTP.
+type Service struct {
+	endpoint string          // The host:port endpoint for this service
+	cors     []string        // Allowed CORS domains
+	vhosts   []string        // Recognised vhosts
+	schema   *graphql.Schema // The parsed schema, bound to the backend
+	guard    *rpc.Guard      // Protection shared with the JSON-RPC endpoints
+	listener net.Listener    // Listening socket, nil if the service is not running
+}
+
+// New constructs a new GraphQL service instance serving the chain data of backend.
+// Queries are subject to the access control, rate limits, size limits and call
+// deadline of the guard as calls of the "graphql_query" method, and each block
+// range they resolve is charged as a call of the "graphql_range" method; a nil
+// guard leaves the endpoint unprotected. Queries are bounded in depth and in the
+// number of fields they resolve regardless of the guard.
+func New(backend Backend, endpoint string, cors, vhosts []string, guard *rpc.Guard) (*Service, error) {
+	parsed, err := graphql.ParseSchema(schema, &Resolver{backend}, graphql.MaxDepth(maxQueryDepth), graphql.Tracer(fieldLimiter{max: maxQueryFields}))
+	if err != nil {
+		return nil, err
+	}
+	if guard == nil {
+		guard = new(rpc.Guard)
+	}
+	return &Service{
+		endpoint: endpoint,
+		cors:     cors,
+		vhosts:   vhosts,
+		schema:   parsed,
+		guard:    guard,
+	}, nil
+}
+
+// Protocols implements node.Service, returning the P2P network protocols used
+// by the graphql service (nil as it doesn't use the devp2p overlay network).
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs implements node.Service, returning the RPC API endpoints provided by the
+// graphql service (nil as it doesn't provide any user callable APIs).
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error {
+	listener, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	stack := rpc.NewHTTPHandlerStack(&handler{s.schema, s.guard}, s.cors, s.vhosts)
+	go (&http.Server{Handler: stack}).Serve(listener)
+	log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s", s.endpoint), "cors", strings.Join(s.cors, ","), "vhosts", strings.Join(s.vhosts, ","))
+
+	s.listener = listener
+	return nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+		log.Info("GraphQL endpoint closed", "url", fmt.Sprintf("http://%s", s.endpoint))
+	}
+	return nil
+}
+
+// request is the body of a GraphQL request sent over HTTP.
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// queryMethod is the name GraphQL queries are granted and priced by in the
+// access control policy and the rate limits.
+const queryMethod = "graphql_query"
+
+// handler executes GraphQL requests posted over HTTP against a schema.
+type handler struct {
+	schema *graphql.Schema
+	guard  *rpc.Guard
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	credentials := r.Header.Get("Authorization")
+	switch wait, err := h.guard.Check(credentials, r.RemoteAddr, queryMethod); err {
+	case nil:
+	case rpc.ErrRateLimited:
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	body := r.Body
+	if limit := h.guard.Limits.MaxRequestSize; limit > 0 {
+		body = http.MaxBytesReader(w, r.Body, int64(limit))
+	}
+	var req request
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := h.guard.WithTimeout(r.Context())
+	defer cancel()
+
+	ctx = withRangeCharger(ctx, func() error {
+		_, err := h.guard.Charge(credentials, r.RemoteAddr, rangeMethod)
+		return err
+	})
+	response := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	blob, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if limit := h.guard.Limits.MaxResponseSize; limit > 0 && len(blob) > limit {
+		blob, _ = json.Marshal(&graphql.Response{Errors: []*errors.QueryError{errors.Errorf("response too large (more than %d bytes)", limit)}})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blob)
+}
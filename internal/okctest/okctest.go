@@ -0,0 +1,106 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package okctest provides a node running a full Okcoin service over a short test
+// chain, shared by the tests of the API servers and clients.
+package okctest
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okc"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+var (
+	Key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291") // Key of Addr
+	Addr    = crypto.PubkeyToAddress(Key.PublicKey)                                                 // Account funded in the genesis block
+	Funds   = big.NewInt(1000000000000000000)                                                       // Genesis balance of Addr
+	Payee   = common.HexToAddress("0x0000000000000000000000000000000000000bee")                     // Recipient of the test chain transfers
+	Payment = big.NewInt(1000)                                                                      // Value of the test chain transfers
+)
+
+// NewNode starts a node with networking disabled, running a full Okcoin service
+// funding Addr in its genesis block and the services of the given constructors,
+// registered after it. A nil config stands for the default one.
+func NewNode(t *testing.T, config *node.Config, services ...node.ServiceConstructor) (*node.Node, *okc.Okcoin) {
+	if config == nil {
+		config = new(node.Config)
+	}
+	nodeConfig := *config
+	nodeConfig.P2P.NoDiscovery = true
+	nodeConfig.P2P.MaxPeers = 0
+
+	stack, err := node.New(&nodeConfig)
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	okcConfig := okc.DefaultConfig
+	okcConfig.Okcash.PowMode = okcash.ModeFake
+	okcConfig.Genesis = &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{Addr: {Balance: Funds}},
+	}
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) { return okc.New(ctx, &okcConfig) }); err != nil {
+		t.Fatalf("failed to register Okcoin service: %v", err)
+	}
+	for _, constructor := range services {
+		if err := stack.Register(constructor); err != nil {
+			t.Fatalf("failed to register service: %v", err)
+		}
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	var okcServ *okc.Okcoin
+	if err := stack.Service(&okcServ); err != nil {
+		stack.Stop()
+		t.Fatalf("failed to retrieve Okcoin service: %v", err)
+	}
+	return stack, okcServ
+}
+
+// NewChainNode starts a node like NewNode and imports the test chain into it. The
+// chain holds transfers of Payment from Addr to Payee in blocks 1 and 3 and the
+// creation of a contract emitting an empty log in block 2.
+func NewChainNode(t *testing.T, config *node.Config, services ...node.ServiceConstructor) (*node.Node, *okc.Okcoin) {
+	stack, okcServ := NewNode(t, config, services...)
+
+	chain := okcServ.BlockChain()
+	blocks, _ := core.GenerateChain(params.TestChainConfig, chain.Genesis(), okcash.NewFaker(), okcServ.ChainDb(), 3, func(i int, gen *core.BlockGen) {
+		var tx *types.Transaction
+		if i != 1 {
+			tx = types.NewTransaction(gen.TxNonce(Addr), Payee, Payment, params.TxGas, big.NewInt(1), nil)
+		} else {
+			// PUSH1 0, PUSH1 0, LOG0: an empty contract emitting an empty log
+			tx = types.NewContractCreation(gen.TxNonce(Addr), new(big.Int), 100000, big.NewInt(1), common.FromHex("0x60006000a0"))
+		}
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, Key)
+		gen.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		stack.Stop()
+		t.Fatalf("failed to import test chain: %v", err)
+	}
+	return stack, okcServ
+}
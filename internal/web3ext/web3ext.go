@@ -19,12 +19,15 @@ package web3ext
 
 var Modules = map[string]string{
 	"admin":      Admin_JS,
+	"bft":        BFT_JS,
 	"chequebook": Chequebook_JS,
 	"clique":     Clique_JS,
 	"debug":      Debug_JS,
+	"les":        LES_JS,
 	"okc":        Okc_JS,
 	"miner":      Miner_JS,
 	"net":        Net_JS,
+	"okcash":     Okcash_JS,
 	"personal":   Personal_JS,
 	"rpc":        RPC_JS,
 	"shh":        Shh_JS,
@@ -99,6 +102,18 @@ web3._extend({
 			call: 'clique_discard',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'status',
+			call: 'clique_status',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getVotes',
+			call: 'clique_getVotes',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -109,6 +124,65 @@ web3._extend({
 });
 `
 
+const BFT_JS = `
+web3._extend({
+	property: 'bft',
+	methods: [
+		new web3._extend.Method({
+			name: 'getSnapshot',
+			call: 'bft_getSnapshot',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getSnapshotAtHash',
+			call: 'bft_getSnapshotAtHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getValidators',
+			call: 'bft_getValidators',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getValidatorsAtHash',
+			call: 'bft_getValidatorsAtHash',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'propose',
+			call: 'bft_propose',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'discard',
+			call: 'bft_discard',
+			params: 1
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'proposals',
+			getter: 'bft_proposals'
+		}),
+	]
+});
+`
+
+const Okcash_JS = `
+web3._extend({
+	property: 'okcash',
+	methods: [],
+	properties: [
+		new web3._extend.Property({
+			name: 'generationProgress',
+			getter: 'okcash_generationProgress'
+		}),
+	]
+});
+`
+
 const Admin_JS = `
 web3._extend({
 	property: 'admin',
@@ -481,10 +555,40 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'setGasTarget',
+			call: 'miner_setGasTarget',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setGasLimit',
+			call: 'miner_setGasLimit',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setRecommitInterval',
+			call: 'miner_setRecommitInterval',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'setTxOrdering',
+			call: 'miner_setTxOrdering',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getTxOrdering',
+			call: 'miner_getTxOrdering'
+		}),
+		new web3._extend.Method({
+			name: 'stratumWorkers',
+			call: 'miner_stratumWorkers'
+		}),
 	],
 	properties: []
 });
@@ -632,3 +736,23 @@ web3._extend({
 	]
 });
 `
+
+const LES_JS = `
+web3._extend({
+	property: 'les',
+	methods: [
+		new web3._extend.Method({
+			name: 'getCheckpoint',
+			call: 'les_getCheckpoint',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'latestCheckpoint',
+			getter: 'les_latestCheckpoint'
+		}),
+	]
+});
+`
@@ -18,6 +18,7 @@ package les
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/okcoin/go-okcoin/accounts"
@@ -55,10 +56,17 @@ func (b *LesApiBackend) SetHead(number uint64) {
 	b.okc.blockchain.SetHead(number)
 }
 
+// errNoFinality is returned if the finalized block is requested from a light
+// client, which doesn't track chain finality.
+var errNoFinality = errors.New("finalized block not tracked by light clients")
+
 func (b *LesApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
 	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
 		return b.okc.blockchain.CurrentHeader(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		return nil, errNoFinality
+	}
 
 	return b.okc.blockchain.GetHeaderByNumberOdr(ctx, uint64(blockNr))
 }
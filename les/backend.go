@@ -129,6 +129,11 @@ func New(ctx *node.ServiceContext, config *okc.Config) (*LightOkcoin, error) {
 	if lokc.protocolManager, err = NewProtocolManager(lokc.chainConfig, true, ClientProtocolVersions, config.NetworkId, lokc.eventMux, lokc.engine, lokc.peers, lokc.blockchain, nil, chainDb, lokc.odr, lokc.relay, quitSync, &lokc.wg); err != nil {
 		return nil, err
 	}
+	if config.CheckpointOracle != nil {
+		if lokc.protocolManager.oracle, err = newCheckpointOracle(config.CheckpointOracle, lokc.blockchain, lokc.odr); err != nil {
+			return nil, err
+		}
+	}
 	lokc.ApiBackend = &LesApiBackend{lokc, nil}
 	gpoParams := config.GPO
 	if gpoParams.Default == nil {
@@ -211,6 +216,7 @@ func (s *LightOkcoin) Engine() consensus.Engine           { return s.engine }
 func (s *LightOkcoin) LesVersion() int                    { return int(s.protocolManager.SubProtocols[0].Version) }
 func (s *LightOkcoin) Downloader() *downloader.Downloader { return s.protocolManager.downloader }
 func (s *LightOkcoin) EventMux() *event.TypeMux           { return s.eventMux }
+func (s *LightOkcoin) NetVersion() uint64                 { return s.networkId }
 
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
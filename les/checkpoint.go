@@ -0,0 +1,196 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/accounts/abi/bind"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/contracts/checkpointoracle"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/light"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+const (
+	// oracleSyncTimeout is the time allowance for reading the checkpoint oracle
+	// from the servers before a sync.
+	oracleSyncTimeout = 10 * time.Second
+
+	// oracleCallGas is the gas allowance of the read only oracle calls, plenty
+	// for the oracle getters while bounding the work a server's state can cause.
+	oracleCallGas = 5000000
+)
+
+// errOracleCallFailed is returned if a call of the checkpoint oracle reverts.
+var errOracleCallFailed = errors.New("checkpoint oracle call failed")
+
+// checkpointOracle retrieves the latest checkpoint registered in the checkpoint
+// oracle contract through the light servers. The servers are trusted no more
+// than usual: a checkpoint is only used if it is approved by enough of the
+// admins trusted locally, whatever state it was read from.
+type checkpointOracle struct {
+	config *params.CheckpointOracleConfig
+	chain  *light.LightChain
+	odr    *LesOdr
+}
+
+// newCheckpointOracle creates a checkpoint oracle reader for a light client.
+func newCheckpointOracle(config *params.CheckpointOracleConfig, chain *light.LightChain, odr *LesOdr) (*checkpointOracle, error) {
+	if config.Threshold == 0 || config.Threshold > uint64(len(config.Signers)) {
+		return nil, fmt.Errorf("invalid checkpoint oracle threshold %d of %d signers", config.Threshold, len(config.Signers))
+	}
+	return &checkpointOracle{config: config, chain: chain, odr: odr}, nil
+}
+
+// syncCheckpoint reads the latest checkpoint registered in the oracle at the head
+// announced by the given server, adding it to the chain if it is newer than the
+// sections known locally.
+func (o *checkpointOracle) syncCheckpoint(ctx context.Context, p *peer) error {
+	head := p.headBlockInfo()
+
+	req := &light.HeaderRequest{Hash: head.Hash, Number: head.Number}
+	if err := o.odr.Retrieve(ctx, req); err != nil {
+		return err
+	}
+	caller := &oracleCaller{header: req.Header, chain: o.chain, odr: o.odr}
+	oracle, err := checkpointoracle.NewCheckpointOracle(o.config.Address, caller, nil)
+	if err != nil {
+		return err
+	}
+	checkpoint, _, sigs, err := oracle.LatestCheckpoint(&bind.CallOpts{Context: ctx})
+	if err != nil || checkpoint == nil {
+		return err
+	}
+	if sections, _, _ := o.odr.ChtIndexer().Sections(); checkpoint.SectionIndex < sections {
+		return nil
+	}
+	if err := checkpoint.Verify(o.config.Address, sigs, o.config.Signers, o.config.Threshold); err != nil {
+		return err
+	}
+	o.chain.AddTrustedCheckpoint(light.TrustedCheckpoint{
+		Name:          "oracle",
+		SectionIdx:    checkpoint.SectionIndex,
+		SectionHead:   checkpoint.SectionHead,
+		ChtRoot:       checkpoint.CHTRoot,
+		BloomTrieRoot: checkpoint.BloomTrieRoot,
+	})
+	return nil
+}
+
+// oracleCaller executes the read only calls of the oracle bindings against the
+// state of a given header, retrieved on demand from the light servers.
+type oracleCaller struct {
+	header *types.Header
+	chain  *light.LightChain
+	odr    *LesOdr
+}
+
+// CodeAt returns the code of the given account in the state of the header.
+func (c *oracleCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	statedb := light.NewState(ctx, c.header, c.odr)
+	code := statedb.GetCode(contract)
+	return code, statedb.Error()
+}
+
+// CallContract executes a message call in the state of the header.
+func (c *oracleCaller) CallContract(ctx context.Context, call okcoin.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	statedb := light.NewState(ctx, c.header, c.odr)
+
+	msg := types.NewMessage(call.From, call.To, 0, new(big.Int), oracleCallGas, new(big.Int), call.Data, false)
+	evm := vm.NewEVM(core.NewEVMContext(msg, c.header, c.chain, nil), statedb, c.chain.Config(), vm.Config{})
+
+	// Abort the execution if the call is cancelled or times out
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+	ret, _, failed, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(oracleCallGas))
+	if err := statedb.Error(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if failed {
+		return nil, errOracleCallFailed
+	}
+	return ret, nil
+}
+
+// PrivateLightServerAPI provides an API to access the light client checkpoints
+// produced by a light server, to be signed by the checkpoint oracle admins.
+type PrivateLightServerAPI struct {
+	server *LesServer
+}
+
+// NewPrivateLightServerAPI creates a new light server API.
+func NewPrivateLightServerAPI(server *LesServer) *PrivateLightServerAPI {
+	return &PrivateLightServerAPI{server: server}
+}
+
+// GetCheckpoint returns the checkpoint of the given light client section.
+func (api *PrivateLightServerAPI) GetCheckpoint(index hexutil.Uint64) (*checkpointoracle.Checkpoint, error) {
+	db := api.server.protocolManager.chainDb
+
+	section := uint64(index)
+	head := core.GetCanonicalHash(db, (section+1)*light.CHTFrequencyClient-1)
+	if head == (common.Hash{}) {
+		return nil, fmt.Errorf("section #%d not available", section)
+	}
+	checkpoint := &checkpointoracle.Checkpoint{
+		SectionIndex:  section,
+		SectionHead:   head,
+		CHTRoot:       light.GetChtV2Root(db, section, head),
+		BloomTrieRoot: light.GetBloomTrieRoot(db, section, head),
+	}
+	if checkpoint.CHTRoot == (common.Hash{}) || checkpoint.BloomTrieRoot == (common.Hash{}) {
+		return nil, fmt.Errorf("section #%d not processed yet", section)
+	}
+	return checkpoint, nil
+}
+
+// LatestCheckpoint returns the checkpoint of the latest light client section
+// processed by the server.
+func (api *PrivateLightServerAPI) LatestCheckpoint() (*checkpointoracle.Checkpoint, error) {
+	chtSections, _, _ := api.server.chtIndexer.Sections()
+	sections := chtSections / (light.CHTFrequencyClient / light.CHTFrequencyServer)
+	if bloomTrieSections, _, _ := api.server.bloomTrieIndexer.Sections(); bloomTrieSections < sections {
+		sections = bloomTrieSections
+	}
+	if sections == 0 {
+		return nil, errors.New("no section processed yet")
+	}
+	return api.GetCheckpoint(hexutil.Uint64(sections - 1))
+}
@@ -38,6 +38,7 @@ var (
 	errInvalidMessageType  = errors.New("invalid message type")
 	errInvalidEntryCount   = errors.New("invalid number of response entries")
 	errHeaderUnavailable   = errors.New("header unavailable")
+	errHeaderHashMismatch  = errors.New("header hash mismatch")
 	errTxHashMismatch      = errors.New("transaction hash mismatch")
 	errUncleHashMismatch   = errors.New("uncle hash mismatch")
 	errReceiptHashMismatch = errors.New("receipt hash mismatch")
@@ -58,6 +59,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 	switch r := req.(type) {
 	case *light.BlockRequest:
 		return (*BlockRequest)(r)
+	case *light.HeaderRequest:
+		return (*HeaderRequest)(r)
 	case *light.ReceiptsRequest:
 		return (*ReceiptsRequest)(r)
 	case *light.TrieRequest:
@@ -129,6 +132,48 @@ func (r *BlockRequest) Validate(db okcdb.Database, msg *Msg) error {
 	return nil
 }
 
+// HeaderRequest is the ODR request type for block headers by hash
+type HeaderRequest light.HeaderRequest
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *HeaderRequest) GetCost(peer *peer) uint64 {
+	return peer.GetRequestCost(GetBlockHeadersMsg, 1)
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *HeaderRequest) CanSend(peer *peer) bool {
+	return peer.HasBlock(r.Hash, r.Number)
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *HeaderRequest) Request(reqID uint64, peer *peer) error {
+	peer.Log().Debug("Requesting block header", "hash", r.Hash)
+	return peer.RequestHeadersByHash(reqID, r.GetCost(peer), r.Hash, 1, 0, false)
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *HeaderRequest) Validate(db okcdb.Database, msg *Msg) error {
+	log.Debug("Validating block header", "hash", r.Hash)
+
+	// Ensure we have a correct message with the single requested header
+	if msg.MsgType != MsgBlockHeaders {
+		return errInvalidMessageType
+	}
+	headers := msg.Obj.([]*types.Header)
+	if len(headers) != 1 {
+		return errInvalidEntryCount
+	}
+	header := headers[0]
+	if header.Hash() != r.Hash || header.Number == nil || header.Number.Uint64() != r.Number {
+		return errHeaderHashMismatch
+	}
+	r.Header = header
+	return nil
+}
+
 // ReceiptsRequest is the ODR request type for block receipts by block hash
 type ReceiptsRequest light.ReceiptsRequest
 
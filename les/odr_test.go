@@ -56,6 +56,42 @@ func odrGetBlock(ctx context.Context, db okcdb.Database, config *params.ChainCon
 	return rlp
 }
 
+// Tests that headers announced by the servers are retrieved by hash.
+func TestOdrHeaderRequestLes2(t *testing.T) {
+	peers := newPeerSet()
+	dist := newRequestDistributor(peers, make(chan struct{}))
+	rm := newRetrieveManager(peers, dist, nil)
+	db, _ := okcdb.NewMemDatabase()
+	ldb, _ := okcdb.NewMemDatabase()
+	odr := NewLesOdr(ldb, light.NewChtIndexer(db, true), light.NewBloomTrieIndexer(db, true), okc.NewBloomIndexer(db, light.BloomTrieFrequency), rm)
+	pm := newTestProtocolManagerMust(t, false, 4, testChainGen, nil, nil, db)
+	lpm := newTestProtocolManagerMust(t, true, 0, nil, peers, odr, ldb)
+	_, err1, _, err2 := newTestPeerPair("peer", 2, pm, lpm)
+	select {
+	case <-time.After(time.Millisecond * 100):
+	case err := <-err1:
+		t.Fatalf("peer 1 handshake error: %v", err)
+	case err := <-err2:
+		t.Fatalf("peer 1 handshake error: %v", err)
+	}
+	head := pm.blockchain.CurrentHeader()
+
+	// Headers not announced by the servers can't be retrieved, announced ones can
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := odr.Retrieve(ctx, &light.HeaderRequest{Hash: common.Hash{1}, Number: head.Number.Uint64()}); err == nil {
+		t.Errorf("unknown header retrieved")
+	}
+	req := &light.HeaderRequest{Hash: head.Hash(), Number: head.Number.Uint64()}
+	if err := odr.Retrieve(ctx, req); err != nil {
+		t.Fatalf("failed to retrieve header: %v", err)
+	}
+	if req.Header.Hash() != head.Hash() {
+		t.Errorf("header mismatch: have %x, want %x", req.Header.Hash(), head.Hash())
+	}
+}
+
 func TestOdrGetReceiptsLes1(t *testing.T) { testOdr(t, 1, 1, odrGetReceipts) }
 
 func TestOdrGetReceiptsLes2(t *testing.T) { testOdr(t, 2, 1, odrGetReceipts) }
@@ -34,6 +34,7 @@ import (
 	"github.com/okcoin/go-okcoin/p2p"
 	"github.com/okcoin/go-okcoin/p2p/discv5"
 	"github.com/okcoin/go-okcoin/rlp"
+	"github.com/okcoin/go-okcoin/rpc"
 )
 
 type LesServer struct {
@@ -106,6 +107,18 @@ func (s *LesServer) Protocols() []p2p.Protocol {
 	return s.protocolManager.SubProtocols
 }
 
+// APIs returns the collection of RPC services the LES server offers.
+func (s *LesServer) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "les",
+			Version:   "1.0",
+			Service:   NewPrivateLightServerAPI(s),
+			Public:    false,
+		},
+	}
+}
+
 // Start starts the LES server
 func (s *LesServer) Start(srvr *p2p.Server) {
 	s.protocolManager.Start(s.config.LightPeers)
@@ -23,6 +23,7 @@ import (
 	"github.com/okcoin/go-okcoin/core"
 	"github.com/okcoin/go-okcoin/okc/downloader"
 	"github.com/okcoin/go-okcoin/light"
+	"github.com/okcoin/go-okcoin/log"
 )
 
 const (
@@ -77,6 +78,14 @@ func (pm *ProtocolManager) synchronise(peer *peer) {
 		return
 	}
 
+	// Retrieve the latest checkpoint approved in the oracle to sync from it
+	if pm.oracle != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), oracleSyncTimeout)
+		if err := pm.oracle.syncCheckpoint(ctx, peer); err != nil {
+			log.Debug("Failed to retrieve oracle checkpoint", "peer", peer.id, "err", err)
+		}
+		cancel()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 	pm.blockchain.(*light.LightChain).SyncCht(ctx)
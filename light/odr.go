@@ -115,6 +115,18 @@ func (req *BlockRequest) StoreResult(db okcdb.Database) {
 	core.WriteBodyRLP(db, req.Hash, req.Number, req.Rlp)
 }
 
+// HeaderRequest is the ODR request type for retrieving a block header by hash
+type HeaderRequest struct {
+	OdrRequest
+	Hash   common.Hash
+	Number uint64
+	Header *types.Header
+}
+
+// StoreResult does not store the retrieved header, it is not verified to be
+// part of the chain
+func (req *HeaderRequest) StoreResult(db okcdb.Database) {}
+
 // ReceiptsRequest is the ODR request type for retrieving block bodies
 type ReceiptsRequest struct {
 	OdrRequest
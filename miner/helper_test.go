@@ -0,0 +1,96 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/accounts"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+var (
+	testKeys    []*ecdsa.PrivateKey
+	testAddrs   []common.Address
+	testBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Okcer))
+	testSigner  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+)
+
+func init() {
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		testKeys = append(testKeys, key)
+		testAddrs = append(testAddrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+}
+
+// testBackend implements Backend on top of an in-memory chain and pool.
+type testBackend struct {
+	db      okcdb.Database
+	genesis *core.Genesis
+	chain   *core.BlockChain
+	txPool  *core.TxPool
+}
+
+func newTestBackend(t *testing.T, engine consensus.Engine, gasLimit uint64) *testBackend {
+	var (
+		db, _ = okcdb.NewMemDatabase()
+		alloc = make(core.GenesisAlloc)
+	)
+	for _, addr := range testAddrs {
+		alloc[addr] = core.GenesisAccount{Balance: testBalance}
+	}
+	genesis := &core.Genesis{Config: params.TestChainConfig, GasLimit: gasLimit, Alloc: alloc}
+	genesis.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	poolConfig := core.DefaultTxPoolConfig
+	poolConfig.Journal = ""
+
+	return &testBackend{
+		db:      db,
+		genesis: genesis,
+		chain:   chain,
+		txPool:  core.NewTxPool(poolConfig, params.TestChainConfig, chain),
+	}
+}
+
+func (b *testBackend) AccountManager() *accounts.Manager { return accounts.NewManager() }
+func (b *testBackend) BlockChain() *core.BlockChain      { return b.chain }
+func (b *testBackend) TxPool() *core.TxPool              { return b.txPool }
+func (b *testBackend) ChainDb() okcdb.Database           { return b.db }
+
+// newTestTransfer creates a signed value transfer of the given sender.
+func newTestTransfer(t *testing.T, key *ecdsa.PrivateKey, nonce uint64, price int64) *types.Transaction {
+	tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0xff}, big.NewInt(1), params.TxGas, big.NewInt(price), nil), testSigner, key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	return tx
+}
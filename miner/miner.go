@@ -20,6 +20,7 @@ package miner
 import (
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/okcoin/go-okcoin/accounts"
 	"github.com/okcoin/go-okcoin/common"
@@ -163,6 +164,39 @@ func (self *Miner) SetExtra(extra []byte) error {
 	return nil
 }
 
+// SetTxOrdering changes the strategy used to order transactions into the blocks
+// being mined. It takes effect starting with the next block.
+func (self *Miner) SetTxOrdering(ordering TxOrdering) {
+	self.worker.setOrdering(ordering)
+}
+
+// TxOrdering returns the strategy currently used to order transactions.
+func (self *Miner) TxOrdering() TxOrdering {
+	return self.worker.txOrdering()
+}
+
+// SetGasTarget sets the gas limit the miner raises the block gas limit towards.
+func (self *Miner) SetGasTarget(floor uint64) {
+	self.worker.setGasFloor(floor)
+}
+
+// SetGasLimit sets the gas limit above which the miner lowers the block gas
+// limit. Zero removes the bound.
+func (self *Miner) SetGasLimit(ceil uint64) {
+	self.worker.setGasCeil(ceil)
+}
+
+// GasBounds returns the gas limit range the miner is currently targeting.
+func (self *Miner) GasBounds() (uint64, uint64) {
+	return self.worker.gasBounds()
+}
+
+// SetRecommitInterval sets the minimum interval at which the block being mined
+// is recreated to pick up newly arrived, better paying transactions.
+func (self *Miner) SetRecommitInterval(interval time.Duration) {
+	self.worker.setRecommitInterval(interval)
+}
+
 // Pending returns the currently pending block and associated state.
 func (self *Miner) Pending() (*types.Block, *state.StateDB) {
 	return self.worker.pending()
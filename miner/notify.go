@@ -0,0 +1,164 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/log"
+)
+
+const (
+	notifyTimeout    = time.Second            // Time allowed for a single notification request
+	notifyAttempts   = 3                      // Number of times a notification is tried before giving up
+	notifyRetryDelay = 250 * time.Millisecond // Base delay between retries, growing linearly
+)
+
+// workNotification is the JSON payload POSTed to the notification endpoints.
+type workNotification struct {
+	HeaderHash common.Hash    `json:"headerHash"`
+	SeedHash   common.Hash    `json:"seedHash"`
+	Target     common.Hash    `json:"target"`
+	Number     hexutil.Uint64 `json:"number"`
+}
+
+// workNotifier pushes new work packages to a set of remote endpoints. Every
+// endpoint is served by its own goroutine which only ever delivers the latest
+// package, so slow or dead endpoints never hold up the miner nor each other.
+type workNotifier struct {
+	client *http.Client
+	slots  []chan *WorkPackage // Latest undelivered package of each endpoint
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newWorkNotifier starts a notifier delivering work packages to the given URLs.
+func newWorkNotifier(urls []string) *workNotifier {
+	n := &workNotifier{
+		client: &http.Client{Timeout: notifyTimeout},
+		quit:   make(chan struct{}),
+	}
+	for _, url := range urls {
+		slot := make(chan *WorkPackage, 1)
+		n.slots = append(n.slots, slot)
+
+		n.wg.Add(1)
+		go n.loop(url, slot)
+	}
+	return n
+}
+
+// notify schedules a work package for delivery to all endpoints, replacing any
+// package not yet delivered. It never blocks.
+func (n *workNotifier) notify(pkg *WorkPackage) {
+	for _, slot := range n.slots {
+		select {
+		case slot <- pkg:
+		default:
+			// Endpoint still busy, drop its stale package and queue the new one
+			select {
+			case <-slot:
+			default:
+			}
+			select {
+			case slot <- pkg:
+			default:
+			}
+		}
+	}
+}
+
+// close terminates all deliveries in flight and waits for them to return.
+func (n *workNotifier) close() {
+	close(n.quit)
+	n.wg.Wait()
+}
+
+// loop delivers the work packages of a single endpoint.
+func (n *workNotifier) loop(url string, slot chan *WorkPackage) {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case pkg := <-slot:
+			n.deliver(url, pkg, slot)
+		case <-n.quit:
+			return
+		}
+	}
+}
+
+// deliver POSTs a work package to the endpoint, retrying a few times on failure.
+// Retries are abandoned if a newer package becomes available meanwhile.
+func (n *workNotifier) deliver(url string, pkg *WorkPackage, slot chan *WorkPackage) {
+	for attempt := 1; ; attempt++ {
+		err := n.post(url, pkg)
+		if err == nil {
+			return
+		}
+		if attempt == notifyAttempts {
+			log.Warn("Failed to notify remote miner", "url", url, "number", pkg.Number, "err", err)
+			return
+		}
+		log.Debug("Retrying remote miner notification", "url", url, "number", pkg.Number, "attempt", attempt, "err", err)
+
+		select {
+		case <-time.After(time.Duration(attempt) * notifyRetryDelay):
+		case newer := <-slot:
+			pkg, attempt = newer, 0
+		case <-n.quit:
+			return
+		}
+	}
+}
+
+// post sends a single notification request.
+func (n *workNotifier) post(url string, pkg *WorkPackage) error {
+	blob, err := json.Marshal(&workNotification{
+		HeaderHash: pkg.Hash,
+		SeedHash:   pkg.Seed,
+		Target:     pkg.Target,
+		Number:     hexutil.Uint64(pkg.Number),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	return nil
+}
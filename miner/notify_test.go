@@ -0,0 +1,135 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core/types"
+)
+
+// newNotifyTestWork creates a sealing task for the given block number.
+func newNotifyTestWork(number int64) *Work {
+	header := &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(1000)}
+	return &Work{Block: types.NewBlockWithHeader(header), createdAt: time.Now()}
+}
+
+// Tests that new work packages are POSTed to the notification endpoints, with
+// failed deliveries being retried.
+func TestRemoteAgentNotify(t *testing.T) {
+	var (
+		flaky    int32
+		received = make(chan *workNotification, 10)
+	)
+	// Create an endpoint failing the first request and accepting the rest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&flaky, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method != "POST" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("invalid notification request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		pkg := new(workNotification)
+		if err := json.NewDecoder(r.Body).Decode(pkg); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+		received <- pkg
+	}))
+	defer server.Close()
+
+	agent := NewRemoteAgent(nil, okcash.NewFaker())
+	agent.SetNotify([]string{server.URL})
+	agent.SetReturnCh(make(chan *Result))
+	agent.Start()
+	defer agent.Stop()
+
+	work := newNotifyTestWork(42)
+	agent.Work() <- work
+
+	select {
+	case pkg := <-received:
+		want := newWorkPackage(work)
+		if pkg.HeaderHash != want.Hash || pkg.SeedHash != want.Seed || pkg.Target != want.Target || uint64(pkg.Number) != want.Number {
+			t.Errorf("notification mismatch: have %+v, want %+v", pkg, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("notification not delivered")
+	}
+	if attempts := atomic.LoadInt32(&flaky); attempts != 2 {
+		t.Errorf("delivery attempt mismatch: have %d, want 2", attempts)
+	}
+	// The notified work must be submittable without a preceding getWork
+	if agent.pendingHeader(work.Block.HashNoNonce()) == nil {
+		t.Errorf("notified work not tracked")
+	}
+}
+
+// Tests that unresponsive endpoints neither block the agent nor the delivery
+// to other endpoints.
+func TestRemoteAgentNotifyStalled(t *testing.T) {
+	var (
+		release  = make(chan struct{})
+		received = make(chan *workNotification, 10)
+	)
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer stalled.Close()
+	defer close(release)
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pkg := new(workNotification)
+		json.NewDecoder(r.Body).Decode(pkg)
+		received <- pkg
+	}))
+	defer healthy.Close()
+
+	agent := NewRemoteAgent(nil, okcash.NewFaker())
+	agent.SetNotify([]string{stalled.URL, healthy.URL})
+	agent.SetReturnCh(make(chan *Result))
+	agent.Start()
+	defer agent.Stop()
+
+	// Push a series of work packages, none of them may block
+	start := time.Now()
+	for i := int64(1); i <= 5; i++ {
+		agent.Work() <- newNotifyTestWork(i)
+	}
+	if elapsed := time.Since(start); elapsed > notifyTimeout {
+		t.Errorf("work submission blocked for %v", elapsed)
+	}
+	// The healthy endpoint must eventually see the latest package
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case pkg := <-received:
+			if pkg.Number == 5 {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("latest notification not delivered")
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+)
+
+// Names of the transaction ordering strategies built into the miner.
+const (
+	OrderByPrice    = "price"    // Most profitable first (default)
+	OrderByArrival  = "fifo"     // Strictly by local arrival time
+	OrderByPriority = "priority" // Configured senders first, then by price
+	OrderByReserved = "reserved" // Gas share set aside for configured system senders
+)
+
+// OrderingConfig contains the parameters of the transaction ordering strategies.
+type OrderingConfig struct {
+	Strategy        string           // Name of the strategy to use when filling blocks
+	PrioritySenders []common.Address // Senders preferred by the priority strategy
+	SystemSenders   []common.Address // Senders the reserved strategy keeps gas aside for
+	SystemGasShare  uint64           // Percentage of the block gas limit reserved for system senders
+}
+
+// DefaultOrderingConfig contains the default transaction ordering settings.
+var DefaultOrderingConfig = OrderingConfig{
+	Strategy: OrderByPrice,
+}
+
+// TransactionSet is an iterator over a nonce-ordered set of transactions from
+// which the worker fills a block.
+type TransactionSet interface {
+	// Peek returns the next transaction to execute, or nil if the set is drained.
+	Peek() *types.Transaction
+
+	// Shift replaces the current transaction with the next one from the same
+	// sender.
+	Shift()
+
+	// Pop removes the current transaction and all subsequent ones from the same
+	// sender, since they cannot be executed anymore.
+	Pop()
+}
+
+// OrderingEnv is the context handed to a TxOrdering when assembling the
+// transaction set of a new block.
+type OrderingEnv struct {
+	Signer types.Signer  // Signer to derive transaction senders with
+	Header *types.Header // Header being filled, GasUsed is updated as txs execute
+
+	arrivals *txArrivals // Local arrival times of the pending transactions
+}
+
+// Arrival returns the time the transaction was first seen locally, or the zero
+// time if it's unknown (e.g. it was already pooled when the miner started).
+func (env *OrderingEnv) Arrival(tx *types.Transaction) time.Time {
+	if env.arrivals == nil {
+		return time.Time{}
+	}
+	return env.arrivals.get(tx.Hash())
+}
+
+// TxOrdering is a strategy deciding in which order pending transactions are
+// included into a new block. Implementations must honour account nonce order.
+type TxOrdering interface {
+	// Name returns the identifier the strategy is selected by.
+	Name() string
+
+	// Order creates the transaction set to fill the block with. The pending map
+	// is reowned by the strategy.
+	Order(env *OrderingEnv, pending map[common.Address]types.Transactions) TransactionSet
+}
+
+// NewTxOrdering creates the transaction ordering strategy requested by config.
+func NewTxOrdering(config OrderingConfig) (TxOrdering, error) {
+	switch config.Strategy {
+	case "", OrderByPrice:
+		return priceOrdering{}, nil
+	case OrderByArrival:
+		return arrivalOrdering{}, nil
+	case OrderByPriority:
+		if len(config.PrioritySenders) == 0 {
+			return nil, fmt.Errorf("%s ordering requires priority senders", OrderByPriority)
+		}
+		return &priorityOrdering{senders: newAddressSet(config.PrioritySenders)}, nil
+	case OrderByReserved:
+		if len(config.SystemSenders) == 0 {
+			return nil, fmt.Errorf("%s ordering requires system senders", OrderByReserved)
+		}
+		if config.SystemGasShare > 100 {
+			return nil, fmt.Errorf("system gas share above 100%%: %d", config.SystemGasShare)
+		}
+		return &reservedOrdering{senders: newAddressSet(config.SystemSenders), share: config.SystemGasShare}, nil
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering: %q", config.Strategy)
+	}
+}
+
+// priceOrdering is the classical profit maximising strategy.
+type priceOrdering struct{}
+
+func (priceOrdering) Name() string { return OrderByPrice }
+
+func (priceOrdering) Order(env *OrderingEnv, pending map[common.Address]types.Transactions) TransactionSet {
+	return types.NewTransactionsByPriceAndNonce(env.Signer, pending)
+}
+
+// arrivalOrdering includes transactions in the order they were first seen,
+// disregarding their price. Transactions of unknown arrival go first, ordered
+// by price among themselves.
+type arrivalOrdering struct{}
+
+func (arrivalOrdering) Name() string { return OrderByArrival }
+
+func (arrivalOrdering) Order(env *OrderingEnv, pending map[common.Address]types.Transactions) TransactionSet {
+	return newTransactionsByNonce(env.Signer, pending, func(a, b *types.Transaction) bool {
+		if ta, tb := env.Arrival(a), env.Arrival(b); !ta.Equal(tb) {
+			return ta.Before(tb)
+		}
+		return a.GasPrice().Cmp(b.GasPrice()) > 0
+	})
+}
+
+// priorityOrdering includes all transactions of a preferred set of senders
+// before any others, using price ordering within both groups.
+type priorityOrdering struct {
+	senders map[common.Address]struct{}
+}
+
+func (o *priorityOrdering) Name() string { return OrderByPriority }
+
+func (o *priorityOrdering) Order(env *OrderingEnv, pending map[common.Address]types.Transactions) TransactionSet {
+	preferred, others := splitPending(pending, o.senders)
+	return &chainedSet{sets: []TransactionSet{
+		types.NewTransactionsByPriceAndNonce(env.Signer, preferred),
+		types.NewTransactionsByPriceAndNonce(env.Signer, others),
+	}}
+}
+
+// reservedOrdering includes transactions of system senders first and restricts
+// everybody else to the non-reserved share of the block gas limit, so system
+// transactions arriving while the block is pending still fit.
+type reservedOrdering struct {
+	senders map[common.Address]struct{}
+	share   uint64
+}
+
+func (o *reservedOrdering) Name() string { return OrderByReserved }
+
+func (o *reservedOrdering) Order(env *OrderingEnv, pending map[common.Address]types.Transactions) TransactionSet {
+	system, others := splitPending(pending, o.senders)
+
+	reserved := env.Header.GasLimit / 100 * o.share
+	return &chainedSet{sets: []TransactionSet{
+		types.NewTransactionsByPriceAndNonce(env.Signer, system),
+		&cappedSet{
+			set:    types.NewTransactionsByPriceAndNonce(env.Signer, others),
+			header: env.Header,
+			limit:  env.Header.GasLimit - reserved,
+		},
+	}}
+}
+
+// splitPending separates the pending transactions of the given senders from
+// all the others.
+func splitPending(pending map[common.Address]types.Transactions, senders map[common.Address]struct{}) (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	selected := make(map[common.Address]types.Transactions)
+	for addr, txs := range pending {
+		if _, ok := senders[addr]; ok {
+			selected[addr] = txs
+			delete(pending, addr)
+		}
+	}
+	return selected, pending
+}
+
+func newAddressSet(addrs []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+// chainedSet drains a list of transaction sets one after the other.
+type chainedSet struct {
+	sets []TransactionSet
+}
+
+// current returns the first not yet drained set, or nil if all are done.
+func (s *chainedSet) current() TransactionSet {
+	for len(s.sets) > 0 {
+		if s.sets[0].Peek() != nil {
+			return s.sets[0]
+		}
+		s.sets = s.sets[1:]
+	}
+	return nil
+}
+
+func (s *chainedSet) Peek() *types.Transaction {
+	if set := s.current(); set != nil {
+		return set.Peek()
+	}
+	return nil
+}
+
+func (s *chainedSet) Shift() {
+	if set := s.current(); set != nil {
+		set.Shift()
+	}
+}
+
+func (s *chainedSet) Pop() {
+	if set := s.current(); set != nil {
+		set.Pop()
+	}
+}
+
+// cappedSet hides transactions which would push the gas used by the block
+// being filled above a limit lower than the block's gas limit.
+type cappedSet struct {
+	set    TransactionSet
+	header *types.Header
+	limit  uint64
+}
+
+func (s *cappedSet) Peek() *types.Transaction {
+	for {
+		tx := s.set.Peek()
+		if tx == nil || s.header.GasUsed+tx.Gas() <= s.limit {
+			return tx
+		}
+		s.set.Pop()
+	}
+}
+
+func (s *cappedSet) Shift() { s.set.Shift() }
+func (s *cappedSet) Pop()   { s.set.Pop() }
+
+// txHeads is a heap of the next transaction of each account, ordered by an
+// arbitrary comparator.
+type txHeads struct {
+	txs  []*types.Transaction
+	less func(a, b *types.Transaction) bool
+}
+
+func (h txHeads) Len() int            { return len(h.txs) }
+func (h txHeads) Less(i, j int) bool  { return h.less(h.txs[i], h.txs[j]) }
+func (h txHeads) Swap(i, j int)       { h.txs[i], h.txs[j] = h.txs[j], h.txs[i] }
+func (h *txHeads) Push(x interface{}) { h.txs = append(h.txs, x.(*types.Transaction)) }
+
+func (h *txHeads) Pop() interface{} {
+	old := h.txs
+	n := len(old)
+	x := old[n-1]
+	h.txs = old[0 : n-1]
+	return x
+}
+
+// transactionsByNonce is the generalisation of types.TransactionsByPriceAndNonce
+// to any ordering between the accounts' head transactions.
+type transactionsByNonce struct {
+	txs    map[common.Address]types.Transactions // Per account nonce-sorted list of transactions
+	heads  *txHeads                              // Next transaction for each unique account
+	signer types.Signer                          // Signer for the set of transactions
+}
+
+// newTransactionsByNonce creates a transaction set that retrieves transactions
+// ordered by less in a nonce-honouring way. The input map is reowned.
+func newTransactionsByNonce(signer types.Signer, txs map[common.Address]types.Transactions, less func(a, b *types.Transaction) bool) *transactionsByNonce {
+	heads := &txHeads{txs: make([]*types.Transaction, 0, len(txs)), less: less}
+	for acc, accTxs := range txs {
+		heads.txs = append(heads.txs, accTxs[0])
+		txs[acc] = accTxs[1:]
+	}
+	heap.Init(heads)
+
+	return &transactionsByNonce{
+		txs:    txs,
+		heads:  heads,
+		signer: signer,
+	}
+}
+
+func (t *transactionsByNonce) Peek() *types.Transaction {
+	if len(t.heads.txs) == 0 {
+		return nil
+	}
+	return t.heads.txs[0]
+}
+
+func (t *transactionsByNonce) Shift() {
+	acc, _ := types.Sender(t.signer, t.heads.txs[0])
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		t.heads.txs[0], t.txs[acc] = txs[0], txs[1:]
+		heap.Fix(t.heads, 0)
+	} else {
+		heap.Pop(t.heads)
+	}
+}
+
+func (t *transactionsByNonce) Pop() {
+	heap.Pop(t.heads)
+}
+
+// txArrivals tracks the local arrival time of pending transactions.
+type txArrivals struct {
+	seen map[common.Hash]time.Time
+	lock sync.RWMutex
+}
+
+func newTxArrivals() *txArrivals {
+	return &txArrivals{seen: make(map[common.Hash]time.Time)}
+}
+
+// add records the arrival of a transaction, unless already known.
+func (a *txArrivals) add(hash common.Hash, at time.Time) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, ok := a.seen[hash]; !ok {
+		a.seen[hash] = at
+	}
+}
+
+func (a *txArrivals) get(hash common.Hash) time.Time {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.seen[hash]
+}
+
+// retain drops the arrival times of all transactions not pending anymore.
+func (a *txArrivals) retain(pending map[common.Address]types.Transactions) {
+	keep := make(map[common.Hash]time.Time)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for _, txs := range pending {
+		for _, tx := range txs {
+			if at, ok := a.seen[tx.Hash()]; ok {
+				keep[tx.Hash()] = at
+			}
+		}
+	}
+	a.seen = keep
+}
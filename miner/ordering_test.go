@@ -0,0 +1,238 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/core/vm"
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/okcdb"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// mineWithOrdering fills a new block from the pool of the backend with the
+// given ordering, validates it by importing it into a fresh chain and returns
+// it.
+func mineWithOrdering(t *testing.T, backend *testBackend, w *worker, ordering TxOrdering) *types.Block {
+	w.setOrdering(ordering)
+	w.commitNewWork()
+
+	w.currentMu.Lock()
+	block := w.current.Block
+	w.currentMu.Unlock()
+
+	db, _ := okcdb.NewMemDatabase()
+	backend.genesis.MustCommit(db)
+	chain, err := core.NewBlockChain(db, nil, params.TestChainConfig, okcash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create verification chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("%s: mined block invalid: %v", ordering.Name(), err)
+	}
+	// Regardless of the strategy, every sender's nonces must be gapless
+	nonces := make(map[common.Address]uint64)
+	for i, tx := range block.Transactions() {
+		from, _ := types.Sender(testSigner, tx)
+		if tx.Nonce() != nonces[from] {
+			t.Errorf("%s: tx %d: nonce mismatch: have %d, want %d", ordering.Name(), i, tx.Nonce(), nonces[from])
+		}
+		nonces[from]++
+	}
+	return block
+}
+
+// senders returns the sender of every transaction in the block.
+func senders(block *types.Block) []common.Address {
+	var addrs []common.Address
+	for _, tx := range block.Transactions() {
+		from, _ := types.Sender(testSigner, tx)
+		addrs = append(addrs, from)
+	}
+	return addrs
+}
+
+// Tests that each of the ordering strategies produces valid blocks ordered as
+// the strategy promises.
+func TestTxOrderingPrice(t *testing.T) {
+	backend := newTestBackend(t, okcash.NewFaker(), params.GenesisGasLimit)
+	defer backend.chain.Stop()
+
+	// Sender 0 pays the least, sender 2 the most
+	for i, key := range testKeys {
+		for nonce := uint64(0); nonce < 3; nonce++ {
+			backend.txPool.AddRemote(newTestTransfer(t, key, nonce, int64(i+1)))
+		}
+	}
+	w := newWorker(params.TestChainConfig, okcash.NewFaker(), common.Address{}, backend, new(event.TypeMux))
+
+	block := mineWithOrdering(t, backend, w, priceOrdering{})
+	if len(block.Transactions()) != 9 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), 9)
+	}
+	want := []common.Address{testAddrs[2], testAddrs[2], testAddrs[2], testAddrs[1], testAddrs[1], testAddrs[1], testAddrs[0], testAddrs[0], testAddrs[0]}
+	for i, addr := range senders(block) {
+		if addr != want[i] {
+			t.Errorf("tx %d: sender mismatch: have %x, want %x", i, addr, want[i])
+		}
+	}
+}
+
+func TestTxOrderingArrival(t *testing.T) {
+	backend := newTestBackend(t, okcash.NewFaker(), params.GenesisGasLimit)
+	defer backend.chain.Stop()
+
+	// Sender 0 pays the most but arrives last, interleave the others
+	var (
+		now     = time.Now()
+		arrived = make(map[common.Hash]time.Time)
+	)
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		for i, key := range testKeys {
+			tx := newTestTransfer(t, key, nonce, int64(len(testKeys)-i))
+			backend.txPool.AddRemote(tx)
+
+			arrived[tx.Hash()] = now.Add(time.Duration(int(nonce)*len(testKeys)+len(testKeys)-i) * time.Second)
+		}
+	}
+	w := newWorker(params.TestChainConfig, okcash.NewFaker(), common.Address{}, backend, new(event.TypeMux))
+	for hash, at := range arrived {
+		w.arrivals.add(hash, at)
+	}
+	block := mineWithOrdering(t, backend, w, arrivalOrdering{})
+	if len(block.Transactions()) != 6 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), 6)
+	}
+	for i := 1; i < len(block.Transactions()); i++ {
+		prev, next := arrived[block.Transactions()[i-1].Hash()], arrived[block.Transactions()[i].Hash()]
+		if next.Before(prev) {
+			t.Errorf("tx %d: arrived before its predecessor: %v < %v", i, next, prev)
+		}
+	}
+}
+
+func TestTxOrderingPriority(t *testing.T) {
+	backend := newTestBackend(t, okcash.NewFaker(), params.GenesisGasLimit)
+	defer backend.chain.Stop()
+
+	// The priority sender pays the least
+	for i, key := range testKeys {
+		for nonce := uint64(0); nonce < 3; nonce++ {
+			backend.txPool.AddRemote(newTestTransfer(t, key, nonce, int64(i+1)))
+		}
+	}
+	w := newWorker(params.TestChainConfig, okcash.NewFaker(), common.Address{}, backend, new(event.TypeMux))
+
+	ordering, err := NewTxOrdering(OrderingConfig{Strategy: OrderByPriority, PrioritySenders: []common.Address{testAddrs[0]}})
+	if err != nil {
+		t.Fatalf("failed to create ordering: %v", err)
+	}
+	block := mineWithOrdering(t, backend, w, ordering)
+	if len(block.Transactions()) != 9 {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(block.Transactions()), 9)
+	}
+	for i, addr := range senders(block)[:3] {
+		if addr != testAddrs[0] {
+			t.Errorf("tx %d: sender mismatch: have %x, want %x", i, addr, testAddrs[0])
+		}
+	}
+}
+
+func TestTxOrderingReserved(t *testing.T) {
+	// Create a chain with room for about 20 transfers per block
+	backend := newTestBackend(t, okcash.NewFaker(), 20*params.TxGas)
+	defer backend.chain.Stop()
+
+	// Reserve most of the block for the system sender, who pays the least
+	for i, key := range testKeys {
+		for nonce := uint64(0); nonce < 4; nonce++ {
+			backend.txPool.AddRemote(newTestTransfer(t, key, nonce, int64(i+1)))
+		}
+	}
+	w := newWorker(params.TestChainConfig, okcash.NewFaker(), common.Address{}, backend, new(event.TypeMux))
+
+	ordering, err := NewTxOrdering(OrderingConfig{Strategy: OrderByReserved, SystemSenders: []common.Address{testAddrs[0]}, SystemGasShare: 75})
+	if err != nil {
+		t.Fatalf("failed to create ordering: %v", err)
+	}
+	block := mineWithOrdering(t, backend, w, ordering)
+
+	var system, others uint64
+	for i, addr := range senders(block) {
+		if addr == testAddrs[0] {
+			if others > 0 {
+				t.Errorf("tx %d: system transaction after non-system ones", i)
+			}
+			system += params.TxGas
+		} else {
+			others += params.TxGas
+		}
+	}
+	if system != 4*params.TxGas {
+		t.Errorf("system gas mismatch: have %d, want %d", system, 4*params.TxGas)
+	}
+	if limit := block.GasLimit() - block.GasLimit()/100*75; others+system > limit {
+		t.Errorf("block gas above unreserved share: have %d, limit %d", others+system, limit)
+	}
+	if others == 0 {
+		t.Errorf("no non-system transactions included")
+	}
+}
+
+// Tests that strategies can be switched on a live worker and that invalid
+// configurations are rejected.
+func TestTxOrderingSelection(t *testing.T) {
+	tests := []struct {
+		config OrderingConfig
+		name   string
+		fail   bool
+	}{
+		{config: OrderingConfig{}, name: OrderByPrice},
+		{config: OrderingConfig{Strategy: OrderByArrival}, name: OrderByArrival},
+		{config: OrderingConfig{Strategy: OrderByPriority}, fail: true},
+		{config: OrderingConfig{Strategy: OrderByPriority, PrioritySenders: testAddrs}, name: OrderByPriority},
+		{config: OrderingConfig{Strategy: OrderByReserved, SystemSenders: testAddrs, SystemGasShare: 101}, fail: true},
+		{config: OrderingConfig{Strategy: OrderByReserved, SystemSenders: testAddrs, SystemGasShare: 10}, name: OrderByReserved},
+		{config: OrderingConfig{Strategy: "lifo"}, fail: true},
+	}
+	backend := newTestBackend(t, okcash.NewFaker(), params.GenesisGasLimit)
+	defer backend.chain.Stop()
+
+	w := newWorker(params.TestChainConfig, okcash.NewFaker(), common.Address{}, backend, new(event.TypeMux))
+	for i, tt := range tests {
+		ordering, err := NewTxOrdering(tt.config)
+		if (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		w.setOrdering(ordering)
+		if name := w.txOrdering().Name(); name != tt.name {
+			t.Errorf("test %d: strategy mismatch: have %s, want %s", i, name, tt.name)
+		}
+	}
+}
@@ -27,9 +27,38 @@ import (
 	"github.com/okcoin/go-okcoin/consensus"
 	"github.com/okcoin/go-okcoin/consensus/okcash"
 	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/event"
 	"github.com/okcoin/go-okcoin/log"
 )
 
+// WorkPackage is a sealing task handed out to remote miners.
+type WorkPackage struct {
+	Number     uint64      // Number of the block being sealed
+	Hash       common.Hash // Pow-hash of the header to seal (hash without nonce)
+	Seed       common.Hash // Seed hash of the epoch's DAG
+	Target     common.Hash // Boundary condition of a valid solution, 2^256/difficulty
+	Difficulty *big.Int    // Difficulty of the block being sealed
+}
+
+// newWorkPackage assembles the package of a sealing task for remote miners.
+func newWorkPackage(work *Work) *WorkPackage {
+	block := work.Block
+
+	// Calculate the "target" to be returned to the external miner
+	n := big.NewInt(1)
+	n.Lsh(n, 255)
+	n.Div(n, block.Difficulty())
+	n.Lsh(n, 1)
+
+	return &WorkPackage{
+		Number:     block.NumberU64(),
+		Hash:       block.HashNoNonce(),
+		Seed:       common.BytesToHash(okcash.SeedHash(block.NumberU64())),
+		Target:     common.BytesToHash(n.Bytes()),
+		Difficulty: new(big.Int).Set(block.Difficulty()),
+	}
+}
+
 type hashrate struct {
 	ping time.Time
 	rate uint64
@@ -50,6 +79,12 @@ type RemoteAgent struct {
 	hashrateMu sync.RWMutex
 	hashrate   map[common.Hash]hashrate
 
+	workFeed  event.Feed
+	workScope event.SubscriptionScope
+
+	notifyURLs []string      // Endpoints to POST new work packages to
+	notifier   *workNotifier // Notifier delivering work packages while running
+
 	running int32 // running indicates whokcer the agent is active. Call atomically
 }
 
@@ -69,6 +104,12 @@ func (a *RemoteAgent) SubmitHashrate(id common.Hash, rate uint64) {
 	a.hashrate[id] = hashrate{time.Now(), rate}
 }
 
+// SetNotify configures the URLs every new work package is POSTed to as JSON
+// while the agent is running. It must be called before the agent is started.
+func (a *RemoteAgent) SetNotify(urls []string) {
+	a.notifyURLs = urls
+}
+
 func (a *RemoteAgent) Work() chan<- *Work {
 	return a.workCh
 }
@@ -83,7 +124,12 @@ func (a *RemoteAgent) Start() {
 	}
 	a.quitCh = make(chan struct{})
 	a.workCh = make(chan *Work, 1)
-	go a.loop(a.workCh, a.quitCh)
+
+	var notifier *workNotifier
+	if len(a.notifyURLs) > 0 {
+		notifier = newWorkNotifier(a.notifyURLs)
+	}
+	go a.loop(a.workCh, a.quitCh, notifier)
 }
 
 func (a *RemoteAgent) Stop() {
@@ -113,24 +159,37 @@ func (a *RemoteAgent) GetWork() ([3]string, error) {
 	var res [3]string
 
 	if a.currentWork != nil {
-		block := a.currentWork.Block
-
-		res[0] = block.HashNoNonce().Hex()
-		seedHash := okcash.SeedHash(block.NumberU64())
-		res[1] = common.BytesToHash(seedHash).Hex()
-		// Calculate the "target" to be returned to the external miner
-		n := big.NewInt(1)
-		n.Lsh(n, 255)
-		n.Div(n, block.Difficulty())
-		n.Lsh(n, 1)
-		res[2] = common.BytesToHash(n.Bytes()).Hex()
-
-		a.work[block.HashNoNonce()] = a.currentWork
+		pkg := newWorkPackage(a.currentWork)
+
+		res[0] = pkg.Hash.Hex()
+		res[1] = pkg.Seed.Hex()
+		res[2] = pkg.Target.Hex()
+
+		a.work[pkg.Hash] = a.currentWork
 		return res, nil
 	}
 	return res, errors.New("No work available yet, don't panic.")
 }
 
+// SubscribeWork registers a subscription for the work packages of all new
+// sealing tasks, delivered as soon as the worker produces them. Submitted
+// solutions for any of them are accepted without a preceding GetWork.
+func (a *RemoteAgent) SubscribeWork(ch chan<- *WorkPackage) event.Subscription {
+	return a.workScope.Track(a.workFeed.Subscribe(ch))
+}
+
+// pendingHeader returns the header of a sealing task handed out to remote
+// miners, or nil if the task is unknown or expired.
+func (a *RemoteAgent) pendingHeader(hash common.Hash) *types.Header {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if work := a.work[hash]; work != nil {
+		return work.Block.Header()
+	}
+	return nil
+}
+
 // SubmitWork tries to inject a pow solution into the remote agent, returning
 // whokcer the solution was accepted or not (not can be both a bad pow as well as
 // any other error, like no work pending).
@@ -167,11 +226,15 @@ func (a *RemoteAgent) SubmitWork(nonce types.BlockNonce, mixDigest, hash common.
 //
 // Note, the reason the work and quit channels are passed as parameters is because
 // RemoteAgent.Start() constantly recreates these channels, so the loop code cannot
-// assume data stability in these member fields.
-func (a *RemoteAgent) loop(workCh chan *Work, quitCh chan struct{}) {
+// assume data stability in these member fields. The same goes for the notifier.
+func (a *RemoteAgent) loop(workCh chan *Work, quitCh chan struct{}, notifier *workNotifier) {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	if notifier != nil {
+		defer notifier.close()
+	}
+
 	for {
 		select {
 		case <-quitCh:
@@ -180,6 +243,19 @@ func (a *RemoteAgent) loop(workCh chan *Work, quitCh chan struct{}) {
 			a.mu.Lock()
 			a.currentWork = work
 			a.mu.Unlock()
+
+			if work != nil {
+				pkg := newWorkPackage(work)
+
+				a.mu.Lock()
+				a.work[pkg.Hash] = work
+				a.mu.Unlock()
+
+				if notifier != nil {
+					notifier.notify(pkg)
+				}
+				a.workFeed.Send(pkg)
+			}
 		case <-ticker.C:
 			// cleanup
 			a.mu.Lock()
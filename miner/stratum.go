@@ -0,0 +1,760 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/log"
+)
+
+const (
+	stratumProtocol     = "EthereumStratum/1.0.0"
+	stratumMaxLineSize  = 16 * 1024        // Maximum size of a single request line
+	stratumReadTimeout  = 10 * time.Minute // Idle time after which a connection is dropped
+	stratumWriteTimeout = 10 * time.Second // Time allowed to push a message to a miner
+	stratumJobLifetime  = 7 * (12 * time.Second)
+	stratumRateWindow   = 10 * time.Minute // Window over which worker hashrates are estimated
+	stratumRateInterval = 5 * time.Second  // Interval of hashrate reports to the remote agent
+	stratumMaxJobShares = 16 * 1024        // Maximum number of shares accepted for verification per job
+	stratumMaxWorkers   = 16               // Maximum number of workers authorized on a single connection
+	stratumWorkerExpiry = time.Hour        // Idle time after which the accounting of a worker is dropped
+)
+
+// Stratum error codes, as used across the mining pool ecosystem.
+const (
+	stratumErrOther          = 20
+	stratumErrStaleJob       = 21
+	stratumErrDuplicateShare = 22
+	stratumErrLowDifficulty  = 23
+	stratumErrUnauthorized   = 24
+	stratumErrNotSubscribed  = 25
+)
+
+var (
+	errStratumUnsupportedEngine = errors.New("consensus engine does not support share verification")
+	errStratumNoExtranonce      = errors.New("all extranonces in use")
+)
+
+// StratumConfig contains the settings of the built-in Stratum mining server.
+type StratumConfig struct {
+	Addr           string // Listening address, the server is disabled if empty
+	Difficulty     uint64 // Initial share difficulty of every connection
+	MinDifficulty  uint64 // Lowest share difficulty miners may suggest, bounding the verification load
+	ExtranonceSize int    // Leading nonce bytes partitioning the search space between connections
+}
+
+// DefaultStratumConfig contains the default Stratum server settings.
+var DefaultStratumConfig = StratumConfig{
+	Difficulty:     1 << 32,
+	MinDifficulty:  1 << 30,
+	ExtranonceSize: 2,
+}
+
+// shareVerifier is implemented by consensus engines able to verify solutions
+// at a difficulty lower than the block's.
+type shareVerifier interface {
+	VerifyShare(header *types.Header, nonce types.BlockNonce, difficulty *big.Int) (common.Hash, bool, error)
+}
+
+// StratumWorkerStats is the share accounting of a single Stratum worker.
+type StratumWorkerStats struct {
+	Accepted  uint64    `json:"accepted"`
+	Rejected  uint64    `json:"rejected"`
+	Stale     uint64    `json:"stale"`
+	Blocks    uint64    `json:"blocks"`
+	Hashrate  uint64    `json:"hashrate"`
+	LastShare time.Time `json:"lastShare"`
+}
+
+// stratumShare is an accepted share, kept to estimate a worker's hashrate.
+type stratumShare struct {
+	time       time.Time
+	difficulty *big.Int
+}
+
+// stratumWorker tracks the shares submitted by a named worker.
+type stratumWorker struct {
+	stats  StratumWorkerStats
+	shares []stratumShare
+	first  time.Time
+	active time.Time // Last time a share of the worker was accounted
+}
+
+// hashrate estimates the worker's hashrate from the difficulty of the shares it
+// submitted within the rate window.
+func (w *stratumWorker) hashrate(now time.Time) uint64 {
+	for len(w.shares) > 0 && now.Sub(w.shares[0].time) > stratumRateWindow {
+		w.shares = w.shares[1:]
+	}
+	total := new(big.Int)
+	for _, share := range w.shares {
+		total.Add(total, share.difficulty)
+	}
+	elapsed := now.Sub(w.first)
+	if elapsed > stratumRateWindow {
+		elapsed = stratumRateWindow
+	}
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+	return total.Div(total, big.NewInt(int64(elapsed/time.Second))).Uint64()
+}
+
+// stratumJob is a work package pushed to the miners.
+type stratumJob struct {
+	pkg     *WorkPackage
+	shares  map[types.BlockNonce]struct{} // Nonces already submitted, to reject duplicates
+	created time.Time
+}
+
+// StratumServer serves sealing work to external miners over the Stratum
+// protocol: newline separated JSON-RPC messages over plain TCP. Work is pushed
+// to the miners as soon as the worker produces it, the search space is split
+// between connections via a per connection extranonce, and shares are verified
+// at a per connection difficulty to account for the hashrate of each worker.
+//
+// Difficulties are plain okcash difficulties, i.e. a share of difficulty d has
+// a pow value of at most 2^256/d. Submitted nonces exclude the extranonce.
+type StratumServer struct {
+	config   StratumConfig
+	agent    *RemoteAgent
+	verifier shareVerifier
+
+	listener net.Listener
+	workCh   chan *WorkPackage
+	workSub  event.Subscription
+
+	lock       sync.Mutex
+	sessions   map[*stratumSession]struct{}
+	jobs       map[common.Hash]*stratumJob
+	current    *stratumJob
+	workers    map[string]*stratumWorker
+	extranonce map[uint64]struct{} // Extranonces of the connected sessions
+	nextID     uint64              // Next session ID, the extranonce to try allocating first
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewStratumServer creates a Stratum server handing out the work of the given
+// remote agent, which must be registered with the miner.
+func NewStratumServer(config StratumConfig, agent *RemoteAgent, engine consensus.Engine) (*StratumServer, error) {
+	verifier, ok := engine.(shareVerifier)
+	if !ok {
+		return nil, errStratumUnsupportedEngine
+	}
+	if config.ExtranonceSize < 0 || config.ExtranonceSize > 4 {
+		return nil, fmt.Errorf("invalid extranonce size: %d", config.ExtranonceSize)
+	}
+	if config.Difficulty == 0 {
+		config.Difficulty = DefaultStratumConfig.Difficulty
+	}
+	if config.MinDifficulty == 0 {
+		config.MinDifficulty = DefaultStratumConfig.MinDifficulty
+	}
+	if config.Difficulty < config.MinDifficulty {
+		log.Warn("Stratum difficulty below the minimum, raising", "provided", config.Difficulty, "updated", config.MinDifficulty)
+		config.Difficulty = config.MinDifficulty
+	}
+	return &StratumServer{
+		config:     config,
+		agent:      agent,
+		verifier:   verifier,
+		sessions:   make(map[*stratumSession]struct{}),
+		jobs:       make(map[common.Hash]*stratumJob),
+		workers:    make(map[string]*stratumWorker),
+		extranonce: make(map[uint64]struct{}),
+	}, nil
+}
+
+// Start opens the listening socket and starts serving miners.
+func (s *StratumServer) Start() error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.quit = make(chan struct{})
+	s.workCh = make(chan *WorkPackage, 16)
+	s.workSub = s.agent.SubscribeWork(s.workCh)
+
+	s.wg.Add(2)
+	go s.accept()
+	go s.loop()
+
+	log.Info("Stratum server started", "addr", listener.Addr())
+	return nil
+}
+
+// Stop closes the listening socket and disconnects all miners.
+func (s *StratumServer) Stop() {
+	if s.listener == nil {
+		return
+	}
+	s.workSub.Unsubscribe()
+	close(s.quit)
+	s.listener.Close()
+
+	s.lock.Lock()
+	for session := range s.sessions {
+		session.conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	s.listener = nil
+
+	log.Info("Stratum server stopped")
+}
+
+// Addr returns the address the server is listening on.
+func (s *StratumServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Workers returns the share accounting of the workers tracked by the server,
+// the ones which submitted a share of a known job recently.
+func (s *StratumServer) Workers() map[string]StratumWorkerStats {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	stats := make(map[string]StratumWorkerStats, len(s.workers))
+	for name, worker := range s.workers {
+		worker.stats.Hashrate = worker.hashrate(now)
+		stats[name] = worker.stats
+	}
+	return stats
+}
+
+// accept serves incoming miner connections until the listener is closed.
+func (s *StratumServer) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				log.Warn("Stratum server failed to accept connection", "err", err)
+			}
+			return
+		}
+		session, err := s.newSession(conn)
+		if err != nil {
+			log.Warn("Stratum server refused connection", "remote", conn.RemoteAddr(), "err", err)
+			conn.Close()
+			continue
+		}
+		s.wg.Add(2)
+		go func() {
+			defer s.wg.Done()
+			session.serve()
+
+			s.lock.Lock()
+			delete(s.sessions, session)
+			delete(s.extranonce, session.id)
+			s.lock.Unlock()
+		}()
+		go func() {
+			defer s.wg.Done()
+			session.pushLoop()
+		}()
+	}
+}
+
+// loop pushes new work to the miners and periodically reports the hashrate of
+// the workers to the remote agent. Jobs are only queued on the sessions, so that
+// a slow miner doesn't delay the others nor the remote agent feeding the loop.
+func (s *StratumServer) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(stratumRateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case pkg := <-s.workCh:
+			job := &stratumJob{pkg: pkg, shares: make(map[types.BlockNonce]struct{}), created: time.Now()}
+
+			s.lock.Lock()
+			for hash, old := range s.jobs {
+				if time.Since(old.created) > stratumJobLifetime {
+					delete(s.jobs, hash)
+				}
+			}
+			s.jobs[pkg.Hash] = job
+			s.current = job
+
+			sessions := make([]*stratumSession, 0, len(s.sessions))
+			for session := range s.sessions {
+				sessions = append(sessions, session)
+			}
+			s.lock.Unlock()
+
+			for _, session := range sessions {
+				session.push(job)
+			}
+
+		case <-ticker.C:
+			s.expireWorkers()
+			s.reportHashrate()
+
+		case <-s.workSub.Err():
+			return
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// reportHashrate submits the estimated hashrate of every active worker to the
+// remote agent, making it part of the miner's total hashrate.
+func (s *StratumServer) reportHashrate() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	for name, worker := range s.workers {
+		if rate := worker.hashrate(now); rate > 0 {
+			s.agent.SubmitHashrate(crypto.Keccak256Hash([]byte("stratum"), []byte(name)), rate)
+		}
+	}
+}
+
+// expireWorkers drops the accounting of the workers which didn't submit any
+// share for a while, bounding the set of tracked workers to the active ones.
+func (s *StratumServer) expireWorkers() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := time.Now()
+	for name, worker := range s.workers {
+		if now.Sub(worker.active) > stratumWorkerExpiry {
+			delete(s.workers, name)
+		}
+	}
+}
+
+// newSession creates the state of a new miner connection and registers it,
+// allocating an extranonce no other connection uses. The extranonces of closed
+// connections are recycled, new connections are refused while all are in use.
+func (s *StratumServer) newSession(conn net.Conn) (*stratumSession, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id := s.nextID
+	if size := s.config.ExtranonceSize; size > 0 {
+		space := uint64(1) << uint(8*size)
+		if uint64(len(s.extranonce)) >= space {
+			return nil, errStratumNoExtranonce
+		}
+		for {
+			id = s.nextID % space
+			s.nextID = id + 1
+			if _, used := s.extranonce[id]; !used {
+				break
+			}
+		}
+		s.extranonce[id] = struct{}{}
+	} else {
+		s.nextID++ // No partitioning, only an identifier
+	}
+	var blob [8]byte
+	binary.BigEndian.PutUint64(blob[:], id)
+
+	session := &stratumSession{
+		server:     s,
+		conn:       conn,
+		id:         id,
+		extranonce: common.CopyBytes(blob[8-s.config.ExtranonceSize:]),
+		difficulty: new(big.Int).SetUint64(s.config.Difficulty),
+		authorized: make(map[string]struct{}),
+		pending:    make(chan *stratumJob, 1),
+		closed:     make(chan struct{}),
+	}
+	s.sessions[session] = struct{}{}
+	return session, nil
+}
+
+// submit verifies a share of an authorized worker, accounting for it and
+// submitting the block if the share solves it. Workers are only tracked once
+// they submit a share for a known job.
+func (s *StratumServer) submit(worker string, hash common.Hash, nonce types.BlockNonce, difficulty *big.Int) *stratumError {
+	s.lock.Lock()
+	now := time.Now()
+	stats := s.workers[worker]
+
+	job := s.jobs[hash]
+	if job == nil {
+		if stats != nil {
+			stats.stats.Stale++
+			stats.active = now
+		}
+		s.lock.Unlock()
+		return &stratumError{stratumErrStaleJob, "Job not found"}
+	}
+	if stats == nil {
+		stats = &stratumWorker{first: now}
+		s.workers[worker] = stats
+	}
+	stats.active = now
+
+	if _, ok := job.shares[nonce]; ok {
+		stats.stats.Rejected++
+		s.lock.Unlock()
+		return &stratumError{stratumErrDuplicateShare, "Duplicate share"}
+	}
+	if len(job.shares) >= stratumMaxJobShares {
+		stats.stats.Rejected++
+		s.lock.Unlock()
+		return &stratumError{stratumErrOther, "Share limit of job reached"}
+	}
+	job.shares[nonce] = struct{}{}
+	s.lock.Unlock()
+
+	header := s.agent.pendingHeader(hash)
+	if header == nil {
+		s.lock.Lock()
+		stats.stats.Stale++
+		s.lock.Unlock()
+		return &stratumError{stratumErrStaleJob, "Job expired"}
+	}
+	if difficulty.Cmp(header.Difficulty) > 0 {
+		difficulty = header.Difficulty
+	}
+	digest, solved, err := s.verifier.VerifyShare(header, nonce, difficulty)
+	if err != nil {
+		s.lock.Lock()
+		stats.stats.Rejected++
+		s.lock.Unlock()
+
+		log.Debug("Stratum share rejected", "worker", worker, "hash", hash, "err", err)
+		if err == okcash.ErrLowShareDifficulty {
+			return &stratumError{stratumErrLowDifficulty, "Low difficulty share"}
+		}
+		return &stratumError{stratumErrOther, fmt.Sprintf("Invalid share: %v", err)}
+	}
+	if solved {
+		if !s.agent.SubmitWork(nonce, digest, hash) {
+			log.Warn("Stratum block solution rejected", "worker", worker, "hash", hash)
+			solved = false
+		} else {
+			log.Info("Stratum worker sealed new block", "worker", worker, "number", header.Number, "hash", hash)
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now = time.Now()
+	stats.stats.Accepted++
+	stats.stats.LastShare = now
+	if solved {
+		stats.stats.Blocks++
+	}
+	stats.shares = append(stats.shares, stratumShare{time: now, difficulty: difficulty})
+	return nil
+}
+
+// stratumRequest is a message received from a miner.
+type stratumRequest struct {
+	Id     *json.RawMessage  `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// stratumResponse is the reply to a miner's request.
+type stratumResponse struct {
+	Id     *json.RawMessage `json:"id"`
+	Result interface{}      `json:"result"`
+	Error  interface{}      `json:"error"`
+}
+
+// stratumNotification is a message pushed to a miner.
+type stratumNotification struct {
+	Id     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params interface{}      `json:"params"`
+}
+
+// stratumError is a request failure, encoded as [code, message, traceback].
+type stratumError struct {
+	code    int
+	message string
+}
+
+func (err *stratumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{err.code, err.message, nil})
+}
+
+// stratumSession is a single miner connection.
+type stratumSession struct {
+	server *StratumServer
+	conn   net.Conn
+
+	id         uint64
+	extranonce []byte
+	difficulty *big.Int
+	subscribed bool
+	authorized map[string]struct{}
+
+	lock    sync.Mutex // Protects the fields above, modified by the session's request loop
+	writeMu sync.Mutex // Serialises writes of responses and pushed notifications
+
+	pending chan *stratumJob // Latest job not yet pushed to the miner
+	closed  chan struct{}    // Closed when the request loop terminates
+}
+
+// serve processes the requests of the miner until the connection drops.
+func (ss *stratumSession) serve() {
+	defer close(ss.closed)
+	defer ss.conn.Close()
+
+	scanner := bufio.NewScanner(ss.conn)
+	scanner.Buffer(make([]byte, 0, 1024), stratumMaxLineSize)
+
+	for {
+		ss.conn.SetReadDeadline(time.Now().Add(stratumReadTimeout))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				log.Debug("Stratum connection failed", "remote", ss.conn.RemoteAddr(), "err", err)
+			}
+			return
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var req stratumRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			log.Debug("Stratum request malformed", "remote", ss.conn.RemoteAddr(), "err", err)
+			return
+		}
+		if err := ss.handle(&req); err != nil {
+			log.Debug("Stratum connection failed", "remote", ss.conn.RemoteAddr(), "err", err)
+			return
+		}
+	}
+}
+
+// handle processes a single request of the miner.
+func (ss *stratumSession) handle(req *stratumRequest) error {
+	switch req.Method {
+	case "mining.subscribe":
+		ss.lock.Lock()
+		ss.subscribed = true
+		ss.lock.Unlock()
+
+		result := []interface{}{
+			[]string{"mining.notify", fmt.Sprintf("%x", ss.id), stratumProtocol},
+			common.Bytes2Hex(ss.extranonce),
+		}
+		if err := ss.reply(req.Id, result, nil); err != nil {
+			return err
+		}
+		if err := ss.sendDifficulty(); err != nil {
+			return err
+		}
+		ss.server.lock.Lock()
+		job := ss.server.current
+		ss.server.lock.Unlock()
+
+		if job != nil {
+			return ss.notify(job, true)
+		}
+		return nil
+
+	case "mining.extranonce.subscribe":
+		// The extranonce of a session never changes, nothing to do
+		return ss.reply(req.Id, true, nil)
+
+	case "mining.authorize":
+		var worker string
+		if len(req.Params) > 0 {
+			json.Unmarshal(req.Params[0], &worker)
+		}
+		if worker == "" {
+			return ss.reply(req.Id, false, &stratumError{stratumErrUnauthorized, "Missing worker name"})
+		}
+		ss.lock.Lock()
+		_, known := ss.authorized[worker]
+		if !known && len(ss.authorized) >= stratumMaxWorkers {
+			ss.lock.Unlock()
+			return ss.reply(req.Id, false, &stratumError{stratumErrUnauthorized, "Too many workers"})
+		}
+		ss.authorized[worker] = struct{}{}
+		ss.lock.Unlock()
+
+		return ss.reply(req.Id, true, nil)
+
+	case "mining.suggest_difficulty":
+		var difficulty uint64
+		if len(req.Params) == 0 || json.Unmarshal(req.Params[0], &difficulty) != nil || difficulty == 0 {
+			return ss.reply(req.Id, false, &stratumError{stratumErrOther, "Invalid difficulty"})
+		}
+		// Cheap shares would let a miner flood the server with verifications
+		if min := ss.server.config.MinDifficulty; difficulty < min {
+			difficulty = min
+		}
+		ss.lock.Lock()
+		ss.difficulty = new(big.Int).SetUint64(difficulty)
+		ss.lock.Unlock()
+
+		if err := ss.reply(req.Id, true, nil); err != nil {
+			return err
+		}
+		return ss.sendDifficulty()
+
+	case "mining.submit":
+		if err := ss.submit(req.Params); err != nil {
+			return ss.reply(req.Id, false, err)
+		}
+		return ss.reply(req.Id, true, nil)
+
+	default:
+		return ss.reply(req.Id, nil, &stratumError{stratumErrOther, fmt.Sprintf("Unknown method %q", req.Method)})
+	}
+}
+
+// submit validates and processes a mining.submit request of [worker, job, nonce].
+func (ss *stratumSession) submit(params []json.RawMessage) *stratumError {
+	var worker, job, suffix string
+	if len(params) < 3 || json.Unmarshal(params[0], &worker) != nil || json.Unmarshal(params[1], &job) != nil || json.Unmarshal(params[2], &suffix) != nil {
+		return &stratumError{stratumErrOther, "Malformed submission"}
+	}
+	ss.lock.Lock()
+	_, authorized := ss.authorized[worker]
+	subscribed := ss.subscribed
+	difficulty := new(big.Int).Set(ss.difficulty)
+	ss.lock.Unlock()
+
+	if !subscribed {
+		return &stratumError{stratumErrNotSubscribed, "Not subscribed"}
+	}
+	if !authorized {
+		return &stratumError{stratumErrUnauthorized, "Unauthorized worker"}
+	}
+	blob := common.FromHex(suffix)
+	if len(ss.extranonce)+len(blob) != len(types.BlockNonce{}) {
+		return &stratumError{stratumErrOther, "Malformed nonce"}
+	}
+	var nonce types.BlockNonce
+	copy(nonce[:], ss.extranonce)
+	copy(nonce[len(ss.extranonce):], blob)
+
+	return ss.server.submit(worker, common.HexToHash(job), nonce, difficulty)
+}
+
+// sendDifficulty pushes the share difficulty of the session to the miner.
+func (ss *stratumSession) sendDifficulty() error {
+	ss.lock.Lock()
+	difficulty := ss.difficulty.Uint64()
+	ss.lock.Unlock()
+
+	return ss.write(&stratumNotification{Method: "mining.set_difficulty", Params: []uint64{difficulty}})
+}
+
+// push queues a job for the miner, replacing the previous one if it has not
+// been delivered yet. It never blocks, the job is written by pushLoop.
+func (ss *stratumSession) push(job *stratumJob) {
+	for {
+		select {
+		case ss.pending <- job:
+			return
+		default:
+		}
+		select {
+		case <-ss.pending:
+		default:
+		}
+	}
+}
+
+// pushLoop writes the queued jobs to the miner until the session terminates,
+// dropping the connection if a write fails.
+func (ss *stratumSession) pushLoop() {
+	for {
+		select {
+		case job := <-ss.pending:
+			if err := ss.notify(job, true); err != nil {
+				log.Debug("Stratum job push failed", "remote", ss.conn.RemoteAddr(), "err", err)
+				ss.conn.Close()
+				return
+			}
+		case <-ss.closed:
+			return
+		}
+	}
+}
+
+// notify pushes a job to the miner, if it already subscribed.
+func (ss *stratumSession) notify(job *stratumJob, clean bool) error {
+	ss.lock.Lock()
+	subscribed := ss.subscribed
+	ss.lock.Unlock()
+
+	if !subscribed {
+		return nil
+	}
+	params := []interface{}{
+		common.Bytes2Hex(job.pkg.Hash[:]),
+		common.Bytes2Hex(job.pkg.Seed[:]),
+		common.Bytes2Hex(job.pkg.Hash[:]),
+		clean,
+	}
+	return ss.write(&stratumNotification{Method: "mining.notify", Params: params})
+}
+
+// reply sends the response of a request to the miner.
+func (ss *stratumSession) reply(id *json.RawMessage, result interface{}, err *stratumError) error {
+	res := &stratumResponse{Id: id, Result: result}
+	if err != nil {
+		res.Error = err
+	}
+	return ss.write(res)
+}
+
+// write sends a single message line to the miner.
+func (ss *stratumSession) write(msg interface{}) error {
+	blob, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	ss.writeMu.Lock()
+	defer ss.writeMu.Unlock()
+
+	ss.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+	_, err = ss.conn.Write(append(blob, '\n'))
+	return err
+}
@@ -0,0 +1,390 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// stratumTestClient is a minimal Stratum miner.
+type stratumTestClient struct {
+	t       *testing.T
+	conn    net.Conn
+	scanner *bufio.Scanner
+	nextId  int
+	pushed  []*stratumTestMessage
+}
+
+// stratumTestMessage is any message received from the server.
+type stratumTestMessage struct {
+	Id     *int              `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result"`
+	Error  []interface{}     `json:"error"`
+}
+
+func newStratumTestClient(t *testing.T, server *StratumServer) *stratumTestClient {
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to stratum server: %v", err)
+	}
+	return &stratumTestClient{t: t, conn: conn, scanner: bufio.NewScanner(conn)}
+}
+
+// read waits for the next message from the server.
+func (c *stratumTestClient) read() *stratumTestMessage {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if !c.scanner.Scan() {
+		c.t.Fatalf("failed to read stratum message: %v", c.scanner.Err())
+	}
+	msg := new(stratumTestMessage)
+	if err := json.Unmarshal(c.scanner.Bytes(), msg); err != nil {
+		c.t.Fatalf("failed to decode stratum message %s: %v", c.scanner.Bytes(), err)
+	}
+	return msg
+}
+
+// call issues a request and waits for its response, collecting the pushed
+// notifications received in between.
+func (c *stratumTestClient) call(method string, params ...interface{}) *stratumTestMessage {
+	c.nextId++
+	blob, _ := json.Marshal(map[string]interface{}{"id": c.nextId, "method": method, "params": params})
+	if _, err := c.conn.Write(append(blob, '\n')); err != nil {
+		c.t.Fatalf("failed to send stratum request: %v", err)
+	}
+	for {
+		msg := c.read()
+		if msg.Id == nil {
+			c.pushed = append(c.pushed, msg)
+			continue
+		}
+		if *msg.Id != c.nextId {
+			c.t.Fatalf("response id mismatch: have %d, want %d", *msg.Id, c.nextId)
+		}
+		return msg
+	}
+}
+
+// notification waits for the next pushed message of the given method.
+func (c *stratumTestClient) notification(method string) *stratumTestMessage {
+	for {
+		var msg *stratumTestMessage
+		if len(c.pushed) > 0 {
+			msg, c.pushed = c.pushed[0], c.pushed[1:]
+		} else {
+			msg = c.read()
+		}
+		if msg.Method == method {
+			return msg
+		}
+	}
+}
+
+// subscribe subscribes and authorizes the client, returning its extranonce and
+// the first job pushed by the server.
+func (c *stratumTestClient) subscribe(worker string) ([]byte, string) {
+	res := c.call("mining.subscribe", "tester", stratumProtocol)
+	if res.Error != nil {
+		c.t.Fatalf("failed to subscribe: %v", res.Error)
+	}
+	var result []json.RawMessage
+	if err := json.Unmarshal(res.Result, &result); err != nil || len(result) != 2 {
+		c.t.Fatalf("invalid subscription result: %s", res.Result)
+	}
+	var extranonce string
+	json.Unmarshal(result[1], &extranonce)
+
+	if res := c.call("mining.authorize", worker, "x"); string(res.Result) != "true" {
+		c.t.Fatalf("failed to authorize: %v", res.Error)
+	}
+	var job string
+	json.Unmarshal(c.notification("mining.notify").Params[0], &job)
+
+	return common.FromHex(extranonce), job
+}
+
+// expectError checks that a response failed with the given stratum error code.
+func (c *stratumTestClient) expectError(res *stratumTestMessage, code int) {
+	c.t.Helper()
+	if res.Error == nil {
+		c.t.Errorf("expected error %d, got success", code)
+		return
+	}
+	if have, _ := res.Error[0].(float64); int(have) != code {
+		c.t.Errorf("error code mismatch: have %v, want %d", res.Error, code)
+	}
+}
+
+// newStratumTester creates a mining worker with a registered remote agent and a
+// Stratum server in front of it, returning them after the first work is pushed.
+func newStratumTester(t *testing.T, config StratumConfig) (*testBackend, *worker, *RemoteAgent, *StratumServer) {
+	engine := okcash.NewTester()
+	backend := newTestBackend(t, engine, params.GenesisGasLimit)
+
+	w := newWorker(params.TestChainConfig, engine, common.Address{1}, backend, new(event.TypeMux))
+	agent := NewRemoteAgent(backend.chain, engine)
+	w.register(agent)
+
+	config.Addr = "127.0.0.1:0"
+	server, err := NewStratumServer(config, agent, engine)
+	if err != nil {
+		t.Fatalf("failed to create stratum server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start stratum server: %v", err)
+	}
+	w.start()
+	w.commitNewWork()
+
+	return backend, w, agent, server
+}
+
+// Tests the share submission flows of the Stratum server: accepted shares,
+// duplicates, stale jobs, unauthorized workers and low difficulty shares.
+func TestStratumShares(t *testing.T) {
+	backend, w, agent, server := newStratumTester(t, StratumConfig{Difficulty: 1, MinDifficulty: 1, ExtranonceSize: 2})
+	defer backend.chain.Stop()
+	defer w.stop()
+	defer server.Stop()
+
+	// Connect two miners and ensure their search spaces are split
+	alice, bob := newStratumTestClient(t, server), newStratumTestClient(t, server)
+	aliceExtra, job := alice.subscribe("alice")
+	bobExtra, _ := bob.subscribe("bob")
+
+	if len(aliceExtra) != 2 || common.Bytes2Hex(aliceExtra) == common.Bytes2Hex(bobExtra) {
+		t.Fatalf("invalid extranonce partitioning: %x, %x", aliceExtra, bobExtra)
+	}
+	// A share at difficulty one is always valid, but only once
+	if res := alice.call("mining.submit", "alice", job, "000000000001"); string(res.Result) != "true" {
+		t.Fatalf("valid share rejected: %v", res.Error)
+	}
+	alice.expectError(alice.call("mining.submit", "alice", job, "000000000001"), stratumErrDuplicateShare)
+
+	// Other invalid submissions
+	alice.expectError(alice.call("mining.submit", "alice", common.Bytes2Hex(make([]byte, 32)), "000000000002"), stratumErrStaleJob)
+	alice.expectError(alice.call("mining.submit", "bob", job, "000000000003"), stratumErrUnauthorized)
+	alice.expectError(alice.call("mining.submit", "alice", job, "0004"), stratumErrOther)
+
+	// Raise the share difficulty beyond reach and ensure shares are rejected
+	if res := bob.call("mining.suggest_difficulty", uint64(1)<<62); string(res.Result) != "true" {
+		t.Fatalf("failed to change difficulty: %v", res.Error)
+	}
+	var difficulty uint64
+	json.Unmarshal(bob.notification("mining.set_difficulty").Params[0], &difficulty)
+	if difficulty != 1<<62 {
+		t.Errorf("pushed difficulty mismatch: have %d, want %d", difficulty, uint64(1)<<62)
+	}
+	// The block difficulty caps the share one, so this may only fail by
+	// chance of one in the block difficulty
+	bob.expectError(bob.call("mining.submit", "bob", job, "000000000005"), stratumErrLowDifficulty)
+
+	// Check the accounting and that the hashrate is reported to the miner
+	stats := server.Workers()
+	if have := stats["alice"]; have.Accepted != 1 || have.Rejected != 1 || have.Stale != 1 {
+		t.Errorf("alice stats mismatch: have %+v", have)
+	}
+	if have := stats["bob"]; have.Accepted != 0 || have.Rejected != 1 {
+		t.Errorf("bob stats mismatch: have %+v", have)
+	}
+	server.reportHashrate()
+	if rate := agent.GetHashRate(); rate == 0 {
+		t.Errorf("stratum hashrate not reported")
+	}
+}
+
+// Tests that miners can't lower the share difficulty below the configured
+// minimum and that the shares verified per job are capped.
+func TestStratumShareLimits(t *testing.T) {
+	backend, w, _, server := newStratumTester(t, StratumConfig{Difficulty: 1, MinDifficulty: 1 << 10})
+	defer backend.chain.Stop()
+	defer w.stop()
+	defer server.Stop()
+
+	miner := newStratumTestClient(t, server)
+	_, job := miner.subscribe("rig")
+
+	// The initial difficulty is raised to the minimum, suggestions are clamped
+	if res := miner.call("mining.suggest_difficulty", uint64(1)); string(res.Result) != "true" {
+		t.Fatalf("failed to change difficulty: %v", res.Error)
+	}
+	var difficulty uint64
+	json.Unmarshal(miner.notification("mining.set_difficulty").Params[0], &difficulty)
+	if difficulty != 1<<10 {
+		t.Errorf("pushed difficulty mismatch: have %d, want %d", difficulty, 1<<10)
+	}
+	// Fill up the shares of the job and ensure further ones are refused
+	server.lock.Lock()
+	shares := server.jobs[common.HexToHash(job)].shares
+	for i := 0; i < stratumMaxJobShares; i++ {
+		shares[types.EncodeNonce(uint64(1)<<63|uint64(i))] = struct{}{}
+	}
+	server.lock.Unlock()
+
+	miner.expectError(miner.call("mining.submit", "rig", job, "0000000000000001"), stratumErrOther)
+	if have := server.Workers()["rig"]; have.Rejected != 1 {
+		t.Errorf("rig stats mismatch: have %+v", have)
+	}
+}
+
+// Tests that new work is pushed to the miners and that block solutions found
+// by them are sealed into the chain.
+func TestStratumBlockSubmission(t *testing.T) {
+	backend, w, agent, server := newStratumTester(t, StratumConfig{Difficulty: 1, MinDifficulty: 1})
+	defer backend.chain.Stop()
+	defer w.stop()
+	defer server.Stop()
+
+	miner := newStratumTestClient(t, server)
+	extranonce, job := miner.subscribe("rig")
+	if len(extranonce) != 0 {
+		t.Fatalf("unexpected extranonce: %x", extranonce)
+	}
+	// Recommitting the work must push a fresh job
+	w.commitNewWork()
+	json.Unmarshal(miner.notification("mining.notify").Params[0], &job)
+
+	// Solve the job locally and submit the solution
+	header := agent.pendingHeader(common.HexToHash(job))
+	if header == nil {
+		t.Fatalf("pushed job %s unknown to the agent", job)
+	}
+	sealed, err := okcash.NewTester().Seal(nil, types.NewBlockWithHeader(header), nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	nonce := types.EncodeNonce(sealed.Nonce())
+	if res := miner.call("mining.submit", "rig", job, common.Bytes2Hex(nonce[:])); string(res.Result) != "true" {
+		t.Fatalf("block solution rejected: %v", res.Error)
+	}
+	for i := 0; i < 50 && backend.chain.CurrentBlock().NumberU64() == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	if head := backend.chain.CurrentBlock(); head.NumberU64() != 1 || head.Nonce() != sealed.Nonce() {
+		t.Fatalf("solution not sealed: head #%d nonce %x", head.NumberU64(), head.Nonce())
+	}
+	if stats := server.Workers()["rig"]; stats.Blocks != 1 {
+		t.Errorf("sealed block count mismatch: have %d, want 1", stats.Blocks)
+	}
+}
+
+// Tests that only authorized workers submitting shares of known jobs are tracked,
+// that the workers of a connection are capped and that idle ones expire.
+func TestStratumWorkerAccounting(t *testing.T) {
+	backend, w, _, server := newStratumTester(t, StratumConfig{Difficulty: 1, MinDifficulty: 1, ExtranonceSize: 1})
+	defer backend.chain.Stop()
+	defer w.stop()
+	defer server.Stop()
+
+	miner := newStratumTestClient(t, server)
+	_, job := miner.subscribe("rig")
+
+	// Submissions for unknown jobs must not create any worker
+	for i := 0; i < 8; i++ {
+		if res := miner.call("mining.authorize", fmt.Sprintf("ghost%d", i), "x"); string(res.Result) != "true" {
+			t.Fatalf("failed to authorize worker %d: %v", i, res.Error)
+		}
+		miner.expectError(miner.call("mining.submit", fmt.Sprintf("ghost%d", i), common.Bytes2Hex(make([]byte, 32)), "00000000000001"), stratumErrStaleJob)
+	}
+	if workers := server.Workers(); len(workers) != 0 {
+		t.Errorf("workers tracked for unknown jobs: %v", workers)
+	}
+	// The number of workers per connection is capped
+	for i := 9; i < stratumMaxWorkers; i++ {
+		if res := miner.call("mining.authorize", fmt.Sprintf("ghost%d", i), "x"); string(res.Result) != "true" {
+			t.Fatalf("failed to authorize worker %d: %v", i, res.Error)
+		}
+	}
+	miner.expectError(miner.call("mining.authorize", "overflow", "x"), stratumErrUnauthorized)
+	if res := miner.call("mining.authorize", "rig", "x"); string(res.Result) != "true" {
+		t.Errorf("failed to reauthorize worker: %v", res.Error)
+	}
+	// Valid shares are accounted, until the worker stays idle for too long
+	if res := miner.call("mining.submit", "rig", job, "00000000000001"); string(res.Result) != "true" {
+		t.Fatalf("valid share rejected: %v", res.Error)
+	}
+	if _, ok := server.Workers()["rig"]; !ok {
+		t.Fatalf("worker not tracked")
+	}
+	server.expireWorkers()
+	if _, ok := server.Workers()["rig"]; !ok {
+		t.Fatalf("active worker expired")
+	}
+	server.lock.Lock()
+	server.workers["rig"].active = time.Now().Add(-stratumWorkerExpiry - time.Second)
+	server.lock.Unlock()
+
+	server.expireWorkers()
+	if workers := server.Workers(); len(workers) != 0 {
+		t.Errorf("idle worker not expired: %v", workers)
+	}
+}
+
+// Tests that extranonces are unique among the connected miners, recycled once
+// their connection closes, and that miners are refused when all are in use.
+func TestStratumExtranonceAllocation(t *testing.T) {
+	backend, w, _, server := newStratumTester(t, StratumConfig{Difficulty: 1, MinDifficulty: 1, ExtranonceSize: 1})
+	defer backend.chain.Stop()
+	defer w.stop()
+	defer server.Stop()
+
+	// Occupy all but one extranonce
+	server.lock.Lock()
+	for id := uint64(0); id < 255; id++ {
+		server.extranonce[id] = struct{}{}
+	}
+	server.lock.Unlock()
+
+	miner := newStratumTestClient(t, server)
+	extranonce, _ := miner.subscribe("rig")
+	if len(extranonce) != 1 || extranonce[0] != 255 {
+		t.Fatalf("allocated extranonce mismatch: have %x, want ff", extranonce)
+	}
+	// With the space exhausted, new miners must be refused
+	refused := newStratumTestClient(t, server)
+	refused.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if refused.scanner.Scan() || refused.scanner.Err() != nil {
+		t.Fatalf("connection not closed while extranonces are exhausted: %v", refused.scanner.Err())
+	}
+	// Closing a connection must release its extranonce for the next miner
+	miner.conn.Close()
+	for i := 0; i < 50; i++ {
+		server.lock.Lock()
+		_, used := server.extranonce[255]
+		server.lock.Unlock()
+		if !used {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	next := newStratumTestClient(t, server)
+	if extranonce, _ := next.subscribe("rig"); len(extranonce) != 1 || extranonce[0] != 255 {
+		t.Fatalf("recycled extranonce mismatch: have %x, want ff", extranonce)
+	}
+}
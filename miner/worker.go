@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/math"
 	"github.com/okcoin/go-okcoin/consensus"
 	"github.com/okcoin/go-okcoin/consensus/misc"
 	"github.com/okcoin/go-okcoin/core"
@@ -49,6 +50,13 @@ const (
 	chainHeadChanSize = 10
 	// chainSideChanSize is the size of channel listening to ChainSideEvent.
 	chainSideChanSize = 10
+
+	// minRecommitInterval is the minimal time interval to recreate the mining
+	// block with any newly arrived transactions.
+	minRecommitInterval = 1 * time.Second
+	// maxRecommitInterval is the maximum time interval the recommit interval
+	// backs off to while recommits keep yielding no extra fees.
+	maxRecommitInterval = 15 * time.Second
 )
 
 // Agent can register themself with the worker
@@ -71,6 +79,7 @@ type Work struct {
 	family    *set.Set       // family set (used for checking uncle invalidity)
 	uncles    *set.Set       // uncle set
 	tcount    int            // tx count in cycle
+	fees      *big.Int       // total fees paid by the included transactions
 
 	Block *types.Block // the new block
 
@@ -113,6 +122,13 @@ type worker struct {
 
 	coinbase common.Address
 	extra    []byte
+	ordering TxOrdering  // strategy ordering the pending transactions into blocks
+	arrivals *txArrivals // local arrival times of pending transactions
+	gasFloor uint64      // target gas limit the block gas limit is raised towards
+	gasCeil  uint64      // maximum gas limit the block gas limit is lowered towards
+
+	recommit   time.Duration // minimum interval between recommits of the mining block
+	recommitCh chan struct{} // notification channel of recommit interval changes
 
 	currentMu sync.Mutex
 	current   *Work
@@ -142,6 +158,10 @@ func newWorker(config *params.ChainConfig, engine consensus.Engine, coinbase com
 		proc:           okc.BlockChain().Validator(),
 		possibleUncles: make(map[common.Hash]*types.Block),
 		coinbase:       coinbase,
+		ordering:       priceOrdering{},
+		arrivals:       newTxArrivals(),
+		recommit:       minRecommitInterval,
+		recommitCh:     make(chan struct{}, 1),
 		agents:         make(map[Agent]struct{}),
 		unconfirmed:    newUnconfirmedBlocks(okc.BlockChain(), miningLogAtDepth),
 	}
@@ -170,6 +190,106 @@ func (self *worker) setExtra(extra []byte) {
 	self.extra = extra
 }
 
+func (self *worker) setOrdering(ordering TxOrdering) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.ordering = ordering
+}
+
+func (self *worker) txOrdering() TxOrdering {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.ordering
+}
+
+// setGasFloor sets the target gas limit the miner raises the block gas limit
+// towards. Zero means the network default target.
+func (self *worker) setGasFloor(floor uint64) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.gasFloor = floor
+}
+
+// setGasCeil sets the gas limit above which the miner lowers the block gas
+// limit. Zero means no upper bound.
+func (self *worker) setGasCeil(ceil uint64) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.gasCeil = ceil
+}
+
+// gasBounds returns the effective gas limit range targeted by the miner.
+func (self *worker) gasBounds() (uint64, uint64) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.gasBoundsLocked()
+}
+
+// gasBoundsLocked is gasBounds with the worker lock already held.
+func (self *worker) gasBoundsLocked() (uint64, uint64) {
+	floor, ceil := self.gasFloor, self.gasCeil
+	if floor == 0 {
+		floor = params.TargetGasLimit
+	}
+	if ceil == 0 {
+		ceil = math.MaxUint64
+	}
+	return floor, ceil
+}
+
+// setRecommitInterval sets the minimum interval between recreations of the
+// mining block, clamped to a sane minimum.
+func (self *worker) setRecommitInterval(interval time.Duration) {
+	if interval < minRecommitInterval {
+		log.Warn("Sanitizing miner recommit interval", "provided", interval, "updated", minRecommitInterval)
+		interval = minRecommitInterval
+	}
+	self.mu.Lock()
+	self.recommit = interval
+	self.mu.Unlock()
+
+	select {
+	case self.recommitCh <- struct{}{}:
+	default:
+	}
+}
+
+// recommitInterval returns the minimum interval between recommits.
+func (self *worker) recommitInterval() time.Duration {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.recommit
+}
+
+// currentFees returns the parent and the total fees of the current mining block.
+func (self *worker) currentFees() (common.Hash, *big.Int) {
+	self.currentMu.Lock()
+	defer self.currentMu.Unlock()
+	return self.current.header.ParentHash, self.current.fees
+}
+
+// recalcRecommit calculates the next recommit interval. Recommits yielding more
+// fees move the interval halfway back to the minimum, fruitless ones make it
+// back off by a quarter up to maxRecommitInterval (or the minimum if larger).
+func recalcRecommit(current, minimum time.Duration, improved bool) time.Duration {
+	if improved {
+		current -= (current - minimum) / 2
+	} else {
+		current += current / 4
+	}
+	limit := maxRecommitInterval
+	if minimum > limit {
+		limit = minimum
+	}
+	if current > limit {
+		current = limit
+	}
+	if current < minimum {
+		current = minimum
+	}
+	return current
+}
+
 func (self *worker) pending() (*types.Block, *state.StateDB) {
 	self.currentMu.Lock()
 	defer self.currentMu.Unlock()
@@ -245,12 +365,36 @@ func (self *worker) update() {
 	defer self.chainHeadSub.Unsubscribe()
 	defer self.chainSideSub.Unsubscribe()
 
+	minRecommit := self.recommitInterval()
+	recommit := minRecommit
+
+	timer := time.NewTimer(recommit)
+	defer timer.Stop()
+
 	for {
 		// A real event arrived, process interesting content
 		select {
 		// Handle ChainHeadEvent
 		case <-self.chainHeadCh:
 			self.commitNewWork()
+			resetTimer(timer, recommit)
+
+		// Recreate the mining block to pick up better paying transactions
+		case <-timer.C:
+			if atomic.LoadInt32(&self.mining) == 1 && !(self.config.Clique != nil && self.config.Clique.Period == 0) {
+				parent, fees := self.currentFees()
+				self.commitNewWork()
+
+				newParent, newFees := self.currentFees()
+				recommit = recalcRecommit(recommit, minRecommit, newParent == parent && newFees.Cmp(fees) > 0)
+			}
+			timer.Reset(recommit)
+
+		// Handle recommit interval changes
+		case <-self.recommitCh:
+			minRecommit = self.recommitInterval()
+			recommit = minRecommit
+			resetTimer(timer, recommit)
 
 		// Handle ChainSideEvent
 		case ev := <-self.chainSideCh:
@@ -260,12 +404,16 @@ func (self *worker) update() {
 
 		// Handle TxPreEvent
 		case ev := <-self.txCh:
+			self.arrivals.add(ev.Tx.Hash(), time.Now())
+
 			// Apply transaction to the pending state if we're not mining
 			if atomic.LoadInt32(&self.mining) == 0 {
+				ordering := self.txOrdering()
+
 				self.currentMu.Lock()
 				acc, _ := types.Sender(self.current.signer, ev.Tx)
 				txs := map[common.Address]types.Transactions{acc: {ev.Tx}}
-				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs)
+				txset := ordering.Order(self.current.orderingEnv(self.arrivals), txs)
 
 				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase)
 				self.currentMu.Unlock()
@@ -287,6 +435,17 @@ func (self *worker) update() {
 	}
 }
 
+// resetTimer restarts a possibly running or expired timer with a new duration.
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(d)
+}
+
 func (self *worker) wait() {
 	for {
 		mustCommitNewWork := true
@@ -368,6 +527,7 @@ func (self *worker) makeCurrent(parent *types.Block, header *types.Header) error
 		family:    set.New(),
 		uncles:    set.New(),
 		header:    header,
+		fees:      new(big.Int),
 		createdAt: time.Now(),
 	}
 
@@ -409,10 +569,11 @@ func (self *worker) commitNewWork() {
 	}
 
 	num := parent.Number()
+	gasFloor, gasCeil := self.gasBoundsLocked()
 	header := &types.Header{
 		ParentHash: parent.Hash(),
 		Number:     num.Add(num, common.Big1),
-		GasLimit:   core.CalcGasLimit(parent),
+		GasLimit:   core.CalcGasLimit(parent, gasFloor, gasCeil),
 		Extra:      self.extra,
 		Time:       big.NewInt(tstamp),
 	}
@@ -453,7 +614,9 @@ func (self *worker) commitNewWork() {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
-	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
+	self.arrivals.retain(pending)
+
+	txs := self.ordering.Order(work.orderingEnv(self.arrivals), pending)
 	work.commitTransactions(self.mux, txs, self.chain, self.coinbase)
 
 	// compute uncles for the new block.
@@ -506,7 +669,16 @@ func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
 	return nil
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
+// orderingEnv creates the context for ordering transactions into this work.
+func (env *Work) orderingEnv(arrivals *txArrivals) *OrderingEnv {
+	return &OrderingEnv{
+		Signer:   env.signer,
+		Header:   env.header,
+		arrivals: arrivals,
+	}
+}
+
+func (env *Work) commitTransactions(mux *event.TypeMux, txs TransactionSet, bc *core.BlockChain, coinbase common.Address) {
 	gp := new(core.GasPool).AddGas(env.header.GasLimit)
 
 	var coalescedLogs []*types.Log
@@ -600,5 +772,8 @@ func (env *Work) commitTransaction(tx *types.Transaction, bc *core.BlockChain, c
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
 
+	fee := new(big.Int).SetUint64(receipt.GasUsed)
+	env.fees.Add(env.fees, fee.Mul(fee, tx.GasPrice()))
+
 	return nil, receipt.Logs
 }
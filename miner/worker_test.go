@@ -0,0 +1,76 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/event"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// Tests that the recommit interval shrinks towards the minimum while recommits
+// pay off and backs off up to the maximum while they don't.
+func TestRecalcRecommit(t *testing.T) {
+	tests := []struct {
+		current  time.Duration
+		minimum  time.Duration
+		improved bool
+		want     time.Duration
+	}{
+		{4 * time.Second, 2 * time.Second, true, 3 * time.Second},
+		{2 * time.Second, 2 * time.Second, true, 2 * time.Second},
+		{4 * time.Second, 2 * time.Second, false, 5 * time.Second},
+		{14 * time.Second, 2 * time.Second, false, maxRecommitInterval},
+		{20 * time.Second, 20 * time.Second, false, 20 * time.Second},
+	}
+	for i, tt := range tests {
+		if have := recalcRecommit(tt.current, tt.minimum, tt.improved); have != tt.want {
+			t.Errorf("test %d: interval mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that a mining worker periodically recreates its block, picking up the
+// transactions arrived meanwhile and honouring the configured gas bounds.
+func TestWorkerRecommit(t *testing.T) {
+	backend := newTestBackend(t, okcash.NewFaker(), params.GenesisGasLimit)
+	defer backend.chain.Stop()
+
+	w := newWorker(params.TestChainConfig, okcash.NewFaker(), common.Address{1}, backend, new(event.TypeMux))
+	w.setGasFloor(2 * params.GenesisGasLimit)
+	w.setRecommitInterval(minRecommitInterval)
+	w.start()
+	defer w.stop()
+
+	// Transactions arriving while mining are not added to the block in flight
+	backend.txPool.AddRemote(newTestTransfer(t, testKeys[0], 0, 1))
+
+	for i := 0; i < 50 && len(w.pendingBlock().Transactions()) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	block := w.pendingBlock()
+	if txs := len(block.Transactions()); txs != 1 {
+		t.Fatalf("recommitted block transaction count mismatch: have %d, want 1", txs)
+	}
+	if block.GasLimit() <= params.GenesisGasLimit {
+		t.Errorf("gas limit not raised towards the target: have %d, parent %d", block.GasLimit(), params.GenesisGasLimit)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/okcoin/go-okcoin/accounts"
 	"github.com/okcoin/go-okcoin/accounts/keystore"
@@ -33,6 +34,7 @@ import (
 	"github.com/okcoin/go-okcoin/log"
 	"github.com/okcoin/go-okcoin/p2p"
 	"github.com/okcoin/go-okcoin/p2p/discover"
+	"github.com/okcoin/go-okcoin/rpc"
 )
 
 const (
@@ -125,7 +127,8 @@ type Config struct {
 
 	// WSPort is the TCP port number on which to start the websocket RPC server. The
 	// default zero value is/ valid and will pick a port number randomly (useful for
-	// ephemeral nodes).
+	// ephemeral nodes). If the host and port match the HTTP ones, websocket requests
+	// are served on the listener of the HTTP RPC server.
 	WSPort int `toml:",omitempty"`
 
 	// WSOrigins is the list of domain to accept websocket requests from. Please be
@@ -133,6 +136,11 @@ type Config struct {
 	// cannot verify the validity of the request header.
 	WSOrigins []string `toml:",omitempty"`
 
+	// WSVirtualHosts is the list of virtual hostnames which are allowed on incoming
+	// websocket requests, checked like HTTPVirtualHosts. If empty, the Host header
+	// of websocket requests is not checked.
+	WSVirtualHosts []string `toml:",omitempty"`
+
 	// WSModules is a list of API modules to expose via the websocket RPC interface.
 	// If the module list is empty, all RPC API endpoints designated public will be
 	// exposed.
@@ -145,6 +153,59 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// GraphQLHost is the host interface on which to start the GraphQL server. If
+	// this field is empty, no GraphQL endpoint will be started.
+	GraphQLHost string `toml:",omitempty"`
+
+	// GraphQLPort is the TCP port number on which to start the GraphQL server. The
+	// default zero value is/ valid and will pick a port number randomly.
+	GraphQLPort int `toml:",omitempty"`
+
+	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
+	// clients of the GraphQL server, like HTTPCors.
+	GraphQLCors []string `toml:",omitempty"`
+
+	// GraphQLVirtualHosts is the list of virtual hostnames which are allowed on
+	// incoming GraphQL requests, checked like HTTPVirtualHosts.
+	GraphQLVirtualHosts []string `toml:",omitempty"`
+
+	// GRPCHost is the host interface on which to start the gRPC server. If this
+	// field is empty, no gRPC endpoint will be started.
+	GRPCHost string `toml:",omitempty"`
+
+	// GRPCPort is the TCP port number on which to start the gRPC server. The
+	// default zero value is valid and will pick a port number randomly.
+	GRPCPort int `toml:",omitempty"`
+
+	// RPCAuthSecret is the file holding the hex encoded shared secret of the HS256
+	// JWT tokens authenticating the clients of the HTTP and websocket endpoints.
+	RPCAuthSecret string `toml:",omitempty"`
+
+	// RPCAuthPolicy is the JSON file holding the access control policy of the RPC
+	// endpoints, granting namespaces and methods to the clients by credentials.
+	// The IPC clients, which carry no credentials, get the "local" permissions of
+	// the policy. If only a secret is set, valid tokens and IPC clients are granted
+	// everything.
+	RPCAuthPolicy string `toml:",omitempty"`
+
+	// RPCRateLimit are the request budgets of the clients of the HTTP and websocket
+	// endpoints, identified by their credentials if authenticated or IP address
+	// otherwise. If nil, clients are not limited.
+	RPCRateLimit *rpc.RateLimitConfig `toml:",omitempty"`
+
+	// RPCMaxRequestSize, RPCMaxBatchItems and RPCMaxResponseSize limit the size of
+	// the requests and responses of the HTTP and websocket endpoints, and of the IPC
+	// one if set explicitly. Zero values select the defaults of the rpc package for
+	// the former and leave the IPC endpoint unlimited, negative values disable the
+	// limit on all of them.
+	RPCMaxRequestSize  int `toml:",omitempty"`
+	RPCMaxBatchItems   int `toml:",omitempty"`
+	RPCMaxResponseSize int `toml:",omitempty"`
+
+	// RPCTimeout is the deadline of the calls made over the IPC, HTTP and websocket
+	// endpoints, after which long running methods are aborted. Zero disables it.
+	RPCTimeout time.Duration `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -224,6 +285,80 @@ func DefaultWSEndpoint() string {
 	return config.WSEndpoint()
 }
 
+// GraphQLEndpoint resolves a GraphQL endpoint based on the configured host
+// interface and port parameters.
+func (c *Config) GraphQLEndpoint() string {
+	if c.GraphQLHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GraphQLHost, c.GraphQLPort)
+}
+
+// GRPCEndpoint resolves a gRPC endpoint based on the configured host interface
+// and port parameters.
+func (c *Config) GRPCEndpoint() string {
+	if c.GRPCHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GRPCHost, c.GRPCPort)
+}
+
+// RPCAuthenticator returns the access control of the RPC endpoints, nil if none
+// is configured.
+func (c *Config) RPCAuthenticator() (*rpc.Authenticator, error) {
+	if c.RPCAuthSecret == "" && c.RPCAuthPolicy == "" {
+		return nil, nil
+	}
+	var (
+		secret []byte
+		policy *rpc.Policy
+		err    error
+	)
+	if c.RPCAuthSecret != "" {
+		if secret, err = rpc.LoadJWTSecret(c.RPCAuthSecret); err != nil {
+			return nil, err
+		}
+	}
+	if c.RPCAuthPolicy != "" {
+		if policy, err = rpc.LoadPolicy(c.RPCAuthPolicy); err != nil {
+			return nil, err
+		}
+	}
+	return rpc.NewAuthenticator(secret, policy), nil
+}
+
+// rpcLimits returns the size limits of the HTTP and websocket endpoints, the
+// defaults of the rpc package unless configured.
+func (c *Config) rpcLimits() rpc.Limits {
+	return rpc.Limits{
+		MaxRequestSize:  configLimit(c.RPCMaxRequestSize, rpc.DefaultLimits.MaxRequestSize),
+		MaxBatchItems:   configLimit(c.RPCMaxBatchItems, rpc.DefaultLimits.MaxBatchItems),
+		MaxResponseSize: configLimit(c.RPCMaxResponseSize, rpc.DefaultLimits.MaxResponseSize),
+	}
+}
+
+// ipcLimits returns the size limits of the IPC endpoint, unlimited unless
+// configured explicitly.
+func (c *Config) ipcLimits() rpc.Limits {
+	return rpc.Limits{
+		MaxRequestSize:  configLimit(c.RPCMaxRequestSize, 0),
+		MaxBatchItems:   configLimit(c.RPCMaxBatchItems, 0),
+		MaxResponseSize: configLimit(c.RPCMaxResponseSize, 0),
+	}
+}
+
+// configLimit resolves a configured size limit, zero selecting the given default
+// and negative values disabling the limit.
+func configLimit(limit, def int) int {
+	switch {
+	case limit == 0:
+		return def
+	case limit < 0:
+		return 0
+	}
+	return limit
+}
+
 // NodeName returns the devp2p node identifier.
 func (c *Config) NodeName() string {
 	name := c.name()
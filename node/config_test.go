@@ -22,10 +22,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/okcoin/go-okcoin/crypto"
 	"github.com/okcoin/go-okcoin/p2p"
+	"github.com/okcoin/go-okcoin/rpc"
 )
 
 // Tests that datadirs can be successfully created, be them manually configured
@@ -148,3 +150,72 @@ func TestNodeKeyPersistency(t *testing.T) {
 		t.Fatalf("ephemeral node key persisted to disk")
 	}
 }
+
+// Tests that the RPC access control is only configured if requested, and that
+// invalid secrets and policies are rejected.
+func TestRPCAuthenticatorConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	var (
+		secret  = write("secret", "0x"+strings.Repeat("42", 32)+"\n")
+		short   = write("short", strings.Repeat("42", 16))
+		policy  = write("policy.json", `{"anonymous": ["okc"], "apikeys": {"key": ["*"]}}`)
+		invalid = write("invalid.json", `{"anonymous": "okc"}`)
+		missing = filepath.Join(dir, "missing")
+		tests   = []struct {
+			secret, policy string
+			auth, fail     bool
+		}{
+			{"", "", false, false},
+			{secret, "", true, false},
+			{"", policy, true, false},
+			{secret, policy, true, false},
+			{short, policy, false, true},
+			{secret, invalid, false, true},
+			{missing, "", false, true},
+			{"", missing, false, true},
+		}
+	)
+	for i, tt := range tests {
+		config := &Config{RPCAuthSecret: tt.secret, RPCAuthPolicy: tt.policy}
+		auth, err := config.RPCAuthenticator()
+		if (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+		if (auth != nil) != tt.auth {
+			t.Errorf("test %d: authenticator mismatch: have %v, want %v", i, auth != nil, tt.auth)
+		}
+	}
+}
+
+// Tests that the IPC endpoint is only limited if limits are configured explicitly,
+// and that negative limits disable them on all endpoints.
+func TestRPCLimitsConfig(t *testing.T) {
+	tests := []struct {
+		config    Config
+		http, ipc rpc.Limits
+	}{
+		{Config{}, rpc.DefaultLimits, rpc.Limits{}},
+		{Config{RPCMaxRequestSize: 100, RPCMaxBatchItems: -1}, rpc.Limits{MaxRequestSize: 100, MaxResponseSize: rpc.DefaultLimits.MaxResponseSize}, rpc.Limits{MaxRequestSize: 100}},
+		{Config{RPCMaxRequestSize: -1, RPCMaxBatchItems: -1, RPCMaxResponseSize: -1}, rpc.Limits{}, rpc.Limits{}},
+	}
+	for i, tt := range tests {
+		if limits := tt.config.rpcLimits(); limits != tt.http {
+			t.Errorf("test %d: HTTP limits mismatch: have %+v, want %+v", i, limits, tt.http)
+		}
+		if limits := tt.config.ipcLimits(); limits != tt.ipc {
+			t.Errorf("test %d: IPC limits mismatch: have %+v, want %+v", i, limits, tt.ipc)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -51,8 +52,10 @@ type Node struct {
 	serviceFuncs []ServiceConstructor     // Service constructors (in dependency order)
 	services     map[reflect.Type]Service // Currently running services
 
-	rpcAPIs       []rpc.API   // List of APIs currently provided by the node
-	inprocHandler *rpc.Server // In-process RPC request handler to process the API requests
+	rpcAPIs       []rpc.API          // List of APIs currently provided by the node
+	rpcAuth       *rpc.Authenticator // Access control of the HTTP and websocket endpoints (nil = unrestricted)
+	rpcLimiter    *rpc.RateLimiter   // Client budgets of the HTTP and websocket endpoints (nil = unlimited)
+	inprocHandler *rpc.Server        // In-process RPC request handler to process the API requests
 
 	ipcEndpoint string       // IPC endpoint to listen at (empty = IPC disabled)
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
@@ -62,6 +65,7 @@ type Node struct {
 	httpWhitelist []string     // HTTP RPC modules to allow through this endpoint
 	httpListener  net.Listener // HTTP RPC listener socket to server API requests
 	httpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
+	httpMux       *httpWSMux   // HTTP server handler, passing websocket upgrades to a websocket endpoint on the same port
 
 	wsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
@@ -103,6 +107,16 @@ func New(conf *Config) (*Node, error) {
 	if err != nil {
 		return nil, err
 	}
+	auth, err := conf.RPCAuthenticator()
+	if err != nil {
+		return nil, err
+	}
+	var limiter *rpc.RateLimiter
+	if conf.RPCRateLimit != nil {
+		if limiter, err = rpc.NewRateLimiter(*conf.RPCRateLimit); err != nil {
+			return nil, err
+		}
+	}
 	if conf.Logger == nil {
 		conf.Logger = log.New()
 	}
@@ -113,6 +127,8 @@ func New(conf *Config) (*Node, error) {
 		ephemeralKeystore: ephemeralKeystore,
 		config:            conf,
 		serviceFuncs:      []ServiceConstructor{},
+		rpcAuth:           auth,
+		rpcLimiter:        limiter,
 		ipcEndpoint:       conf.IPCEndpoint(),
 		httpEndpoint:      conf.HTTPEndpoint(),
 		wsEndpoint:        conf.WSEndpoint(),
@@ -166,28 +182,9 @@ func (n *Node) Start() error {
 	n.log.Info("Starting peer-to-peer node", "instance", n.serverConfig.Name)
 
 	// Otherwise copy and specialize the P2P configuration
-	services := make(map[reflect.Type]Service)
-	for _, constructor := range n.serviceFuncs {
-		// Create a new context for the particular service
-		ctx := &ServiceContext{
-			config:         n.config,
-			services:       make(map[reflect.Type]Service),
-			EventMux:       n.eventmux,
-			AccountManager: n.accman,
-		}
-		for kind, s := range services { // copy needed for threaded access
-			ctx.services[kind] = s
-		}
-		// Construct and save the service
-		service, err := constructor(ctx)
-		if err != nil {
-			return err
-		}
-		kind := reflect.TypeOf(service)
-		if _, exists := services[kind]; exists {
-			return &DuplicateServiceError{Kind: kind}
-		}
-		services[kind] = service
+	services, err := n.newServices(n.config)
+	if err != nil {
+		return err
 	}
 	// Gather the protocols and start the freshly assembled P2P server
 	for _, service := range services {
@@ -227,6 +224,74 @@ func (n *Node) Start() error {
 	return nil
 }
 
+// newServices constructs the registered services, handing them the given
+// configuration.
+func (n *Node) newServices(config *Config) (map[reflect.Type]Service, error) {
+	services := make(map[reflect.Type]Service)
+	for _, constructor := range n.serviceFuncs {
+		// Create a new context for the particular service
+		ctx := &ServiceContext{
+			config:         config,
+			services:       make(map[reflect.Type]Service),
+			rpcGuard:       n.rpcGuard(),
+			EventMux:       n.eventmux,
+			AccountManager: n.accman,
+		}
+		for kind, s := range services { // copy needed for threaded access
+			ctx.services[kind] = s
+		}
+		// Construct and save the service
+		service, err := constructor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		kind := reflect.TypeOf(service)
+		if _, exists := services[kind]; exists {
+			return nil, &DuplicateServiceError{Kind: kind}
+		}
+		services[kind] = service
+	}
+	return services, nil
+}
+
+// APIServer constructs the registered services without starting them and returns
+// an in-process RPC server handling all the APIs of the node and the services,
+// for tools inspecting the API of a stopped node. Neither the P2P server nor any
+// endpoint is started, and the services are given an ephemeral copy of the node
+// configuration, leaving the data directory untouched and unlocked. Since they
+// can't read the chain configuration stored there either, services depending on
+// it must be configured with it otherwise (e.g. the genesis of the Okcoin service
+// for the APIs of its consensus engine). The services are never started nor
+// stopped, the server is only meant for introspection: the APIs whose
+// implementation is only set up on start (e.g. the net namespace) are described
+// but can't be called.
+func (n *Node) APIServer() (*rpc.Server, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	if n.server != nil {
+		return nil, ErrNodeRunning
+	}
+	config := *n.config
+	config.DataDir = ""
+
+	services, err := n.newServices(&config)
+	if err != nil {
+		return nil, err
+	}
+	apis := n.apis()
+	for _, service := range services {
+		apis = append(apis, service.APIs()...)
+	}
+	handler := rpc.NewServer()
+	for _, api := range apis {
+		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
+			return nil, err
+		}
+	}
+	return handler, nil
+}
+
 func (n *Node) openDataDir() error {
 	if n.config.DataDir == "" {
 		return nil // ephemeral
@@ -301,6 +366,17 @@ func (n *Node) stopInProc() {
 	}
 }
 
+// rpcGuard returns the protection of the HTTP and websocket endpoints, for the
+// services serving network endpoints of their own.
+func (n *Node) rpcGuard() *rpc.Guard {
+	return &rpc.Guard{
+		Auth:    n.rpcAuth,
+		Limiter: n.rpcLimiter,
+		Limits:  n.config.rpcLimits(),
+		Timeout: n.config.RPCTimeout,
+	}
+}
+
 // startIPC initializes and starts the IPC RPC endpoint.
 func (n *Node) startIPC(apis []rpc.API) error {
 	// Short circuit if the IPC endpoint isn't being exposed
@@ -315,6 +391,12 @@ func (n *Node) startIPC(apis []rpc.API) error {
 		}
 		n.log.Debug("IPC registered", "service", api.Service, "namespace", api.Namespace)
 	}
+	handler.SetLimits(n.config.ipcLimits())
+	handler.SetTimeout(n.config.RPCTimeout)
+	// The clients of the socket (console, attach) send no credentials, they get the
+	// local permissions of the access control policy
+	handler.SetAuthenticator(n.rpcAuth)
+
 	// All APIs registered, start the IPC listener
 	var (
 		listener net.Listener
@@ -385,6 +467,14 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 			n.log.Debug("HTTP registered", "service", api.Service, "namespace", api.Namespace)
 		}
 	}
+	handler.SetLimits(n.config.rpcLimits())
+	handler.SetTimeout(n.config.RPCTimeout)
+	if n.rpcAuth != nil {
+		handler.SetAuthenticator(n.rpcAuth)
+	}
+	if n.rpcLimiter != nil {
+		handler.SetRateLimiter(n.rpcLimiter)
+	}
 	// All APIs registered, start the HTTP listener
 	var (
 		listener net.Listener
@@ -393,18 +483,25 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return err
 	}
-	go rpc.NewHTTPServer(cors, vhosts, handler).Serve(listener)
+	mux := &httpWSMux{http: rpc.NewHTTPHandler(cors, vhosts, handler)}
+	go (&http.Server{Handler: mux}).Serve(listener)
 	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
 	n.httpHandler = handler
+	n.httpMux = mux
 
 	return nil
 }
 
-// stopHTTP terminates the HTTP RPC endpoint.
+// stopHTTP terminates the HTTP RPC endpoint, along with the websocket one if it
+// shares the port.
 func (n *Node) stopHTTP() {
+	if n.wsHandler != nil && n.wsListener == nil {
+		n.stopWS()
+	}
+	n.httpMux = nil
 	if n.httpListener != nil {
 		n.httpListener.Close()
 		n.httpListener = nil
@@ -438,7 +535,25 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 			n.log.Debug("WebSocket registered", "service", api.Service, "namespace", api.Namespace)
 		}
 	}
-	// All APIs registered, start the HTTP listener
+	handler.SetLimits(n.config.rpcLimits())
+	handler.SetTimeout(n.config.RPCTimeout)
+	if n.rpcAuth != nil {
+		handler.SetAuthenticator(n.rpcAuth)
+	}
+	if n.rpcLimiter != nil {
+		handler.SetRateLimiter(n.rpcLimiter)
+	}
+	// All APIs registered, share the HTTP listener if on the same endpoint
+	wsHandler := rpc.NewWSHandler(wsOrigins, n.config.WSVirtualHosts, handler)
+	if n.httpMux != nil && endpoint == n.httpEndpoint {
+		n.httpMux.setWS(wsHandler)
+		n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", n.httpListener.Addr()), "shared", "http")
+
+		n.wsEndpoint = endpoint
+		n.wsHandler = handler
+		return nil
+	}
+	// Otherwise start a dedicated listener
 	var (
 		listener net.Listener
 		err      error
@@ -446,7 +561,7 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return err
 	}
-	go rpc.NewWSServer(wsOrigins, handler).Serve(listener)
+	go (&http.Server{Handler: wsHandler}).Serve(listener)
 	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
 
 	// All listeners booted successfully
@@ -464,6 +579,10 @@ func (n *Node) stopWS() {
 		n.wsListener = nil
 
 		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("ws://%s", n.wsEndpoint))
+	} else if n.wsHandler != nil && n.httpMux != nil {
+		n.httpMux.setWS(nil)
+
+		n.log.Info("WebSocket endpoint closed", "url", fmt.Sprintf("ws://%s", n.wsEndpoint), "shared", "http")
 	}
 	if n.wsHandler != nil {
 		n.wsHandler.Stop()
@@ -471,6 +590,37 @@ func (n *Node) stopWS() {
 	}
 }
 
+// httpWSMux is the handler of the HTTP RPC endpoint, which passes websocket
+// upgrade requests to the websocket RPC endpoint if that runs on the same port.
+type httpWSMux struct {
+	http http.Handler // Handler of the HTTP RPC endpoint
+	ws   http.Handler // Handler of the websocket RPC endpoint, nil if not shared
+	lock sync.RWMutex
+}
+
+// setWS sets the handler of the websocket RPC endpoint, nil to stop sharing.
+func (m *httpWSMux) setWS(ws http.Handler) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.ws = ws
+}
+
+// ServeHTTP dispatches a request to the HTTP or websocket RPC endpoint.
+func (m *httpWSMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rpc.IsWebsocket(r) {
+		m.lock.RLock()
+		ws := m.ws
+		m.lock.RUnlock()
+
+		if ws != nil {
+			ws.ServeHTTP(w, r)
+			return
+		}
+	}
+	m.http.ServeHTTP(w, r)
+}
+
 // Stop terminates a running node along with all it's services. In the node was
 // not started, an error is returned.
 func (n *Node) Stop() error {
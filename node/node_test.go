@@ -20,6 +20,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -573,3 +574,112 @@ func TestAPIGather(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the HTTP and websocket RPC endpoints share a listener if configured
+// on the same port, each serving its own modules.
+func TestSharedHTTPWSEndpoint(t *testing.T) {
+	config := testNodeConfig()
+	config.HTTPHost, config.HTTPModules = "127.0.0.1", []string{"web3"}
+	config.WSHost, config.WSModules = "127.0.0.1", []string{"admin"}
+
+	stack, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	defer stack.Stop()
+
+	if stack.wsListener != nil {
+		t.Fatalf("websocket endpoint opened a separate listener")
+	}
+	addr := stack.httpListener.Addr().String()
+
+	modules := func(url string) map[string]string {
+		client, err := rpc.Dial(url)
+		if err != nil {
+			t.Fatalf("failed to dial %s: %v", url, err)
+		}
+		defer client.Close()
+
+		modules, err := client.SupportedModules()
+		if err != nil {
+			t.Fatalf("failed to retrieve modules from %s: %v", url, err)
+		}
+		return modules
+	}
+	if have := modules("http://" + addr); have["web3"] == "" || have["admin"] != "" {
+		t.Errorf("HTTP modules mismatch: have %v", have)
+	}
+	if have := modules("ws://" + addr); have["admin"] == "" || have["web3"] != "" {
+		t.Errorf("websocket modules mismatch: have %v", have)
+	}
+	// Ensure stopping the websocket endpoint leaves the HTTP one running
+	stack.stopWS()
+	if _, err := rpc.Dial("ws://" + addr); err == nil {
+		t.Errorf("websocket endpoint still served after stopping")
+	}
+	if have := modules("http://" + addr); have["web3"] == "" {
+		t.Errorf("HTTP modules mismatch after stopping websocket endpoint: have %v", have)
+	}
+}
+
+// Tests that the API server of a stopped node handles the APIs of the services
+// without starting them or touching the data directory.
+func TestAPIServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	stack, err := New(&Config{DataDir: dir, Name: "test"})
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	called := make(chan struct{}, 1)
+	started := false
+	service := &InstrumentedService{
+		apis:      []rpc.API{{Namespace: "single", Version: "1", Service: &OneMethodApi{fun: func() { called <- struct{}{} }}, Public: true}},
+		startHook: func(*p2p.Server) { started = true },
+	}
+	var persistent bool
+	if err := stack.Register(func(ctx *ServiceContext) (Service, error) {
+		persistent = ctx.ResolvePath("data") != ""
+		return service, nil
+	}); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	handler, err := stack.APIServer()
+	if err != nil {
+		t.Fatalf("failed to create API server: %v", err)
+	}
+	defer handler.Stop()
+
+	if started {
+		t.Errorf("service started")
+	}
+	if persistent {
+		t.Errorf("service given persistent storage")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "test")); !os.IsNotExist(err) {
+		t.Errorf("instance directory created: %v", err)
+	}
+	client := rpc.DialInProc(handler)
+	defer client.Close()
+
+	if err := client.Call(nil, "single_theOneMethod"); err != nil {
+		t.Fatalf("API request failed: %v", err)
+	}
+	select {
+	case <-called:
+	default:
+		t.Errorf("service API not called")
+	}
+	// The node itself should still be startable afterwards
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start protocol stack: %v", err)
+	}
+	stack.Stop()
+}
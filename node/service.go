@@ -32,6 +32,7 @@ import (
 type ServiceContext struct {
 	config         *Config
 	services       map[reflect.Type]Service // Index of the already constructed services
+	rpcGuard       *rpc.Guard               // Protection of the HTTP and websocket endpoints
 	EventMux       *event.TypeMux           // Event multiplexer used for decoupled notifications
 	AccountManager *accounts.Manager        // Account manager created by the node.
 }
@@ -67,6 +68,13 @@ func (ctx *ServiceContext) Service(service interface{}) error {
 	return ErrServiceUnknown
 }
 
+// RPCGuard retrieves the access control, rate limits, size limits and call
+// deadline of the HTTP and websocket endpoints, for services serving network
+// endpoints of their own to enforce them too. It is nil outside of a node.
+func (ctx *ServiceContext) RPCGuard() *rpc.Guard {
+	return ctx.rpcGuard
+}
+
 // ServiceConstructor is the function signature of the constructors needed to be
 // registered for service instantiation.
 type ServiceConstructor func(ctx *ServiceContext) (Service, error)
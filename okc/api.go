@@ -19,11 +19,13 @@ package okc
 import (
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/common/hexutil"
@@ -74,6 +76,7 @@ type PublicMinerAPI struct {
 // NewPublicMinerAPI create a new PublicMinerAPI instance.
 func NewPublicMinerAPI(e *Okcoin) *PublicMinerAPI {
 	agent := miner.NewRemoteAgent(e.BlockChain(), e.Engine())
+	agent.SetNotify(e.config.MinerNotify)
 	e.Miner().Register(agent)
 
 	return &PublicMinerAPI{e, agent}
@@ -187,6 +190,32 @@ func (api *PrivateMinerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
 	return true
 }
 
+// SetGasTarget sets the gas limit the miner raises the block gas limit towards.
+func (api *PrivateMinerAPI) SetGasTarget(gasTarget hexutil.Uint64) (bool, error) {
+	if _, ceil := api.e.Miner().GasBounds(); uint64(gasTarget) > ceil {
+		return false, fmt.Errorf("gas target %d above gas limit %d", gasTarget, ceil)
+	}
+	api.e.Miner().SetGasTarget(uint64(gasTarget))
+	return true, nil
+}
+
+// SetGasLimit sets the gas limit above which the miner lowers the block gas
+// limit. Zero removes the bound.
+func (api *PrivateMinerAPI) SetGasLimit(gasLimit hexutil.Uint64) (bool, error) {
+	if floor, _ := api.e.Miner().GasBounds(); gasLimit != 0 && uint64(gasLimit) < floor {
+		return false, fmt.Errorf("gas limit %d below gas target %d", gasLimit, floor)
+	}
+	api.e.Miner().SetGasLimit(uint64(gasLimit))
+	return true, nil
+}
+
+// SetRecommitInterval sets the minimum interval, in milliseconds, at which the
+// miner recreates the block being mined to include newly arrived transactions.
+func (api *PrivateMinerAPI) SetRecommitInterval(interval int) bool {
+	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
+	return true
+}
+
 // SetOkcerbase sets the okcerbase of the miner
 func (api *PrivateMinerAPI) SetOkcerbase(okcerbase common.Address) bool {
 	api.e.SetOkcerbase(okcerbase)
@@ -198,6 +227,35 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return uint64(api.e.miner.HashRate())
 }
 
+// SetTxOrdering switches the strategy the miner orders transactions into blocks
+// with. The sender sets of the priority and reserved strategies are taken from
+// the node configuration.
+func (api *PrivateMinerAPI) SetTxOrdering(strategy string) (bool, error) {
+	config := api.e.config.TxOrdering
+	config.Strategy = strategy
+
+	ordering, err := miner.NewTxOrdering(config)
+	if err != nil {
+		return false, err
+	}
+	api.e.Miner().SetTxOrdering(ordering)
+	return true, nil
+}
+
+// StratumWorkers returns the share accounting of the workers connected to the
+// built-in Stratum server.
+func (api *PrivateMinerAPI) StratumWorkers() (map[string]miner.StratumWorkerStats, error) {
+	if api.e.stratum == nil {
+		return nil, errors.New("stratum server not enabled")
+	}
+	return api.e.stratum.Workers(), nil
+}
+
+// GetTxOrdering returns the name of the transaction ordering strategy in use.
+func (api *PrivateMinerAPI) GetTxOrdering() string {
+	return api.e.Miner().TxOrdering().Name()
+}
+
 // PrivateAdminAPI is the collection of Okcoin full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -313,9 +371,12 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 		return stateDb.RawDump(), nil
 	}
 	var block *types.Block
-	if blockNr == rpc.LatestBlockNumber {
+	switch blockNr {
+	case rpc.LatestBlockNumber:
 		block = api.okc.blockchain.CurrentBlock()
-	} else {
+	case rpc.FinalizedBlockNumber:
+		block = api.okc.blockchain.FinalizedBlock()
+	default:
 		block = api.okc.blockchain.GetBlockByNumber(uint64(blockNr))
 	}
 	if block == nil {
@@ -368,7 +429,7 @@ type storageEntry struct {
 
 // StorageRangeAt returns the storage at the given block height and transaction index.
 func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
-	_, _, statedb, err := api.computeTxEnv(blockHash, txIndex, 0)
+	_, _, statedb, err := api.computeTxEnv(ctx, blockHash, txIndex, 0)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
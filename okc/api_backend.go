@@ -65,6 +65,9 @@ func (b *OkcApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 	if blockNr == rpc.LatestBlockNumber {
 		return b.okc.blockchain.CurrentBlock().Header(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		return b.okc.blockchain.FinalizedBlock().Header(), nil
+	}
 	return b.okc.blockchain.GetHeaderByNumber(uint64(blockNr)), nil
 }
 
@@ -78,6 +81,9 @@ func (b *OkcApiBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumb
 	if blockNr == rpc.LatestBlockNumber {
 		return b.okc.blockchain.CurrentBlock(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		return b.okc.blockchain.FinalizedBlock(), nil
+	}
 	return b.okc.blockchain.GetBlockByNumber(uint64(blockNr)), nil
 }
 
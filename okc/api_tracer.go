@@ -82,6 +82,29 @@ type blockTraceResult struct {
 	Traces []*txTraceResult `json:"traces"` // Trace results produced by the task
 }
 
+// partialTraceError is returned by block traces interrupted by a timeout or by
+// the client going away, carrying the results of the transactions traced before
+// the interruption. Transactions not traced have nil results.
+type partialTraceError struct {
+	err     error
+	results []*txTraceResult
+}
+
+func (e *partialTraceError) Error() string {
+	traced := 0
+	for _, result := range e.results {
+		if result != nil {
+			traced++
+		}
+	}
+	return fmt.Sprintf("%v (%d of %d transactions traced)", e.err, traced, len(e.results))
+}
+
+// ErrorData returns the partial trace results, implementing rpc.DataError.
+func (e *partialTraceError) ErrorData() interface{} {
+	return e.results
+}
+
 // txTraceTask represents a single transaction trace task when an entire block
 // is being traced.
 type txTraceTask struct {
@@ -100,6 +123,8 @@ func (api *PrivateDebugAPI) TraceChain(ctx context.Context, start, end rpc.Block
 		from = api.okc.miner.PendingBlock()
 	case rpc.LatestBlockNumber:
 		from = api.okc.blockchain.CurrentBlock()
+	case rpc.FinalizedBlockNumber:
+		from = api.okc.blockchain.FinalizedBlock()
 	default:
 		from = api.okc.blockchain.GetBlockByNumber(uint64(start))
 	}
@@ -108,6 +133,8 @@ func (api *PrivateDebugAPI) TraceChain(ctx context.Context, start, end rpc.Block
 		to = api.okc.miner.PendingBlock()
 	case rpc.LatestBlockNumber:
 		to = api.okc.blockchain.CurrentBlock()
+	case rpc.FinalizedBlockNumber:
+		to = api.okc.blockchain.FinalizedBlock()
 	default:
 		to = api.okc.blockchain.GetBlockByNumber(uint64(end))
 	}
@@ -342,6 +369,8 @@ func (api *PrivateDebugAPI) TraceBlockByNumber(ctx context.Context, number rpc.B
 		block = api.okc.miner.PendingBlock()
 	case rpc.LatestBlockNumber:
 		block = api.okc.blockchain.CurrentBlock()
+	case rpc.FinalizedBlockNumber:
+		block = api.okc.blockchain.FinalizedBlock()
 	default:
 		block = api.okc.blockchain.GetBlockByNumber(uint64(number))
 	}
@@ -398,7 +427,7 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	statedb, err := api.computeStateDB(parent, reexec)
+	statedb, err := api.computeStateDB(ctx, parent, reexec)
 	if err != nil {
 		return nil, err
 	}
@@ -428,7 +457,10 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 
 				res, err := api.traceTx(ctx, msg, vmctx, task.statedb, config)
 				if err != nil {
-					results[task.index] = &txTraceResult{Error: err.Error()}
+					// Leave transactions aborted by the cancellation untraced
+					if ctx.Err() == nil {
+						results[task.index] = &txTraceResult{Error: err.Error()}
+					}
 					continue
 				}
 				results[task.index] = &txTraceResult{Result: res}
@@ -438,6 +470,10 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	// Feed the transactions into the tracers and return
 	var failed error
 	for i, tx := range txs {
+		// Stop feeding if the request was canceled
+		if ctx.Err() != nil {
+			break
+		}
 		// Send the trace task over for execution
 		jobs <- &txTraceTask{statedb: statedb.Copy(), index: i}
 
@@ -460,13 +496,16 @@ func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block,
 	if failed != nil {
 		return nil, failed
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, &partialTraceError{err: err, results: results}
+	}
 	return results, nil
 }
 
 // computeStateDB retrieves the state database associated with a certain block.
 // If no state is locally available for the given block, a number of blocks are
 // attempted to be reexecuted to generate the desired state.
-func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*state.StateDB, error) {
+func (api *PrivateDebugAPI) computeStateDB(ctx context.Context, block *types.Block, reexec uint64) (*state.StateDB, error) {
 	// If we have the state fully available, use that
 	statedb, err := api.okc.blockchain.StateAt(block.Root())
 	if err == nil {
@@ -500,6 +539,10 @@ func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*
 		proot  common.Hash
 	)
 	for block.NumberU64() < origin {
+		// Abort if the request was canceled in the meantime
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("state regeneration aborted at block #%d of #%d: %v", block.NumberU64(), origin, err)
+		}
 		// Print progress logs if long enough time elapsed
 		if time.Since(logged) > 8*time.Second {
 			log.Info("Regenerating historical state", "block", block.NumberU64()+1, "target", origin, "elapsed", time.Since(start))
@@ -541,7 +584,7 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	msg, vmctx, statedb, err := api.computeTxEnv(blockHash, int(index), reexec)
+	msg, vmctx, statedb, err := api.computeTxEnv(ctx, blockHash, int(index), reexec)
 	if err != nil {
 		return nil, err
 	}
@@ -585,13 +628,25 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	default:
 		tracer = vm.NewStructLogger(config.LogConfig)
 	}
-	// Run the transaction with tracing enabled.
+	// Run the transaction with tracing enabled, aborting it on RPC cancellations
 	vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
 
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			vmenv.Cancel()
+		case <-done:
+		}
+	}()
 	ret, gas, failed, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas()))
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %v", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("tracing aborted: %v", err)
+	}
 	// Depending on the tracer type, format and return the output
 	switch tracer := tracer.(type) {
 	case *vm.StructLogger:
@@ -611,7 +666,7 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 }
 
 // computeTxEnv returns the execution environment of a certain transaction.
-func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
+func (api *PrivateDebugAPI) computeTxEnv(ctx context.Context, blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
 	// Create the parent state database
 	block := api.okc.blockchain.GetBlockByHash(blockHash)
 	if block == nil {
@@ -621,7 +676,7 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 	if parent == nil {
 		return nil, vm.Context{}, nil, fmt.Errorf("parent %x not found", block.ParentHash())
 	}
-	statedb, err := api.computeStateDB(parent, reexec)
+	statedb, err := api.computeStateDB(ctx, parent, reexec)
 	if err != nil {
 		return nil, vm.Context{}, nil, err
 	}
@@ -29,6 +29,7 @@ import (
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/common/hexutil"
 	"github.com/okcoin/go-okcoin/consensus"
+	"github.com/okcoin/go-okcoin/consensus/bft"
 	"github.com/okcoin/go-okcoin/consensus/clique"
 	"github.com/okcoin/go-okcoin/consensus/okcash"
 	"github.com/okcoin/go-okcoin/core"
@@ -54,6 +55,7 @@ type LesServer interface {
 	Start(srvr *p2p.Server)
 	Stop()
 	Protocols() []p2p.Protocol
+	APIs() []rpc.API
 	SetBloomBitsIndexer(bbIndexer *core.ChainIndexer)
 }
 
@@ -85,9 +87,12 @@ type Okcoin struct {
 	ApiBackend *OkcApiBackend
 
 	miner     *miner.Miner
+	stratum   *miner.StratumServer
 	gasPrice  *big.Int
 	okcerbase common.Address
 
+	remoteSigner *clique.RemoteSigner // External clique signer, if configured
+
 	networkId     uint64
 	netRPCService *okcapi.PublicNetAPI
 
@@ -108,6 +113,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Okcoin, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	if config.MinerGasCeil != 0 && config.MinerGasFloor > config.MinerGasCeil {
+		return nil, fmt.Errorf("miner gas floor %d above gas ceiling %d", config.MinerGasFloor, config.MinerGasCeil)
+	}
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
@@ -158,6 +166,17 @@ func New(ctx *node.ServiceContext, config *Config) (*Okcoin, error) {
 		okc.blockchain.SetHead(compat.RewindTo)
 		core.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
+	// Refuse reorgs below the configured finality
+	if config.MaxReorgDepth > 0 || len(config.FinalityCheckpoints) > 0 {
+		finality := &core.FinalityConfig{
+			MaxReorgDepth: config.MaxReorgDepth,
+			Checkpoints:   config.FinalityCheckpoints,
+			Signer:        config.CheckpointSigner,
+		}
+		if err := okc.blockchain.SetFinality(finality); err != nil {
+			return nil, err
+		}
+	}
 	okc.bloomIndexer.Start(okc.blockchain)
 
 	if config.TxPool.Journal != "" {
@@ -170,6 +189,24 @@ func New(ctx *node.ServiceContext, config *Config) (*Okcoin, error) {
 	}
 	okc.miner = miner.New(okc, okc.chainConfig, okc.EventMux(), okc.engine)
 	okc.miner.SetExtra(makeExtraData(config.ExtraData))
+	okc.miner.SetGasTarget(config.MinerGasFloor)
+	okc.miner.SetGasLimit(config.MinerGasCeil)
+	okc.miner.SetRecommitInterval(config.MinerRecommit)
+
+	ordering, err := miner.NewTxOrdering(config.TxOrdering)
+	if err != nil {
+		return nil, err
+	}
+	okc.miner.SetTxOrdering(ordering)
+
+	if config.Stratum.Addr != "" {
+		agent := miner.NewRemoteAgent(okc.blockchain, okc.engine)
+		okc.miner.Register(agent)
+
+		if okc.stratum, err = miner.NewStratumServer(config.Stratum, agent, okc.engine); err != nil {
+			return nil, err
+		}
+	}
 
 	okc.ApiBackend = &OkcApiBackend{okc, nil}
 	gpoParams := config.GPO
@@ -216,6 +253,10 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *okcash.Config, chai
 	if chainConfig.Clique != nil {
 		return clique.New(chainConfig.Clique, db)
 	}
+	// If byzantine fault tolerance is requested, set it up
+	if chainConfig.BFT != nil {
+		return bft.New(chainConfig.BFT)
+	}
 	// Otherwise assume proof-of-work
 	switch {
 	case config.PowMode == okcash.ModeFake:
@@ -249,6 +290,11 @@ func (s *Okcoin) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append any APIs exposed by the light server
+	if s.lesServer != nil {
+		apis = append(apis, s.lesServer.APIs()...)
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -370,12 +416,32 @@ func (s *Okcoin) StartMining(local bool) error {
 		return fmt.Errorf("okcerbase missing: %v", err)
 	}
 	if clique, ok := s.engine.(*clique.Clique); ok {
+		if s.config.MinerSigner != "" {
+			signer, err := s.dialRemoteSigner()
+			if err != nil {
+				log.Error("Remote signer unavailable", "endpoint", s.config.MinerSigner, "err", err)
+				return fmt.Errorf("signer missing: %v", err)
+			}
+			if signer.Address() != eb {
+				return fmt.Errorf("okcerbase %x differs from remote signer account %x", eb, signer.Address())
+			}
+			clique.AuthorizeHeaderSigner(eb, signer.SignHeader)
+		} else {
+			wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
+			if wallet == nil || err != nil {
+				log.Error("Okcerbase account unavailable locally", "err", err)
+				return fmt.Errorf("signer missing: %v", err)
+			}
+			clique.Authorize(eb, wallet.SignHash)
+		}
+	}
+	if engine, ok := s.engine.(*bft.BFT); ok {
 		wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
 		if wallet == nil || err != nil {
 			log.Error("Okcerbase account unavailable locally", "err", err)
-			return fmt.Errorf("signer missing: %v", err)
+			return fmt.Errorf("validator missing: %v", err)
 		}
-		clique.Authorize(eb, wallet.SignHash)
+		engine.Authorize(eb, wallet.SignHash)
 	}
 	if local {
 		// If local (CPU) mining is started, we can disable the transaction rejection
@@ -388,6 +454,23 @@ func (s *Okcoin) StartMining(local bool) error {
 	return nil
 }
 
+// dialRemoteSigner connects to the configured external clique signer, reusing
+// the connection across mining sessions.
+func (s *Okcoin) dialRemoteSigner() (*clique.RemoteSigner, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.remoteSigner == nil {
+		signer, err := clique.DialRemoteSigner(s.config.MinerSigner)
+		if err != nil {
+			return nil, err
+		}
+		log.Info("Connected to remote signer", "endpoint", s.config.MinerSigner, "address", signer.Address())
+		s.remoteSigner = signer
+	}
+	return s.remoteSigner, nil
+}
+
 func (s *Okcoin) StopMining()         { s.miner.Stop() }
 func (s *Okcoin) IsMining() bool      { return s.miner.Mining() }
 func (s *Okcoin) Miner() *miner.Miner { return s.miner }
@@ -406,10 +489,15 @@ func (s *Okcoin) Downloader() *downloader.Downloader { return s.protocolManager.
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *Okcoin) Protocols() []p2p.Protocol {
-	if s.lesServer == nil {
-		return s.protocolManager.SubProtocols
+	protocols := s.protocolManager.SubProtocols
+	if s.lesServer != nil {
+		protocols = append(protocols, s.lesServer.Protocols()...)
 	}
-	return append(s.protocolManager.SubProtocols, s.lesServer.Protocols()...)
+	// Byzantine fault tolerant validators agree on blocks over their own protocol
+	if engine, ok := s.engine.(*bft.BFT); ok {
+		protocols = append(protocols, engine.Protocols()...)
+	}
+	return protocols
 }
 
 // Start implements node.Service, starting all internal goroutines needed by the
@@ -434,6 +522,24 @@ func (s *Okcoin) Start(srvr *p2p.Server) error {
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	// Start serving external miners if requested
+	if s.stratum != nil {
+		if err := s.stratum.Start(); err != nil {
+			return err
+		}
+	}
+	// Start agreeing on blocks with the other validators if running BFT
+	if engine, ok := s.engine.(*bft.BFT); ok {
+		if err := engine.Start(s.blockchain); err != nil {
+			return err
+		}
+	}
+	// Start announcing the canonical signer changes if running Clique
+	if engine, ok := s.engine.(*clique.Clique); ok {
+		if err := engine.Start(s.blockchain); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -444,13 +550,25 @@ func (s *Okcoin) Stop() error {
 		s.stopDbUpgrade()
 	}
 	s.bloomIndexer.Close()
+	if engine, ok := s.engine.(*bft.BFT); ok {
+		engine.Stop()
+	}
+	if engine, ok := s.engine.(*clique.Clique); ok {
+		engine.Stop()
+	}
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
 	if s.lesServer != nil {
 		s.lesServer.Stop()
 	}
 	s.txPool.Stop()
+	if s.stratum != nil {
+		s.stratum.Stop()
+	}
 	s.miner.Stop()
+	if s.remoteSigner != nil {
+		s.remoteSigner.Close()
+	}
 	s.eventMux.Stop()
 
 	s.chainDb.Close()
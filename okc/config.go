@@ -28,6 +28,7 @@ import (
 	"github.com/okcoin/go-okcoin/common/hexutil"
 	"github.com/okcoin/go-okcoin/consensus/okcash"
 	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/miner"
 	"github.com/okcoin/go-okcoin/okc/downloader"
 	"github.com/okcoin/go-okcoin/okc/gasprice"
 	"github.com/okcoin/go-okcoin/params"
@@ -49,8 +50,12 @@ var DefaultConfig = Config{
 	TrieCache:     256,
 	TrieTimeout:   5 * time.Minute,
 	GasPrice:      big.NewInt(2 * params.Shannon),
+	MinerGasFloor: params.GenesisGasLimit,
+	MinerRecommit: 3 * time.Second,
 
-	TxPool: core.DefaultTxPoolConfig,
+	TxPool:     core.DefaultTxPoolConfig,
+	TxOrdering: miner.DefaultOrderingConfig,
+	Stratum:    miner.DefaultStratumConfig,
 	GPO: gasprice.Config{
 		Blocks:     20,
 		Percentile: 60,
@@ -83,9 +88,15 @@ type Config struct {
 	SyncMode  downloader.SyncMode
 	NoPruning bool
 
+	// Finality options
+	MaxReorgDepth       uint64                     `toml:",omitempty"` // Maximum number of blocks a reorg may drop (0 = unlimited)
+	FinalityCheckpoints []*core.FinalityCheckpoint `toml:",omitempty"` // Operator signed blocks the chain is final up to
+	CheckpointSigner    common.Address             `toml:",omitempty"` // Operator account signing the finality checkpoints
+
 	// Light client options
-	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
-	LightPeers int `toml:",omitempty"` // Maximum number of LES client peers
+	LightServ        int                            `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
+	LightPeers       int                            `toml:",omitempty"` // Maximum number of LES client peers
+	CheckpointOracle *params.CheckpointOracleConfig `toml:",omitempty"` // Checkpoint oracle light clients start syncing from
 
 	// Database options
 	SkipBcVersionCheck bool `toml:"-"`
@@ -99,6 +110,15 @@ type Config struct {
 	MinerThreads int            `toml:",omitempty"`
 	ExtraData    []byte         `toml:",omitempty"`
 	GasPrice     *big.Int
+	MinerNotify  []string `toml:",omitempty"` // URLs new work packages are POSTed to
+	MinerSigner  string   `toml:",omitempty"` // IPC path or URL of an external clique signer
+
+	MinerGasFloor uint64        // Target gas limit for mined blocks
+	MinerGasCeil  uint64        // Maximum gas limit for mined blocks, zero for none
+	MinerRecommit time.Duration // Minimum interval of recreating the mining block
+
+	// Stratum server options for external miners
+	Stratum miner.StratumConfig
 
 	// Okcash options
 	Okcash okcash.Config
@@ -106,6 +126,9 @@ type Config struct {
 	// Transaction pool options
 	TxPool core.TxPoolConfig
 
+	// Transaction ordering options of the miner
+	TxOrdering miner.OrderingConfig
+
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
@@ -241,6 +241,9 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 		matchedLogs = make(chan []*types.Log)
 	)
 
+	if err := api.resolveFinalized(ctx, &crit); err != nil {
+		return nil, err
+	}
 	logsSub, err := api.events.SubscribeLogs(okcoin.FilterQuery(crit), matchedLogs)
 	if err != nil {
 		return nil, err
@@ -291,6 +294,9 @@ type FilterCriteria struct {
 //
 // https://github.com/okcoin/wiki/wiki/JSON-RPC#okc_newfilter
 func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
+	if err := api.resolveFinalized(context.Background(), &crit); err != nil {
+		return rpc.ID(""), err
+	}
 	logs := make(chan []*types.Log)
 	logsSub, err := api.events.SubscribeLogs(okcoin.FilterQuery(crit), logs)
 	if err != nil {
@@ -322,6 +328,30 @@ func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 	return logsSub.ID, nil
 }
 
+// resolveFinalized replaces the finalized block tags in the range of a log
+// subscription with the number of the current finalized block, as subscribed
+// filters only follow the latest and pending blocks.
+func (api *PublicFilterAPI) resolveFinalized(ctx context.Context, crit *FilterCriteria) error {
+	finalized := big.NewInt(rpc.FinalizedBlockNumber.Int64())
+	if (crit.FromBlock == nil || crit.FromBlock.Cmp(finalized) != 0) && (crit.ToBlock == nil || crit.ToBlock.Cmp(finalized) != 0) {
+		return nil
+	}
+	header, err := api.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		return errors.New("finalized block not found")
+	}
+	if crit.FromBlock != nil && crit.FromBlock.Cmp(finalized) == 0 {
+		crit.FromBlock = new(big.Int).Set(header.Number)
+	}
+	if crit.ToBlock != nil && crit.ToBlock.Cmp(finalized) == 0 {
+		crit.ToBlock = new(big.Int).Set(header.Number)
+	}
+	return nil
+}
+
 // GetLogs returns logs matching the given argument that are stored within the state.
 //
 // https://github.com/okcoin/wiki/wiki/JSON-RPC#okc_getlogs
@@ -338,7 +368,7 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 
 	logs, err := filter.Logs(ctx)
 	if err != nil {
-		return nil, err
+		return nil, logsError(ctx, filter, logs, err)
 	}
 	return returnLogs(logs), err
 }
@@ -386,7 +416,7 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 
 	logs, err := filter.Logs(ctx)
 	if err != nil {
-		return nil, err
+		return nil, logsError(ctx, filter, logs, err)
 	}
 	return returnLogs(logs), nil
 }
@@ -434,6 +464,36 @@ func returnHashes(hashes []common.Hash) []common.Hash {
 	return hashes
 }
 
+// partialLogsError is returned by log queries interrupted by a timeout or by the
+// client going away, carrying the logs found before the interruption.
+type partialLogsError struct {
+	err  error
+	next uint64 // First block not searched
+	logs []*types.Log
+}
+
+func (e *partialLogsError) Error() string {
+	return fmt.Sprintf("%v (%d logs found before block %d)", e.err, len(e.logs), e.next)
+}
+
+// ErrorData returns the logs found and the block to resume the query from,
+// implementing rpc.DataError.
+func (e *partialLogsError) ErrorData() interface{} {
+	return map[string]interface{}{
+		"logs":      returnLogs(e.logs),
+		"nextBlock": hexutil.Uint64(e.next),
+	}
+}
+
+// logsError returns the error of a failed log query, attaching the logs found so
+// far if it was interrupted by the cancellation of the request.
+func logsError(ctx context.Context, filter *Filter, logs []*types.Log, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return &partialLogsError{err: err, next: uint64(filter.begin), logs: logs}
+}
+
 // returnLogs is a helper that will return an empty log array in case the given logs array is nil,
 // otherwise the given logs array is returned.
 func returnLogs(logs []*types.Log) []*types.Log {
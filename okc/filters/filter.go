@@ -109,6 +109,20 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	if f.begin == rpc.FinalizedBlockNumber.Int64() || f.end == rpc.FinalizedBlockNumber.Int64() {
+		header, _ = f.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+		if header == nil {
+			return nil, nil
+		}
+		finalized := header.Number.Uint64()
+
+		if f.begin == rpc.FinalizedBlockNumber.Int64() {
+			f.begin = int64(finalized)
+		}
+		if f.end == rpc.FinalizedBlockNumber.Int64() {
+			end = finalized
+		}
+	}
 	// Gather all indexed logs, and finish with non indexed ones
 	var (
 		logs []*types.Log
@@ -183,6 +197,9 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 	var logs []*types.Log
 
 	for ; f.begin <= int64(end); f.begin++ {
+		if err := ctx.Err(); err != nil {
+			return logs, err
+		}
 		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(f.begin))
 		if header == nil || err != nil {
 			return logs, err
@@ -31,6 +31,7 @@ import (
 	"github.com/okcoin/go-okcoin/okcdb"
 	"github.com/okcoin/go-okcoin/event"
 	"github.com/okcoin/go-okcoin/params"
+	"github.com/okcoin/go-okcoin/rpc"
 )
 
 func makeReceipt(addr common.Address) *types.Receipt {
@@ -56,7 +57,7 @@ func BenchmarkFilters(b *testing.B) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, 0}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr1      = crypto.PubkeyToAddress(key1.PublicKey)
 		addr2      = common.BytesToAddress([]byte("jeff"))
@@ -121,7 +122,7 @@ func TestFilters(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, 0}
 		key1, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
 		addr       = crypto.PubkeyToAddress(key1.PublicKey)
 
@@ -240,4 +241,42 @@ func TestFilters(t *testing.T) {
 	if len(logs) != 0 {
 		t.Error("expected 0 log, got", len(logs))
 	}
+	// Ensure interrupted queries report where to resume from
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	filter = New(backend, 500, -1, []common.Address{addr}, nil)
+	logs, err = filter.Logs(ctx)
+	if err != context.Canceled {
+		t.Fatalf("error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	perr, ok := logsError(ctx, filter, logs, err).(*partialLogsError)
+	if !ok {
+		t.Fatalf("interruption not reported as partial results")
+	}
+	if perr.next != 500 {
+		t.Errorf("resume block mismatch: have %d, want 500", perr.next)
+	}
+
+	// Ensure the finalized block is resolved on every query, like the latest one
+	backend.finalized = 998
+
+	filter = New(backend, 0, rpc.FinalizedBlockNumber.Int64(), []common.Address{addr}, nil)
+	if logs, _ = filter.Logs(context.Background()); len(logs) != 2 {
+		t.Errorf("expected 2 logs up to finalized, got %d", len(logs))
+	}
+	filter = New(backend, rpc.FinalizedBlockNumber.Int64(), -1, []common.Address{addr}, nil)
+	if logs, _ = filter.Logs(context.Background()); len(logs) != 2 || logs[0].Topics[0] != hash3 {
+		t.Errorf("expected 2 logs from finalized, got %v", logs)
+	}
+	// Ensure installed filters pin the finalized block they were created at
+	api := NewPublicFilterAPI(backend, false)
+	id, err := api.NewFilter(FilterCriteria{FromBlock: big.NewInt(rpc.FinalizedBlockNumber.Int64())})
+	if err != nil {
+		t.Fatalf("failed to install finalized filter: %v", err)
+	}
+	if from := api.filters[id].crit.FromBlock; from.Uint64() != 998 {
+		t.Errorf("filter start mismatch: have %v, want 998", from)
+	}
+	api.UninstallFilter(id)
 }
@@ -4,13 +4,16 @@ package okc
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/common/hexutil"
 	"github.com/okcoin/go-okcoin/consensus/okcash"
 	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/miner"
 	"github.com/okcoin/go-okcoin/okc/downloader"
 	"github.com/okcoin/go-okcoin/okc/gasprice"
+	"github.com/okcoin/go-okcoin/params"
 )
 
 var _ = (*configMarshaling)(nil)
@@ -20,17 +23,28 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               uint64
 		SyncMode                downloader.SyncMode
-		LightServ               int  `toml:",omitempty"`
-		LightPeers              int  `toml:",omitempty"`
-		SkipBcVersionCheck      bool `toml:"-"`
-		DatabaseHandles         int  `toml:"-"`
+		MaxReorgDepth           uint64                         `toml:",omitempty"`
+		FinalityCheckpoints     []*core.FinalityCheckpoint     `toml:",omitempty"`
+		CheckpointSigner        common.Address                 `toml:",omitempty"`
+		LightServ               int                            `toml:",omitempty"`
+		LightPeers              int                            `toml:",omitempty"`
+		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		SkipBcVersionCheck      bool                           `toml:"-"`
+		DatabaseHandles         int                            `toml:"-"`
 		DatabaseCache           int
 		Okcerbase               common.Address `toml:",omitempty"`
 		MinerThreads            int            `toml:",omitempty"`
 		ExtraData               hexutil.Bytes  `toml:",omitempty"`
 		GasPrice                *big.Int
+		MinerNotify             []string `toml:",omitempty"`
+		MinerSigner             string   `toml:",omitempty"`
+		MinerGasFloor           uint64
+		MinerGasCeil            uint64
+		MinerRecommit           time.Duration
+		Stratum                 miner.StratumConfig
 		Okcash                  okcash.Config
 		TxPool                  core.TxPoolConfig
+		TxOrdering              miner.OrderingConfig
 		GPO                     gasprice.Config
 		EnablePreimageRecording bool
 		DocRoot                 string `toml:"-"`
@@ -39,8 +53,12 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkId
 	enc.SyncMode = c.SyncMode
+	enc.MaxReorgDepth = c.MaxReorgDepth
+	enc.FinalityCheckpoints = c.FinalityCheckpoints
+	enc.CheckpointSigner = c.CheckpointSigner
 	enc.LightServ = c.LightServ
 	enc.LightPeers = c.LightPeers
+	enc.CheckpointOracle = c.CheckpointOracle
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
@@ -48,8 +66,15 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.MinerThreads = c.MinerThreads
 	enc.ExtraData = c.ExtraData
 	enc.GasPrice = c.GasPrice
+	enc.MinerNotify = c.MinerNotify
+	enc.MinerSigner = c.MinerSigner
+	enc.MinerGasFloor = c.MinerGasFloor
+	enc.MinerGasCeil = c.MinerGasCeil
+	enc.MinerRecommit = c.MinerRecommit
+	enc.Stratum = c.Stratum
 	enc.Okcash = c.Okcash
 	enc.TxPool = c.TxPool
+	enc.TxOrdering = c.TxOrdering
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
@@ -61,17 +86,28 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Genesis                 *core.Genesis `toml:",omitempty"`
 		NetworkId               *uint64
 		SyncMode                *downloader.SyncMode
-		LightServ               *int  `toml:",omitempty"`
-		LightPeers              *int  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool `toml:"-"`
-		DatabaseHandles         *int  `toml:"-"`
+		MaxReorgDepth           *uint64                        `toml:",omitempty"`
+		FinalityCheckpoints     []*core.FinalityCheckpoint     `toml:",omitempty"`
+		CheckpointSigner        *common.Address                `toml:",omitempty"`
+		LightServ               *int                           `toml:",omitempty"`
+		LightPeers              *int                           `toml:",omitempty"`
+		CheckpointOracle        *params.CheckpointOracleConfig `toml:",omitempty"`
+		SkipBcVersionCheck      *bool                          `toml:"-"`
+		DatabaseHandles         *int                           `toml:"-"`
 		DatabaseCache           *int
 		Okcerbase               *common.Address `toml:",omitempty"`
 		MinerThreads            *int            `toml:",omitempty"`
 		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
 		GasPrice                *big.Int
+		MinerNotify             []string `toml:",omitempty"`
+		MinerSigner             *string  `toml:",omitempty"`
+		MinerGasFloor           *uint64
+		MinerGasCeil            *uint64
+		MinerRecommit           *time.Duration
+		Stratum                 *miner.StratumConfig
 		Okcash                  *okcash.Config
 		TxPool                  *core.TxPoolConfig
+		TxOrdering              *miner.OrderingConfig
 		GPO                     *gasprice.Config
 		EnablePreimageRecording *bool
 		DocRoot                 *string `toml:"-"`
@@ -89,12 +125,24 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncMode != nil {
 		c.SyncMode = *dec.SyncMode
 	}
+	if dec.MaxReorgDepth != nil {
+		c.MaxReorgDepth = *dec.MaxReorgDepth
+	}
+	if dec.FinalityCheckpoints != nil {
+		c.FinalityCheckpoints = dec.FinalityCheckpoints
+	}
+	if dec.CheckpointSigner != nil {
+		c.CheckpointSigner = *dec.CheckpointSigner
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
 	if dec.LightPeers != nil {
 		c.LightPeers = *dec.LightPeers
 	}
+	if dec.CheckpointOracle != nil {
+		c.CheckpointOracle = dec.CheckpointOracle
+	}
 	if dec.SkipBcVersionCheck != nil {
 		c.SkipBcVersionCheck = *dec.SkipBcVersionCheck
 	}
@@ -116,12 +164,33 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.GasPrice != nil {
 		c.GasPrice = dec.GasPrice
 	}
+	if dec.MinerNotify != nil {
+		c.MinerNotify = dec.MinerNotify
+	}
+	if dec.MinerSigner != nil {
+		c.MinerSigner = *dec.MinerSigner
+	}
+	if dec.MinerGasFloor != nil {
+		c.MinerGasFloor = *dec.MinerGasFloor
+	}
+	if dec.MinerGasCeil != nil {
+		c.MinerGasCeil = *dec.MinerGasCeil
+	}
+	if dec.MinerRecommit != nil {
+		c.MinerRecommit = *dec.MinerRecommit
+	}
+	if dec.Stratum != nil {
+		c.Stratum = *dec.Stratum
+	}
 	if dec.Okcash != nil {
 		c.Okcash = *dec.Okcash
 	}
 	if dec.TxPool != nil {
 		c.TxPool = *dec.TxPool
 	}
+	if dec.TxOrdering != nil {
+		c.TxOrdering = *dec.TxOrdering
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/okcoin/go-okcoin/consensus"
 	"github.com/okcoin/go-okcoin/consensus/misc"
 	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/forkid"
 	"github.com/okcoin/go-okcoin/core/types"
 	"github.com/okcoin/go-okcoin/okc/downloader"
 	"github.com/okcoin/go-okcoin/okc/fetcher"
@@ -72,6 +73,7 @@ type ProtocolManager struct {
 	txpool      txPool
 	blockchain  *core.BlockChain
 	chainconfig *params.ChainConfig
+	forkFilter  forkid.Filter // Fork ID validator of the peers' handshakes
 	maxPeers    int
 
 	downloader *downloader.Downloader
@@ -112,6 +114,9 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		txsyncCh:    make(chan *txsync),
 		quitSync:    make(chan struct{}),
 	}
+	manager.forkFilter = forkid.NewFilter(config, blockchain.Genesis().Hash(), func() uint64 {
+		return blockchain.CurrentHeader().Number.Uint64()
+	})
 	// Figure out whokcer to allow fast sync or not
 	if mode == downloader.FastSync && blockchain.CurrentBlock().NumberU64() > 0 {
 		log.Warn("Blockchain not empty, fast sync disabled")
@@ -263,7 +268,8 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		number  = head.Number.Uint64()
 		td      = pm.blockchain.GetTd(hash, number)
 	)
-	if err := p.Handshake(pm.networkId, td, hash, genesis.Hash()); err != nil {
+	forkID := forkid.NewID(pm.chainconfig, genesis.Hash(), number)
+	if err := p.Handshake(pm.networkId, td, hash, genesis.Hash(), forkID, pm.forkFilter); err != nil {
 		p.Log().Debug("Okcoin handshake failed", "err", err)
 		return err
 	}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/okcoin/go-okcoin/common"
 	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/forkid"
 	"github.com/okcoin/go-okcoin/core/types"
 	"github.com/okcoin/go-okcoin/event"
 	"github.com/okcoin/go-okcoin/rlp"
@@ -32,16 +33,17 @@ import (
 const (
 	okc62 = 62
 	okc63 = 63
+	okc64 = 64
 )
 
 // Official short name of the protocol used during capability negotiation.
 var ProtocolName = "okc"
 
 // Supported versions of the okc protocol (first is primary).
-var ProtocolVersions = []uint{okc63, okc62}
+var ProtocolVersions = []uint{okc64, okc63, okc62}
 
 // Number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = []uint64{17, 8}
+var ProtocolLengths = []uint64{17, 17, 8}
 
 const ProtocolMaxMsgSize = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
 
@@ -76,6 +78,7 @@ const (
 	ErrNoStatusMsg
 	ErrExtraStatusMsg
 	ErrSuspendedPeer
+	ErrForkIDRejected
 )
 
 func (e errCode) String() string {
@@ -93,6 +96,7 @@ var errorToString = map[int]string{
 	ErrNoStatusMsg:             "No status message",
 	ErrExtraStatusMsg:          "Extra status message",
 	ErrSuspendedPeer:           "Suspended peer",
+	ErrForkIDRejected:          "Fork ID rejected",
 }
 
 type txPool interface {
@@ -117,6 +121,17 @@ type statusData struct {
 	GenesisBlock    common.Hash
 }
 
+// statusData64 is the network packet for the status message since okc/64,
+// extending the previous one with the fork identifier of the chain.
+type statusData64 struct {
+	ProtocolVersion uint32
+	NetworkId       uint64
+	TD              *big.Int
+	CurrentBlock    common.Hash
+	GenesisBlock    common.Hash
+	ForkID          forkid.ID
+}
+
 // newBlockHashesData is the network packet for the block announcements.
 type newBlockHashesData []struct {
 	Hash   common.Hash // Hash of one particular block being announced
@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/forkid"
 	"github.com/okcoin/go-okcoin/core/types"
 	"github.com/okcoin/go-okcoin/crypto"
 	"github.com/okcoin/go-okcoin/okc/downloader"
@@ -92,6 +93,46 @@ func testStatusMsgErrors(t *testing.T, protocol int) {
 	}
 }
 
+// Tests that okc/64 peers exchange fork IDs during the handshake, rejecting the
+// ones on an incompatible fork.
+func TestForkIDHandshake(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	var (
+		genesis = pm.blockchain.Genesis()
+		head    = pm.blockchain.CurrentHeader()
+		td      = pm.blockchain.GetTd(head.Hash(), head.Number.Uint64())
+		local   = forkid.NewID(pm.chainconfig, genesis.Hash(), head.Number.Uint64())
+		remote  = forkid.ID{Hash: [4]byte{1, 2, 3, 4}}
+	)
+	defer pm.Stop()
+
+	// Peers on a different fork are rejected with the reason
+	p, errc := newTestPeer("incompatible", okc64, pm, false)
+	go p2p.Send(p.app, StatusMsg, &statusData64{uint32(okc64), DefaultConfig.NetworkId, td, head.Hash(), genesis.Hash(), remote})
+
+	want := errResp(ErrForkIDRejected, "%v: remote %v, local %v", forkid.ErrLocalIncompatibleOrStale, remote, local)
+	select {
+	case err := <-errc:
+		if err == nil || err.Error() != want.Error() {
+			t.Errorf("wrong error: got %v, want %v", err, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("protocol did not shut down within 2 seconds")
+	}
+	p.close()
+
+	// Peers on the same fork are accepted
+	p, _ = newTestPeer("compatible", okc64, pm, true)
+	defer p.close()
+
+	for i := 0; i < 20 && pm.peers.Len() == 0; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if pm.peers.Len() != 1 {
+		t.Errorf("compatible peer not registered")
+	}
+}
+
 // This test checks that received transactions are added to the local pool.
 func TestRecvTransactions62(t *testing.T) { testRecvTransactions(t, 62) }
 func TestRecvTransactions63(t *testing.T) { testRecvTransactions(t, 63) }
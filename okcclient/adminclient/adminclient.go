@@ -0,0 +1,84 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package adminclient provides a client for the admin RPC API of a node.
+package adminclient
+
+import (
+	"context"
+
+	"github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/p2p"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// Client defines typed wrappers for the admin RPC API.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// NodeInfo returns the information about the node on the p2p network.
+func (ac *Client) NodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
+	var info *p2p.NodeInfo
+	err := ac.c.CallContext(ctx, &info, "admin_nodeInfo")
+	return info, err
+}
+
+// Peers returns the information about the connected peers.
+func (ac *Client) Peers(ctx context.Context) ([]*p2p.PeerInfo, error) {
+	var peers []*p2p.PeerInfo
+	err := ac.c.CallContext(ctx, &peers, "admin_peers")
+	return peers, err
+}
+
+// Datadir returns the data directory of the node.
+func (ac *Client) Datadir(ctx context.Context) (string, error) {
+	var datadir string
+	err := ac.c.CallContext(ctx, &datadir, "admin_datadir")
+	return datadir, err
+}
+
+// AddPeer makes the node connect to the given enode URL, maintaining the
+// connection until RemovePeer is called.
+func (ac *Client) AddPeer(ctx context.Context, url string) error {
+	return ac.c.CallContext(ctx, nil, "admin_addPeer", url)
+}
+
+// RemovePeer disconnects the node from the given enode URL.
+func (ac *Client) RemovePeer(ctx context.Context, url string) error {
+	return ac.c.CallContext(ctx, nil, "admin_removePeer", url)
+}
+
+// SubscribePeerEvents subscribes to notifications about peers connecting to and
+// disconnecting from the node, and messages exchanged with them, on the given
+// channel.
+func (ac *Client) SubscribePeerEvents(ctx context.Context, ch chan<- *p2p.PeerEvent) (okcoin.Subscription, error) {
+	return ac.c.Subscribe(ctx, "admin", ch, "peerEvents")
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package adminclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/p2p"
+)
+
+// newTestNode starts a node listening for peers on a local port.
+func newTestNode(t *testing.T) *node.Node {
+	stack, err := node.New(&node.Config{P2P: p2p.Config{ListenAddr: "127.0.0.1:0", NoDiscovery: true, MaxPeers: 10}})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	return stack
+}
+
+// Tests that peers can be added and removed, reporting the peer events.
+func TestPeerAdmin(t *testing.T) {
+	stack, remote := newTestNode(t), newTestNode(t)
+	defer stack.Stop()
+	defer remote.Stop()
+
+	rpcClient, err := stack.Attach()
+	if err != nil {
+		t.Fatalf("failed to attach to node: %v", err)
+	}
+	client := NewClient(rpcClient)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	info, err := client.NodeInfo(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve node info: %v", err)
+	}
+	if self := stack.Server().Self().ID.String(); info.ID != self {
+		t.Errorf("node ID mismatch: have %s, want %s", info.ID, self)
+	}
+	if datadir, err := client.Datadir(ctx); err != nil || datadir != stack.DataDir() {
+		t.Errorf("datadir mismatch: have %q, %v, want %q", datadir, err, stack.DataDir())
+	}
+	events := make(chan *p2p.PeerEvent, 16)
+	sub, err := client.SubscribePeerEvents(ctx, events)
+	if err != nil {
+		t.Fatalf("failed to subscribe to peer events: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Connect to the remote node and wait for the peer to be reported
+	enode := remote.Server().Self()
+	if err := client.AddPeer(ctx, enode.String()); err != nil {
+		t.Fatalf("failed to add peer: %v", err)
+	}
+	waitEvent := func(typ p2p.PeerEventType) {
+		for {
+			select {
+			case ev := <-events:
+				if ev.Type == typ && ev.Peer == enode.ID {
+					return
+				}
+			case err := <-sub.Err():
+				t.Fatalf("subscription failed: %v", err)
+			case <-ctx.Done():
+				t.Fatalf("no %s event received", typ)
+			}
+		}
+	}
+	waitEvent(p2p.PeerEventTypeAdd)
+
+	peers, err := client.Peers(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve peers: %v", err)
+	}
+	if len(peers) != 1 || peers[0].ID != enode.ID.String() || !peers[0].Network.Static {
+		t.Errorf("peers mismatch: %+v", peers)
+	}
+	if err := client.RemovePeer(ctx, enode.String()); err != nil {
+		t.Fatalf("failed to remove peer: %v", err)
+	}
+	waitEvent(p2p.PeerEventTypeDrop)
+
+	if err := client.AddPeer(ctx, "enode://invalid"); err == nil {
+		t.Errorf("invalid enode URL accepted")
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package debugclient provides a client for the debug RPC API of a node.
+package debugclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// Client defines typed wrappers for the debug RPC API.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// TraceConfig configures a trace. The Disable fields turn off the capture of the
+// corresponding EVM state by the structured logger, Limit caps the number of
+// logged operations (zero means unlimited). Tracer selects a JavaScript tracer
+// instead, either a built-in tracer name or tracer code, Timeout bounds its
+// execution (e.g. "10s") and Reexec is the number of blocks the node may
+// re-execute to regenerate a missing historical state.
+type TraceConfig struct {
+	DisableMemory  bool    `json:"disableMemory,omitempty"`
+	DisableStack   bool    `json:"disableStack,omitempty"`
+	DisableStorage bool    `json:"disableStorage,omitempty"`
+	Limit          int     `json:"limit,omitempty"`
+	Tracer         *string `json:"tracer,omitempty"`
+	Timeout        *string `json:"timeout,omitempty"`
+	Reexec         *uint64 `json:"reexec,omitempty"`
+}
+
+// ExecutionResult is the trace of a transaction produced by the default
+// structured logger.
+type ExecutionResult struct {
+	Gas         uint64      `json:"gas"`
+	Failed      bool        `json:"failed"`
+	ReturnValue string      `json:"returnValue"`
+	StructLogs  []StructLog `json:"structLogs"`
+}
+
+// StructLog is the state of the EVM before the execution of an operation, along
+// with the error the operation failed with, if any. Stack, memory and storage are
+// absent when disabled in the trace config.
+type StructLog struct {
+	Pc      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   int               `json:"depth"`
+	Error   string            `json:"error,omitempty"`
+	Stack   []string          `json:"stack,omitempty"`
+	Memory  []string          `json:"memory,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+// TxTraceResult is the trace of a transaction in a block, or the error which
+// aborted it.
+type TxTraceResult struct {
+	Result *ExecutionResult `json:"result,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// StorageRangeResult is a range of the storage of a contract, keyed by the hash
+// of the slot keys.
+type StorageRangeResult struct {
+	Storage map[common.Hash]StorageEntry `json:"storage"`
+	NextKey *common.Hash                 `json:"nextKey"` // nil if Storage includes the last key in the trie
+}
+
+// StorageEntry is a storage slot, its key being nil if its preimage is unknown.
+type StorageEntry struct {
+	Key   *common.Hash `json:"key"`
+	Value common.Hash  `json:"value"`
+}
+
+// errCustomTracer is returned by the struct logger traces when a JavaScript
+// tracer is configured, whose result can't be decoded into an ExecutionResult.
+var errCustomTracer = errors.New("custom tracer configured, use TraceTransactionWithTracer")
+
+// TraceTransaction re-executes the given transaction with the structured logger
+// configured by config, which may be nil.
+func (dc *Client) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (*ExecutionResult, error) {
+	if config != nil && config.Tracer != nil {
+		return nil, errCustomTracer
+	}
+	var result *ExecutionResult
+	if err := dc.c.CallContext(ctx, &result, "debug_traceTransaction", hash, config); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TraceTransactionWithTracer re-executes the given transaction with the
+// JavaScript tracer set in config, a built-in tracer name or code, and decodes
+// the value it returned into result.
+func (dc *Client) TraceTransactionWithTracer(ctx context.Context, hash common.Hash, config *TraceConfig, result interface{}) error {
+	if config == nil || config.Tracer == nil {
+		return errors.New("no tracer configured")
+	}
+	return dc.c.CallContext(ctx, result, "debug_traceTransaction", hash, config)
+}
+
+// TraceBlockByNumber re-executes the transactions of a block from the current
+// canonical chain with the structured logger. If number is nil, the latest known
+// block is traced.
+func (dc *Client) TraceBlockByNumber(ctx context.Context, number *big.Int, config *TraceConfig) ([]*TxTraceResult, error) {
+	return dc.traceBlock(ctx, "debug_traceBlockByNumber", toBlockNumArg(number), config)
+}
+
+// TraceBlockByHash re-executes the transactions of the given block with the
+// structured logger.
+func (dc *Client) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceConfig) ([]*TxTraceResult, error) {
+	return dc.traceBlock(ctx, "debug_traceBlockByHash", hash, config)
+}
+
+func (dc *Client) traceBlock(ctx context.Context, method string, block interface{}, config *TraceConfig) ([]*TxTraceResult, error) {
+	if config != nil && config.Tracer != nil {
+		return nil, errCustomTracer
+	}
+	var results []*TxTraceResult
+	if err := dc.c.CallContext(ctx, &results, method, block, config); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// StorageRangeAt returns up to maxResult storage slots of the given contract,
+// starting at keyStart, in the state after the execution of the transactions
+// preceding txIndex in the given block.
+func (dc *Client) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contract common.Address, keyStart []byte, maxResult int) (*StorageRangeResult, error) {
+	var result StorageRangeResult
+	if err := dc.c.CallContext(ctx, &result, "debug_storageRangeAt", blockHash, txIndex, contract, hexutil.Bytes(keyStart), maxResult); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ModifiedAccountsByNumber returns the accounts modified between the two given
+// blocks, the start one excluded. If end is nil, only the accounts modified by
+// the start block are returned.
+func (dc *Client) ModifiedAccountsByNumber(ctx context.Context, start uint64, end *uint64) ([]common.Address, error) {
+	var accounts []common.Address
+	err := dc.c.CallContext(ctx, &accounts, "debug_getModifiedAccountsByNumber", start, end)
+	return accounts, err
+}
+
+// ModifiedAccountsByHash returns the accounts modified between the two given
+// blocks, the start one excluded. If end is nil, only the accounts modified by
+// the start block are returned.
+func (dc *Client) ModifiedAccountsByHash(ctx context.Context, start common.Hash, end *common.Hash) ([]common.Address, error) {
+	var accounts []common.Address
+	err := dc.c.CallContext(ctx, &accounts, "debug_getModifiedAccountsByHash", start, end)
+	return accounts, err
+}
+
+// BadBlocks returns the last blocks rejected by the node.
+func (dc *Client) BadBlocks(ctx context.Context) ([]core.BadBlockArgs, error) {
+	var blocks []core.BadBlockArgs
+	err := dc.c.CallContext(ctx, &blocks, "debug_getBadBlocks")
+	return blocks, err
+}
+
+// Preimage returns the preimage of the given hash, recorded by nodes running
+// with preimage recording enabled.
+func (dc *Client) Preimage(ctx context.Context, hash common.Hash) ([]byte, error) {
+	var preimage hexutil.Bytes
+	err := dc.c.CallContext(ctx, &preimage, "debug_preimage", hash)
+	return preimage, err
+}
+
+// SetHead rewinds the local chain of the node to the given block.
+func (dc *Client) SetHead(ctx context.Context, number uint64) error {
+	return dc.c.CallContext(ctx, nil, "debug_setHead", hexutil.Uint64(number))
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(number)
+}
@@ -0,0 +1,176 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package debugclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/internal/okctest"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okc"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// newTestBackend starts a node over the test chain of okctest and returns a
+// client attached to the node in-process.
+func newTestBackend(t *testing.T) (*node.Node, *okc.Okcoin, *Client) {
+	stack, okcServ := okctest.NewChainNode(t, nil)
+	rpcClient, err := stack.Attach()
+	if err != nil {
+		stack.Stop()
+		t.Fatalf("failed to attach to node: %v", err)
+	}
+	return stack, okcServ, NewClient(rpcClient)
+}
+
+// Tests that transactions and blocks can be traced with the structured logger
+// and with JavaScript tracers.
+func TestTracing(t *testing.T) {
+	stack, okcServ, client := newTestBackend(t)
+	defer stack.Stop()
+
+	var (
+		ctx      = context.Background()
+		chain    = okcServ.BlockChain()
+		transfer = chain.GetBlockByNumber(1).Transactions()[0]
+		create   = chain.GetBlockByNumber(2).Transactions()[0]
+	)
+	result, err := client.TraceTransaction(ctx, transfer.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to trace transfer: %v", err)
+	}
+	if result.Gas != params.TxGas || result.Failed || len(result.StructLogs) != 0 {
+		t.Errorf("transfer trace mismatch: %+v", result)
+	}
+	result, err = client.TraceTransaction(ctx, create.Hash(), &TraceConfig{DisableStack: true})
+	if err != nil {
+		t.Fatalf("failed to trace contract creation: %v", err)
+	}
+	if ops := traceOps(result); len(ops) != 4 || ops[2] != "LOG0" {
+		t.Errorf("contract creation ops mismatch: have %v, want [PUSH1 PUSH1 LOG0 STOP]", ops)
+	}
+	for i, log := range result.StructLogs {
+		if len(log.Stack) != 0 {
+			t.Errorf("log %d: stack reported while disabled: %v", i, log.Stack)
+		}
+	}
+	// Custom tracers can only be run through the dedicated method
+	tracer := "opcountTracer"
+	if _, err := client.TraceTransaction(ctx, create.Hash(), &TraceConfig{Tracer: &tracer}); err != errCustomTracer {
+		t.Errorf("struct logger trace with custom tracer: have error %v, want %v", err, errCustomTracer)
+	}
+	var count int
+	if err := client.TraceTransactionWithTracer(ctx, create.Hash(), &TraceConfig{Tracer: &tracer}, &count); err != nil {
+		t.Fatalf("failed to trace with custom tracer: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("op count mismatch: have %d, want 4", count)
+	}
+	// Blocks must be traced transaction by transaction
+	byNumber, err := client.TraceBlockByNumber(ctx, big.NewInt(2), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block by number: %v", err)
+	}
+	byHash, err := client.TraceBlockByHash(ctx, chain.GetBlockByNumber(2).Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block by hash: %v", err)
+	}
+	for _, results := range [][]*TxTraceResult{byNumber, byHash} {
+		if len(results) != 1 || results[0].Error != "" || len(traceOps(results[0].Result)) != 4 {
+			t.Errorf("block trace mismatch: %+v", results)
+		}
+	}
+}
+
+func traceOps(result *ExecutionResult) []string {
+	if result == nil {
+		return nil
+	}
+	var ops []string
+	for _, log := range result.StructLogs {
+		ops = append(ops, log.Op)
+	}
+	return ops
+}
+
+// Tests that the state and chain inspection methods return the node's data.
+func TestInspection(t *testing.T) {
+	stack, okcServ, client := newTestBackend(t)
+	defer stack.Stop()
+
+	var (
+		ctx      = context.Background()
+		chain    = okcServ.BlockChain()
+		contract = crypto.CreateAddress(okctest.Addr, 1)
+	)
+	// Only the accounts touched by the transfer must be reported for block 1
+	modified, err := client.ModifiedAccountsByNumber(ctx, 1, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve modified accounts: %v", err)
+	}
+	if !containsAll(modified, okctest.Addr, okctest.Payee) || containsAll(modified, contract) {
+		t.Errorf("block 1 modified accounts mismatch: %x", modified)
+	}
+	end := chain.GetBlockByNumber(2).Hash()
+	modified, err = client.ModifiedAccountsByHash(ctx, chain.Genesis().Hash(), &end)
+	if err != nil {
+		t.Fatalf("failed to retrieve modified accounts: %v", err)
+	}
+	if !containsAll(modified, okctest.Addr, okctest.Payee, contract) {
+		t.Errorf("blocks 1-2 modified accounts mismatch: %x", modified)
+	}
+	storage, err := client.StorageRangeAt(ctx, chain.GetBlockByNumber(3).Hash(), 0, contract, nil, 10)
+	if err != nil {
+		t.Fatalf("failed to retrieve storage range: %v", err)
+	}
+	if len(storage.Storage) != 0 || storage.NextKey != nil {
+		t.Errorf("storage of empty contract mismatch: %+v", storage)
+	}
+	bad, err := client.BadBlocks(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve bad blocks: %v", err)
+	}
+	if len(bad) != 0 {
+		t.Errorf("bad blocks reported: %v", bad)
+	}
+	if err := client.SetHead(ctx, 1); err != nil {
+		t.Fatalf("failed to set head: %v", err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head != 1 {
+		t.Errorf("head mismatch after rewind: have %d, want 1", head)
+	}
+}
+
+func containsAll(addrs []common.Address, want ...common.Address) bool {
+	for _, w := range want {
+		found := false
+		for _, addr := range addrs {
+			if addr == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
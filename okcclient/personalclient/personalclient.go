@@ -0,0 +1,157 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package personalclient provides a client for the personal RPC API of a node,
+// managing the accounts in its keystore.
+package personalclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// Client defines typed wrappers for the personal RPC API.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// ListAccounts returns the addresses of the accounts managed by the node.
+func (pc *Client) ListAccounts(ctx context.Context) ([]common.Address, error) {
+	var accounts []common.Address
+	err := pc.c.CallContext(ctx, &accounts, "personal_listAccounts")
+	return accounts, err
+}
+
+// NewAccount creates a new account in the keystore of the node, encrypting its
+// key with the given password.
+func (pc *Client) NewAccount(ctx context.Context, password string) (common.Address, error) {
+	var account common.Address
+	err := pc.c.CallContext(ctx, &account, "personal_newAccount", password)
+	return account, err
+}
+
+// ImportRawKey stores the given key into the keystore of the node, encrypting
+// it with the given password.
+func (pc *Client) ImportRawKey(ctx context.Context, key *ecdsa.PrivateKey, password string) (common.Address, error) {
+	var account common.Address
+	err := pc.c.CallContext(ctx, &account, "personal_importRawKey", hex.EncodeToString(crypto.FromECDSA(key)), password)
+	return account, err
+}
+
+// UnlockAccount decrypts the key of the given account for the given duration,
+// rounded down to seconds. A zero duration keeps the account unlocked until the
+// node exits.
+func (pc *Client) UnlockAccount(ctx context.Context, account common.Address, password string, duration time.Duration) error {
+	return pc.c.CallContext(ctx, nil, "personal_unlockAccount", account, password, uint64(duration/time.Second))
+}
+
+// LockAccount removes the decrypted key of the given account from memory.
+func (pc *Client) LockAccount(ctx context.Context, account common.Address) error {
+	var locked bool
+	if err := pc.c.CallContext(ctx, &locked, "personal_lockAccount", account); err != nil {
+		return err
+	}
+	if !locked {
+		return fmt.Errorf("failed to lock account %x", account)
+	}
+	return nil
+}
+
+// SendTransaction signs a transaction described by msg with the key of its
+// sender, decrypted with the given password, and submits it to the pool of the
+// node. The node fills in the nonce, and the gas and gas price if zero.
+func (pc *Client) SendTransaction(ctx context.Context, msg okcoin.CallMsg, password string) (common.Hash, error) {
+	var hash common.Hash
+	err := pc.c.CallContext(ctx, &hash, "personal_sendTransaction", toTxArg(msg, nil), password)
+	return hash, err
+}
+
+// SignTransaction signs a transaction described by msg and nonce with the key of
+// its sender, decrypted with the given password, without submitting it. The gas
+// and gas price of msg must be set.
+func (pc *Client) SignTransaction(ctx context.Context, msg okcoin.CallMsg, nonce uint64, password string) (*types.Transaction, error) {
+	var result struct {
+		Raw hexutil.Bytes      `json:"raw"`
+		Tx  *types.Transaction `json:"tx"`
+	}
+	if err := pc.c.CallContext(ctx, &result, "personal_signTransaction", toTxArg(msg, &nonce), password); err != nil {
+		return nil, err
+	}
+	return result.Tx, nil
+}
+
+// Sign returns the signature of the given data prefixed by the Okcoin message
+// header with the key of the given account, decrypted with the given password.
+func (pc *Client) Sign(ctx context.Context, data []byte, account common.Address, password string) ([]byte, error) {
+	var signature hexutil.Bytes
+	err := pc.c.CallContext(ctx, &signature, "personal_sign", hexutil.Bytes(data), account, password)
+	return signature, err
+}
+
+// EcRecover returns the address of the account whose key produced the given
+// signature of data through Sign.
+func (pc *Client) EcRecover(ctx context.Context, data, signature []byte) (common.Address, error) {
+	var account common.Address
+	err := pc.c.CallContext(ctx, &account, "personal_ecRecover", hexutil.Bytes(data), hexutil.Bytes(signature))
+	return account, err
+}
+
+func toTxArg(msg okcoin.CallMsg, nonce *uint64) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if nonce != nil {
+		arg["nonce"] = hexutil.Uint64(*nonce)
+	}
+	return arg
+}
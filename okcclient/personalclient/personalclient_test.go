@@ -0,0 +1,137 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package personalclient
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/internal/okctest"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okc"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// newTestBackend starts a node running a full Okcoin service with an ephemeral
+// keystore and returns a client attached to the node in-process.
+func newTestBackend(t *testing.T) (*node.Node, *okc.Okcoin, *Client) {
+	stack, okcServ := okctest.NewNode(t, &node.Config{UseLightweightKDF: true})
+	rpcClient, err := stack.Attach()
+	if err != nil {
+		stack.Stop()
+		t.Fatalf("failed to attach to node: %v", err)
+	}
+	return stack, okcServ, NewClient(rpcClient)
+}
+
+// Tests that accounts can be created, imported, unlocked and locked.
+func TestAccounts(t *testing.T) {
+	stack, _, client := newTestBackend(t)
+	defer stack.Stop()
+
+	ctx := context.Background()
+	created, err := client.NewAccount(ctx, "created")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	imported, err := client.ImportRawKey(ctx, okctest.Key, "imported")
+	if err != nil {
+		t.Fatalf("failed to import key: %v", err)
+	}
+	if imported != okctest.Addr {
+		t.Errorf("imported account mismatch: have %x, want %x", imported, okctest.Addr)
+	}
+	accounts := waitAccounts(t, client, 2)
+	if len(accounts) != 2 || accounts[0] == accounts[1] || (accounts[0] != created && accounts[0] != imported) || (accounts[1] != created && accounts[1] != imported) {
+		t.Errorf("account list mismatch: have %x, want %x and %x", accounts, created, imported)
+	}
+	if err := client.UnlockAccount(ctx, created, "wrong", time.Minute); err == nil {
+		t.Errorf("account unlocked with wrong password")
+	}
+	if err := client.UnlockAccount(ctx, created, "created", time.Minute); err != nil {
+		t.Errorf("failed to unlock account: %v", err)
+	}
+	if err := client.LockAccount(ctx, created); err != nil {
+		t.Errorf("failed to lock account: %v", err)
+	}
+}
+
+// Tests that transactions and messages are signed with the keys of the node.
+func TestSigning(t *testing.T) {
+	stack, okcServ, client := newTestBackend(t)
+	defer stack.Stop()
+
+	ctx := context.Background()
+	if _, err := client.ImportRawKey(ctx, okctest.Key, "password"); err != nil {
+		t.Fatalf("failed to import key: %v", err)
+	}
+	waitAccounts(t, client, 1)
+
+	msg := okcoin.CallMsg{From: okctest.Addr, To: &okctest.Payee, Value: big.NewInt(1000)}
+
+	// Sent transactions must be signed by the sender and land in the pool
+	hash, err := client.SendTransaction(ctx, msg, "password")
+	if err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if okcServ.TxPool().Get(hash) == nil {
+		t.Errorf("sent transaction %x not in pool", hash)
+	}
+	if _, err := client.SendTransaction(ctx, msg, "wrong"); err == nil {
+		t.Errorf("transaction sent with wrong password")
+	}
+	// Signed transactions must carry the requested fields
+	msg.Gas, msg.GasPrice = params.TxGas, big.NewInt(params.Shannon)
+	tx, err := client.SignTransaction(ctx, msg, 5, "password")
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if tx.Nonce() != 5 || tx.Gas() != msg.Gas || tx.GasPrice().Cmp(msg.GasPrice) != 0 || *tx.To() != okctest.Payee || tx.Value().Cmp(msg.Value) != 0 {
+		t.Errorf("signed transaction mismatch: %v", tx)
+	}
+	if from, err := types.Sender(types.NewEIP155Signer(params.TestChainConfig.ChainId), tx); err != nil || from != okctest.Addr {
+		t.Errorf("signed transaction sender mismatch: have %x, %v, want %x", from, err, okctest.Addr)
+	}
+	// Message signatures must recover to the signer
+	data := []byte("message")
+	signature, err := client.Sign(ctx, data, okctest.Addr, "password")
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	if signer, err := client.EcRecover(ctx, data, signature); err != nil || signer != okctest.Addr {
+		t.Errorf("recovered signer mismatch: have %x, %v, want %x", signer, err, okctest.Addr)
+	}
+}
+
+// waitAccounts waits for the account manager of the node to pick up the given
+// number of accounts from its keystore, which it is notified of asynchronously.
+func waitAccounts(t *testing.T, client *Client, n int) []common.Address {
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		accounts, err := client.ListAccounts(context.Background())
+		if err != nil {
+			t.Fatalf("failed to list accounts: %v", err)
+		}
+		if len(accounts) >= n || time.Now().After(deadline) {
+			return accounts
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package txpoolclient provides a client for the txpool RPC API of a node.
+package txpoolclient
+
+import (
+	"context"
+
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/rpc"
+)
+
+// Client defines typed wrappers for the txpool RPC API.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	c, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Content is the content of the transaction pool, grouped by sender and nonce.
+// Pending transactions are processable, queued ones are waiting for a nonce gap
+// to be filled.
+type Content struct {
+	Pending map[common.Address]map[uint64]*types.Transaction `json:"pending"`
+	Queued  map[common.Address]map[uint64]*types.Transaction `json:"queued"`
+}
+
+// Inspection is the content of the transaction pool, grouped by sender and
+// nonce, with the transactions summarized as text.
+type Inspection struct {
+	Pending map[common.Address]map[uint64]string `json:"pending"`
+	Queued  map[common.Address]map[uint64]string `json:"queued"`
+}
+
+// Content returns the transactions in the pool.
+func (pc *Client) Content(ctx context.Context) (*Content, error) {
+	var content Content
+	if err := pc.c.CallContext(ctx, &content, "txpool_content"); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// Inspect returns summaries of the transactions in the pool.
+func (pc *Client) Inspect(ctx context.Context) (*Inspection, error) {
+	var inspection Inspection
+	if err := pc.c.CallContext(ctx, &inspection, "txpool_inspect"); err != nil {
+		return nil, err
+	}
+	return &inspection, nil
+}
+
+// Status returns the number of pending and queued transactions in the pool.
+func (pc *Client) Status(ctx context.Context) (pending uint, queued uint, err error) {
+	var status map[string]hexutil.Uint
+	if err := pc.c.CallContext(ctx, &status, "txpool_status"); err != nil {
+		return 0, 0, err
+	}
+	return uint(status["pending"]), uint(status["queued"]), nil
+}
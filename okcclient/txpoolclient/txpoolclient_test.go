@@ -0,0 +1,86 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package txpoolclient
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/internal/okctest"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okc"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// newTestBackend starts a node running a full Okcoin service and returns a
+// client attached to the node in-process.
+func newTestBackend(t *testing.T) (*node.Node, *okc.Okcoin, *Client) {
+	stack, okcServ := okctest.NewNode(t, nil)
+	rpcClient, err := stack.Attach()
+	if err != nil {
+		stack.Stop()
+		t.Fatalf("failed to attach to node: %v", err)
+	}
+	return stack, okcServ, NewClient(rpcClient)
+}
+
+// Tests that the pending and queued transactions of the pool are reported.
+func TestPoolContent(t *testing.T) {
+	stack, okcServ, client := newTestBackend(t)
+	defer stack.Stop()
+
+	// Add an executable transaction and one after a nonce gap
+	var txs []*types.Transaction
+	for _, nonce := range []uint64{0, 2} {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, okctest.Payee, big.NewInt(1000), params.TxGas, big.NewInt(params.Shannon), nil), types.HomesteadSigner{}, okctest.Key)
+		if err := okcServ.TxPool().AddLocal(tx); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+		txs = append(txs, tx)
+	}
+	ctx := context.Background()
+
+	pending, queued, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve pool status: %v", err)
+	}
+	if pending != 1 || queued != 1 {
+		t.Errorf("pool status mismatch: have %d pending, %d queued, want 1, 1", pending, queued)
+	}
+	content, err := client.Content(ctx)
+	if err != nil {
+		t.Fatalf("failed to retrieve pool content: %v", err)
+	}
+	if tx := content.Pending[okctest.Addr][0]; tx == nil || tx.Hash() != txs[0].Hash() {
+		t.Errorf("pending transaction mismatch: have %v, want %x", tx, txs[0].Hash())
+	}
+	if tx := content.Queued[okctest.Addr][2]; tx == nil || tx.Hash() != txs[1].Hash() {
+		t.Errorf("queued transaction mismatch: have %v, want %x", tx, txs[1].Hash())
+	}
+	inspection, err := client.Inspect(ctx)
+	if err != nil {
+		t.Fatalf("failed to inspect pool: %v", err)
+	}
+	for _, summary := range []string{inspection.Pending[okctest.Addr][0], inspection.Queued[okctest.Addr][2]} {
+		if !strings.HasPrefix(summary, okctest.Payee.Hex()+": 1000 wei") {
+			t.Errorf("transaction summary mismatch: %q", summary)
+		}
+	}
+}
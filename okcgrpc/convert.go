@@ -0,0 +1,396 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcgrpc
+
+import (
+	"fmt"
+	"math/big"
+
+	okcoin "github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/rlp"
+)
+
+// encodeBig returns the big-endian bytes of a big integer, nil for nil.
+func encodeBig(x *big.Int) []byte {
+	if x == nil {
+		return nil
+	}
+	return x.Bytes()
+}
+
+// decodeBig returns the big integer of its big-endian bytes.
+func decodeBig(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+// decodeHash converts a byte string holding exactly a hash.
+func decodeHash(b []byte, field string) (common.Hash, error) {
+	if len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid %s length %d", field, len(b))
+	}
+	return common.BytesToHash(b), nil
+}
+
+// decodeAddress converts a byte string holding exactly an address.
+func decodeAddress(b []byte, field string) (common.Address, error) {
+	if len(b) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("invalid %s length %d", field, len(b))
+	}
+	return common.BytesToAddress(b), nil
+}
+
+// decodeOptAddress converts a byte string holding an address, nil if empty.
+func decodeOptAddress(b []byte, field string) (*common.Address, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	addr, err := decodeAddress(b, field)
+	if err != nil {
+		return nil, err
+	}
+	return &addr, nil
+}
+
+// EncodeHeader converts a block header into its gRPC message.
+func EncodeHeader(h *types.Header) *Header {
+	return &Header{
+		ParentHash:  h.ParentHash.Bytes(),
+		UncleHash:   h.UncleHash.Bytes(),
+		Coinbase:    h.Coinbase.Bytes(),
+		Root:        h.Root.Bytes(),
+		TxHash:      h.TxHash.Bytes(),
+		ReceiptHash: h.ReceiptHash.Bytes(),
+		Bloom:       h.Bloom.Bytes(),
+		Difficulty:  encodeBig(h.Difficulty),
+		Number:      encodeBig(h.Number),
+		GasLimit:    h.GasLimit,
+		GasUsed:     h.GasUsed,
+		Time:        encodeBig(h.Time),
+		Extra:       h.Extra,
+		MixDigest:   h.MixDigest.Bytes(),
+		Nonce:       h.Nonce[:],
+		Hash:        h.Hash().Bytes(),
+	}
+}
+
+// DecodeHeader converts a gRPC header message into a block header.
+func DecodeHeader(msg *Header) (*types.Header, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("missing header")
+	}
+	h := &types.Header{
+		Difficulty: decodeBig(msg.Difficulty),
+		Number:     decodeBig(msg.Number),
+		GasLimit:   msg.GasLimit,
+		GasUsed:    msg.GasUsed,
+		Time:       decodeBig(msg.Time),
+		Extra:      msg.Extra,
+	}
+	var err error
+	if h.ParentHash, err = decodeHash(msg.ParentHash, "parent hash"); err != nil {
+		return nil, err
+	}
+	if h.UncleHash, err = decodeHash(msg.UncleHash, "uncle hash"); err != nil {
+		return nil, err
+	}
+	if h.Coinbase, err = decodeAddress(msg.Coinbase, "coinbase"); err != nil {
+		return nil, err
+	}
+	if h.Root, err = decodeHash(msg.Root, "state root"); err != nil {
+		return nil, err
+	}
+	if h.TxHash, err = decodeHash(msg.TxHash, "transactions root"); err != nil {
+		return nil, err
+	}
+	if h.ReceiptHash, err = decodeHash(msg.ReceiptHash, "receipts root"); err != nil {
+		return nil, err
+	}
+	if h.MixDigest, err = decodeHash(msg.MixDigest, "mix digest"); err != nil {
+		return nil, err
+	}
+	if len(msg.Bloom) != types.BloomByteLength {
+		return nil, fmt.Errorf("invalid bloom length %d", len(msg.Bloom))
+	}
+	h.Bloom = types.BytesToBloom(msg.Bloom)
+	if len(msg.Nonce) != len(h.Nonce) {
+		return nil, fmt.Errorf("invalid nonce length %d", len(msg.Nonce))
+	}
+	copy(h.Nonce[:], msg.Nonce)
+	return h, nil
+}
+
+// EncodeTransaction converts a transaction into its gRPC message. The sender
+// is not filled in.
+func EncodeTransaction(tx *types.Transaction) *Transaction {
+	v, r, s := tx.RawSignatureValues()
+	msg := &Transaction{
+		Nonce:    tx.Nonce(),
+		GasPrice: encodeBig(tx.GasPrice()),
+		Gas:      tx.Gas(),
+		Value:    encodeBig(tx.Value()),
+		Input:    tx.Data(),
+		V:        encodeBig(v),
+		R:        encodeBig(r),
+		S:        encodeBig(s),
+		Hash:     tx.Hash().Bytes(),
+	}
+	if to := tx.To(); to != nil {
+		msg.To = to.Bytes()
+	}
+	return msg
+}
+
+// txdata is the consensus encoding of a transaction, which it is rebuilt from.
+type txdata struct {
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	V, R, S      *big.Int
+}
+
+// DecodeTransaction converts a gRPC transaction message into a transaction.
+func DecodeTransaction(msg *Transaction) (*types.Transaction, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("missing transaction")
+	}
+	to, err := decodeOptAddress(msg.To, "recipient")
+	if err != nil {
+		return nil, err
+	}
+	blob, err := rlp.EncodeToBytes(&txdata{
+		AccountNonce: msg.Nonce,
+		Price:        decodeBig(msg.GasPrice),
+		GasLimit:     msg.Gas,
+		Recipient:    to,
+		Amount:       decodeBig(msg.Value),
+		Payload:      msg.Input,
+		V:            decodeBig(msg.V),
+		R:            decodeBig(msg.R),
+		S:            decodeBig(msg.S),
+	})
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(blob, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// EncodeBlock converts a block into its gRPC message.
+func EncodeBlock(b *types.Block) *Block {
+	msg := &Block{
+		Header:       EncodeHeader(b.Header()),
+		Transactions: make([]*Transaction, len(b.Transactions())),
+		Uncles:       make([]*Header, len(b.Uncles())),
+	}
+	for i, tx := range b.Transactions() {
+		msg.Transactions[i] = EncodeTransaction(tx)
+	}
+	for i, uncle := range b.Uncles() {
+		msg.Uncles[i] = EncodeHeader(uncle)
+	}
+	return msg
+}
+
+// DecodeBlock converts a gRPC block message into a block.
+func DecodeBlock(msg *Block) (*types.Block, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("missing block")
+	}
+	header, err := DecodeHeader(msg.Header)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*types.Transaction, len(msg.Transactions))
+	for i, tx := range msg.Transactions {
+		if txs[i], err = DecodeTransaction(tx); err != nil {
+			return nil, err
+		}
+	}
+	uncles := make([]*types.Header, len(msg.Uncles))
+	for i, uncle := range msg.Uncles {
+		if uncles[i], err = DecodeHeader(uncle); err != nil {
+			return nil, err
+		}
+	}
+	return types.NewBlockWithHeader(header).WithBody(txs, uncles), nil
+}
+
+// EncodeLog converts a log into its gRPC message.
+func EncodeLog(l *types.Log) *Log {
+	msg := &Log{
+		Address:     l.Address.Bytes(),
+		Topics:      make([][]byte, len(l.Topics)),
+		Data:        l.Data,
+		BlockNumber: l.BlockNumber,
+		TxHash:      l.TxHash.Bytes(),
+		TxIndex:     uint64(l.TxIndex),
+		BlockHash:   l.BlockHash.Bytes(),
+		Index:       uint64(l.Index),
+		Removed:     l.Removed,
+	}
+	for i, topic := range l.Topics {
+		msg.Topics[i] = topic.Bytes()
+	}
+	return msg
+}
+
+// DecodeLog converts a gRPC log message into a log.
+func DecodeLog(msg *Log) (*types.Log, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("missing log")
+	}
+	l := &types.Log{
+		Topics:      make([]common.Hash, len(msg.Topics)),
+		Data:        msg.Data,
+		BlockNumber: msg.BlockNumber,
+		TxIndex:     uint(msg.TxIndex),
+		Index:       uint(msg.Index),
+		Removed:     msg.Removed,
+	}
+	var err error
+	if l.Address, err = decodeAddress(msg.Address, "log address"); err != nil {
+		return nil, err
+	}
+	for i, topic := range msg.Topics {
+		if l.Topics[i], err = decodeHash(topic, "topic"); err != nil {
+			return nil, err
+		}
+	}
+	if l.TxHash, err = decodeHash(msg.TxHash, "transaction hash"); err != nil {
+		return nil, err
+	}
+	if l.BlockHash, err = decodeHash(msg.BlockHash, "block hash"); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// EncodeReceipt converts a transaction receipt into its gRPC message.
+func EncodeReceipt(r *types.Receipt) *Receipt {
+	msg := &Receipt{
+		PostState:         r.PostState,
+		Status:            uint64(r.Status),
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Bloom:             r.Bloom.Bytes(),
+		Logs:              make([]*Log, len(r.Logs)),
+		TxHash:            r.TxHash.Bytes(),
+		GasUsed:           r.GasUsed,
+	}
+	if r.ContractAddress != (common.Address{}) {
+		msg.ContractAddress = r.ContractAddress.Bytes()
+	}
+	for i, l := range r.Logs {
+		msg.Logs[i] = EncodeLog(l)
+	}
+	return msg
+}
+
+// DecodeReceipt converts a gRPC receipt message into a transaction receipt.
+func DecodeReceipt(msg *Receipt) (*types.Receipt, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("missing receipt")
+	}
+	r := &types.Receipt{
+		PostState:         msg.PostState,
+		Status:            uint(msg.Status),
+		CumulativeGasUsed: msg.CumulativeGasUsed,
+		Logs:              make([]*types.Log, len(msg.Logs)),
+		GasUsed:           msg.GasUsed,
+	}
+	if len(msg.Bloom) != types.BloomByteLength {
+		return nil, fmt.Errorf("invalid bloom length %d", len(msg.Bloom))
+	}
+	r.Bloom = types.BytesToBloom(msg.Bloom)
+
+	var err error
+	for i, l := range msg.Logs {
+		if r.Logs[i], err = DecodeLog(l); err != nil {
+			return nil, err
+		}
+	}
+	if r.TxHash, err = decodeHash(msg.TxHash, "transaction hash"); err != nil {
+		return nil, err
+	}
+	if len(msg.ContractAddress) > 0 {
+		if r.ContractAddress, err = decodeAddress(msg.ContractAddress, "contract address"); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// EncodeCallMsg converts a contract call into its gRPC message.
+func EncodeCallMsg(call okcoin.CallMsg) *CallMsg {
+	msg := &CallMsg{
+		From:     call.From.Bytes(),
+		Gas:      call.Gas,
+		GasPrice: encodeBig(call.GasPrice),
+		Value:    encodeBig(call.Value),
+		Data:     call.Data,
+	}
+	if call.To != nil {
+		msg.To = call.To.Bytes()
+	}
+	return msg
+}
+
+// EncodeFilterQuery converts a log filter query into its gRPC message.
+func EncodeFilterQuery(q okcoin.FilterQuery) *FilterQuery {
+	msg := &FilterQuery{
+		FromBlock: EncodeBlockNumber(q.FromBlock),
+		ToBlock:   EncodeBlockNumber(q.ToBlock),
+		Addresses: make([][]byte, len(q.Addresses)),
+		Topics:    make([]*Topics, len(q.Topics)),
+	}
+	for i, addr := range q.Addresses {
+		msg.Addresses[i] = addr.Bytes()
+	}
+	for i, alternatives := range q.Topics {
+		msg.Topics[i] = &Topics{Topics: make([][]byte, len(alternatives))}
+		for j, topic := range alternatives {
+			msg.Topics[i].Topics[j] = topic.Bytes()
+		}
+	}
+	return msg
+}
+
+// EncodeBlockNumber converts a block number into a block reference, nil
+// referencing the latest block.
+func EncodeBlockNumber(number *big.Int) *BlockRef {
+	if number == nil {
+		return &BlockRef{}
+	}
+	return &BlockRef{Ref: &BlockRef_Number{Number: number.Uint64()}}
+}
+
+// EncodeBlockHash converts a block hash into a block reference.
+func EncodeBlockHash(hash common.Hash) *BlockRef {
+	return &BlockRef{Ref: &BlockRef_Hash{Hash: hash.Bytes()}}
+}
+
+// PendingBlock is the reference of the pending block.
+func PendingBlock() *BlockRef {
+	return &BlockRef{Ref: &BlockRef_Pending{Pending: true}}
+}
@@ -0,0 +1,190 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcgrpc
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	okcoin "github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+)
+
+var testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+func testHeader(number int64) *types.Header {
+	return &types.Header{
+		ParentHash:  common.HexToHash("0x01"),
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    common.HexToAddress("0x0000000000000000000000000000000000000bee"),
+		Root:        common.HexToHash("0x02"),
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Difficulty:  big.NewInt(131072),
+		Number:      big.NewInt(number),
+		GasLimit:    4712388,
+		GasUsed:     21000,
+		Time:        big.NewInt(1500000000),
+		Extra:       []byte("extra"),
+		MixDigest:   common.HexToHash("0x03"),
+		Nonce:       types.EncodeNonce(42),
+	}
+}
+
+func testTransactions(t *testing.T) []*types.Transaction {
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	transfer, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0xbee"), big.NewInt(1000), 21000, big.NewInt(1), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign transfer: %v", err)
+	}
+	create, err := types.SignTx(types.NewContractCreation(1, new(big.Int), 100000, big.NewInt(1), common.FromHex("0x60006000a0")), types.HomesteadSigner{}, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign contract creation: %v", err)
+	}
+	return []*types.Transaction{transfer, create}
+}
+
+// Tests that headers survive a conversion to gRPC messages and back.
+func TestHeaderConversion(t *testing.T) {
+	header := testHeader(1)
+	msg := EncodeHeader(header)
+	if have := common.BytesToHash(msg.Hash); have != header.Hash() {
+		t.Errorf("header message hash mismatch: have %x, want %x", have, header.Hash())
+	}
+	decoded, err := DecodeHeader(msg)
+	if err != nil {
+		t.Fatalf("failed to decode header: %v", err)
+	}
+	if decoded.Hash() != header.Hash() {
+		t.Errorf("decoded header mismatch:\nhave %+v\nwant %+v", decoded, header)
+	}
+	// Malformed fields must be rejected
+	msg.ParentHash = msg.ParentHash[1:]
+	if _, err := DecodeHeader(msg); err == nil {
+		t.Errorf("header with short parent hash decoded")
+	}
+}
+
+// Tests that transactions, transfers and contract creations alike, survive a
+// conversion to gRPC messages and back.
+func TestTransactionConversion(t *testing.T) {
+	for i, tx := range testTransactions(t) {
+		msg := EncodeTransaction(tx)
+		if have := common.BytesToHash(msg.Hash); have != tx.Hash() {
+			t.Errorf("tx %d: message hash mismatch: have %x, want %x", i, have, tx.Hash())
+		}
+		decoded, err := DecodeTransaction(msg)
+		if err != nil {
+			t.Fatalf("tx %d: failed to decode: %v", i, err)
+		}
+		if decoded.Hash() != tx.Hash() {
+			t.Errorf("tx %d: decoded hash mismatch: have %x, want %x", i, decoded.Hash(), tx.Hash())
+		}
+		if !reflect.DeepEqual(decoded.To(), tx.To()) {
+			t.Errorf("tx %d: decoded recipient mismatch: have %v, want %v", i, decoded.To(), tx.To())
+		}
+	}
+	if _, err := DecodeTransaction(&Transaction{To: []byte{1, 2, 3}}); err == nil {
+		t.Errorf("transaction with short recipient decoded")
+	}
+}
+
+// Tests that blocks survive a conversion to gRPC messages and back.
+func TestBlockConversion(t *testing.T) {
+	block := types.NewBlock(testHeader(2), testTransactions(t), []*types.Header{testHeader(1)}, nil)
+
+	decoded, err := DecodeBlock(EncodeBlock(block))
+	if err != nil {
+		t.Fatalf("failed to decode block: %v", err)
+	}
+	if decoded.Hash() != block.Hash() {
+		t.Errorf("decoded block hash mismatch: have %x, want %x", decoded.Hash(), block.Hash())
+	}
+	if types.DeriveSha(decoded.Transactions()) != block.TxHash() {
+		t.Errorf("decoded block transactions mismatch")
+	}
+	if types.CalcUncleHash(decoded.Uncles()) != block.UncleHash() {
+		t.Errorf("decoded block uncles mismatch")
+	}
+}
+
+// Tests that receipts along with their logs survive a conversion to gRPC
+// messages and back.
+func TestReceiptConversion(t *testing.T) {
+	log := &types.Log{
+		Address:     common.HexToAddress("0xc0de"),
+		Topics:      []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")},
+		Data:        []byte{0xde, 0xad},
+		BlockNumber: 2,
+		TxHash:      common.HexToHash("0x03"),
+		TxIndex:     1,
+		BlockHash:   common.HexToHash("0x04"),
+		Index:       3,
+	}
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 74000,
+		Logs:              []*types.Log{log},
+		TxHash:            common.HexToHash("0x03"),
+		ContractAddress:   common.HexToAddress("0xc0de"),
+		GasUsed:           53000,
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+	decoded, err := DecodeReceipt(EncodeReceipt(receipt))
+	if err != nil {
+		t.Fatalf("failed to decode receipt: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, receipt) {
+		t.Errorf("decoded receipt mismatch:\nhave %+v\nwant %+v", decoded, receipt)
+	}
+	if _, err := DecodeLog(&Log{Address: log.Address.Bytes(), Topics: [][]byte{{1}}}); err == nil {
+		t.Errorf("log with short topic decoded")
+	}
+}
+
+// Tests that filter queries survive a conversion to gRPC messages and back, and
+// that their range may only be given by block numbers.
+func TestFilterQueryConversion(t *testing.T) {
+	tests := []okcoin.FilterQuery{
+		{},
+		{FromBlock: big.NewInt(0), ToBlock: big.NewInt(10)},
+		{
+			FromBlock: big.NewInt(5),
+			Addresses: []common.Address{common.HexToAddress("0xc0de")},
+			Topics:    [][]common.Hash{{common.HexToHash("0x01")}, {}, {common.HexToHash("0x02"), common.HexToHash("0x03")}},
+		},
+	}
+	for i, q := range tests {
+		decoded, err := filterQuery(EncodeFilterQuery(q))
+		if err != nil {
+			t.Fatalf("test %d: failed to decode query: %v", i, err)
+		}
+		if !reflect.DeepEqual(decoded.FromBlock, q.FromBlock) || !reflect.DeepEqual(decoded.ToBlock, q.ToBlock) {
+			t.Errorf("test %d: range mismatch: have %v-%v, want %v-%v", i, decoded.FromBlock, decoded.ToBlock, q.FromBlock, q.ToBlock)
+		}
+		if len(decoded.Addresses) != len(q.Addresses) || len(decoded.Topics) != len(q.Topics) {
+			t.Errorf("test %d: criteria mismatch: have %+v, want %+v", i, decoded, q)
+		}
+	}
+	if _, err := filterQuery(&FilterQuery{FromBlock: PendingBlock()}); err == nil {
+		t.Errorf("filter starting at the pending block accepted")
+	}
+}
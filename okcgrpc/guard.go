@@ -0,0 +1,85 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcgrpc
+
+import (
+	"math"
+	"strings"
+
+	"github.com/okcoin/go-okcoin/rpc"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// guardOptions returns the server options enforcing the access control, rate
+// limits, size limits and call deadline of a guard on the gRPC calls. Methods
+// are checked as "grpc_<Method>" (e.g. grpc_BlockByRef), the credentials being
+// taken from the "authorization" metadata of the call. The deadline only applies
+// to unary calls, streams being long lived subscriptions.
+func guardOptions(guard *rpc.Guard) []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := checkCall(ctx, guard, info.FullMethod); err != nil {
+				return nil, err
+			}
+			ctx, cancel := guard.WithTimeout(ctx)
+			defer cancel()
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := checkCall(stream.Context(), guard, info.FullMethod); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+	if limit := guard.Limits.MaxRequestSize; limit > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(limit))
+	}
+	if limit := guard.Limits.MaxResponseSize; limit > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(limit))
+	}
+	return opts
+}
+
+// checkCall runs a call of the given full method name (/package.Service/Method)
+// through the guard, converting rejections into gRPC status errors.
+func checkCall(ctx context.Context, guard *rpc.Guard, fullMethod string) error {
+	var credentials, remoteAddr string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md["authorization"]; len(values) > 0 {
+			credentials = values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	method := "grpc_" + fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+
+	switch wait, err := guard.Check(credentials, remoteAddr, method); err {
+	case nil:
+		return nil
+	case rpc.ErrRateLimited:
+		return status.Errorf(codes.ResourceExhausted, "%v, retry in %d seconds", err, int(math.Ceil(wait.Seconds())))
+	default:
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+}
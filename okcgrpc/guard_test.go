@@ -0,0 +1,71 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcgrpc
+
+import (
+	"net"
+	"testing"
+
+	"github.com/okcoin/go-okcoin/rpc"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Tests that calls are authorized and charged by the guard under their method
+// name, rejections being reported with the matching status codes.
+func TestCheckCall(t *testing.T) {
+	limiter, err := rpc.NewRateLimiter(rpc.RateLimitConfig{Rate: 0.001, Burst: 2})
+	if err != nil {
+		t.Fatalf("failed to create rate limiter: %v", err)
+	}
+	guard := &rpc.Guard{
+		Auth: rpc.NewAuthenticator(nil, &rpc.Policy{
+			APIKeys: map[string][]string{"reader": {"grpc_BlockByRef", "grpc_NetworkID"}},
+		}),
+		Limiter: limiter,
+	}
+	call := func(credentials, method string) codes.Code {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 30303}})
+		if credentials != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+credentials))
+		}
+		s, _ := status.FromError(checkCall(ctx, guard, "/okcgrpc.Okcoin/"+method))
+		return s.Code()
+	}
+	tests := []struct {
+		credentials, method string
+		want                codes.Code
+	}{
+		{"", "BlockByRef", codes.PermissionDenied},
+		{"reader", "SendTransaction", codes.PermissionDenied},
+		{"reader", "BlockByRef", codes.OK},
+		{"reader", "NetworkID", codes.OK},
+		{"reader", "BlockByRef", codes.ResourceExhausted},
+	}
+	for i, tt := range tests {
+		if have := call(tt.credentials, tt.method); have != tt.want {
+			t.Errorf("test %d: %s by %q status mismatch: have %v, want %v", i, tt.method, tt.credentials, have, tt.want)
+		}
+	}
+	// The zero guard should permit everything
+	if err := checkCall(context.Background(), new(rpc.Guard), "/okcgrpc.Okcoin/SendTransaction"); err != nil {
+		t.Errorf("zero guard rejected call: %v", err)
+	}
+}
@@ -0,0 +1,1917 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: okc.proto
+
+/*
+Package okcgrpc is a generated protocol buffer package.
+
+It is generated from these files:
+
+	okc.proto
+
+It has these top-level messages:
+
+	Empty
+	Hash
+	Count
+	Quantity
+	Data
+	BlockRef
+	AccountRef
+	StorageRef
+	TransactionIndex
+	Header
+	Transaction
+	TransactionResult
+	Block
+	Log
+	Logs
+	Receipt
+	CallMsg
+	CallRequest
+	Topics
+	FilterQuery
+	SyncStatus
+*/
+package okcgrpc
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+// Empty is the argument or result of calls without one.
+type Empty struct {
+}
+
+func (m *Empty) Reset()                    { *m = Empty{} }
+func (m *Empty) String() string            { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()               {}
+func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
+// Hash is a 32 byte hash.
+type Hash struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (m *Hash) Reset()                    { *m = Hash{} }
+func (m *Hash) String() string            { return proto.CompactTextString(m) }
+func (*Hash) ProtoMessage()               {}
+func (*Hash) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Hash) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+// Count is an unsigned 64 bit integer result.
+type Count struct {
+	Count uint64 `protobuf:"varint,1,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *Count) Reset()                    { *m = Count{} }
+func (m *Count) String() string            { return proto.CompactTextString(m) }
+func (*Count) ProtoMessage()               {}
+func (*Count) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *Count) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+// Quantity is a big integer result.
+type Quantity struct {
+	Value []byte `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *Quantity) Reset()                    { *m = Quantity{} }
+func (m *Quantity) String() string            { return proto.CompactTextString(m) }
+func (*Quantity) ProtoMessage()               {}
+func (*Quantity) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *Quantity) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+// Data is a byte string result.
+type Data struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *Data) Reset()                    { *m = Data{} }
+func (m *Data) String() string            { return proto.CompactTextString(m) }
+func (*Data) ProtoMessage()               {}
+func (*Data) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *Data) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// BlockRef references a block by hash or number, or the pending block. An
+// empty reference denotes the latest block.
+type BlockRef struct {
+	// Types that are valid to be assigned to Ref:
+	//	*BlockRef_Hash
+	//	*BlockRef_Number
+	//	*BlockRef_Pending
+	Ref isBlockRef_Ref `protobuf_oneof:"ref"`
+}
+
+func (m *BlockRef) Reset()                    { *m = BlockRef{} }
+func (m *BlockRef) String() string            { return proto.CompactTextString(m) }
+func (*BlockRef) ProtoMessage()               {}
+func (*BlockRef) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+type isBlockRef_Ref interface{ isBlockRef_Ref() }
+
+type BlockRef_Hash struct {
+	Hash []byte `protobuf:"bytes,1,opt,name=hash,proto3,oneof"`
+}
+type BlockRef_Number struct {
+	Number uint64 `protobuf:"varint,2,opt,name=number,oneof"`
+}
+type BlockRef_Pending struct {
+	Pending bool `protobuf:"varint,3,opt,name=pending,oneof"`
+}
+
+func (*BlockRef_Hash) isBlockRef_Ref()    {}
+func (*BlockRef_Number) isBlockRef_Ref()  {}
+func (*BlockRef_Pending) isBlockRef_Ref() {}
+
+func (m *BlockRef) GetRef() isBlockRef_Ref {
+	if m != nil {
+		return m.Ref
+	}
+	return nil
+}
+
+func (m *BlockRef) GetHash() []byte {
+	if x, ok := m.GetRef().(*BlockRef_Hash); ok {
+		return x.Hash
+	}
+	return nil
+}
+
+func (m *BlockRef) GetNumber() uint64 {
+	if x, ok := m.GetRef().(*BlockRef_Number); ok {
+		return x.Number
+	}
+	return 0
+}
+
+func (m *BlockRef) GetPending() bool {
+	if x, ok := m.GetRef().(*BlockRef_Pending); ok {
+		return x.Pending
+	}
+	return false
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*BlockRef) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _BlockRef_OneofMarshaler, _BlockRef_OneofUnmarshaler, _BlockRef_OneofSizer, []interface{}{
+		(*BlockRef_Hash)(nil),
+		(*BlockRef_Number)(nil),
+		(*BlockRef_Pending)(nil),
+	}
+}
+
+func _BlockRef_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*BlockRef)
+	// ref
+	switch x := m.Ref.(type) {
+	case *BlockRef_Hash:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		b.EncodeRawBytes(x.Hash)
+	case *BlockRef_Number:
+		b.EncodeVarint(2<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.Number))
+	case *BlockRef_Pending:
+		t := uint64(0)
+		if x.Pending {
+			t = 1
+		}
+		b.EncodeVarint(3<<3 | proto.WireVarint)
+		b.EncodeVarint(t)
+	case nil:
+	default:
+		return fmt.Errorf("BlockRef.Ref has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _BlockRef_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*BlockRef)
+	switch tag {
+	case 1: // ref.hash
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeRawBytes(true)
+		m.Ref = &BlockRef_Hash{x}
+		return true, err
+	case 2: // ref.number
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Ref = &BlockRef_Number{x}
+		return true, err
+	case 3: // ref.pending
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeVarint()
+		m.Ref = &BlockRef_Pending{x != 0}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _BlockRef_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*BlockRef)
+	// ref
+	switch x := m.Ref.(type) {
+	case *BlockRef_Hash:
+		n += proto.SizeVarint(1<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Hash)))
+		n += len(x.Hash)
+	case *BlockRef_Number:
+		n += proto.SizeVarint(2<<3 | proto.WireVarint)
+		n += proto.SizeVarint(uint64(x.Number))
+	case *BlockRef_Pending:
+		n += proto.SizeVarint(3<<3 | proto.WireVarint)
+		n += 1
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// AccountRef references an account at a block.
+type AccountRef struct {
+	Address []byte    `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Block   *BlockRef `protobuf:"bytes,2,opt,name=block" json:"block,omitempty"`
+}
+
+func (m *AccountRef) Reset()                    { *m = AccountRef{} }
+func (m *AccountRef) String() string            { return proto.CompactTextString(m) }
+func (*AccountRef) ProtoMessage()               {}
+func (*AccountRef) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *AccountRef) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *AccountRef) GetBlock() *BlockRef {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+// StorageRef references a storage slot of an account at a block.
+type StorageRef struct {
+	Address []byte    `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Key     []byte    `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Block   *BlockRef `protobuf:"bytes,3,opt,name=block" json:"block,omitempty"`
+}
+
+func (m *StorageRef) Reset()                    { *m = StorageRef{} }
+func (m *StorageRef) String() string            { return proto.CompactTextString(m) }
+func (*StorageRef) ProtoMessage()               {}
+func (*StorageRef) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *StorageRef) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *StorageRef) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *StorageRef) GetBlock() *BlockRef {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+// TransactionIndex references a transaction by its position within a block.
+type TransactionIndex struct {
+	BlockHash []byte `protobuf:"bytes,1,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	Index     uint64 `protobuf:"varint,2,opt,name=index" json:"index,omitempty"`
+}
+
+func (m *TransactionIndex) Reset()                    { *m = TransactionIndex{} }
+func (m *TransactionIndex) String() string            { return proto.CompactTextString(m) }
+func (*TransactionIndex) ProtoMessage()               {}
+func (*TransactionIndex) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *TransactionIndex) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *TransactionIndex) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+type Header struct {
+	ParentHash  []byte `protobuf:"bytes,1,opt,name=parent_hash,json=parentHash,proto3" json:"parent_hash,omitempty"`
+	UncleHash   []byte `protobuf:"bytes,2,opt,name=uncle_hash,json=uncleHash,proto3" json:"uncle_hash,omitempty"`
+	Coinbase    []byte `protobuf:"bytes,3,opt,name=coinbase,proto3" json:"coinbase,omitempty"`
+	Root        []byte `protobuf:"bytes,4,opt,name=root,proto3" json:"root,omitempty"`
+	TxHash      []byte `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	ReceiptHash []byte `protobuf:"bytes,6,opt,name=receipt_hash,json=receiptHash,proto3" json:"receipt_hash,omitempty"`
+	Bloom       []byte `protobuf:"bytes,7,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	Difficulty  []byte `protobuf:"bytes,8,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	Number      []byte `protobuf:"bytes,9,opt,name=number,proto3" json:"number,omitempty"`
+	GasLimit    uint64 `protobuf:"varint,10,opt,name=gas_limit,json=gasLimit" json:"gas_limit,omitempty"`
+	GasUsed     uint64 `protobuf:"varint,11,opt,name=gas_used,json=gasUsed" json:"gas_used,omitempty"`
+	Time        []byte `protobuf:"bytes,12,opt,name=time,proto3" json:"time,omitempty"`
+	Extra       []byte `protobuf:"bytes,13,opt,name=extra,proto3" json:"extra,omitempty"`
+	MixDigest   []byte `protobuf:"bytes,14,opt,name=mix_digest,json=mixDigest,proto3" json:"mix_digest,omitempty"`
+	Nonce       []byte `protobuf:"bytes,15,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// Hash of the header, ignored when decoding.
+	Hash []byte `protobuf:"bytes,16,opt,name=hash,proto3" json:"hash,omitempty"`
+}
+
+func (m *Header) Reset()                    { *m = Header{} }
+func (m *Header) String() string            { return proto.CompactTextString(m) }
+func (*Header) ProtoMessage()               {}
+func (*Header) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *Header) GetParentHash() []byte {
+	if m != nil {
+		return m.ParentHash
+	}
+	return nil
+}
+
+func (m *Header) GetUncleHash() []byte {
+	if m != nil {
+		return m.UncleHash
+	}
+	return nil
+}
+
+func (m *Header) GetCoinbase() []byte {
+	if m != nil {
+		return m.Coinbase
+	}
+	return nil
+}
+
+func (m *Header) GetRoot() []byte {
+	if m != nil {
+		return m.Root
+	}
+	return nil
+}
+
+func (m *Header) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *Header) GetReceiptHash() []byte {
+	if m != nil {
+		return m.ReceiptHash
+	}
+	return nil
+}
+
+func (m *Header) GetBloom() []byte {
+	if m != nil {
+		return m.Bloom
+	}
+	return nil
+}
+
+func (m *Header) GetDifficulty() []byte {
+	if m != nil {
+		return m.Difficulty
+	}
+	return nil
+}
+
+func (m *Header) GetNumber() []byte {
+	if m != nil {
+		return m.Number
+	}
+	return nil
+}
+
+func (m *Header) GetGasLimit() uint64 {
+	if m != nil {
+		return m.GasLimit
+	}
+	return 0
+}
+
+func (m *Header) GetGasUsed() uint64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+func (m *Header) GetTime() []byte {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *Header) GetExtra() []byte {
+	if m != nil {
+		return m.Extra
+	}
+	return nil
+}
+
+func (m *Header) GetMixDigest() []byte {
+	if m != nil {
+		return m.MixDigest
+	}
+	return nil
+}
+
+func (m *Header) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+func (m *Header) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+type Transaction struct {
+	Nonce    uint64 `protobuf:"varint,1,opt,name=nonce" json:"nonce,omitempty"`
+	GasPrice []byte `protobuf:"bytes,2,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`
+	Gas      uint64 `protobuf:"varint,3,opt,name=gas" json:"gas,omitempty"`
+	To       []byte `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	Value    []byte `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Input    []byte `protobuf:"bytes,6,opt,name=input,proto3" json:"input,omitempty"`
+	V        []byte `protobuf:"bytes,7,opt,name=v,proto3" json:"v,omitempty"`
+	R        []byte `protobuf:"bytes,8,opt,name=r,proto3" json:"r,omitempty"`
+	S        []byte `protobuf:"bytes,9,opt,name=s,proto3" json:"s,omitempty"`
+	// Hash of the transaction, ignored when decoding.
+	Hash []byte `protobuf:"bytes,10,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Sender of the transaction as recovered by the server, ignored when sending.
+	From []byte `protobuf:"bytes,11,opt,name=from,proto3" json:"from,omitempty"`
+}
+
+func (m *Transaction) Reset()                    { *m = Transaction{} }
+func (m *Transaction) String() string            { return proto.CompactTextString(m) }
+func (*Transaction) ProtoMessage()               {}
+func (*Transaction) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *Transaction) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *Transaction) GetGasPrice() []byte {
+	if m != nil {
+		return m.GasPrice
+	}
+	return nil
+}
+
+func (m *Transaction) GetGas() uint64 {
+	if m != nil {
+		return m.Gas
+	}
+	return 0
+}
+
+func (m *Transaction) GetTo() []byte {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *Transaction) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *Transaction) GetInput() []byte {
+	if m != nil {
+		return m.Input
+	}
+	return nil
+}
+
+func (m *Transaction) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *Transaction) GetR() []byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *Transaction) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+func (m *Transaction) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *Transaction) GetFrom() []byte {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+// TransactionResult is a transaction along with its position in the chain.
+type TransactionResult struct {
+	Transaction *Transaction `protobuf:"bytes,1,opt,name=transaction" json:"transaction,omitempty"`
+	Pending     bool         `protobuf:"varint,2,opt,name=pending" json:"pending,omitempty"`
+	BlockHash   []byte       `protobuf:"bytes,3,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	BlockNumber uint64       `protobuf:"varint,4,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	Index       uint64       `protobuf:"varint,5,opt,name=index" json:"index,omitempty"`
+}
+
+func (m *TransactionResult) Reset()                    { *m = TransactionResult{} }
+func (m *TransactionResult) String() string            { return proto.CompactTextString(m) }
+func (*TransactionResult) ProtoMessage()               {}
+func (*TransactionResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *TransactionResult) GetTransaction() *Transaction {
+	if m != nil {
+		return m.Transaction
+	}
+	return nil
+}
+
+func (m *TransactionResult) GetPending() bool {
+	if m != nil {
+		return m.Pending
+	}
+	return false
+}
+
+func (m *TransactionResult) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *TransactionResult) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *TransactionResult) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+type Block struct {
+	Header       *Header        `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	Transactions []*Transaction `protobuf:"bytes,2,rep,name=transactions" json:"transactions,omitempty"`
+	Uncles       []*Header      `protobuf:"bytes,3,rep,name=uncles" json:"uncles,omitempty"`
+}
+
+func (m *Block) Reset()                    { *m = Block{} }
+func (m *Block) String() string            { return proto.CompactTextString(m) }
+func (*Block) ProtoMessage()               {}
+func (*Block) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *Block) GetHeader() *Header {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *Block) GetTransactions() []*Transaction {
+	if m != nil {
+		return m.Transactions
+	}
+	return nil
+}
+
+func (m *Block) GetUncles() []*Header {
+	if m != nil {
+		return m.Uncles
+	}
+	return nil
+}
+
+type Log struct {
+	Address     []byte   `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Topics      [][]byte `protobuf:"bytes,2,rep,name=topics,proto3" json:"topics,omitempty"`
+	Data        []byte   `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	BlockNumber uint64   `protobuf:"varint,4,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	TxHash      []byte   `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	TxIndex     uint64   `protobuf:"varint,6,opt,name=tx_index,json=txIndex" json:"tx_index,omitempty"`
+	BlockHash   []byte   `protobuf:"bytes,7,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	Index       uint64   `protobuf:"varint,8,opt,name=index" json:"index,omitempty"`
+	Removed     bool     `protobuf:"varint,9,opt,name=removed" json:"removed,omitempty"`
+}
+
+func (m *Log) Reset()                    { *m = Log{} }
+func (m *Log) String() string            { return proto.CompactTextString(m) }
+func (*Log) ProtoMessage()               {}
+func (*Log) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *Log) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *Log) GetTopics() [][]byte {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+func (m *Log) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Log) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *Log) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *Log) GetTxIndex() uint64 {
+	if m != nil {
+		return m.TxIndex
+	}
+	return 0
+}
+
+func (m *Log) GetBlockHash() []byte {
+	if m != nil {
+		return m.BlockHash
+	}
+	return nil
+}
+
+func (m *Log) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *Log) GetRemoved() bool {
+	if m != nil {
+		return m.Removed
+	}
+	return false
+}
+
+type Logs struct {
+	Logs []*Log `protobuf:"bytes,1,rep,name=logs" json:"logs,omitempty"`
+}
+
+func (m *Logs) Reset()                    { *m = Logs{} }
+func (m *Logs) String() string            { return proto.CompactTextString(m) }
+func (*Logs) ProtoMessage()               {}
+func (*Logs) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *Logs) GetLogs() []*Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+type Receipt struct {
+	PostState         []byte `protobuf:"bytes,1,opt,name=post_state,json=postState,proto3" json:"post_state,omitempty"`
+	Status            uint64 `protobuf:"varint,2,opt,name=status" json:"status,omitempty"`
+	CumulativeGasUsed uint64 `protobuf:"varint,3,opt,name=cumulative_gas_used,json=cumulativeGasUsed" json:"cumulative_gas_used,omitempty"`
+	Bloom             []byte `protobuf:"bytes,4,opt,name=bloom,proto3" json:"bloom,omitempty"`
+	Logs              []*Log `protobuf:"bytes,5,rep,name=logs" json:"logs,omitempty"`
+	TxHash            []byte `protobuf:"bytes,6,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	ContractAddress   []byte `protobuf:"bytes,7,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"`
+	GasUsed           uint64 `protobuf:"varint,8,opt,name=gas_used,json=gasUsed" json:"gas_used,omitempty"`
+}
+
+func (m *Receipt) Reset()                    { *m = Receipt{} }
+func (m *Receipt) String() string            { return proto.CompactTextString(m) }
+func (*Receipt) ProtoMessage()               {}
+func (*Receipt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *Receipt) GetPostState() []byte {
+	if m != nil {
+		return m.PostState
+	}
+	return nil
+}
+
+func (m *Receipt) GetStatus() uint64 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *Receipt) GetCumulativeGasUsed() uint64 {
+	if m != nil {
+		return m.CumulativeGasUsed
+	}
+	return 0
+}
+
+func (m *Receipt) GetBloom() []byte {
+	if m != nil {
+		return m.Bloom
+	}
+	return nil
+}
+
+func (m *Receipt) GetLogs() []*Log {
+	if m != nil {
+		return m.Logs
+	}
+	return nil
+}
+
+func (m *Receipt) GetTxHash() []byte {
+	if m != nil {
+		return m.TxHash
+	}
+	return nil
+}
+
+func (m *Receipt) GetContractAddress() []byte {
+	if m != nil {
+		return m.ContractAddress
+	}
+	return nil
+}
+
+func (m *Receipt) GetGasUsed() uint64 {
+	if m != nil {
+		return m.GasUsed
+	}
+	return 0
+}
+
+type CallMsg struct {
+	From     []byte `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To       []byte `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Gas      uint64 `protobuf:"varint,3,opt,name=gas" json:"gas,omitempty"`
+	GasPrice []byte `protobuf:"bytes,4,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`
+	Value    []byte `protobuf:"bytes,5,opt,name=value,proto3" json:"value,omitempty"`
+	Data     []byte `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *CallMsg) Reset()                    { *m = CallMsg{} }
+func (m *CallMsg) String() string            { return proto.CompactTextString(m) }
+func (*CallMsg) ProtoMessage()               {}
+func (*CallMsg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *CallMsg) GetFrom() []byte {
+	if m != nil {
+		return m.From
+	}
+	return nil
+}
+
+func (m *CallMsg) GetTo() []byte {
+	if m != nil {
+		return m.To
+	}
+	return nil
+}
+
+func (m *CallMsg) GetGas() uint64 {
+	if m != nil {
+		return m.Gas
+	}
+	return 0
+}
+
+func (m *CallMsg) GetGasPrice() []byte {
+	if m != nil {
+		return m.GasPrice
+	}
+	return nil
+}
+
+func (m *CallMsg) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *CallMsg) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type CallRequest struct {
+	Msg   *CallMsg  `protobuf:"bytes,1,opt,name=msg" json:"msg,omitempty"`
+	Block *BlockRef `protobuf:"bytes,2,opt,name=block" json:"block,omitempty"`
+}
+
+func (m *CallRequest) Reset()                    { *m = CallRequest{} }
+func (m *CallRequest) String() string            { return proto.CompactTextString(m) }
+func (*CallRequest) ProtoMessage()               {}
+func (*CallRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *CallRequest) GetMsg() *CallMsg {
+	if m != nil {
+		return m.Msg
+	}
+	return nil
+}
+
+func (m *CallRequest) GetBlock() *BlockRef {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+// Topics is the set of alternatives matched at one topic position.
+type Topics struct {
+	Topics [][]byte `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
+}
+
+func (m *Topics) Reset()                    { *m = Topics{} }
+func (m *Topics) String() string            { return proto.CompactTextString(m) }
+func (*Topics) ProtoMessage()               {}
+func (*Topics) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *Topics) GetTopics() [][]byte {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+type FilterQuery struct {
+	FromBlock *BlockRef `protobuf:"bytes,1,opt,name=from_block,json=fromBlock" json:"from_block,omitempty"`
+	ToBlock   *BlockRef `protobuf:"bytes,2,opt,name=to_block,json=toBlock" json:"to_block,omitempty"`
+	Addresses [][]byte  `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	Topics    []*Topics `protobuf:"bytes,4,rep,name=topics" json:"topics,omitempty"`
+}
+
+func (m *FilterQuery) Reset()                    { *m = FilterQuery{} }
+func (m *FilterQuery) String() string            { return proto.CompactTextString(m) }
+func (*FilterQuery) ProtoMessage()               {}
+func (*FilterQuery) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *FilterQuery) GetFromBlock() *BlockRef {
+	if m != nil {
+		return m.FromBlock
+	}
+	return nil
+}
+
+func (m *FilterQuery) GetToBlock() *BlockRef {
+	if m != nil {
+		return m.ToBlock
+	}
+	return nil
+}
+
+func (m *FilterQuery) GetAddresses() [][]byte {
+	if m != nil {
+		return m.Addresses
+	}
+	return nil
+}
+
+func (m *FilterQuery) GetTopics() []*Topics {
+	if m != nil {
+		return m.Topics
+	}
+	return nil
+}
+
+type SyncStatus struct {
+	Syncing       bool   `protobuf:"varint,1,opt,name=syncing" json:"syncing,omitempty"`
+	StartingBlock uint64 `protobuf:"varint,2,opt,name=starting_block,json=startingBlock" json:"starting_block,omitempty"`
+	CurrentBlock  uint64 `protobuf:"varint,3,opt,name=current_block,json=currentBlock" json:"current_block,omitempty"`
+	HighestBlock  uint64 `protobuf:"varint,4,opt,name=highest_block,json=highestBlock" json:"highest_block,omitempty"`
+	PulledStates  uint64 `protobuf:"varint,5,opt,name=pulled_states,json=pulledStates" json:"pulled_states,omitempty"`
+	KnownStates   uint64 `protobuf:"varint,6,opt,name=known_states,json=knownStates" json:"known_states,omitempty"`
+}
+
+func (m *SyncStatus) Reset()                    { *m = SyncStatus{} }
+func (m *SyncStatus) String() string            { return proto.CompactTextString(m) }
+func (*SyncStatus) ProtoMessage()               {}
+func (*SyncStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *SyncStatus) GetSyncing() bool {
+	if m != nil {
+		return m.Syncing
+	}
+	return false
+}
+
+func (m *SyncStatus) GetStartingBlock() uint64 {
+	if m != nil {
+		return m.StartingBlock
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetCurrentBlock() uint64 {
+	if m != nil {
+		return m.CurrentBlock
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetHighestBlock() uint64 {
+	if m != nil {
+		return m.HighestBlock
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetPulledStates() uint64 {
+	if m != nil {
+		return m.PulledStates
+	}
+	return 0
+}
+
+func (m *SyncStatus) GetKnownStates() uint64 {
+	if m != nil {
+		return m.KnownStates
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "okcgrpc.Empty")
+	proto.RegisterType((*Hash)(nil), "okcgrpc.Hash")
+	proto.RegisterType((*Count)(nil), "okcgrpc.Count")
+	proto.RegisterType((*Quantity)(nil), "okcgrpc.Quantity")
+	proto.RegisterType((*Data)(nil), "okcgrpc.Data")
+	proto.RegisterType((*BlockRef)(nil), "okcgrpc.BlockRef")
+	proto.RegisterType((*AccountRef)(nil), "okcgrpc.AccountRef")
+	proto.RegisterType((*StorageRef)(nil), "okcgrpc.StorageRef")
+	proto.RegisterType((*TransactionIndex)(nil), "okcgrpc.TransactionIndex")
+	proto.RegisterType((*Header)(nil), "okcgrpc.Header")
+	proto.RegisterType((*Transaction)(nil), "okcgrpc.Transaction")
+	proto.RegisterType((*TransactionResult)(nil), "okcgrpc.TransactionResult")
+	proto.RegisterType((*Block)(nil), "okcgrpc.Block")
+	proto.RegisterType((*Log)(nil), "okcgrpc.Log")
+	proto.RegisterType((*Logs)(nil), "okcgrpc.Logs")
+	proto.RegisterType((*Receipt)(nil), "okcgrpc.Receipt")
+	proto.RegisterType((*CallMsg)(nil), "okcgrpc.CallMsg")
+	proto.RegisterType((*CallRequest)(nil), "okcgrpc.CallRequest")
+	proto.RegisterType((*Topics)(nil), "okcgrpc.Topics")
+	proto.RegisterType((*FilterQuery)(nil), "okcgrpc.FilterQuery")
+	proto.RegisterType((*SyncStatus)(nil), "okcgrpc.SyncStatus")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Okcoin service
+
+type OkcoinClient interface {
+	// BlockByRef returns a block along with its transactions.
+	BlockByRef(ctx context.Context, in *BlockRef, opts ...grpc.CallOption) (*Block, error)
+	// HeaderByRef returns a block header.
+	HeaderByRef(ctx context.Context, in *BlockRef, opts ...grpc.CallOption) (*Header, error)
+	// TransactionCount returns the number of transactions in a block.
+	TransactionCount(ctx context.Context, in *BlockRef, opts ...grpc.CallOption) (*Count, error)
+	// TransactionByHash returns a transaction, mined or pending.
+	TransactionByHash(ctx context.Context, in *Hash, opts ...grpc.CallOption) (*TransactionResult, error)
+	// TransactionInBlock returns a transaction by its index within a block.
+	TransactionInBlock(ctx context.Context, in *TransactionIndex, opts ...grpc.CallOption) (*Transaction, error)
+	// TransactionReceipt returns the receipt of a mined transaction.
+	TransactionReceipt(ctx context.Context, in *Hash, opts ...grpc.CallOption) (*Receipt, error)
+	// SendTransaction injects a signed transaction into the transaction pool.
+	SendTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Hash, error)
+	// BalanceAt returns the balance of an account in wei.
+	BalanceAt(ctx context.Context, in *AccountRef, opts ...grpc.CallOption) (*Quantity, error)
+	// StorageAt returns the value of a storage slot of an account.
+	StorageAt(ctx context.Context, in *StorageRef, opts ...grpc.CallOption) (*Data, error)
+	// CodeAt returns the contract code of an account.
+	CodeAt(ctx context.Context, in *AccountRef, opts ...grpc.CallOption) (*Data, error)
+	// NonceAt returns the nonce of an account.
+	NonceAt(ctx context.Context, in *AccountRef, opts ...grpc.CallOption) (*Count, error)
+	// CallContract executes a message call without creating a transaction.
+	CallContract(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*Data, error)
+	// EstimateGas returns the gas needed to execute a message call.
+	EstimateGas(ctx context.Context, in *CallMsg, opts ...grpc.CallOption) (*Count, error)
+	// SuggestGasPrice returns a gas price for timely inclusion of transactions.
+	SuggestGasPrice(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Quantity, error)
+	// FilterLogs returns the logs matching a filter query.
+	FilterLogs(ctx context.Context, in *FilterQuery, opts ...grpc.CallOption) (*Logs, error)
+	// SubscribeFilterLogs streams the logs matching a filter query as they are
+	// imported, starting with the next block.
+	SubscribeFilterLogs(ctx context.Context, in *FilterQuery, opts ...grpc.CallOption) (Okcoin_SubscribeFilterLogsClient, error)
+	// SubscribeNewHead streams the headers of the new chain heads.
+	SubscribeNewHead(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Okcoin_SubscribeNewHeadClient, error)
+	// SyncProgress returns the progress of the chain synchronisation.
+	SyncProgress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SyncStatus, error)
+	// NetworkID returns the network identifier of the node.
+	NetworkID(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Count, error)
+}
+
+type okcoinClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewOkcoinClient(cc *grpc.ClientConn) OkcoinClient {
+	return &okcoinClient{cc}
+}
+
+func (c *okcoinClient) BlockByRef(ctx context.Context, in *BlockRef, opts ...grpc.CallOption) (*Block, error) {
+	out := new(Block)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/BlockByRef", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) HeaderByRef(ctx context.Context, in *BlockRef, opts ...grpc.CallOption) (*Header, error) {
+	out := new(Header)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/HeaderByRef", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) TransactionCount(ctx context.Context, in *BlockRef, opts ...grpc.CallOption) (*Count, error) {
+	out := new(Count)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/TransactionCount", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) TransactionByHash(ctx context.Context, in *Hash, opts ...grpc.CallOption) (*TransactionResult, error) {
+	out := new(TransactionResult)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/TransactionByHash", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) TransactionInBlock(ctx context.Context, in *TransactionIndex, opts ...grpc.CallOption) (*Transaction, error) {
+	out := new(Transaction)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/TransactionInBlock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) TransactionReceipt(ctx context.Context, in *Hash, opts ...grpc.CallOption) (*Receipt, error) {
+	out := new(Receipt)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/TransactionReceipt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) SendTransaction(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Hash, error) {
+	out := new(Hash)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/SendTransaction", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) BalanceAt(ctx context.Context, in *AccountRef, opts ...grpc.CallOption) (*Quantity, error) {
+	out := new(Quantity)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/BalanceAt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) StorageAt(ctx context.Context, in *StorageRef, opts ...grpc.CallOption) (*Data, error) {
+	out := new(Data)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/StorageAt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) CodeAt(ctx context.Context, in *AccountRef, opts ...grpc.CallOption) (*Data, error) {
+	out := new(Data)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/CodeAt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) NonceAt(ctx context.Context, in *AccountRef, opts ...grpc.CallOption) (*Count, error) {
+	out := new(Count)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/NonceAt", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) CallContract(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*Data, error) {
+	out := new(Data)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/CallContract", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) EstimateGas(ctx context.Context, in *CallMsg, opts ...grpc.CallOption) (*Count, error) {
+	out := new(Count)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/EstimateGas", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) SuggestGasPrice(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Quantity, error) {
+	out := new(Quantity)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/SuggestGasPrice", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) FilterLogs(ctx context.Context, in *FilterQuery, opts ...grpc.CallOption) (*Logs, error) {
+	out := new(Logs)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/FilterLogs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) SubscribeFilterLogs(ctx context.Context, in *FilterQuery, opts ...grpc.CallOption) (Okcoin_SubscribeFilterLogsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Okcoin_serviceDesc.Streams[0], c.cc, "/okcgrpc.Okcoin/SubscribeFilterLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &okcoinSubscribeFilterLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Okcoin_SubscribeFilterLogsClient interface {
+	Recv() (*Log, error)
+	grpc.ClientStream
+}
+
+type okcoinSubscribeFilterLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *okcoinSubscribeFilterLogsClient) Recv() (*Log, error) {
+	m := new(Log)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *okcoinClient) SubscribeNewHead(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Okcoin_SubscribeNewHeadClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Okcoin_serviceDesc.Streams[1], c.cc, "/okcgrpc.Okcoin/SubscribeNewHead", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &okcoinSubscribeNewHeadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Okcoin_SubscribeNewHeadClient interface {
+	Recv() (*Header, error)
+	grpc.ClientStream
+}
+
+type okcoinSubscribeNewHeadClient struct {
+	grpc.ClientStream
+}
+
+func (x *okcoinSubscribeNewHeadClient) Recv() (*Header, error) {
+	m := new(Header)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *okcoinClient) SyncProgress(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SyncStatus, error) {
+	out := new(SyncStatus)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/SyncProgress", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *okcoinClient) NetworkID(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Count, error) {
+	out := new(Count)
+	err := grpc.Invoke(ctx, "/okcgrpc.Okcoin/NetworkID", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Okcoin service
+
+type OkcoinServer interface {
+	// BlockByRef returns a block along with its transactions.
+	BlockByRef(context.Context, *BlockRef) (*Block, error)
+	// HeaderByRef returns a block header.
+	HeaderByRef(context.Context, *BlockRef) (*Header, error)
+	// TransactionCount returns the number of transactions in a block.
+	TransactionCount(context.Context, *BlockRef) (*Count, error)
+	// TransactionByHash returns a transaction, mined or pending.
+	TransactionByHash(context.Context, *Hash) (*TransactionResult, error)
+	// TransactionInBlock returns a transaction by its index within a block.
+	TransactionInBlock(context.Context, *TransactionIndex) (*Transaction, error)
+	// TransactionReceipt returns the receipt of a mined transaction.
+	TransactionReceipt(context.Context, *Hash) (*Receipt, error)
+	// SendTransaction injects a signed transaction into the transaction pool.
+	SendTransaction(context.Context, *Transaction) (*Hash, error)
+	// BalanceAt returns the balance of an account in wei.
+	BalanceAt(context.Context, *AccountRef) (*Quantity, error)
+	// StorageAt returns the value of a storage slot of an account.
+	StorageAt(context.Context, *StorageRef) (*Data, error)
+	// CodeAt returns the contract code of an account.
+	CodeAt(context.Context, *AccountRef) (*Data, error)
+	// NonceAt returns the nonce of an account.
+	NonceAt(context.Context, *AccountRef) (*Count, error)
+	// CallContract executes a message call without creating a transaction.
+	CallContract(context.Context, *CallRequest) (*Data, error)
+	// EstimateGas returns the gas needed to execute a message call.
+	EstimateGas(context.Context, *CallMsg) (*Count, error)
+	// SuggestGasPrice returns a gas price for timely inclusion of transactions.
+	SuggestGasPrice(context.Context, *Empty) (*Quantity, error)
+	// FilterLogs returns the logs matching a filter query.
+	FilterLogs(context.Context, *FilterQuery) (*Logs, error)
+	// SubscribeFilterLogs streams the logs matching a filter query as they are
+	// imported, starting with the next block.
+	SubscribeFilterLogs(*FilterQuery, Okcoin_SubscribeFilterLogsServer) error
+	// SubscribeNewHead streams the headers of the new chain heads.
+	SubscribeNewHead(*Empty, Okcoin_SubscribeNewHeadServer) error
+	// SyncProgress returns the progress of the chain synchronisation.
+	SyncProgress(context.Context, *Empty) (*SyncStatus, error)
+	// NetworkID returns the network identifier of the node.
+	NetworkID(context.Context, *Empty) (*Count, error)
+}
+
+func RegisterOkcoinServer(s *grpc.Server, srv OkcoinServer) {
+	s.RegisterService(&_Okcoin_serviceDesc, srv)
+}
+
+func _Okcoin_BlockByRef_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).BlockByRef(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/BlockByRef",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).BlockByRef(ctx, req.(*BlockRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_HeaderByRef_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).HeaderByRef(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/HeaderByRef",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).HeaderByRef(ctx, req.(*BlockRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_TransactionCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).TransactionCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/TransactionCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).TransactionCount(ctx, req.(*BlockRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_TransactionByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Hash)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).TransactionByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/TransactionByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).TransactionByHash(ctx, req.(*Hash))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_TransactionInBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionIndex)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).TransactionInBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/TransactionInBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).TransactionInBlock(ctx, req.(*TransactionIndex))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_TransactionReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Hash)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).TransactionReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/TransactionReceipt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).TransactionReceipt(ctx, req.(*Hash))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Transaction)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).SendTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/SendTransaction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).SendTransaction(ctx, req.(*Transaction))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_BalanceAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).BalanceAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/BalanceAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).BalanceAt(ctx, req.(*AccountRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_StorageAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).StorageAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/StorageAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).StorageAt(ctx, req.(*StorageRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_CodeAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).CodeAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/CodeAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).CodeAt(ctx, req.(*AccountRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_NonceAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).NonceAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/NonceAt",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).NonceAt(ctx, req.(*AccountRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_CallContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).CallContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/CallContract",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).CallContract(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_EstimateGas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallMsg)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).EstimateGas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/EstimateGas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).EstimateGas(ctx, req.(*CallMsg))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_SuggestGasPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).SuggestGasPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/SuggestGasPrice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).SuggestGasPrice(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_FilterLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FilterQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).FilterLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/FilterLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).FilterLogs(ctx, req.(*FilterQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_SubscribeFilterLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FilterQuery)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OkcoinServer).SubscribeFilterLogs(m, &okcoinSubscribeFilterLogsServer{stream})
+}
+
+type Okcoin_SubscribeFilterLogsServer interface {
+	Send(*Log) error
+	grpc.ServerStream
+}
+
+type okcoinSubscribeFilterLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *okcoinSubscribeFilterLogsServer) Send(m *Log) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Okcoin_SubscribeNewHead_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OkcoinServer).SubscribeNewHead(m, &okcoinSubscribeNewHeadServer{stream})
+}
+
+type Okcoin_SubscribeNewHeadServer interface {
+	Send(*Header) error
+	grpc.ServerStream
+}
+
+type okcoinSubscribeNewHeadServer struct {
+	grpc.ServerStream
+}
+
+func (x *okcoinSubscribeNewHeadServer) Send(m *Header) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Okcoin_SyncProgress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).SyncProgress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/SyncProgress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).SyncProgress(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Okcoin_NetworkID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OkcoinServer).NetworkID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/okcgrpc.Okcoin/NetworkID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OkcoinServer).NetworkID(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Okcoin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "okcgrpc.Okcoin",
+	HandlerType: (*OkcoinServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "BlockByRef",
+			Handler:    _Okcoin_BlockByRef_Handler,
+		},
+		{
+			MethodName: "HeaderByRef",
+			Handler:    _Okcoin_HeaderByRef_Handler,
+		},
+		{
+			MethodName: "TransactionCount",
+			Handler:    _Okcoin_TransactionCount_Handler,
+		},
+		{
+			MethodName: "TransactionByHash",
+			Handler:    _Okcoin_TransactionByHash_Handler,
+		},
+		{
+			MethodName: "TransactionInBlock",
+			Handler:    _Okcoin_TransactionInBlock_Handler,
+		},
+		{
+			MethodName: "TransactionReceipt",
+			Handler:    _Okcoin_TransactionReceipt_Handler,
+		},
+		{
+			MethodName: "SendTransaction",
+			Handler:    _Okcoin_SendTransaction_Handler,
+		},
+		{
+			MethodName: "BalanceAt",
+			Handler:    _Okcoin_BalanceAt_Handler,
+		},
+		{
+			MethodName: "StorageAt",
+			Handler:    _Okcoin_StorageAt_Handler,
+		},
+		{
+			MethodName: "CodeAt",
+			Handler:    _Okcoin_CodeAt_Handler,
+		},
+		{
+			MethodName: "NonceAt",
+			Handler:    _Okcoin_NonceAt_Handler,
+		},
+		{
+			MethodName: "CallContract",
+			Handler:    _Okcoin_CallContract_Handler,
+		},
+		{
+			MethodName: "EstimateGas",
+			Handler:    _Okcoin_EstimateGas_Handler,
+		},
+		{
+			MethodName: "SuggestGasPrice",
+			Handler:    _Okcoin_SuggestGasPrice_Handler,
+		},
+		{
+			MethodName: "FilterLogs",
+			Handler:    _Okcoin_FilterLogs_Handler,
+		},
+		{
+			MethodName: "SyncProgress",
+			Handler:    _Okcoin_SyncProgress_Handler,
+		},
+		{
+			MethodName: "NetworkID",
+			Handler:    _Okcoin_NetworkID_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeFilterLogs",
+			Handler:       _Okcoin_SubscribeFilterLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeNewHead",
+			Handler:       _Okcoin_SubscribeNewHead_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "okc.proto",
+}
+
+func init() { proto.RegisterFile("okc.proto", fileDescriptor0) }
+
+var fileDescriptor0 = []byte{
+	// 1468 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x57, 0xcb, 0x6e, 0xdb, 0x46,
+	0x17, 0x0e, 0xad, 0xfb, 0x11, 0x7d, 0xc9, 0xc4, 0xc8, 0xcf, 0xe8, 0xff, 0xf3, 0xd7, 0x61, 0x10,
+	0x24, 0x45, 0x53, 0x3b, 0x97, 0x26, 0x28, 0x50, 0xa0, 0x40, 0xec, 0xa4, 0x71, 0x00, 0xd7, 0x49,
+	0xa8, 0x74, 0x13, 0xa0, 0x10, 0x46, 0xe4, 0x98, 0x22, 0x44, 0x71, 0x54, 0xce, 0xd0, 0x91, 0xb6,
+	0x5d, 0xb4, 0x0f, 0xd0, 0xe7, 0xe8, 0xae, 0xdb, 0x3e, 0x4b, 0xfb, 0x0a, 0x7d, 0x83, 0xe2, 0xcc,
+	0x0c, 0x2f, 0x92, 0x25, 0x27, 0xbb, 0x39, 0x67, 0xce, 0x9c, 0xeb, 0x7c, 0x1f, 0x87, 0xd0, 0xe1,
+	0x63, 0x7f, 0x7f, 0x9a, 0x72, 0xc9, 0x49, 0x8b, 0x8f, 0xfd, 0x30, 0x9d, 0xfa, 0x6e, 0x0b, 0x1a,
+	0x2f, 0x26, 0x53, 0x39, 0x77, 0x7b, 0x50, 0x3f, 0xa6, 0x62, 0x44, 0x08, 0xd4, 0x47, 0x54, 0x8c,
+	0x1c, 0x6b, 0xcf, 0xba, 0x67, 0x7b, 0x6a, 0xed, 0xde, 0x84, 0xc6, 0x11, 0xcf, 0x12, 0x49, 0x76,
+	0xa1, 0xe1, 0xe3, 0x42, 0xed, 0xd6, 0x3d, 0x2d, 0xb8, 0x7b, 0xd0, 0x7e, 0x9b, 0xd1, 0x44, 0x46,
+	0x72, 0x8e, 0x16, 0xe7, 0x34, 0xce, 0x98, 0x39, 0xaf, 0x05, 0x74, 0xfe, 0x9c, 0x4a, 0x8a, 0xce,
+	0x03, 0x2a, 0x69, 0xee, 0x1c, 0xd7, 0xee, 0x8f, 0xd0, 0x3e, 0x8c, 0xb9, 0x3f, 0xf6, 0xd8, 0x19,
+	0xd9, 0xad, 0x06, 0x3f, 0xbe, 0xa2, 0xc3, 0x13, 0x07, 0x9a, 0x49, 0x36, 0x19, 0xb2, 0xd4, 0xd9,
+	0xc0, 0xb0, 0xc7, 0x57, 0x3c, 0x23, 0x93, 0x1e, 0xb4, 0xa6, 0x2c, 0x09, 0xa2, 0x24, 0x74, 0x6a,
+	0x7b, 0xd6, 0xbd, 0xf6, 0xf1, 0x15, 0x2f, 0x57, 0x1c, 0x36, 0xa0, 0x96, 0xb2, 0x33, 0xf7, 0x35,
+	0xc0, 0x33, 0x5f, 0xe5, 0x89, 0x01, 0x1c, 0x68, 0xd1, 0x20, 0x48, 0x99, 0x10, 0x26, 0x87, 0x5c,
+	0x24, 0x77, 0xa1, 0x31, 0xc4, 0x34, 0x54, 0x8c, 0xee, 0xa3, 0xab, 0xfb, 0xa6, 0x43, 0xfb, 0x79,
+	0x72, 0x9e, 0xde, 0x77, 0x29, 0x40, 0x5f, 0xf2, 0x94, 0x86, 0xec, 0x72, 0x87, 0x3b, 0x50, 0x1b,
+	0xb3, 0xb9, 0x72, 0x67, 0x7b, 0xb8, 0x2c, 0x43, 0xd4, 0x3e, 0x12, 0xe2, 0x25, 0xec, 0xbc, 0x4b,
+	0x69, 0x22, 0xa8, 0x2f, 0x23, 0x9e, 0xbc, 0x4a, 0x02, 0x36, 0x23, 0x37, 0x01, 0xd4, 0xe6, 0xa0,
+	0x32, 0x9d, 0x8e, 0xd2, 0xa8, 0xb1, 0xed, 0x42, 0x23, 0x42, 0x3b, 0xdd, 0x22, 0x4f, 0x0b, 0xee,
+	0xef, 0x35, 0x68, 0x1e, 0x33, 0x1a, 0xb0, 0x94, 0x7c, 0x06, 0xdd, 0x29, 0x4d, 0x59, 0x22, 0xab,
+	0x0e, 0x40, 0xab, 0x94, 0x87, 0x9b, 0x00, 0x59, 0xe2, 0xc7, 0x4c, 0xef, 0xeb, 0xb4, 0x3b, 0x4a,
+	0xa3, 0xb6, 0x7b, 0xd0, 0xf6, 0x79, 0x94, 0x0c, 0xa9, 0x60, 0x2a, 0x7f, 0xdb, 0x2b, 0x64, 0x1c,
+	0x6b, 0xca, 0xb9, 0x74, 0xea, 0x7a, 0xac, 0xb8, 0x26, 0xff, 0x81, 0x96, 0x9c, 0x69, 0x5f, 0x0d,
+	0xa5, 0x6e, 0xca, 0x99, 0x72, 0x74, 0x0b, 0xec, 0x94, 0xf9, 0x2c, 0x9a, 0x9a, 0x4c, 0x9a, 0x6a,
+	0xb7, 0x6b, 0x74, 0x79, 0x31, 0xc3, 0x98, 0xf3, 0x89, 0xd3, 0xd2, 0x97, 0x48, 0x09, 0xe4, 0xff,
+	0x00, 0x41, 0x74, 0x76, 0x16, 0xf9, 0x59, 0x2c, 0xe7, 0x4e, 0x5b, 0x17, 0x50, 0x6a, 0xc8, 0xf5,
+	0xe2, 0x9a, 0x74, 0x74, 0x40, 0x2d, 0x91, 0xff, 0x42, 0x27, 0xa4, 0x62, 0x10, 0x47, 0x93, 0x48,
+	0x3a, 0xa0, 0xda, 0xd3, 0x0e, 0xa9, 0x38, 0x41, 0x99, 0xdc, 0x00, 0x5c, 0x0f, 0x32, 0xc1, 0x02,
+	0xa7, 0xab, 0xf6, 0x5a, 0x21, 0x15, 0x3f, 0x08, 0x16, 0x60, 0x55, 0x32, 0x9a, 0x30, 0xc7, 0xd6,
+	0x55, 0xe1, 0x1a, 0x33, 0x63, 0x33, 0x99, 0x52, 0x67, 0x53, 0x67, 0xa6, 0x04, 0x6c, 0xdd, 0x24,
+	0x9a, 0x0d, 0x82, 0x28, 0x64, 0x42, 0x3a, 0x5b, 0xba, 0x75, 0x93, 0x68, 0xf6, 0x5c, 0x29, 0xf0,
+	0x50, 0xc2, 0x13, 0x9f, 0x39, 0xdb, 0xfa, 0x90, 0x12, 0x0a, 0xa0, 0xed, 0x54, 0x80, 0xf6, 0xb7,
+	0x05, 0xdd, 0xca, 0xe4, 0xcb, 0x93, 0x06, 0x6f, 0xfa, 0xa4, 0x29, 0x68, 0x9a, 0x46, 0x3e, 0x33,
+	0x83, 0xc2, 0x22, 0xde, 0xa0, 0x8c, 0xd7, 0x2e, 0xa4, 0x42, 0x8d, 0xa8, 0xee, 0xe1, 0x92, 0x6c,
+	0xc1, 0x86, 0xe4, 0x66, 0x36, 0x1b, 0x92, 0x97, 0x10, 0x6d, 0x54, 0x20, 0xaa, 0x2f, 0xd0, 0x34,
+	0x93, 0x66, 0x1e, 0x5a, 0x20, 0x36, 0x58, 0xe7, 0x66, 0x0a, 0xd6, 0x39, 0x4a, 0xa9, 0x69, 0xbc,
+	0x95, 0xa2, 0x24, 0x4c, 0xab, 0x2d, 0x51, 0x94, 0x03, 0x65, 0x39, 0xa8, 0x3b, 0x4b, 0xf9, 0x44,
+	0x35, 0xd6, 0xf6, 0xd4, 0xda, 0xfd, 0xd3, 0x82, 0xab, 0x95, 0x12, 0x3d, 0x26, 0xb2, 0x58, 0x92,
+	0xa7, 0xd0, 0x95, 0xa5, 0x52, 0x95, 0xdb, 0x7d, 0xb4, 0x5b, 0x00, 0xa4, 0x7a, 0xa0, 0x6a, 0x88,
+	0xf0, 0xcb, 0x09, 0x00, 0x1b, 0xd1, 0x2e, 0xe0, 0xbf, 0x84, 0x97, 0xda, 0x32, 0x5e, 0x6e, 0x81,
+	0xad, 0xb7, 0xcd, 0x95, 0xa9, 0xab, 0x7e, 0x75, 0x95, 0xee, 0x54, 0xa9, 0x4a, 0x48, 0x35, 0xaa,
+	0x90, 0xfa, 0xcd, 0x82, 0x86, 0xc2, 0x2b, 0xb9, 0x0b, 0xcd, 0x91, 0xc2, 0x96, 0x49, 0x77, 0xbb,
+	0x48, 0x57, 0x43, 0xce, 0x33, 0xdb, 0xe4, 0x6b, 0xb0, 0x2b, 0x39, 0x0b, 0x67, 0x63, 0xaf, 0xb6,
+	0xb6, 0xba, 0x05, 0x4b, 0x0c, 0xa1, 0x10, 0x88, 0xf3, 0xac, 0xad, 0x0c, 0xa1, 0xb7, 0xdd, 0x7f,
+	0x2c, 0xa8, 0x9d, 0xf0, 0xf0, 0x12, 0x3a, 0xba, 0x0e, 0x4d, 0xc9, 0xa7, 0x91, 0xaf, 0xc3, 0xdb,
+	0x9e, 0x91, 0x0a, 0x4a, 0xae, 0x95, 0x94, 0xfc, 0x29, 0xcd, 0x59, 0x0b, 0xef, 0x1b, 0xd0, 0x96,
+	0xb3, 0x81, 0x6e, 0x5c, 0x53, 0x03, 0x4a, 0xce, 0x56, 0x51, 0x58, 0x6b, 0x2d, 0x85, 0xb5, 0x2b,
+	0xfd, 0xc6, 0x8a, 0x52, 0x36, 0xe1, 0xe7, 0x2c, 0x50, 0x77, 0xad, 0xed, 0xe5, 0xa2, 0x7b, 0x0f,
+	0xea, 0x27, 0x3c, 0x14, 0x64, 0x0f, 0xea, 0x31, 0x0f, 0xb1, 0x60, 0x6c, 0x91, 0x5d, 0xb4, 0xe8,
+	0x84, 0x87, 0x9e, 0xda, 0x71, 0x7f, 0xdd, 0x80, 0x96, 0xa7, 0xf9, 0x05, 0x93, 0x98, 0x72, 0x21,
+	0x07, 0x42, 0x52, 0x99, 0x7f, 0xa5, 0x3a, 0xa8, 0xe9, 0xa3, 0x02, 0xdb, 0x84, 0x3b, 0x99, 0x30,
+	0x44, 0x6a, 0x24, 0xb2, 0x0f, 0xd7, 0xfc, 0x6c, 0x92, 0xc5, 0x54, 0x46, 0xe7, 0x6c, 0x50, 0x50,
+	0x86, 0x86, 0xd9, 0xd5, 0x72, 0xeb, 0xa5, 0x21, 0x8f, 0x82, 0xc2, 0xea, 0x55, 0x0a, 0xcb, 0x53,
+	0x6d, 0xac, 0x4b, 0xb5, 0xda, 0xd7, 0xe6, 0x42, 0x5f, 0x3f, 0x87, 0x1d, 0x9f, 0x27, 0x32, 0xa5,
+	0xbe, 0x1c, 0xe4, 0x23, 0xd6, 0x2d, 0xdc, 0xce, 0xf5, 0xcf, 0xcc, 0xa8, 0xab, 0x9c, 0xd6, 0x5e,
+	0xe0, 0x34, 0xf7, 0x17, 0x0b, 0x5a, 0x47, 0x34, 0x8e, 0xbf, 0x17, 0x61, 0x81, 0x4e, 0xab, 0x44,
+	0xa7, 0xe1, 0x8a, 0x8d, 0x82, 0x2b, 0x2e, 0xb2, 0xc9, 0x02, 0xf9, 0xd4, 0x97, 0xc8, 0x67, 0x35,
+	0xb5, 0xe4, 0x57, 0xac, 0x59, 0xf9, 0xea, 0xbf, 0x87, 0x2e, 0xe6, 0xe1, 0xb1, 0x9f, 0x32, 0xa4,
+	0x48, 0x17, 0x6a, 0x13, 0x11, 0x1a, 0x20, 0xed, 0x14, 0x7d, 0x31, 0xa9, 0x7a, 0xb8, 0xf9, 0xe9,
+	0x5f, 0xe8, 0x3d, 0x68, 0xbe, 0xd3, 0x97, 0xbb, 0xbc, 0xf4, 0x56, 0xf5, 0xd2, 0xbb, 0x7f, 0x58,
+	0xd0, 0xfd, 0x2e, 0x8a, 0x25, 0x4b, 0xdf, 0x66, 0x2c, 0x9d, 0x93, 0x07, 0x00, 0x58, 0xfe, 0x40,
+	0xfb, 0xb7, 0xd6, 0xf9, 0xef, 0xa0, 0x91, 0x92, 0xc8, 0x7d, 0x68, 0x4b, 0x3e, 0xf8, 0x48, 0x3e,
+	0x2d, 0xc9, 0xb5, 0xf5, 0xff, 0xa0, 0x63, 0x66, 0x66, 0xa0, 0x6c, 0x7b, 0xa5, 0x02, 0x51, 0x6e,
+	0xb2, 0xac, 0x2f, 0xa1, 0x5c, 0x97, 0x51, 0xa4, 0xfd, 0x97, 0x05, 0xd0, 0x9f, 0x27, 0x7e, 0x5f,
+	0xdf, 0x49, 0x07, 0x5a, 0x62, 0x9e, 0xf8, 0x48, 0x7e, 0x96, 0x86, 0x86, 0x11, 0xc9, 0x1d, 0xd8,
+	0x12, 0x92, 0xa6, 0x32, 0x4a, 0xc2, 0x4a, 0x8e, 0x75, 0x6f, 0x33, 0xd7, 0xea, 0xb4, 0x6e, 0xc3,
+	0xa6, 0x9f, 0xa5, 0xea, 0x51, 0x50, 0x3e, 0x4c, 0xea, 0x9e, 0x6d, 0x94, 0x85, 0xd1, 0x28, 0x0a,
+	0x47, 0x4c, 0xe4, 0x46, 0x9a, 0x0d, 0x6c, 0xa3, 0x2c, 0x8c, 0xa6, 0x59, 0x1c, 0xb3, 0x40, 0xe3,
+	0x4a, 0x18, 0xce, 0xb4, 0xb5, 0x52, 0x41, 0x4b, 0x20, 0xad, 0x8c, 0x13, 0xfe, 0x21, 0xc9, 0x6d,
+	0x34, 0x3d, 0x74, 0x95, 0x4e, 0x9b, 0x3c, 0xfa, 0xb9, 0x0d, 0xcd, 0xd7, 0x63, 0x7c, 0x58, 0x90,
+	0x03, 0x00, 0xe5, 0xfb, 0x70, 0x8e, 0xef, 0xac, 0x8b, 0xdd, 0xed, 0x6d, 0x2d, 0xaa, 0xc8, 0x43,
+	0xe8, 0x6a, 0x56, 0x5c, 0x7b, 0x62, 0x99, 0x3e, 0xc9, 0x93, 0x85, 0x87, 0x96, 0x7e, 0xe3, 0x5e,
+	0x1a, 0x49, 0x9b, 0x7c, 0xbb, 0xf0, 0x09, 0x3b, 0x9c, 0x2b, 0x80, 0x6e, 0x96, 0xce, 0xa9, 0x18,
+	0xf5, 0x7a, 0x2b, 0xe9, 0x5d, 0x7f, 0xed, 0x5e, 0x00, 0x59, 0x78, 0xdf, 0xe9, 0xfc, 0x6f, 0xac,
+	0x3a, 0xa1, 0x98, 0xb3, 0xb7, 0xf2, 0x5b, 0x41, 0x9e, 0x2c, 0xb8, 0xc9, 0x09, 0x6e, 0x29, 0x8f,
+	0x12, 0x4c, 0xb9, 0xc1, 0x53, 0xd8, 0xee, 0xb3, 0x24, 0x58, 0x78, 0x67, 0xac, 0xf2, 0xdf, 0x5b,
+	0xf4, 0x44, 0x1e, 0x43, 0xe7, 0x90, 0xc6, 0x34, 0xf1, 0xd9, 0x33, 0x49, 0xae, 0x15, 0x7b, 0xe5,
+	0xeb, 0xba, 0x57, 0xb6, 0xae, 0xf8, 0x1f, 0x38, 0x80, 0x8e, 0x79, 0x2d, 0x2f, 0x1c, 0x2a, 0x5f,
+	0xd0, 0x95, 0x28, 0xea, 0x17, 0xe1, 0x3e, 0x34, 0x8f, 0x78, 0xb0, 0x36, 0xc4, 0x92, 0xf5, 0x3e,
+	0xb4, 0x4e, 0xf9, 0x25, 0x19, 0x2d, 0x4f, 0xee, 0x31, 0xd8, 0xc8, 0x29, 0x47, 0x86, 0x31, 0x2b,
+	0x85, 0x57, 0xd8, 0x68, 0x39, 0xc8, 0x01, 0x74, 0x5f, 0x08, 0x19, 0x4d, 0xa8, 0x44, 0x7a, 0x27,
+	0x17, 0xe8, 0xe9, 0x42, 0x94, 0xaf, 0x60, 0xbb, 0x9f, 0x85, 0xf8, 0xf6, 0x7b, 0x99, 0x33, 0x63,
+	0x69, 0xa2, 0x7e, 0xb7, 0x56, 0xb5, 0xea, 0x21, 0x80, 0xe6, 0x24, 0xf5, 0x55, 0x2b, 0x33, 0xab,
+	0x10, 0x55, 0x25, 0x33, 0x65, 0xf4, 0x0d, 0x5c, 0xeb, 0x67, 0x43, 0xe1, 0xa7, 0xd1, 0x90, 0x7d,
+	0xf4, 0xec, 0xc2, 0xe7, 0xe6, 0x81, 0x85, 0x97, 0xbf, 0x38, 0x7c, 0xca, 0x3e, 0x20, 0x24, 0x2e,
+	0xa4, 0xb9, 0x8c, 0x98, 0x07, 0x16, 0xb6, 0x10, 0x39, 0xe8, 0x4d, 0xca, 0x43, 0xf5, 0xb5, 0x59,
+	0x3e, 0x52, 0x19, 0x72, 0x49, 0x55, 0x5f, 0x40, 0xe7, 0x94, 0xc9, 0x0f, 0x3c, 0x1d, 0xbf, 0x7a,
+	0x7e, 0xe1, 0xc4, 0x52, 0xfb, 0x0e, 0xef, 0xbc, 0xbf, 0x1d, 0x46, 0x72, 0x94, 0x0d, 0xf7, 0x7d,
+	0x3e, 0x39, 0xe0, 0x8a, 0x0e, 0x0e, 0x42, 0xfe, 0xa5, 0x59, 0x19, 0xe3, 0x61, 0x53, 0xfd, 0xca,
+	0x3e, 0xfe, 0x77, 0x00, 0xad, 0x0e, 0xf3, 0x52, 0xd7, 0x0e, 0x00, 0x00,
+}
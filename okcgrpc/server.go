@@ -0,0 +1,513 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcgrpc
+
+import (
+	"fmt"
+	"math/big"
+
+	okcoin "github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/common/hexutil"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/state"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/internal/okcapi"
+	"github.com/okcoin/go-okcoin/okc/filters"
+	"github.com/okcoin/go-okcoin/rpc"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Backend is the chain data access of the gRPC service: the one of the public
+// Okcoin APIs, extended with the log filtering support of the filters package.
+type Backend interface {
+	okcapi.Backend
+	filters.Backend
+}
+
+// server implements the Okcoin gRPC service on top of a backend.
+type server struct {
+	backend   Backend
+	networkID uint64
+	chain     *okcapi.PublicBlockChainAPI // Call and gas estimation implementation
+	events    *filters.EventSystem        // Source of the log and chain head streams
+}
+
+// newServer creates the gRPC service implementation serving the chain data of a
+// backend, the event system following the light chain if light is set.
+func newServer(backend Backend, networkID uint64, light bool) *server {
+	return &server{
+		backend:   backend,
+		networkID: networkID,
+		chain:     okcapi.NewPublicBlockChainAPI(backend),
+		events:    filters.NewEventSystem(backend.EventMux(), backend, light),
+	}
+}
+
+// invalidArgument wraps an error caused by a malformed request.
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// notFound returns the error of a missing chain item.
+func notFound(what string) error {
+	return status.Error(codes.NotFound, what+" not found")
+}
+
+// blockNumber resolves a block reference into a block number, looking up the
+// number of blocks referenced by hash.
+func (s *server) blockNumber(ctx context.Context, ref *BlockRef) (rpc.BlockNumber, error) {
+	switch ref := ref.GetRef().(type) {
+	case *BlockRef_Hash:
+		block, err := s.block(ctx, ref)
+		if err != nil {
+			return 0, err
+		}
+		return rpc.BlockNumber(block.NumberU64()), nil
+	case *BlockRef_Number:
+		return rpc.BlockNumber(ref.Number), nil
+	case *BlockRef_Pending:
+		return rpc.PendingBlockNumber, nil
+	default:
+		return rpc.LatestBlockNumber, nil
+	}
+}
+
+// block retrieves a block by hash.
+func (s *server) block(ctx context.Context, ref *BlockRef_Hash) (*types.Block, error) {
+	hash, err := decodeHash(ref.Hash, "block hash")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	block, err := s.backend.GetBlock(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, notFound("block")
+	}
+	return block, nil
+}
+
+// resolveBlock retrieves a referenced block.
+func (s *server) resolveBlock(ctx context.Context, ref *BlockRef) (*types.Block, error) {
+	if ref, ok := ref.GetRef().(*BlockRef_Hash); ok {
+		return s.block(ctx, ref)
+	}
+	number, err := s.blockNumber(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.backend.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, notFound("block")
+	}
+	return block, nil
+}
+
+// resolveState retrieves the state at a referenced block.
+func (s *server) resolveState(ctx context.Context, ref *BlockRef) (*state.StateDB, error) {
+	number, err := s.blockNumber(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	state, _, err := s.backend.StateAndHeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, notFound("block")
+	}
+	return state, nil
+}
+
+// encodeTransaction converts a transaction into its gRPC message, along with
+// its sender.
+func encodeTransaction(tx *types.Transaction) *Transaction {
+	var signer types.Signer = types.FrontierSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	msg := EncodeTransaction(tx)
+	if from, err := types.Sender(signer, tx); err == nil {
+		msg.From = from.Bytes()
+	}
+	return msg
+}
+
+func (s *server) BlockByRef(ctx context.Context, ref *BlockRef) (*Block, error) {
+	block, err := s.resolveBlock(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	msg := EncodeBlock(block)
+	for i, tx := range block.Transactions() {
+		msg.Transactions[i] = encodeTransaction(tx)
+	}
+	return msg, nil
+}
+
+func (s *server) HeaderByRef(ctx context.Context, ref *BlockRef) (*Header, error) {
+	if ref, ok := ref.GetRef().(*BlockRef_Hash); ok {
+		block, err := s.block(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		return EncodeHeader(block.Header()), nil
+	}
+	number, err := s.blockNumber(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	header, err := s.backend.HeaderByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, notFound("header")
+	}
+	return EncodeHeader(header), nil
+}
+
+func (s *server) TransactionCount(ctx context.Context, ref *BlockRef) (*Count, error) {
+	block, err := s.resolveBlock(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return &Count{Count: uint64(len(block.Transactions()))}, nil
+}
+
+func (s *server) TransactionByHash(ctx context.Context, req *Hash) (*TransactionResult, error) {
+	hash, err := decodeHash(req.Hash, "transaction hash")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	if tx, blockHash, blockNumber, index := core.GetTransaction(s.backend.ChainDb(), hash); tx != nil {
+		return &TransactionResult{
+			Transaction: encodeTransaction(tx),
+			BlockHash:   blockHash.Bytes(),
+			BlockNumber: blockNumber,
+			Index:       index,
+		}, nil
+	}
+	if tx := s.backend.GetPoolTransaction(hash); tx != nil {
+		return &TransactionResult{Transaction: encodeTransaction(tx), Pending: true}, nil
+	}
+	return nil, notFound("transaction")
+}
+
+func (s *server) TransactionInBlock(ctx context.Context, req *TransactionIndex) (*Transaction, error) {
+	block, err := s.block(ctx, &BlockRef_Hash{Hash: req.BlockHash})
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if req.Index >= uint64(len(txs)) {
+		return nil, notFound("transaction")
+	}
+	return encodeTransaction(txs[req.Index]), nil
+}
+
+func (s *server) TransactionReceipt(ctx context.Context, req *Hash) (*Receipt, error) {
+	hash, err := decodeHash(req.Hash, "transaction hash")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	tx, blockHash, _, index := core.GetTransaction(s.backend.ChainDb(), hash)
+	if tx == nil {
+		return nil, notFound("receipt")
+	}
+	receipts, err := s.backend.GetReceipts(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(receipts)) <= index {
+		return nil, notFound("receipt")
+	}
+	return EncodeReceipt(receipts[index]), nil
+}
+
+func (s *server) SendTransaction(ctx context.Context, req *Transaction) (*Hash, error) {
+	tx, err := DecodeTransaction(req)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	if err := s.backend.SendTx(ctx, tx); err != nil {
+		return nil, err
+	}
+	return &Hash{Hash: tx.Hash().Bytes()}, nil
+}
+
+func (s *server) BalanceAt(ctx context.Context, req *AccountRef) (*Quantity, error) {
+	addr, err := decodeAddress(req.Address, "address")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	state, err := s.resolveState(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	return &Quantity{Value: encodeBig(state.GetBalance(addr))}, state.Error()
+}
+
+func (s *server) StorageAt(ctx context.Context, req *StorageRef) (*Data, error) {
+	addr, err := decodeAddress(req.Address, "address")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	key, err := decodeHash(req.Key, "storage key")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	state, err := s.resolveState(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	return &Data{Data: state.GetState(addr, key).Bytes()}, state.Error()
+}
+
+func (s *server) CodeAt(ctx context.Context, req *AccountRef) (*Data, error) {
+	addr, err := decodeAddress(req.Address, "address")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	state, err := s.resolveState(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	return &Data{Data: state.GetCode(addr)}, state.Error()
+}
+
+func (s *server) NonceAt(ctx context.Context, req *AccountRef) (*Count, error) {
+	addr, err := decodeAddress(req.Address, "address")
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	state, err := s.resolveState(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	return &Count{Count: state.GetNonce(addr)}, state.Error()
+}
+
+// callArgs converts a gRPC call message into the arguments of the call API.
+func callArgs(msg *CallMsg) (okcapi.CallArgs, error) {
+	var (
+		args okcapi.CallArgs
+		err  error
+	)
+	if msg == nil {
+		return args, nil
+	}
+	if len(msg.From) > 0 {
+		if args.From, err = decodeAddress(msg.From, "sender"); err != nil {
+			return args, err
+		}
+	}
+	if args.To, err = decodeOptAddress(msg.To, "recipient"); err != nil {
+		return args, err
+	}
+	args.Gas = hexutil.Uint64(msg.Gas)
+	args.GasPrice = hexutil.Big(*decodeBig(msg.GasPrice))
+	args.Value = hexutil.Big(*decodeBig(msg.Value))
+	args.Data = msg.Data
+	return args, nil
+}
+
+func (s *server) CallContract(ctx context.Context, req *CallRequest) (*Data, error) {
+	args, err := callArgs(req.Msg)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	number, err := s.blockNumber(ctx, req.Block)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.chain.Call(ctx, args, number)
+	if err != nil {
+		return nil, err
+	}
+	return &Data{Data: result}, nil
+}
+
+func (s *server) EstimateGas(ctx context.Context, req *CallMsg) (*Count, error) {
+	args, err := callArgs(req)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	gas, err := s.chain.EstimateGas(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	return &Count{Count: uint64(gas)}, nil
+}
+
+func (s *server) SuggestGasPrice(ctx context.Context, req *Empty) (*Quantity, error) {
+	price, err := s.backend.SuggestPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Quantity{Value: encodeBig(price)}, nil
+}
+
+// filterBlock converts a bound of a filter range into a block number, nil for the
+// latest block. Bounds can't reference blocks by hash or the pending block.
+func filterBlock(ref *BlockRef, field string) (*big.Int, error) {
+	switch ref := ref.GetRef().(type) {
+	case nil:
+		return nil, nil
+	case *BlockRef_Number:
+		return new(big.Int).SetUint64(ref.Number), nil
+	default:
+		return nil, fmt.Errorf("%s must be a block number", field)
+	}
+}
+
+// filterQuery converts a gRPC filter query message into a log filter query.
+func filterQuery(msg *FilterQuery) (okcoin.FilterQuery, error) {
+	var (
+		q   okcoin.FilterQuery
+		err error
+	)
+	if q.FromBlock, err = filterBlock(msg.FromBlock, "filter start"); err != nil {
+		return q, err
+	}
+	if q.ToBlock, err = filterBlock(msg.ToBlock, "filter end"); err != nil {
+		return q, err
+	}
+	q.Addresses = make([]common.Address, len(msg.Addresses))
+	for i, addr := range msg.Addresses {
+		var err error
+		if q.Addresses[i], err = decodeAddress(addr, "filter address"); err != nil {
+			return q, err
+		}
+	}
+	q.Topics = make([][]common.Hash, len(msg.Topics))
+	for i, alternatives := range msg.Topics {
+		q.Topics[i] = make([]common.Hash, len(alternatives.Topics))
+		for j, topic := range alternatives.Topics {
+			var err error
+			if q.Topics[i][j], err = decodeHash(topic, "filter topic"); err != nil {
+				return q, err
+			}
+		}
+	}
+	return q, nil
+}
+
+func (s *server) FilterLogs(ctx context.Context, req *FilterQuery) (*Logs, error) {
+	q, err := filterQuery(req)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	begin, end := rpc.LatestBlockNumber.Int64(), rpc.LatestBlockNumber.Int64()
+	if q.FromBlock != nil {
+		begin = q.FromBlock.Int64()
+	}
+	if q.ToBlock != nil {
+		end = q.ToBlock.Int64()
+	}
+	logs, err := filters.New(s.backend, begin, end, q.Addresses, q.Topics).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	msg := &Logs{Logs: make([]*Log, len(logs))}
+	for i, l := range logs {
+		msg.Logs[i] = EncodeLog(l)
+	}
+	return msg, nil
+}
+
+func (s *server) SubscribeFilterLogs(req *FilterQuery, stream Okcoin_SubscribeFilterLogsServer) error {
+	q, err := filterQuery(req)
+	if err != nil {
+		return invalidArgument(err)
+	}
+	logs := make(chan []*types.Log)
+	sub, err := s.events.SubscribeLogs(q, logs)
+	if err != nil {
+		return invalidArgument(err)
+	}
+	defer sub.Unsubscribe()
+
+	// Acknowledge the subscription, so clients can tell it is in place
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case batch := <-logs:
+			for _, l := range batch {
+				if err := stream.Send(EncodeLog(l)); err != nil {
+					return err
+				}
+			}
+		case err := <-sub.Err():
+			return err
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *server) SubscribeNewHead(req *Empty, stream Okcoin_SubscribeNewHeadServer) error {
+	headers := make(chan *types.Header)
+	sub := s.events.SubscribeNewHeads(headers)
+	defer sub.Unsubscribe()
+
+	// Acknowledge the subscription, so clients can tell it is in place
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case header := <-headers:
+			if err := stream.Send(EncodeHeader(header)); err != nil {
+				return err
+			}
+		case err := <-sub.Err():
+			return err
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (s *server) SyncProgress(ctx context.Context, req *Empty) (*SyncStatus, error) {
+	progress := s.backend.Downloader().Progress()
+	if progress.CurrentBlock >= progress.HighestBlock {
+		return &SyncStatus{}, nil
+	}
+	return &SyncStatus{
+		Syncing:       true,
+		StartingBlock: progress.StartingBlock,
+		CurrentBlock:  progress.CurrentBlock,
+		HighestBlock:  progress.HighestBlock,
+		PulledStates:  progress.PulledStates,
+		KnownStates:   progress.KnownStates,
+	}, nil
+}
+
+func (s *server) NetworkID(ctx context.Context, req *Empty) (*Count, error) {
+	return &Count{Count: s.networkID}, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package okcgrpc provides a gRPC interface to the chain data of a node, an
+// alternative to JSON-RPC sparing clients the decoding of hex encoded JSON.
+//
+// The protobuf schema of the service is okc.proto, from which okc.pb.go is
+// generated with protoc-gen-go using the grpc plugin.
+package okcgrpc
+
+import (
+	"net"
+
+	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/p2p"
+	"github.com/okcoin/go-okcoin/rpc"
+	"google.golang.org/grpc"
+)
+
+// Service is a node service exposing the chain data of a backend over gRPC.
+type Service struct {
+	endpoint string       // The host:port endpoint for this service
+	api      *server      // The gRPC service implementation
+	guard    *rpc.Guard   // Access control and limits of the calls
+	server   *grpc.Server // The gRPC server, nil if the service is not running
+}
+
+// New creates a gRPC service serving the chain data of backend on endpoint. The
+// network ID is reported to clients, light selects the light chain following
+// of the log and chain head streams. The calls are run through guard, nil
+// permitting everything.
+func New(backend Backend, networkID uint64, light bool, endpoint string, guard *rpc.Guard) *Service {
+	if guard == nil {
+		guard = new(rpc.Guard)
+	}
+	return &Service{
+		endpoint: endpoint,
+		api:      newServer(backend, networkID, light),
+		guard:    guard,
+	}
+}
+
+// Protocols implements node.Service, returning the P2P network protocols used
+// by the gRPC service (nil as it doesn't use the devp2p overlay network).
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs implements node.Service, returning the RPC API endpoints provided by the
+// gRPC service (nil as it doesn't provide any JSON-RPC APIs).
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start implements node.Service, starting the gRPC server.
+func (s *Service) Start(server *p2p.Server) error {
+	listener, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	s.server = grpc.NewServer(guardOptions(s.guard)...)
+	RegisterOkcoinServer(s.server, s.api)
+	go s.server.Serve(listener)
+	log.Info("gRPC endpoint opened", "addr", listener.Addr())
+	return nil
+}
+
+// Stop implements node.Service, terminating the gRPC server along with all the
+// calls and streams in progress.
+func (s *Service) Stop() error {
+	if s.server != nil {
+		s.server.Stop()
+		s.server = nil
+		log.Info("gRPC endpoint closed", "addr", s.endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,496 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package okcgrpcclient provides a client for the Okcoin gRPC API, with the
+// methods of the JSON-RPC client of package okcclient.
+package okcgrpcclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/okcgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Client defines typed wrappers for the Okcoin gRPC API.
+type Client struct {
+	conn *grpc.ClientConn
+	c    okcgrpc.OkcoinClient
+}
+
+// Dial connects a client to the given gRPC endpoint (host:port), without
+// transport security.
+func Dial(target string) (*Client, error) {
+	conn, err := grpc.Dial(target, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient creates a client that uses the given gRPC connection.
+func NewClient(conn *grpc.ClientConn) *Client {
+	return &Client{conn, okcgrpc.NewOkcoinClient(conn)}
+}
+
+// Close closes the underlying gRPC connection.
+func (ec *Client) Close() error {
+	return ec.conn.Close()
+}
+
+// convertErr maps the gRPC status of missing chain items to okcoin.NotFound.
+func convertErr(err error) error {
+	if s, ok := status.FromError(err); ok && s.Code() == codes.NotFound {
+		return okcoin.NotFound
+	}
+	return err
+}
+
+// Blockchain Access
+
+// BlockByHash returns the given full block.
+func (ec *Client) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return ec.getBlock(ctx, okcgrpc.EncodeBlockHash(hash))
+}
+
+// BlockByNumber returns a block from the current canonical chain. If number is nil, the
+// latest known block is returned.
+func (ec *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return ec.getBlock(ctx, okcgrpc.EncodeBlockNumber(number))
+}
+
+func (ec *Client) getBlock(ctx context.Context, ref *okcgrpc.BlockRef) (*types.Block, error) {
+	msg, err := ec.c.BlockByRef(ctx, ref)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	block, err := okcgrpc.DecodeBlock(msg)
+	if err != nil {
+		return nil, err
+	}
+	// Fill the sender cache of transactions in the block.
+	for i, tx := range block.Transactions() {
+		if from := msg.Transactions[i].From; len(from) == common.AddressLength {
+			setSenderFromServer(tx, common.BytesToAddress(from), block.Hash())
+		}
+	}
+	return block, nil
+}
+
+// HeaderByHash returns the block header with the given hash.
+func (ec *Client) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return ec.getHeader(ctx, okcgrpc.EncodeBlockHash(hash))
+}
+
+// HeaderByNumber returns a block header from the current canonical chain. If number is
+// nil, the latest known header is returned.
+func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return ec.getHeader(ctx, okcgrpc.EncodeBlockNumber(number))
+}
+
+func (ec *Client) getHeader(ctx context.Context, ref *okcgrpc.BlockRef) (*types.Header, error) {
+	msg, err := ec.c.HeaderByRef(ctx, ref)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return okcgrpc.DecodeHeader(msg)
+}
+
+// TransactionByHash returns the transaction with the given hash.
+func (ec *Client) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	msg, err := ec.c.TransactionByHash(ctx, &okcgrpc.Hash{Hash: hash.Bytes()})
+	if err != nil {
+		return nil, false, convertErr(err)
+	}
+	if tx, err = okcgrpc.DecodeTransaction(msg.Transaction); err != nil {
+		return nil, false, err
+	}
+	if from := msg.Transaction.From; len(from) == common.AddressLength && !msg.Pending {
+		setSenderFromServer(tx, common.BytesToAddress(from), common.BytesToHash(msg.BlockHash))
+	}
+	return tx, msg.Pending, nil
+}
+
+// TransactionSender returns the sender address of the given transaction. The transaction
+// must be known to the remote node and included in the blockchain at the given block and
+// index. The sender is the one derived by the protocol at the time of inclusion.
+//
+// There is a fast-path for transactions retrieved by TransactionByHash and
+// TransactionInBlock. Getting their sender address can be done without a gRPC interaction.
+func (ec *Client) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	// Try to load the address from the cache.
+	sender, err := types.Sender(&senderFromServer{blockhash: block}, tx)
+	if err == nil {
+		return sender, nil
+	}
+	msg, err := ec.c.TransactionInBlock(ctx, &okcgrpc.TransactionIndex{BlockHash: block.Bytes(), Index: uint64(index)})
+	if err != nil {
+		return common.Address{}, convertErr(err)
+	}
+	if common.BytesToHash(msg.Hash) != tx.Hash() {
+		return common.Address{}, errors.New("wrong inclusion block/index")
+	}
+	return common.BytesToAddress(msg.From), nil
+}
+
+// TransactionCount returns the total number of transactions in the given block.
+func (ec *Client) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	msg, err := ec.c.TransactionCount(ctx, okcgrpc.EncodeBlockHash(blockHash))
+	if err != nil {
+		return 0, convertErr(err)
+	}
+	return uint(msg.Count), nil
+}
+
+// TransactionInBlock returns a single transaction at index in the given block.
+func (ec *Client) TransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (*types.Transaction, error) {
+	msg, err := ec.c.TransactionInBlock(ctx, &okcgrpc.TransactionIndex{BlockHash: blockHash.Bytes(), Index: uint64(index)})
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	tx, err := okcgrpc.DecodeTransaction(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(msg.From) == common.AddressLength {
+		setSenderFromServer(tx, common.BytesToAddress(msg.From), blockHash)
+	}
+	return tx, nil
+}
+
+// TransactionReceipt returns the receipt of a transaction by transaction hash.
+// Note that the receipt is not available for pending transactions.
+func (ec *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	msg, err := ec.c.TransactionReceipt(ctx, &okcgrpc.Hash{Hash: txHash.Bytes()})
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return okcgrpc.DecodeReceipt(msg)
+}
+
+// SyncProgress retrieves the current progress of the sync algorithm. If there's
+// no sync currently running, it returns nil.
+func (ec *Client) SyncProgress(ctx context.Context) (*okcoin.SyncProgress, error) {
+	msg, err := ec.c.SyncProgress(ctx, &okcgrpc.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	if !msg.Syncing {
+		return nil, nil
+	}
+	return &okcoin.SyncProgress{
+		StartingBlock: msg.StartingBlock,
+		CurrentBlock:  msg.CurrentBlock,
+		HighestBlock:  msg.HighestBlock,
+		PulledStates:  msg.PulledStates,
+		KnownStates:   msg.KnownStates,
+	}, nil
+}
+
+// SubscribeNewHead subscribes to notifications about the current blockchain head
+// on the given channel.
+func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (okcoin.Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := ec.c.SubscribeNewHead(ctx, &okcgrpc.Empty{})
+	if err == nil {
+		// Wait for the server to acknowledge the subscription
+		_, err = stream.Header()
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return newSubscription(ctx, cancel, func() error {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		header, err := okcgrpc.DecodeHeader(msg)
+		if err != nil {
+			return err
+		}
+		select {
+		case ch <- header:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}), nil
+}
+
+// State Access
+
+// NetworkID returns the network ID (also known as the chain ID) for this chain.
+func (ec *Client) NetworkID(ctx context.Context) (*big.Int, error) {
+	msg, err := ec.c.NetworkID(ctx, &okcgrpc.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(msg.Count), nil
+}
+
+// BalanceAt returns the wei balance of the given account.
+// The block number can be nil, in which case the balance is taken from the latest known block.
+func (ec *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return ec.balanceAt(ctx, account, okcgrpc.EncodeBlockNumber(blockNumber))
+}
+
+func (ec *Client) balanceAt(ctx context.Context, account common.Address, block *okcgrpc.BlockRef) (*big.Int, error) {
+	msg, err := ec.c.BalanceAt(ctx, &okcgrpc.AccountRef{Address: account.Bytes(), Block: block})
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return new(big.Int).SetBytes(msg.Value), nil
+}
+
+// StorageAt returns the value of key in the contract storage of the given account.
+// The block number can be nil, in which case the value is taken from the latest known block.
+func (ec *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	return ec.storageAt(ctx, account, key, okcgrpc.EncodeBlockNumber(blockNumber))
+}
+
+func (ec *Client) storageAt(ctx context.Context, account common.Address, key common.Hash, block *okcgrpc.BlockRef) ([]byte, error) {
+	msg, err := ec.c.StorageAt(ctx, &okcgrpc.StorageRef{Address: account.Bytes(), Key: key.Bytes(), Block: block})
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return msg.Data, nil
+}
+
+// CodeAt returns the contract code of the given account.
+// The block number can be nil, in which case the code is taken from the latest known block.
+func (ec *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return ec.codeAt(ctx, account, okcgrpc.EncodeBlockNumber(blockNumber))
+}
+
+func (ec *Client) codeAt(ctx context.Context, account common.Address, block *okcgrpc.BlockRef) ([]byte, error) {
+	msg, err := ec.c.CodeAt(ctx, &okcgrpc.AccountRef{Address: account.Bytes(), Block: block})
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return msg.Data, nil
+}
+
+// NonceAt returns the account nonce of the given account.
+// The block number can be nil, in which case the nonce is taken from the latest known block.
+func (ec *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return ec.nonceAt(ctx, account, okcgrpc.EncodeBlockNumber(blockNumber))
+}
+
+func (ec *Client) nonceAt(ctx context.Context, account common.Address, block *okcgrpc.BlockRef) (uint64, error) {
+	msg, err := ec.c.NonceAt(ctx, &okcgrpc.AccountRef{Address: account.Bytes(), Block: block})
+	if err != nil {
+		return 0, convertErr(err)
+	}
+	return msg.Count, nil
+}
+
+// Filters
+
+// FilterLogs executes a filter query.
+func (ec *Client) FilterLogs(ctx context.Context, q okcoin.FilterQuery) ([]types.Log, error) {
+	msg, err := ec.c.FilterLogs(ctx, okcgrpc.EncodeFilterQuery(q))
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	logs := make([]types.Log, len(msg.Logs))
+	for i, l := range msg.Logs {
+		log, err := okcgrpc.DecodeLog(l)
+		if err != nil {
+			return nil, err
+		}
+		logs[i] = *log
+	}
+	return logs, nil
+}
+
+// SubscribeFilterLogs subscribes to the results of a streaming filter query.
+func (ec *Client) SubscribeFilterLogs(ctx context.Context, q okcoin.FilterQuery, ch chan<- types.Log) (okcoin.Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := ec.c.SubscribeFilterLogs(ctx, okcgrpc.EncodeFilterQuery(q))
+	if err == nil {
+		// Wait for the server to acknowledge the subscription
+		_, err = stream.Header()
+	}
+	if err != nil {
+		cancel()
+		return nil, convertErr(err)
+	}
+	return newSubscription(ctx, cancel, func() error {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		log, err := okcgrpc.DecodeLog(msg)
+		if err != nil {
+			return err
+		}
+		select {
+		case ch <- *log:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}), nil
+}
+
+// Pending State
+
+// PendingBalanceAt returns the wei balance of the given account in the pending state.
+func (ec *Client) PendingBalanceAt(ctx context.Context, account common.Address) (*big.Int, error) {
+	return ec.balanceAt(ctx, account, okcgrpc.PendingBlock())
+}
+
+// PendingStorageAt returns the value of key in the contract storage of the given account in the pending state.
+func (ec *Client) PendingStorageAt(ctx context.Context, account common.Address, key common.Hash) ([]byte, error) {
+	return ec.storageAt(ctx, account, key, okcgrpc.PendingBlock())
+}
+
+// PendingCodeAt returns the contract code of the given account in the pending state.
+func (ec *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return ec.codeAt(ctx, account, okcgrpc.PendingBlock())
+}
+
+// PendingNonceAt returns the account nonce of the given account in the pending state.
+// This is the nonce that should be used for the next transaction.
+func (ec *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return ec.nonceAt(ctx, account, okcgrpc.PendingBlock())
+}
+
+// PendingTransactionCount returns the total number of transactions in the pending state.
+func (ec *Client) PendingTransactionCount(ctx context.Context) (uint, error) {
+	msg, err := ec.c.TransactionCount(ctx, okcgrpc.PendingBlock())
+	if err != nil {
+		return 0, convertErr(err)
+	}
+	return uint(msg.Count), nil
+}
+
+// Contract Calling
+
+// CallContract executes a message call transaction, which is directly executed in the VM
+// of the node, but never mined into the blockchain.
+//
+// blockNumber selects the block height at which the call runs. It can be nil, in which
+// case the code is taken from the latest known block. Note that state from very old
+// blocks might not be available.
+func (ec *Client) CallContract(ctx context.Context, msg okcoin.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return ec.callContract(ctx, msg, okcgrpc.EncodeBlockNumber(blockNumber))
+}
+
+// PendingCallContract executes a message call transaction using the EVM.
+// The state seen by the contract call is the pending state.
+func (ec *Client) PendingCallContract(ctx context.Context, msg okcoin.CallMsg) ([]byte, error) {
+	return ec.callContract(ctx, msg, okcgrpc.PendingBlock())
+}
+
+func (ec *Client) callContract(ctx context.Context, msg okcoin.CallMsg, block *okcgrpc.BlockRef) ([]byte, error) {
+	res, err := ec.c.CallContract(ctx, &okcgrpc.CallRequest{Msg: okcgrpc.EncodeCallMsg(msg), Block: block})
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	return res.Data, nil
+}
+
+// SuggestGasPrice retrieves the currently suggested gas price to allow a timely
+// execution of a transaction.
+func (ec *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	msg, err := ec.c.SuggestGasPrice(ctx, &okcgrpc.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(msg.Value), nil
+}
+
+// EstimateGas tries to estimate the gas needed to execute a specific transaction based on
+// the current pending state of the backend blockchain. There is no guarantee that this is
+// the true gas limit requirement as other transactions may be added or removed by miners,
+// but it should provide a basis for setting a reasonable default.
+func (ec *Client) EstimateGas(ctx context.Context, msg okcoin.CallMsg) (uint64, error) {
+	res, err := ec.c.EstimateGas(ctx, okcgrpc.EncodeCallMsg(msg))
+	if err != nil {
+		return 0, err
+	}
+	return res.Count, nil
+}
+
+// SendTransaction injects a signed transaction into the pending pool for execution.
+//
+// If the transaction was a contract creation use the TransactionReceipt method to get the
+// contract address after the transaction has been mined.
+func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, err := ec.c.SendTransaction(ctx, okcgrpc.EncodeTransaction(tx))
+	return err
+}
+
+// subscription is an okcoin.Subscription feeding the messages of a gRPC stream
+// into a channel.
+type subscription struct {
+	cancel context.CancelFunc
+	err    chan error
+	once   sync.Once
+	done   chan struct{}
+}
+
+// newSubscription starts a subscription calling deliver to forward stream messages
+// until the context is cancelled or delivering fails.
+func newSubscription(ctx context.Context, cancel context.CancelFunc, deliver func() error) *subscription {
+	sub := &subscription{
+		cancel: cancel,
+		err:    make(chan error, 1),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(sub.done)
+		defer close(sub.err)
+		for {
+			err := deliver()
+			if err == nil {
+				continue
+			}
+			// Report failures of the stream, but not its orderly termination
+			if ctx.Err() == nil && err != io.EOF {
+				sub.err <- err
+			}
+			return
+		}
+	}()
+	return sub
+}
+
+// Err returns the subscription error channel. The channel receives a value if
+// the stream fails, and is closed when the subscription ends.
+func (sub *subscription) Err() <-chan error {
+	return sub.err
+}
+
+// Unsubscribe cancels the stream, waiting for the delivery of messages to end.
+// It can be called any number of times.
+func (sub *subscription) Unsubscribe() {
+	sub.once.Do(func() {
+		sub.cancel()
+		<-sub.done
+	})
+}
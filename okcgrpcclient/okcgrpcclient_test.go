@@ -0,0 +1,213 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package okcgrpcclient
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/okcoin/go-okcoin"
+	"github.com/okcoin/go-okcoin/common"
+	"github.com/okcoin/go-okcoin/consensus/okcash"
+	"github.com/okcoin/go-okcoin/core"
+	"github.com/okcoin/go-okcoin/core/types"
+	"github.com/okcoin/go-okcoin/crypto"
+	"github.com/okcoin/go-okcoin/internal/okctest"
+	"github.com/okcoin/go-okcoin/node"
+	"github.com/okcoin/go-okcoin/okc"
+	"github.com/okcoin/go-okcoin/okcgrpc"
+	"github.com/okcoin/go-okcoin/params"
+)
+
+// Verify that Client implements the okcoin interfaces.
+var (
+	_ = okcoin.ChainReader(&Client{})
+	_ = okcoin.TransactionReader(&Client{})
+	_ = okcoin.ChainStateReader(&Client{})
+	_ = okcoin.ChainSyncReader(&Client{})
+	_ = okcoin.ContractCaller(&Client{})
+	_ = okcoin.GasEstimator(&Client{})
+	_ = okcoin.GasPricer(&Client{})
+	_ = okcoin.LogFilterer(&Client{})
+	_ = okcoin.PendingStateReader(&Client{})
+	_ = okcoin.PendingContractCaller(&Client{})
+	_ = okcoin.TransactionSender(&Client{})
+)
+
+// newTestBackend starts a node over the test chain of okctest, along with a gRPC
+// service serving its chain data, and returns a client connected to the gRPC
+// endpoint.
+func newTestBackend(t *testing.T) (*node.Node, *okc.Okcoin, *Client) {
+	// Pick a free port for the gRPC endpoint
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	endpoint := listener.Addr().String()
+	listener.Close()
+
+	stack, okcServ := okctest.NewChainNode(t, nil, func(ctx *node.ServiceContext) (node.Service, error) {
+		var okcServ *okc.Okcoin
+		if err := ctx.Service(&okcServ); err != nil {
+			return nil, err
+		}
+		return okcgrpc.New(okcServ.ApiBackend, okcServ.NetVersion(), false, endpoint, nil), nil
+	})
+	client, err := Dial(endpoint)
+	if err != nil {
+		stack.Stop()
+		t.Fatalf("failed to dial gRPC endpoint: %v", err)
+	}
+	return stack, okcServ, client
+}
+
+// Tests that blocks, headers and transactions can be retrieved.
+func TestChainAccess(t *testing.T) {
+	stack, okcServ, client := newTestBackend(t)
+	defer stack.Stop()
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	want := okcServ.BlockChain().GetBlockByNumber(1)
+	block, err := client.BlockByNumber(ctx, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to retrieve block: %v", err)
+	}
+	if block.Hash() != want.Hash() || len(block.Transactions()) != 1 {
+		t.Fatalf("block mismatch: have %x with %d txs, want %x with 1", block.Hash(), len(block.Transactions()), want.Hash())
+	}
+	if header, err := client.HeaderByHash(ctx, want.Hash()); err != nil || header.Hash() != want.Hash() {
+		t.Errorf("header by hash mismatch: %v", err)
+	}
+	if header, err := client.HeaderByNumber(ctx, nil); err != nil || header.Number.Uint64() != 3 {
+		t.Errorf("latest header mismatch: %v", err)
+	}
+	if _, err := client.BlockByNumber(ctx, big.NewInt(100)); err != okcoin.NotFound {
+		t.Errorf("unknown block error mismatch: have %v, want %v", err, okcoin.NotFound)
+	}
+	// The senders of retrieved transactions are cached
+	tx := block.Transactions()[0]
+	if sender, err := client.TransactionSender(ctx, tx, block.Hash(), 0); err != nil || sender != okctest.Addr {
+		t.Errorf("transaction sender mismatch: have %x (%v), want %x", sender, err, okctest.Addr)
+	}
+	if count, err := client.TransactionCount(ctx, block.Hash()); err != nil || count != 1 {
+		t.Errorf("transaction count mismatch: have %d (%v), want 1", count, err)
+	}
+	found, pending, err := client.TransactionByHash(ctx, tx.Hash())
+	if err != nil || pending || found.Hash() != tx.Hash() {
+		t.Errorf("transaction by hash mismatch: pending %v, err %v", pending, err)
+	}
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve receipt: %v", err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful || receipt.GasUsed != params.TxGas {
+		t.Errorf("receipt mismatch: status %d, gas used %d", receipt.Status, receipt.GasUsed)
+	}
+	if id, err := client.NetworkID(ctx); err != nil || id.Uint64() != okcServ.NetVersion() {
+		t.Errorf("network ID mismatch: have %v (%v), want %d", id, err, okcServ.NetVersion())
+	}
+}
+
+// Tests that account state and logs can be retrieved.
+func TestStateAccess(t *testing.T) {
+	stack, okcServ, client := newTestBackend(t)
+	defer stack.Stop()
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if balance, err := client.BalanceAt(ctx, okctest.Payee, big.NewInt(0)); err != nil || balance.Sign() != 0 {
+		t.Errorf("genesis balance mismatch: have %v (%v), want 0", balance, err)
+	}
+	want := new(big.Int).Mul(okctest.Payment, big.NewInt(2))
+	if balance, err := client.BalanceAt(ctx, okctest.Payee, nil); err != nil || balance.Cmp(want) != 0 {
+		t.Errorf("latest balance mismatch: have %v (%v), want %v", balance, err, want)
+	}
+	if nonce, err := client.NonceAt(ctx, okctest.Addr, nil); err != nil || nonce != 3 {
+		t.Errorf("nonce mismatch: have %d (%v), want 3", nonce, err)
+	}
+	contract := crypto.CreateAddress(okctest.Addr, 1)
+	if code, err := client.CodeAt(ctx, contract, nil); err != nil || len(code) != 0 {
+		t.Errorf("contract code mismatch: have %x (%v), want empty", code, err)
+	}
+	logs, err := client.FilterLogs(ctx, okcoin.FilterQuery{FromBlock: big.NewInt(0), Addresses: []common.Address{contract}})
+	if err != nil {
+		t.Fatalf("failed to filter logs: %v", err)
+	}
+	create := okcServ.BlockChain().GetBlockByNumber(2).Transactions()[0]
+	if len(logs) != 1 || logs[0].TxHash != create.Hash() || logs[0].BlockNumber != 2 {
+		t.Errorf("filtered logs mismatch: have %+v", logs)
+	}
+}
+
+// Tests that new chain heads are streamed to subscribers and that transactions
+// can be sent.
+func TestSubscriptionsAndSending(t *testing.T) {
+	stack, okcServ, client := newTestBackend(t)
+	defer stack.Stop()
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	heads := make(chan *types.Header)
+	sub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		t.Fatalf("failed to subscribe to new heads: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	chain := okcServ.BlockChain()
+	blocks, _ := core.GenerateChain(params.TestChainConfig, chain.CurrentBlock(), okcash.NewFaker(), okcServ.ChainDb(), 1, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import block: %v", err)
+	}
+	select {
+	case head := <-heads:
+		if head.Hash() != blocks[0].Hash() {
+			t.Errorf("new head mismatch: have %x, want %x", head.Hash(), blocks[0].Hash())
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-ctx.Done():
+		t.Fatalf("new head not delivered")
+	}
+	sub.Unsubscribe()
+
+	// Send a transfer and check that it is pending
+	nonce, err := client.PendingNonceAt(ctx, okctest.Addr)
+	if err != nil {
+		t.Fatalf("failed to retrieve pending nonce: %v", err)
+	}
+	tx, _ := types.SignTx(types.NewTransaction(nonce, okctest.Payee, okctest.Payment, params.TxGas, big.NewInt(params.Shannon), nil), types.HomesteadSigner{}, okctest.Key)
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	if _, pending, err := client.TransactionByHash(ctx, tx.Hash()); err != nil || !pending {
+		t.Errorf("sent transaction not pending: pending %v, err %v", pending, err)
+	}
+	if nonce, err := client.PendingNonceAt(ctx, okctest.Addr); err != nil || nonce != tx.Nonce()+1 {
+		t.Errorf("pending nonce mismatch: have %d (%v), want %d", nonce, err, tx.Nonce()+1)
+	}
+}
@@ -19,6 +19,9 @@ package params
 import (
 	"fmt"
 	"math/big"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/okcoin/go-okcoin/common"
 )
@@ -82,16 +85,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllOkcashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(OkcashConfig), nil}
+	AllOkcashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(OkcashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Okcoin core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(OkcashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(OkcashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -121,10 +124,117 @@ type ChainConfig struct {
 	// Various consensus engines
 	Okcash *OkcashConfig `json:"okcash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+	BFT    *BFTConfig    `json:"bft,omitempty"`
 }
 
 // OkcashConfig is the consensus engine configs for proof-of-work based sealing.
-type OkcashConfig struct{}
+// The base parameters apply from genesis and may be changed by scheduled forks.
+type OkcashConfig struct {
+	OkcashParams
+	Forks []*OkcashFork `json:"forks,omitempty"` // Scheduled parameter changes, ordered by block number
+}
+
+// OkcashParams are the reward and difficulty parameters of the okcash engine.
+// Parameters left unset fall back to the engine's built-in schedule.
+type OkcashParams struct {
+	BlockReward            *big.Int `json:"blockReward,omitempty"`            // Block reward in wei for successfully mining a block
+	UncleReward            *big.Int `json:"uncleReward,omitempty"`            // Uncle reward in wei, scaled by (8 - depth) / 8 (default = block reward)
+	InclusionReward        *big.Int `json:"inclusionReward,omitempty"`        // Reward in wei for including an uncle (default = block reward / 32)
+	BombDelay              *big.Int `json:"bombDelay,omitempty"`              // Number of blocks the difficulty bomb is delayed by
+	DifficultyBoundDivisor *big.Int `json:"difficultyBoundDivisor,omitempty"` // Bound divisor of the difficulty adjustment
+}
+
+// OkcashFork is a scheduled change of the okcash parameters. Only the parameters
+// set are changed, the others are inherited from the preceding forks.
+type OkcashFork struct {
+	Block *big.Int `json:"block"` // Block number the changes activate at
+	OkcashParams
+}
+
+// Params returns the okcash parameters in effect at the given block number.
+func (c *OkcashConfig) Params(num *big.Int) OkcashParams {
+	if c == nil {
+		return OkcashParams{}
+	}
+	params := c.OkcashParams
+	for _, fork := range c.Forks {
+		if !isForked(fork.Block, num) {
+			break
+		}
+		params.override(&fork.OkcashParams)
+	}
+	return params
+}
+
+// validate checks that the base parameters and the scheduled forks are sane.
+func (c *OkcashConfig) validate() error {
+	if err := c.OkcashParams.validate(); err != nil {
+		return err
+	}
+	var last *big.Int
+	for i, fork := range c.Forks {
+		if fork == nil || fork.Block == nil {
+			return fmt.Errorf("fork %d: missing block number", i)
+		}
+		if last != nil && fork.Block.Cmp(last) <= 0 {
+			return fmt.Errorf("fork %d: block %v not after preceding fork block %v", i, fork.Block, last)
+		}
+		if err := fork.OkcashParams.validate(); err != nil {
+			return fmt.Errorf("fork %d: %v", i, err)
+		}
+		last = fork.Block
+	}
+	return nil
+}
+
+// override replaces the parameters with the ones set in changes.
+func (p *OkcashParams) override(changes *OkcashParams) {
+	if changes.BlockReward != nil {
+		p.BlockReward = changes.BlockReward
+	}
+	if changes.UncleReward != nil {
+		p.UncleReward = changes.UncleReward
+	}
+	if changes.InclusionReward != nil {
+		p.InclusionReward = changes.InclusionReward
+	}
+	if changes.BombDelay != nil {
+		p.BombDelay = changes.BombDelay
+	}
+	if changes.DifficultyBoundDivisor != nil {
+		p.DifficultyBoundDivisor = changes.DifficultyBoundDivisor
+	}
+}
+
+// validate checks that the parameters set are within bounds.
+func (p *OkcashParams) validate() error {
+	for _, param := range []struct {
+		name  string
+		value *big.Int
+	}{
+		{"block reward", p.BlockReward},
+		{"uncle reward", p.UncleReward},
+		{"inclusion reward", p.InclusionReward},
+		{"bomb delay", p.BombDelay},
+	} {
+		if param.value != nil && param.value.Sign() < 0 {
+			return fmt.Errorf("negative %s %v", param.name, param.value)
+		}
+	}
+	if p.DifficultyBoundDivisor != nil && p.DifficultyBoundDivisor.Sign() <= 0 {
+		return fmt.Errorf("non-positive difficulty bound divisor %v", p.DifficultyBoundDivisor)
+	}
+	return nil
+}
+
+// equal returns whokcer two parameter sets are identical.
+func (p *OkcashParams) equal(q *OkcashParams) bool {
+	return configNumEqual(p.BlockReward, q.BlockReward) &&
+		configNumEqual(p.UncleReward, q.UncleReward) &&
+		configNumEqual(p.InclusionReward, q.InclusionReward) &&
+		configNumEqual(p.BombDelay, q.BombDelay) &&
+		configNumEqual(p.DifficultyBoundDivisor, q.DifficultyBoundDivisor)
+}
 
 // String implements the stringer interface, returning the consensus engine details.
 func (c *OkcashConfig) String() string {
@@ -142,6 +252,27 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// BFTConfig is the consensus engine configs for Byzantine fault tolerant sealing
+// with immediate finality.
+type BFTConfig struct {
+	Period         uint64 `json:"period"`         // Number of seconds between blocks to enforce
+	Epoch          uint64 `json:"epoch"`          // Epoch length to reset votes and checkpoint
+	RequestTimeout uint64 `json:"requestTimeout"` // Milliseconds to wait for a round to complete before changing it
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *BFTConfig) String() string {
+	return "bft"
+}
+
+// CheckpointOracleConfig represents a set of checkpoint oracle contract settings,
+// used by light clients to verify the checkpoints registered in the oracle.
+type CheckpointOracleConfig struct {
+	Address   common.Address   `json:"address"`   // Address of the deployed oracle contract
+	Signers   []common.Address `json:"signers"`   // Admins trusted to approve checkpoints
+	Threshold uint64           `json:"threshold"` // Number of admin signatures a checkpoint needs
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -150,6 +281,8 @@ func (c *ChainConfig) String() string {
 		engine = c.Okcash
 	case c.Clique != nil:
 		engine = c.Clique
+	case c.BFT != nil:
+		engine = c.BFT
 	default:
 		engine = "unknown"
 	}
@@ -167,6 +300,17 @@ func (c *ChainConfig) String() string {
 	)
 }
 
+// Validate checks the consensus parameters of the chain configuration, returning
+// an error if any of them is out of bounds.
+func (c *ChainConfig) Validate() error {
+	if c.Okcash != nil {
+		if err := c.Okcash.validate(); err != nil {
+			return fmt.Errorf("invalid okcash config: %v", err)
+		}
+	}
+	return nil
+}
+
 // IsHomestead returns whokcer num is either equal to the homestead block or greater.
 func (c *ChainConfig) IsHomestead(num *big.Int) bool {
 	return isForked(c.HomesteadBlock, num)
@@ -197,6 +341,43 @@ func (c *ChainConfig) IsConstantinople(num *big.Int) bool {
 	return isForked(c.ConstantinopleBlock, num)
 }
 
+// ForkBlocks returns the block numbers of all scheduled forks in ascending order,
+// without duplicates and without the ones active from genesis. Every *big.Int
+// field of the config named ...Block is a fork, as are the scheduled changes of
+// the consensus engine parameters.
+func (c *ChainConfig) ForkBlocks() []uint64 {
+	var blocks []*big.Int
+
+	conf := reflect.ValueOf(c).Elem()
+	for i := 0; i < conf.NumField(); i++ {
+		field := conf.Type().Field(i)
+		if !strings.HasSuffix(field.Name, "Block") || field.Type != reflect.TypeOf(new(big.Int)) {
+			continue
+		}
+		blocks = append(blocks, conf.Field(i).Interface().(*big.Int))
+	}
+	if c.Okcash != nil {
+		for _, fork := range c.Okcash.Forks {
+			blocks = append(blocks, fork.Block)
+		}
+	}
+	var forks []uint64
+	for _, block := range blocks {
+		if block != nil && block.Sign() > 0 {
+			forks = append(forks, block.Uint64())
+		}
+	}
+	sort.Slice(forks, func(i, j int) bool { return forks[i] < forks[j] })
+
+	for i := 1; i < len(forks); i++ {
+		if forks[i] == forks[i-1] {
+			forks = append(forks[:i], forks[i+1:]...)
+			i--
+		}
+	}
+	return forks
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -260,6 +441,34 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ConstantinopleBlock, newcfg.ConstantinopleBlock, head) {
 		return newCompatError("Constantinople fork block", c.ConstantinopleBlock, newcfg.ConstantinopleBlock)
 	}
+	if block := okcashParamsConflict(c.Okcash, newcfg.Okcash, head); block != nil {
+		return newCompatError("Okcash parameters", block, block)
+	}
+	return nil
+}
+
+// okcashParamsConflict returns the first block up to head at which the okcash
+// parameters of the two configurations differ, or nil if they are the same.
+func okcashParamsConflict(c1, c2 *OkcashConfig, head *big.Int) *big.Int {
+	blocks := []*big.Int{new(big.Int)}
+	for _, c := range []*OkcashConfig{c1, c2} {
+		if c == nil {
+			continue
+		}
+		for _, fork := range c.Forks {
+			blocks = append(blocks, fork.Block)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Cmp(blocks[j]) < 0 })
+
+	for _, block := range blocks {
+		if !isForked(block, head) {
+			break
+		}
+		if p1, p2 := c1.Params(block), c2.Params(block); !p1.equal(&p2) {
+			return block
+		}
+	}
 	return nil
 }
 
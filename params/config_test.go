@@ -59,6 +59,23 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     0,
 			},
 		},
+		{
+			stored:  &ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{{Block: big.NewInt(10), OkcashParams: OkcashParams{BlockReward: big.NewInt(1)}}}}},
+			new:     &ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{{Block: big.NewInt(20), OkcashParams: OkcashParams{BlockReward: big.NewInt(1)}}}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{{Block: big.NewInt(10), OkcashParams: OkcashParams{BlockReward: big.NewInt(1)}}}}},
+			new:    &ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{{Block: big.NewInt(20), OkcashParams: OkcashParams{BlockReward: big.NewInt(1)}}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Okcash parameters",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
 		{
 			stored: &ChainConfig{HomesteadBlock: big.NewInt(30), EIP150Block: big.NewInt(10)},
 			new:    &ChainConfig{HomesteadBlock: big.NewInt(25), EIP150Block: big.NewInt(20)},
@@ -79,3 +96,77 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestOkcashParams(t *testing.T) {
+	config := &OkcashConfig{
+		OkcashParams: OkcashParams{BlockReward: big.NewInt(3), BombDelay: big.NewInt(100)},
+		Forks: []*OkcashFork{
+			{Block: big.NewInt(10), OkcashParams: OkcashParams{BlockReward: big.NewInt(2)}},
+			{Block: big.NewInt(20), OkcashParams: OkcashParams{DifficultyBoundDivisor: big.NewInt(1024)}},
+		},
+	}
+	tests := []struct {
+		config *OkcashConfig
+		number int64
+		want   OkcashParams
+	}{
+		{nil, 0, OkcashParams{}},
+		{config, 9, OkcashParams{BlockReward: big.NewInt(3), BombDelay: big.NewInt(100)}},
+		{config, 10, OkcashParams{BlockReward: big.NewInt(2), BombDelay: big.NewInt(100)}},
+		{config, 25, OkcashParams{BlockReward: big.NewInt(2), BombDelay: big.NewInt(100), DifficultyBoundDivisor: big.NewInt(1024)}},
+	}
+	for i, tt := range tests {
+		if have := tt.config.Params(big.NewInt(tt.number)); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: params mismatch: have %+v, want %+v", i, have, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	fork := func(block int64, params OkcashParams) *OkcashFork {
+		return &OkcashFork{Block: big.NewInt(block), OkcashParams: params}
+	}
+	tests := []struct {
+		config *ChainConfig
+		valid  bool
+	}{
+		{AllOkcashProtocolChanges, true},
+		{AllCliqueProtocolChanges, true},
+		{&ChainConfig{Okcash: &OkcashConfig{OkcashParams: OkcashParams{BlockReward: big.NewInt(0), BombDelay: big.NewInt(0)}}}, true},
+		{&ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{fork(1, OkcashParams{}), fork(2, OkcashParams{})}}}, true},
+		{&ChainConfig{Okcash: &OkcashConfig{OkcashParams: OkcashParams{BlockReward: big.NewInt(-1)}}}, false},
+		{&ChainConfig{Okcash: &OkcashConfig{OkcashParams: OkcashParams{DifficultyBoundDivisor: big.NewInt(0)}}}, false},
+		{&ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{fork(1, OkcashParams{UncleReward: big.NewInt(-1)})}}}, false},
+		{&ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{fork(2, OkcashParams{}), fork(2, OkcashParams{})}}}, false},
+		{&ChainConfig{Okcash: &OkcashConfig{Forks: []*OkcashFork{{}}}}, false},
+	}
+	for i, tt := range tests {
+		if err := tt.config.Validate(); (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+	}
+}
+
+func TestForkBlocks(t *testing.T) {
+	tests := []struct {
+		config *ChainConfig
+		want   []uint64
+	}{
+		{AllOkcashProtocolChanges, nil},
+		{MainnetChainConfig, []uint64{10, 20, 30, 40, 50, 60}},
+		{RinkebyChainConfig, []uint64{1, 2, 3, 10}},
+		{
+			&ChainConfig{
+				HomesteadBlock: big.NewInt(5),
+				EIP150Block:    big.NewInt(5),
+				Okcash:         &OkcashConfig{Forks: []*OkcashFork{{Block: big.NewInt(7)}, {Block: big.NewInt(3)}}},
+			},
+			[]uint64{3, 5, 7},
+		},
+	}
+	for i, tt := range tests {
+		if have := tt.config.ForkBlocks(); !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: fork blocks mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
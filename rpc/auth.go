@@ -0,0 +1,227 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// minSecretLength is the minimum length of the shared secret JWT tokens are
+// signed with.
+const minSecretLength = 32
+
+var (
+	errInvalidCredentials = errors.New("invalid credentials")
+	errTokenNoExpiry      = errors.New("token without expiry")
+	errTokensDisabled     = errors.New("token authentication disabled")
+)
+
+// Policy is the access control policy of an RPC server, mapping the credentials
+// of the clients to their permissions. A permission is either a namespace
+// ("admin"), a single method ("admin_peers") or "*" for all of them.
+type Policy struct {
+	Anonymous []string            `json:"anonymous"` // Permissions of clients without credentials
+	Subjects  map[string][]string `json:"subjects"`  // Permissions of JWT tokens by subject, "*" for any subject not listed
+	APIKeys   map[string][]string `json:"apikeys"`   // Permissions of static API keys
+	Local     []string            `json:"local"`     // Permissions of local clients (IPC, in-process), which carry no credentials
+}
+
+// LoadPolicy reads an access control policy from a JSON file.
+func LoadPolicy(file string) (*Policy, error) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := new(Policy)
+	if err := json.Unmarshal(blob, policy); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %v", file, err)
+	}
+	return policy, nil
+}
+
+// LoadJWTSecret reads the hex encoded shared secret of JWT tokens from a file.
+func LoadJWTSecret(file string) ([]byte, error) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(blob)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret file %s: %v", file, err)
+	}
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("secret in %s too short: have %d bytes, want at least %d", file, len(secret), minSecretLength)
+	}
+	return secret, nil
+}
+
+// permissions is the set of namespaces and methods a client may call.
+type permissions map[string]bool
+
+func newPermissions(list []string) permissions {
+	perms := make(permissions)
+	for _, perm := range list {
+		perms[perm] = true
+	}
+	return perms
+}
+
+// allows reports whether the permissions include the given method.
+func (p permissions) allows(method string) bool {
+	if p["*"] || p[method] {
+		return true
+	}
+	if i := strings.Index(method, serviceMethodSeparator); i > 0 {
+		return p[method[:i]]
+	}
+	return false
+}
+
+// Authenticator verifies the credentials RPC clients present in the bearer
+// token of their Authorization header, which is either an HS256 signed JWT
+// token or a static API key, and grants them the permissions of the policy.
+type Authenticator struct {
+	secret    []byte
+	anonymous permissions
+	local     permissions
+	subjects  map[string]permissions
+	apikeys   map[string]permissions
+}
+
+// NewAuthenticator creates an authenticator verifying JWT tokens signed with the
+// given secret, which disables tokens if nil. A nil policy grants all permissions
+// to the holders of valid tokens and to local clients, and none to anyone else.
+// Local clients only get the permissions a policy explicitly grants them.
+func NewAuthenticator(secret []byte, policy *Policy) *Authenticator {
+	if policy == nil {
+		policy = &Policy{Subjects: map[string][]string{"*": {"*"}}, Local: []string{"*"}}
+	}
+	auth := &Authenticator{
+		secret:    secret,
+		anonymous: newPermissions(policy.Anonymous),
+		local:     newPermissions(policy.Local),
+		subjects:  make(map[string]permissions),
+		apikeys:   make(map[string]permissions),
+	}
+	for subject, list := range policy.Subjects {
+		auth.subjects[subject] = newPermissions(list)
+	}
+	for key, list := range policy.APIKeys {
+		auth.apikeys[key] = newPermissions(list)
+	}
+	return auth
+}
+
+// authenticate returns the permissions granted to the given Authorization header
+// value, the anonymous ones if empty.
+func (a *Authenticator) authenticate(header string) (permissions, error) {
+	if header == "" {
+		return a.anonymous, nil
+	}
+	if len(header) <= len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return nil, errInvalidCredentials
+	}
+	credentials := header[len("Bearer "):]
+
+	for key, perms := range a.apikeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(credentials)) == 1 {
+			return perms, nil
+		}
+	}
+	subject, err := a.verifyToken(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if perms, ok := a.subjects[subject]; ok {
+		return perms, nil
+	}
+	if perms, ok := a.subjects["*"]; ok {
+		return perms, nil
+	}
+	return make(permissions), nil
+}
+
+// verifyToken checks the signature and expiry of a JWT token, returning its subject.
+func (a *Authenticator) verifyToken(token string) (string, error) {
+	if a.secret == nil {
+		return "", errTokensDisabled
+	}
+	claims := new(jwt.StandardClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unsupported signing method %v", token.Header["alg"])
+		}
+		return a.secret, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("%v: %v", errInvalidCredentials, err)
+	}
+	if claims.ExpiresAt == 0 {
+		return "", errTokenNoExpiry
+	}
+	return claims.Subject, nil
+}
+
+// authorize checks that the credentials of the connection a request arrived on
+// permit calling the requested method, if the server restricts access. Methods
+// of the metadata service are available to all clients, while local connections
+// get the local permissions of the policy.
+func (s *Server) authorize(ctx context.Context, req *serverRequest) Error {
+	if s.auth == nil || req.svcname == MetadataApi {
+		return nil
+	}
+	var err error
+	if info := connInfoFromContext(ctx); info.local {
+		err = s.auth.authorizeLocal(req.fullName())
+	} else {
+		err = s.auth.authorize(info.credentials, req.fullName())
+	}
+	if err != nil {
+		return &accessDeniedError{err.Error()}
+	}
+	return nil
+}
+
+// authorizeLocal checks that the policy grants calling the method to local clients.
+func (a *Authenticator) authorizeLocal(method string) error {
+	if !a.local.allows(method) {
+		return fmt.Errorf("access to %s denied", method)
+	}
+	return nil
+}
+
+// authorize checks that the given Authorization header value grants calling
+// the method.
+func (a *Authenticator) authorize(header, method string) error {
+	perms, err := a.authenticate(header)
+	if err != nil {
+		return err
+	}
+	if !perms.allows(method) {
+		return fmt.Errorf("access to %s denied", method)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/net/websocket"
+)
+
+var testSecret = bytes.Repeat([]byte{0x42}, minSecretLength)
+
+func newTestToken(t *testing.T, secret []byte, subject string, expiry time.Duration) string {
+	claims := jwt.StandardClaims{Subject: subject}
+	if expiry != 0 {
+		claims.ExpiresAt = time.Now().Add(expiry).Unix()
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+func newTestAuthServer() *Server {
+	server := newTestServer("service", new(Service))
+	server.SetAuthenticator(NewAuthenticator(testSecret, &Policy{
+		Anonymous: []string{"service_noArgsRets"},
+		Subjects: map[string][]string{
+			"admin":   {"*"},
+			"monitor": {"service_echo"},
+		},
+		APIKeys: map[string][]string{"apikey": {"service"}},
+		Local:   []string{"service_rets"},
+	}))
+	return server
+}
+
+// Tests that the methods callable over HTTP are the ones the policy grants to
+// the credentials of the request.
+func TestAuthHTTP(t *testing.T) {
+	server := newTestAuthServer()
+	defer server.Stop()
+
+	tests := []struct {
+		credentials string
+		method      string
+		allowed     bool
+	}{
+		{"", "service_noArgsRets", true},
+		{"", "service_echo", false},
+		{"Bearer apikey", "service_echo", true},
+		{"Bearer apikey2", "service_echo", false},
+		{"Bearer " + newTestToken(t, testSecret, "monitor", time.Hour), "service_echo", true},
+		{"Bearer " + newTestToken(t, testSecret, "monitor", time.Hour), "service_rets", false},
+		{"Bearer " + newTestToken(t, testSecret, "admin", time.Hour), "service_rets", true},
+		{"Bearer " + newTestToken(t, testSecret, "nobody", time.Hour), "service_echo", false},
+		{"Bearer " + newTestToken(t, testSecret, "admin", -time.Hour), "service_rets", false},
+		{"Bearer " + newTestToken(t, testSecret, "admin", 0), "service_rets", false},
+		{"Bearer " + newTestToken(t, bytes.Repeat([]byte{0x01}, minSecretLength), "admin", time.Hour), "service_rets", false},
+		{"Basic apikey", "service_echo", false},
+		{"", "rpc_modules", true},
+	}
+	for i, tt := range tests {
+		client, hs := httpTestClient(server, "http", nil)
+		if tt.credentials != "" {
+			client.SetHeader("Authorization", tt.credentials)
+		}
+		var args []interface{}
+		if tt.method == "service_echo" {
+			args = []interface{}{"hello", 1, &Args{"world"}}
+		}
+		err := client.Call(nil, tt.method, args...)
+		switch {
+		case tt.allowed && err != nil:
+			t.Errorf("test %d: %s denied: %v", i, tt.method, err)
+		case !tt.allowed && err == nil:
+			t.Errorf("test %d: %s allowed", i, tt.method)
+		case !tt.allowed && err.(Error).ErrorCode() != new(accessDeniedError).ErrorCode():
+			t.Errorf("test %d: error code mismatch: have %d, want %d", i, err.(Error).ErrorCode(), new(accessDeniedError).ErrorCode())
+		}
+		client.Close()
+		hs.Close()
+	}
+}
+
+// Tests that websocket connections keep the permissions of the credentials they
+// were opened with, subscriptions included.
+func TestAuthWebsocket(t *testing.T) {
+	server := newTestAuthServer()
+	defer server.Stop()
+
+	hs := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer hs.Close()
+
+	dial := func(credentials string) *Client {
+		config, err := websocket.NewConfig("ws://"+hs.Listener.Addr().String(), "http://localhost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if credentials != "" {
+			config.Header.Set("Authorization", credentials)
+		}
+		client, err := newClient(context.Background(), func(context.Context) (net.Conn, error) {
+			return websocket.DialConfig(config)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	anonymous := dial("")
+	defer anonymous.Close()
+
+	if err := anonymous.Call(nil, "service_noArgsRets"); err != nil {
+		t.Errorf("anonymous call denied: %v", err)
+	}
+	if err := anonymous.Call(nil, "service_subscribe", "subscription"); err == nil {
+		t.Errorf("anonymous subscription allowed")
+	}
+	admin := dial("Bearer " + newTestToken(t, testSecret, "admin", time.Hour))
+	defer admin.Close()
+
+	if err := admin.Call(nil, "service_rets"); err != nil {
+		t.Errorf("authenticated call denied: %v", err)
+	}
+}
+
+// Tests that local connections get the local permissions of the policy, all of
+// them only if no policy is set.
+func TestAuthLocal(t *testing.T) {
+	server := newTestAuthServer()
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "service_rets"); err != nil {
+		t.Errorf("granted local call denied: %v", err)
+	}
+	if err := client.Call(nil, "service_noArgsRets"); err == nil {
+		t.Errorf("local call not granted by the policy allowed")
+	}
+	// Without a policy local clients may call everything
+	open := newTestServer("service", new(Service))
+	defer open.Stop()
+	open.SetAuthenticator(NewAuthenticator(testSecret, nil))
+
+	client = DialInProc(open)
+	defer client.Close()
+
+	if err := client.Call(nil, "service_noArgsRets"); err != nil {
+		t.Errorf("local call without policy denied: %v", err)
+	}
+}
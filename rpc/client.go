@@ -109,6 +109,7 @@ type Client struct {
 	idCounter   uint32
 	connectFunc func(ctx context.Context) (net.Conn, error)
 	isHTTP      bool
+	redialCfg   *ReconnectConfig // redial policy, nil unless created by DialReconnecting
 
 	// writeConn is only safe to access outside dispatch, with the
 	// write lock held. The write lock is taken by sending on
@@ -125,6 +126,7 @@ type Client struct {
 	sendDone    chan error                     // signals write completion, releases write lock
 	respWait    map[string]*requestOp          // active requests
 	subs        map[string]*ClientSubscription // active subscriptions
+	suspended   []*ClientSubscription          // subscriptions awaiting a new connection
 }
 
 type requestOp struct {
@@ -132,6 +134,8 @@ type requestOp struct {
 	err  error
 	resp chan *jsonrpcMessage // receives up to len(ids) responses
 	sub  *ClientSubscription  // only set for OkcSubscribe requests
+
+	resubscribe bool // set if sub is resumed on a new connection
 }
 
 func (op *requestOp) wait(ctx context.Context) (*jsonrpcMessage, error) {
@@ -152,7 +156,8 @@ func (op *requestOp) wait(ctx context.Context) (*jsonrpcMessage, error) {
 //
 // For websocket connections, the origin is set to the local host name.
 //
-// The client reconnects automatically if the connection is lost.
+// The client reconnects automatically if the connection is lost. Use DialReconnecting
+// for a client that also resumes its subscriptions.
 func Dial(rawurl string) (*Client, error) {
 	return DialContext(context.Background(), rawurl)
 }
@@ -179,6 +184,12 @@ func DialContext(ctx context.Context, rawurl string) (*Client, error) {
 }
 
 func newClient(initctx context.Context, connectFunc func(context.Context) (net.Conn, error)) (*Client, error) {
+	return newReconnectingClient(initctx, connectFunc, nil)
+}
+
+// newReconnectingClient creates a client. If config is non-nil, the client
+// redials with backoff when its connection is lost and resumes its subscriptions.
+func newReconnectingClient(initctx context.Context, connectFunc func(context.Context) (net.Conn, error), config *ReconnectConfig) (*Client, error) {
 	conn, err := connectFunc(initctx)
 	if err != nil {
 		return nil, err
@@ -189,6 +200,7 @@ func newClient(initctx context.Context, connectFunc func(context.Context) (net.C
 		writeConn:   conn,
 		isHTTP:      isHTTP,
 		connectFunc: connectFunc,
+		redialCfg:   config,
 		close:       make(chan struct{}),
 		didQuit:     make(chan struct{}),
 		reconnected: make(chan net.Conn),
@@ -199,6 +211,9 @@ func newClient(initctx context.Context, connectFunc func(context.Context) (net.C
 		respWait:    make(map[string]*requestOp),
 		subs:        make(map[string]*ClientSubscription),
 	}
+	if config != nil {
+		c.connectFunc = c.backoffDialer(connectFunc)
+	}
 	if !isHTTP {
 		go c.dispatch(conn)
 	}
@@ -393,6 +408,7 @@ func (c *Client) Subscribe(ctx context.Context, namespace string, channel interf
 		resp: make(chan *jsonrpcMessage),
 		sub:  newClientSubscription(c, namespace, chanVal),
 	}
+	op.sub.params = msg.Params
 
 	// Send the subscription request.
 	// The arrival and validity of the response is signaled on sub.quit.
@@ -483,6 +499,9 @@ func (c *Client) dispatch(conn net.Conn) {
 	defer close(c.didQuit)
 	defer func() {
 		c.closeRequestOps(ErrClientQuit)
+		for _, sub := range c.suspended {
+			sub.quitWithError(ErrClientQuit, false)
+		}
 		conn.Close()
 		if reading {
 			// Empty read channels until read is dead.
@@ -525,6 +544,11 @@ func (c *Client) dispatch(conn net.Conn) {
 
 		case err := <-c.readErr:
 			log.Debug(fmt.Sprintf("<-readErr: %v", err))
+			if c.redialCfg != nil {
+				// Keep the subscriptions for resuming them on a new connection.
+				c.suspendSubscriptions(err)
+				go c.redial(conn)
+			}
 			c.closeRequestOps(err)
 			conn.Close()
 			reading = false
@@ -539,6 +563,10 @@ func (c *Client) dispatch(conn net.Conn) {
 			go c.read(newconn)
 			reading = true
 			conn = newconn
+			if len(c.suspended) > 0 {
+				go c.resubscribe(c.suspended)
+				c.suspended = nil
+			}
 
 		// Send path.
 		case op := <-requestOpLock:
@@ -621,11 +649,23 @@ func (c *Client) handleResponse(msg *jsonrpcMessage) {
 	defer close(op.resp)
 	if msg.Error != nil {
 		op.err = msg.Error
-		return
+	} else {
+		var subid string
+		if op.err = json.Unmarshal(msg.Result, &subid); op.err == nil {
+			op.sub.setID(subid)
+			c.subs[subid] = op.sub
+		}
 	}
-	if op.err = json.Unmarshal(msg.Result, &op.sub.subid); op.err == nil {
-		go op.sub.start()
-		c.subs[op.sub.subid] = op.sub
+	switch {
+	case !op.resubscribe:
+		if op.err == nil {
+			go op.sub.start()
+		}
+	case op.err != nil:
+		// The server refused to resume the subscription, end it.
+		op.sub.quitWithError(op.err, false)
+	default:
+		op.sub.resumed()
 	}
 }
 
@@ -668,13 +708,20 @@ type ClientSubscription struct {
 	etype     reflect.Type
 	channel   reflect.Value
 	namespace string
-	subid     string
+	params    json.RawMessage // arguments of the subscribe call, for resuming it
 	in        chan json.RawMessage
 
+	idLock sync.Mutex
+	subid  string // server side ID, empty while the subscription is suspended
+
 	quitOnce sync.Once     // ensures quit is closed once
 	quit     chan struct{} // quit is closed when the subscription exits
 	errOnce  sync.Once     // ensures err is closed once
 	err      chan error
+
+	gaps    chan SubscriptionGap // reports resumptions after connection losses
+	lost    time.Time            // when the connection was lost, zero unless suspended
+	lostErr error                // the error that broke the connection
 }
 
 func newClientSubscription(c *Client, namespace string, channel reflect.Value) *ClientSubscription {
@@ -686,6 +733,7 @@ func newClientSubscription(c *Client, namespace string, channel reflect.Value) *
 		quit:      make(chan struct{}),
 		err:       make(chan error, 1),
 		in:        make(chan json.RawMessage),
+		gaps:      make(chan SubscriptionGap, 1),
 	}
 	return sub
 }
@@ -698,10 +746,23 @@ func newClientSubscription(c *Client, namespace string, channel reflect.Value) *
 // on the underlying client and no other error has occurred.
 //
 // The error channel is closed when Unsubscribe is called on the subscription.
+//
+// Subscriptions of clients created by DialReconnecting don't end when the
+// connection is lost, they are resumed on the new connection instead and
+// report the interruption through Gaps.
 func (sub *ClientSubscription) Err() <-chan error {
 	return sub.err
 }
 
+// Gaps returns the channel reporting the interruptions of the subscription by
+// connection losses, which only occur on clients created by DialReconnecting.
+// Notifications sent by the server during an interruption are missed, so
+// consumers should fill in the gap, like headers of new chain heads, by other
+// means. A gap not received before the next one is merged into it.
+func (sub *ClientSubscription) Gaps() <-chan SubscriptionGap {
+	return sub.gaps
+}
+
 // Unsubscribe unsubscribes the notification and closes the error channel.
 // It can safely be called more than once.
 func (sub *ClientSubscription) Unsubscribe() {
@@ -785,7 +846,23 @@ func (sub *ClientSubscription) unmarshal(result json.RawMessage) (interface{}, e
 	return val.Elem().Interface(), err
 }
 
+func (sub *ClientSubscription) setID(subid string) {
+	sub.idLock.Lock()
+	sub.subid = subid
+	sub.idLock.Unlock()
+}
+
+func (sub *ClientSubscription) id() string {
+	sub.idLock.Lock()
+	defer sub.idLock.Unlock()
+	return sub.subid
+}
+
 func (sub *ClientSubscription) requestUnsubscribe() error {
+	subid := sub.id()
+	if subid == "" {
+		return nil // Suspended, nothing to cancel on the server
+	}
 	var result interface{}
-	return sub.client.Call(&result, sub.namespace+unsubscribeMethodSuffix, sub.subid)
+	return sub.client.Call(&result, sub.namespace+unsubscribeMethodSuffix, subid)
 }
@@ -16,7 +16,10 @@
 
 package rpc
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // request is for an unknown service
 type methodNotFoundError struct {
@@ -58,6 +61,48 @@ func (e *callbackError) ErrorCode() int { return -32000 }
 
 func (e *callbackError) Error() string { return e.message }
 
+// request isn't permitted by the access policy of the server
+type accessDeniedError struct{ message string }
+
+func (e *accessDeniedError) ErrorCode() int { return -32001 }
+
+func (e *accessDeniedError) Error() string { return e.message }
+
+// request exceeds the budget of the client
+type rateLimitedError struct{ wait time.Duration }
+
+func (e *rateLimitedError) ErrorCode() int { return -32005 }
+
+func (e *rateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry in %v", e.wait)
+}
+
+// request message or batch exceeds the limits of the server
+type requestTooLargeError struct{ message string }
+
+func (e *requestTooLargeError) ErrorCode() int { return -32600 }
+
+func (e *requestTooLargeError) Error() string { return e.message }
+
+// response exceeds the limits of the server
+type responseTooLargeError struct{ message string }
+
+func (e *responseTooLargeError) ErrorCode() int { return -32000 }
+
+func (e *responseTooLargeError) Error() string { return e.message }
+
+// request didn't complete within the deadline of the server
+type timeoutError struct {
+	timeout time.Duration
+	message string
+}
+
+func (e *timeoutError) ErrorCode() int { return -32002 }
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("request timed out after %v: %s", e.timeout, e.message)
+}
+
 // issued when a request is received after the server is issued to stop.
 type shutdownError struct{}
 
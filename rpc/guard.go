@@ -0,0 +1,82 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAccessDenied is returned by Guard.Check for calls the access control
+	// policy doesn't grant to the client.
+	ErrAccessDenied = errors.New("access denied")
+
+	// ErrRateLimited is returned by Guard.Check for calls exceeding the budget
+	// of the client.
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// Guard carries the access control, rate limiting, size limits and call deadline
+// of the HTTP and websocket endpoints over to endpoints serving other protocols,
+// such as GraphQL or gRPC, so they don't bypass them. Its methods are named like
+// the JSON-RPC ones, namespace first (e.g. "graphql_query"), to be granted and
+// priced by the same policy. The zero guard permits everything.
+type Guard struct {
+	Auth    *Authenticator // Access control, nil if unrestricted
+	Limiter *RateLimiter   // Budgets of the clients, nil if unlimited
+	Limits  Limits         // Size limits of requests and responses
+	Timeout time.Duration  // Deadline of the calls, zero if unlimited
+}
+
+// Check authorizes a call of a method by the client presenting the given
+// Authorization header value from the given network address, and charges its
+// cost to the budget of the client. If the call is rejected for exceeding the
+// budget, the time until the budget is replenished enough is returned too.
+func (g *Guard) Check(credentials, remoteAddr, method string) (time.Duration, error) {
+	if g.Auth != nil {
+		if err := g.Auth.authorize(credentials, method); err != nil {
+			return 0, ErrAccessDenied
+		}
+	}
+	return g.Charge(credentials, remoteAddr, method)
+}
+
+// Charge charges the cost of a method to the budget of a client without
+// authorizing it, for the work a call checked before goes on to do, such as the
+// block ranges resolved by a GraphQL query. If the budget is insufficient the
+// time until it is replenished enough is returned.
+func (g *Guard) Charge(credentials, remoteAddr, method string) (time.Duration, error) {
+	if g.Limiter != nil {
+		if client := clientID(g.Auth != nil, credentials, remoteAddr); client != "" {
+			if wait, ok := g.Limiter.charge(client, method); !ok {
+				return wait, ErrRateLimited
+			}
+		}
+	}
+	return 0, nil
+}
+
+// WithTimeout derives the context of a call from the one of its request, with
+// the deadline of the guard if one is set.
+func (g *Guard) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if g.Timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, g.Timeout)
+}
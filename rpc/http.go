@@ -44,6 +44,7 @@ var nullAddr, _ = net.ResolveTCPAddr("tcp", "127.0.0.1:0")
 type httpConn struct {
 	client    *http.Client
 	req       *http.Request
+	reqMu     sync.Mutex // Protects the headers of req
 	closeOnce sync.Once
 	closed    chan struct{}
 }
@@ -87,6 +88,19 @@ func DialHTTP(endpoint string) (*Client, error) {
 	return DialHTTPWithClient(endpoint, new(http.Client))
 }
 
+// SetHeader sets a custom HTTP header sent with the client's requests, such as
+// the Authorization one. It has no effect on clients of other transports.
+func (c *Client) SetHeader(key, value string) {
+	if !c.isHTTP {
+		return
+	}
+	hc := c.writeConn.(*httpConn)
+
+	hc.reqMu.Lock()
+	defer hc.reqMu.Unlock()
+	hc.req.Header.Set(key, value)
+}
+
 func (c *Client) sendHTTP(ctx context.Context, op *requestOp, msg interface{}) error {
 	hc := c.writeConn.(*httpConn)
 	respBody, err := hc.doRequest(ctx, msg)
@@ -124,7 +138,14 @@ func (hc *httpConn) doRequest(ctx context.Context, msg interface{}) (io.ReadClos
 	if err != nil {
 		return nil, err
 	}
+	hc.reqMu.Lock()
 	req := hc.req.WithContext(ctx)
+	req.Header = make(http.Header, len(hc.req.Header))
+	for key, values := range hc.req.Header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	hc.reqMu.Unlock()
+
 	req.Body = ioutil.NopCloser(bytes.NewReader(body))
 	req.ContentLength = int64(len(body))
 
@@ -150,10 +171,22 @@ func (t *httpReadWriteNopCloser) Close() error {
 //
 // Deprecated: Server implements http.Handler
 func NewHTTPServer(cors []string, vhosts []string, srv *Server) *http.Server {
+	return &http.Server{Handler: NewHTTPHandler(cors, vhosts, srv)}
+}
+
+// NewHTTPHandler returns a handler that serves JSON-RPC requests over HTTP,
+// enforcing the given CORS and virtual host restrictions.
+func NewHTTPHandler(cors []string, vhosts []string, srv *Server) http.Handler {
+	return NewHTTPHandlerStack(srv, cors, vhosts)
+}
+
+// NewHTTPHandlerStack wraps an arbitrary HTTP handler into the CORS and virtual
+// host checks of the RPC HTTP endpoint, so that other services exposed over HTTP
+// can enforce the same access rules.
+func NewHTTPHandlerStack(srv http.Handler, cors []string, vhosts []string) http.Handler {
 	// Wrap the CORS-handler within a host-handler
 	handler := newCorsHandler(srv, cors)
-	handler = newVHostHandler(vhosts, handler)
-	return &http.Server{Handler: handler}
+	return newVHostHandler(vhosts, handler)
 }
 
 // ServeHTTP serves JSON-RPC requests over HTTP.
@@ -162,10 +195,14 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
 		return
 	}
-	if code, err := validateRequest(r); err != nil {
+	if code, err := validateRequest(r, srv.limits.MaxRequestSize); err != nil {
 		http.Error(w, err.Error(), code)
 		return
 	}
+	if srv.limits.MaxRequestSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(srv.limits.MaxRequestSize))
+	}
+
 	// All checks passed, create a codec that reads direct from the request body
 	// untilEOF and writes the response to w and order the server to process a
 	// single request.
@@ -173,17 +210,54 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer codec.Close()
 
 	w.Header().Set("content-type", contentType)
-	srv.ServeSingleRequest(codec, OptionMethodInvocation)
+	// The request context is canceled if the client goes away, aborting the call
+	srv.serveRequest(contextWithConnInfo(r.Context(), r, w.Header()), codec, true, OptionMethodInvocation)
+}
+
+// connInfo describes the client connection a request arrived on.
+type connInfo struct {
+	remoteAddr  string      // Network address of the client, empty if unknown
+	credentials string      // Authorization header value of the client, if any
+	respHeader  http.Header // Headers of the HTTP response, nil if not applicable
+	local       bool        // Whether the client is local (IPC or in-process), getting the local permissions
+}
+
+// connInfoKey is the context key of the connection info of the request being served.
+type connInfoKey struct{}
+
+// contextWithConnInfo returns a copy of the context holding the description of
+// the connection the given HTTP request arrived on.
+func contextWithConnInfo(ctx context.Context, r *http.Request, respHeader http.Header) context.Context {
+	return context.WithValue(ctx, connInfoKey{}, &connInfo{
+		remoteAddr:  r.RemoteAddr,
+		credentials: r.Header.Get("Authorization"),
+		respHeader:  respHeader,
+	})
+}
+
+// contextWithLocalConn returns a copy of the context marking the connection its
+// requests arrive on as a local one.
+func contextWithLocalConn(ctx context.Context) context.Context {
+	return context.WithValue(ctx, connInfoKey{}, &connInfo{local: true})
+}
+
+// connInfoFromContext returns the description of the connection a request arrived
+// on, an empty one if the context doesn't describe any.
+func connInfoFromContext(ctx context.Context) *connInfo {
+	if info, ok := ctx.Value(connInfoKey{}).(*connInfo); ok {
+		return info
+	}
+	return new(connInfo)
 }
 
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
-func validateRequest(r *http.Request) (int, error) {
+func validateRequest(r *http.Request, maxContentLength int) (int, error) {
 	if r.Method == http.MethodPut || r.Method == http.MethodDelete {
 		return http.StatusMethodNotAllowed, errors.New("method not allowed")
 	}
-	if r.ContentLength > maxRequestContentLength {
-		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, maxRequestContentLength)
+	if maxContentLength > 0 && r.ContentLength > int64(maxContentLength) {
+		err := fmt.Errorf("content length too large (%d>%d)", r.ContentLength, maxContentLength)
 		return http.StatusRequestEntityTooLarge, err
 	}
 	mt, _, err := mime.ParseMediaType(r.Header.Get("content-type"))
@@ -194,7 +268,7 @@ func validateRequest(r *http.Request) (int, error) {
 	return 0, nil
 }
 
-func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
+func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	// disable CORS support if user has not specified a custom CORS configuration
 	if len(allowedOrigins) == 0 {
 		return srv
@@ -19,6 +19,7 @@ package rpc
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
@@ -37,6 +38,8 @@ const (
 	notificationMethodSuffix = "_subscription"
 )
 
+var errMessageTooLarge = errors.New("message too large")
+
 type jsonRequest struct {
 	Method  string          `json:"method"`
 	Version string          `json:"jsonrpc"`
@@ -83,6 +86,38 @@ type jsonCodec struct {
 	encMu  sync.Mutex                // guards the encoder
 	encode func(v interface{}) error // encoder to allow multiple transports
 	rw     io.ReadWriteCloser        // connection
+
+	maxMessageSize int            // maximum size of a request message, zero if unlimited
+	reader         *messageReader // bounds the stream read while decoding, nil for custom decoders
+}
+
+// messageReader limits the number of bytes read from a stream while decoding a
+// single message, failing the read once the budget of the message is used up so
+// oversize messages are never buffered in full.
+type messageReader struct {
+	r      io.Reader
+	limit  int // maximum size of a message, zero if unlimited
+	remain int // bytes left to read for the message being decoded
+}
+
+// reset renews the budget before decoding the next message.
+func (r *messageReader) reset() {
+	r.remain = r.limit
+}
+
+func (r *messageReader) Read(p []byte) (int, error) {
+	if r.limit == 0 {
+		return r.r.Read(p)
+	}
+	if r.remain <= 0 {
+		return 0, errMessageTooLarge
+	}
+	if len(p) > r.remain {
+		p = p[:r.remain]
+	}
+	n, err := r.r.Read(p)
+	r.remain -= n
+	return n, err
 }
 
 func (err *jsonError) Error() string {
@@ -109,8 +144,9 @@ func NewCodec(rwc io.ReadWriteCloser, encode, decode func(v interface{}) error)
 
 // NewJSONCodec creates a new RPC server codec with support for JSON-RPC 2.0.
 func NewJSONCodec(rwc io.ReadWriteCloser) ServerCodec {
+	reader := &messageReader{r: rwc}
 	enc := json.NewEncoder(rwc)
-	dec := json.NewDecoder(rwc)
+	dec := json.NewDecoder(reader)
 	dec.UseNumber()
 
 	return &jsonCodec{
@@ -118,6 +154,20 @@ func NewJSONCodec(rwc io.ReadWriteCloser) ServerCodec {
 		encode: enc.Encode,
 		decode: dec.Decode,
 		rw:     rwc,
+		reader: reader,
+	}
+}
+
+// setMaxMessageSize sets the maximum size of the request messages. Streams stop
+// being read once a message exceeds it, the size of the messages of custom
+// decoders is checked once they are read.
+func (c *jsonCodec) setMaxMessageSize(size int) {
+	c.decMu.Lock()
+	defer c.decMu.Unlock()
+
+	c.maxMessageSize = size
+	if c.reader != nil {
+		c.reader.limit = size
 	}
 }
 
@@ -140,10 +190,20 @@ func (c *jsonCodec) ReadRequestHeaders() ([]rpcRequest, bool, Error) {
 	c.decMu.Lock()
 	defer c.decMu.Unlock()
 
+	if c.reader != nil {
+		c.reader.reset()
+	}
 	var incomingMsg json.RawMessage
 	if err := c.decode(&incomingMsg); err != nil {
+		if err == errMessageTooLarge {
+			// The rest of the message is left unread, the stream can't be resumed
+			return nil, false, &invalidRequestError{fmt.Sprintf("request too large (more than %d bytes)", c.maxMessageSize)}
+		}
 		return nil, false, &invalidRequestError{err.Error()}
 	}
+	if c.maxMessageSize > 0 && len(incomingMsg) > c.maxMessageSize {
+		return nil, false, &requestTooLargeError{fmt.Sprintf("request too large (%d > %d bytes)", len(incomingMsg), c.maxMessageSize)}
+	}
 	if isBatch(incomingMsg) {
 		return parseBatchRequest(incomingMsg)
 	}
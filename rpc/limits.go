@@ -0,0 +1,67 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Limits bound the size of the requests and responses of an RPC server. They
+// apply to all transports alike, zero fields leaving the respective size unlimited.
+type Limits struct {
+	MaxRequestSize  int // Maximum size of a request message in bytes
+	MaxBatchItems   int // Maximum number of calls in a batch request
+	MaxResponseSize int // Maximum size of a response message in bytes
+}
+
+// DefaultLimits are the limits recommended for servers exposed over the network.
+// New servers are unlimited, as suits local IPC and in-process connections.
+var DefaultLimits = Limits{
+	MaxRequestSize:  maxRequestContentLength,
+	MaxBatchItems:   1000,
+	MaxResponseSize: 25 * 1024 * 1024,
+}
+
+// SetLimits sets the size limits of the server, zero fields disabling the
+// respective limit. It must be set before serving requests.
+func (s *Server) SetLimits(limits Limits) {
+	s.limits = limits
+}
+
+// limitResponses encodes the responses to a batch, or a single request, replacing
+// the one the maximum response size is exceeded at and all after it by errors.
+// The returned responses are written verbatim by the codec.
+func (s *Server) limitResponses(codec ServerCodec, reqs []*serverRequest, resps []interface{}) []interface{} {
+	if s.limits.MaxResponseSize == 0 {
+		return resps
+	}
+	size := 0
+	for i, resp := range resps {
+		blob, err := json.Marshal(resp)
+		if err != nil {
+			continue // Leave it to the codec to report
+		}
+		if size += len(blob); size > s.limits.MaxResponseSize {
+			err := &responseTooLargeError{fmt.Sprintf("response too large (more than %d bytes)", s.limits.MaxResponseSize)}
+			resps[i] = codec.CreateErrorResponse(&reqs[i].id, err)
+			continue
+		}
+		resps[i] = json.RawMessage(blob)
+	}
+	return resps
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// limitTestConn is a raw stream connection to a server with custom limits.
+type limitTestConn struct {
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+func newLimitTestConn(t *testing.T, limits Limits) (*limitTestConn, func()) {
+	server := newTestServer("service", new(Service))
+	server.SetLimits(limits)
+
+	p1, p2 := net.Pipe()
+	go server.ServeCodec(NewJSONCodec(p1), OptionMethodInvocation|OptionSubscriptions)
+
+	return &limitTestConn{conn: p2, dec: json.NewDecoder(p2)}, func() {
+		p2.Close()
+		server.Stop()
+	}
+}
+
+// roundtrip sends a raw request message and decodes the response into result.
+func (c *limitTestConn) roundtrip(t *testing.T, request string, result interface{}) {
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := c.conn.Write([]byte(request)); err != nil {
+		t.Fatalf("failed to send request: %v", err)
+	}
+	if err := c.dec.Decode(result); err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+}
+
+func echoRequest(id int, str string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"service_echo","params":["%s",1,{"S":"x"}]}`, id, str)
+}
+
+// Tests that oversize batches are rejected up front, without dropping the
+// connection.
+func TestLimitRequests(t *testing.T) {
+	conn, closer := newLimitTestConn(t, Limits{MaxRequestSize: 300, MaxBatchItems: 2})
+	defer closer()
+
+	var resp jsonErrResponse
+	var resps []jsonErrResponse
+	conn.roundtrip(t, "["+echoRequest(2, "a")+","+echoRequest(3, "b")+"]", &resps)
+	if len(resps) != 2 || resps[0].Error.Code != 0 || resps[1].Error.Code != 0 {
+		t.Errorf("batch within limits rejected: %+v", resps)
+	}
+	resp = jsonErrResponse{}
+	conn.roundtrip(t, "["+echoRequest(4, "a")+","+echoRequest(5, "b")+","+echoRequest(6, "c")+"]", &resp)
+	if resp.Error.Code != new(requestTooLargeError).ErrorCode() || !strings.Contains(resp.Error.Message, "batch too large") {
+		t.Errorf("oversize batch not rejected: %+v", resp.Error)
+	}
+	var result jsonSuccessResponse
+	conn.roundtrip(t, echoRequest(7, "a"), &result)
+	if result.Result == nil {
+		t.Errorf("request after rejections failed")
+	}
+}
+
+// Tests that streams stop being read once a request exceeds the maximum size,
+// rejecting it and closing the connection instead of buffering it in full.
+func TestLimitRequestStream(t *testing.T) {
+	conn, closer := newLimitTestConn(t, Limits{MaxRequestSize: 300})
+	defer closer()
+
+	// The server doesn't read the request to its end, send it in the background
+	conn.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	go conn.conn.Write([]byte(echoRequest(1, strings.Repeat("a", 100000))))
+
+	var resp jsonErrResponse
+	if err := conn.dec.Decode(&resp); err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if resp.Error.Code != new(requestTooLargeError).ErrorCode() || !strings.Contains(resp.Error.Message, "request too large") {
+		t.Errorf("oversize request not rejected: %+v", resp.Error)
+	}
+	if err := conn.dec.Decode(&resp); err == nil {
+		t.Errorf("connection kept open after oversize request")
+	}
+}
+
+// Tests that servers without configured limits accept arbitrarily large messages,
+// as needed by local IPC and in-process clients.
+func TestLimitDefaultUnlimited(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+
+	p1, p2 := net.Pipe()
+	go server.ServeCodec(NewJSONCodec(p1), OptionMethodInvocation)
+	conn := &limitTestConn{conn: p2, dec: json.NewDecoder(p2)}
+	defer p2.Close()
+
+	var result jsonSuccessResponse
+	conn.roundtrip(t, echoRequest(1, strings.Repeat("a", 2*DefaultLimits.MaxRequestSize)), &result)
+	if result.Result == nil {
+		t.Errorf("large request rejected")
+	}
+}
+
+// Tests that oversize responses are replaced by errors, in batches from the
+// first one exceeding the limit on.
+func TestLimitResponses(t *testing.T) {
+	conn, closer := newLimitTestConn(t, Limits{MaxResponseSize: 200})
+	defer closer()
+
+	var resp jsonErrResponse
+	conn.roundtrip(t, echoRequest(1, strings.Repeat("a", 200)), &resp)
+	if resp.Error.Code != new(responseTooLargeError).ErrorCode() || !strings.Contains(resp.Error.Message, "response too large") {
+		t.Errorf("oversize response not replaced: %+v", resp.Error)
+	}
+	var resps []jsonErrResponse
+	conn.roundtrip(t, "["+echoRequest(2, "a")+","+echoRequest(3, strings.Repeat("b", 100))+","+echoRequest(4, "c")+"]", &resps)
+	if len(resps) != 3 {
+		t.Fatalf("response count mismatch: have %d, want 3", len(resps))
+	}
+	for i, want := range []bool{false, true, true} {
+		if failed := resps[i].Error.Code != 0; failed != want {
+			t.Errorf("response %d: failure mismatch: have %v, want %v", i, failed, want)
+		}
+	}
+}
+
+// Tests that HTTP request bodies without a declared length are limited too.
+func TestLimitHTTPChunkedRequest(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetLimits(Limits{MaxRequestSize: 200})
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(echoRequest(1, strings.Repeat("a", 1000))))
+	req.Header.Set("content-type", contentType)
+	req.ContentLength = -1
+
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+
+	var msg jsonErrResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &msg); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if msg.Error.Code == 0 {
+		t.Errorf("oversize request served")
+	}
+}
@@ -0,0 +1,328 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// OpenRPCVersion is the version of the OpenRPC specification the documents
+// returned by rpc_discover conform to.
+const OpenRPCVersion = "1.2.6"
+
+// OpenRPCDocument describes the methods of a server in the OpenRPC format, see
+// https://spec.open-rpc.org.
+type OpenRPCDocument struct {
+	OpenRPC    string            `json:"openrpc"`
+	Info       OpenRPCInfo       `json:"info"`
+	Methods    []*OpenRPCMethod  `json:"methods"`
+	Components OpenRPCComponents `json:"components"`
+}
+
+// OpenRPCInfo is the metadata of an OpenRPC document.
+type OpenRPCInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenRPCMethod describes a method callable on the server. Subscribe methods
+// list the subscriptions of their namespace in the x-subscriptions extension.
+type OpenRPCMethod struct {
+	Name          string                      `json:"name"`
+	Params        []*OpenRPCContentDescriptor `json:"params"`
+	Result        *OpenRPCContentDescriptor   `json:"result"`
+	Subscriptions []*OpenRPCSubscription      `json:"x-subscriptions,omitempty"`
+}
+
+// OpenRPCSubscription describes a subscription and the parameters following
+// its name in the subscribe call.
+type OpenRPCSubscription struct {
+	Name   string                      `json:"name"`
+	Params []*OpenRPCContentDescriptor `json:"params"`
+}
+
+// OpenRPCContentDescriptor describes a parameter or the result of a method.
+type OpenRPCContentDescriptor struct {
+	Name     string         `json:"name"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenRPCSchema `json:"schema"`
+}
+
+// OpenRPCComponents holds the schemas of the named types, referenced from the
+// methods as #/components/schemas/<package>.<type>.
+type OpenRPCComponents struct {
+	Schemas map[string]*OpenRPCSchema `json:"schemas,omitempty"`
+}
+
+// OpenRPCSchema is the subset of JSON schema needed to describe the values
+// exchanged with the server. An empty schema accepts any value.
+type OpenRPCSchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	ContentEncoding      string                    `json:"contentEncoding,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *OpenRPCSchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenRPCSchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenRPCSchema            `json:"additionalProperties,omitempty"`
+}
+
+// hexNumPattern matches the hex encoded big integers returned by callbacks.
+const hexNumPattern = "^-?0x[0-9a-f]+$"
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// knownSchemas describes the types with a custom JSON encoding which can't be
+// told from reflection.
+var knownSchemas = map[reflect.Type]*OpenRPCSchema{
+	bigIntType:                     {Type: "integer"},
+	reflect.TypeOf(BlockNumber(0)): {Type: "string", Pattern: "^(earliest|latest|pending|finalized|0x[0-9a-fA-F]+)$"},
+}
+
+// Discover returns the OpenRPC document describing the methods and
+// subscriptions of all registered services.
+func (s *RPCService) Discover() *OpenRPCDocument {
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    OpenRPCInfo{Title: "Okcoin JSON-RPC API", Version: "1.0"},
+		Methods: []*OpenRPCMethod{},
+	}
+	gen := &schemaGenerator{
+		schemas: make(map[string]*OpenRPCSchema),
+		names:   make(map[reflect.Type]string),
+		types:   make(map[string]reflect.Type),
+	}
+	for _, svc := range s.server.services {
+		for name, cb := range svc.callbacks {
+			doc.Methods = append(doc.Methods, gen.method(svc.name+serviceMethodSeparator+name, cb))
+		}
+		if len(svc.subscriptions) > 0 {
+			doc.Methods = append(doc.Methods, gen.subscribeMethod(svc), unsubscribeMethod(svc))
+		}
+	}
+	sort.Slice(doc.Methods, func(i, j int) bool { return doc.Methods[i].Name < doc.Methods[j].Name })
+
+	if len(gen.schemas) > 0 {
+		doc.Components.Schemas = gen.schemas
+	}
+	return doc
+}
+
+// schemaGenerator derives the JSON schemas of Go types, collecting the ones of
+// named types as components.
+type schemaGenerator struct {
+	schemas map[string]*OpenRPCSchema // Component schemas by name
+	names   map[reflect.Type]string   // Component names of the visited named types
+	types   map[string]reflect.Type   // Named types by component name
+}
+
+// method describes a regular callback.
+func (g *schemaGenerator) method(name string, cb *callback) *OpenRPCMethod {
+	m := &OpenRPCMethod{
+		Name:   name,
+		Params: g.params(cb.argTypes),
+		Result: &OpenRPCContentDescriptor{Name: "result", Schema: &OpenRPCSchema{Type: "null"}},
+	}
+	mtype := cb.method.Type
+	for i := 0; i < mtype.NumOut(); i++ {
+		if i == cb.errPos {
+			continue
+		}
+		if isHexNum(mtype.Out(i)) {
+			m.Result.Schema = &OpenRPCSchema{Type: "string", Pattern: hexNumPattern}
+		} else {
+			m.Result.Schema = g.schema(mtype.Out(i))
+		}
+	}
+	return m
+}
+
+// subscribeMethod describes the subscribe method of a service, taking the name
+// of the subscription followed by its arguments.
+func (g *schemaGenerator) subscribeMethod(svc *service) *OpenRPCMethod {
+	m := &OpenRPCMethod{
+		Name: svc.name + subscribeMethodSuffix,
+		Params: []*OpenRPCContentDescriptor{
+			{Name: "subscription", Required: true, Schema: &OpenRPCSchema{Type: "string"}},
+		},
+		Result: &OpenRPCContentDescriptor{Name: "subscriptionId", Schema: &OpenRPCSchema{Type: "string"}},
+	}
+	names := make([]string, 0, len(svc.subscriptions))
+	for name := range svc.subscriptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	m.Params[0].Schema.Enum = names
+	for _, name := range names {
+		m.Subscriptions = append(m.Subscriptions, &OpenRPCSubscription{
+			Name:   name,
+			Params: g.params(svc.subscriptions[name].argTypes),
+		})
+	}
+	return m
+}
+
+// unsubscribeMethod describes the unsubscribe method of a service.
+func unsubscribeMethod(svc *service) *OpenRPCMethod {
+	return &OpenRPCMethod{
+		Name: svc.name + unsubscribeMethodSuffix,
+		Params: []*OpenRPCContentDescriptor{
+			{Name: "subscriptionId", Required: true, Schema: &OpenRPCSchema{Type: "string"}},
+		},
+		Result: &OpenRPCContentDescriptor{Name: "result", Schema: &OpenRPCSchema{Type: "boolean"}},
+	}
+}
+
+// params describes the arguments of a callback. Pointer arguments are optional,
+// they may be null or omitted at the end of the argument list.
+func (g *schemaGenerator) params(types []reflect.Type) []*OpenRPCContentDescriptor {
+	params := make([]*OpenRPCContentDescriptor, len(types))
+	for i, t := range types {
+		params[i] = &OpenRPCContentDescriptor{
+			Name:     fmt.Sprintf("arg%d", i),
+			Required: t.Kind() != reflect.Ptr,
+			Schema:   g.schema(t),
+		}
+	}
+	return params
+}
+
+// schema returns the schema of t, a reference to a component for named types.
+func (g *schemaGenerator) schema(t reflect.Type) *OpenRPCSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() == "" || t.PkgPath() == "" {
+		return g.describe(t)
+	}
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name // Registered first, for recursive types
+		g.types[name] = t
+		g.schemas[name] = g.describe(t)
+	}
+	return &OpenRPCSchema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName returns a unique component name for a named type, qualified by
+// its package name, or by its full import path on conflicts.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	if _, taken := g.types[name]; taken {
+		name = strings.NewReplacer("/", ".", "~", ".").Replace(t.PkgPath()) + "." + t.Name()
+	}
+	return name
+}
+
+// describe returns the schema of the JSON encoding of t, following the rules of
+// encoding/json.
+func (g *schemaGenerator) describe(t reflect.Type) *OpenRPCSchema {
+	if schema, ok := knownSchemas[t]; ok {
+		known := *schema
+		return &known
+	}
+	ptr := reflect.PtrTo(t)
+	switch {
+	case t.Implements(jsonMarshalerType) || ptr.Implements(jsonUnmarshalerType):
+		// Custom encodings are usually strings, but can't be told apart
+		if t.Implements(textMarshalerType) || ptr.Implements(textUnmarshalerType) {
+			return &OpenRPCSchema{Type: "string"}
+		}
+		return &OpenRPCSchema{}
+	case t.Implements(textMarshalerType) || ptr.Implements(textUnmarshalerType):
+		return &OpenRPCSchema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenRPCSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &OpenRPCSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenRPCSchema{Type: "number"}
+	case reflect.String:
+		return &OpenRPCSchema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &OpenRPCSchema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &OpenRPCSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &OpenRPCSchema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &OpenRPCSchema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		schema := &OpenRPCSchema{Type: "object", Properties: make(map[string]*OpenRPCSchema)}
+		g.addProperties(schema.Properties, t)
+		return schema
+	}
+	// Interfaces and anything else not encodable statically
+	return &OpenRPCSchema{}
+}
+
+// addProperties adds the JSON fields of struct type t to props. The fields of
+// embedded structs are promoted unless shadowed by a field of the outer struct.
+func (g *schemaGenerator) addProperties(props map[string]*OpenRPCSchema, t reflect.Type) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue // Unexported
+		}
+		if name == "" {
+			name = field.Name
+		}
+		props[name] = g.schema(field.Type)
+	}
+	for _, et := range embedded {
+		promoted := make(map[string]*OpenRPCSchema)
+		g.addProperties(promoted, et)
+		for name, schema := range promoted {
+			if _, shadowed := props[name]; !shadowed {
+				props[name] = schema
+			}
+		}
+	}
+}
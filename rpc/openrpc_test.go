@@ -0,0 +1,182 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+type Tree struct {
+	Value    *big.Int `json:"value"`
+	Children []*Tree  `json:"children,omitempty"`
+	Data     []byte   `json:"-"`
+}
+
+type DiscoverService struct{}
+
+func (s *DiscoverService) Balance(number BlockNumber) (*big.Int, error) {
+	return big.NewInt(int64(number)), nil
+}
+
+func (s *DiscoverService) Tree(depth int, labels map[string]string) Tree {
+	return Tree{}
+}
+
+// Tests that rpc_discover describes the methods and subscriptions of all the
+// registered services along with the schemas of their arguments and results.
+func TestDiscover(t *testing.T) {
+	server := newTestServer("calc", new(Service))
+	if err := server.RegisterName("test", new(DiscoverService)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc OpenRPCDocument
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatalf("failed to discover: %v", err)
+	}
+	if doc.OpenRPC != OpenRPCVersion {
+		t.Errorf("OpenRPC version mismatch: have %s, want %s", doc.OpenRPC, OpenRPCVersion)
+	}
+	methods := make(map[string]*OpenRPCMethod)
+	var names []string
+	for _, m := range doc.Methods {
+		methods[m.Name] = m
+		names = append(names, m.Name)
+	}
+	want := []string{
+		"calc_echo", "calc_echoWithCtx", "calc_noArgsRets", "calc_rets", "calc_sleep",
+		"calc_subscribe", "calc_unsubscribe", "rpc_discover", "rpc_modules", "test_balance", "test_tree",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("method list mismatch:\nhave %v\nwant %v", names, want)
+	}
+	ref := func(name string) *OpenRPCSchema {
+		return &OpenRPCSchema{Ref: "#/components/schemas/" + name}
+	}
+	tests := []struct {
+		method string
+		params []*OpenRPCContentDescriptor
+		result *OpenRPCSchema
+	}{
+		{
+			method: "calc_noArgsRets",
+			params: []*OpenRPCContentDescriptor{},
+			result: &OpenRPCSchema{Type: "null"},
+		},
+		{
+			method: "calc_echoWithCtx",
+			params: []*OpenRPCContentDescriptor{
+				{Name: "arg0", Required: true, Schema: &OpenRPCSchema{Type: "string"}},
+				{Name: "arg1", Required: true, Schema: &OpenRPCSchema{Type: "integer"}},
+				{Name: "arg2", Schema: ref("rpc.Args")},
+			},
+			result: ref("rpc.Result"),
+		},
+		{
+			method: "calc_rets",
+			params: []*OpenRPCContentDescriptor{},
+			result: &OpenRPCSchema{Type: "string"},
+		},
+		{
+			method: "test_balance",
+			params: []*OpenRPCContentDescriptor{
+				{Name: "arg0", Required: true, Schema: ref("rpc.BlockNumber")},
+			},
+			result: &OpenRPCSchema{Type: "string", Pattern: hexNumPattern},
+		},
+		{
+			method: "test_tree",
+			params: []*OpenRPCContentDescriptor{
+				{Name: "arg0", Required: true, Schema: &OpenRPCSchema{Type: "integer"}},
+				{Name: "arg1", Required: true, Schema: &OpenRPCSchema{Type: "object", AdditionalProperties: &OpenRPCSchema{Type: "string"}}},
+			},
+			result: ref("rpc.Tree"),
+		},
+	}
+	for _, tt := range tests {
+		m := methods[tt.method]
+		if !reflect.DeepEqual(m.Params, tt.params) {
+			t.Errorf("%s: params mismatch:\nhave %s\nwant %s", tt.method, toJSON(m.Params), toJSON(tt.params))
+		}
+		if !reflect.DeepEqual(m.Result.Schema, tt.result) {
+			t.Errorf("%s: result mismatch:\nhave %s\nwant %s", tt.method, toJSON(m.Result.Schema), toJSON(tt.result))
+		}
+	}
+	// Subscriptions are listed by the subscribe method of their namespace
+	sub := methods["calc_subscribe"]
+	if enum := sub.Params[0].Schema.Enum; !reflect.DeepEqual(enum, []string{"subscription"}) {
+		t.Errorf("subscription names mismatch: have %v", enum)
+	}
+	if len(sub.Subscriptions) != 1 || sub.Subscriptions[0].Name != "subscription" || len(sub.Subscriptions[0].Params) != 0 {
+		t.Errorf("subscriptions mismatch: have %s", toJSON(sub.Subscriptions))
+	}
+	// Named types must be described once as components, recursive ones included
+	schemas := map[string]*OpenRPCSchema{
+		"rpc.Args": {Type: "object", Properties: map[string]*OpenRPCSchema{
+			"S": {Type: "string"},
+		}},
+		"rpc.Result": {Type: "object", Properties: map[string]*OpenRPCSchema{
+			"String": {Type: "string"},
+			"Int":    {Type: "integer"},
+			"Args":   ref("rpc.Args"),
+		}},
+		"rpc.Tree": {Type: "object", Properties: map[string]*OpenRPCSchema{
+			"value":    ref("big.Int"),
+			"children": {Type: "array", Items: ref("rpc.Tree")},
+		}},
+		"rpc.BlockNumber": knownSchemas[reflect.TypeOf(BlockNumber(0))],
+		"big.Int":         {Type: "integer"},
+	}
+	for name, want := range schemas {
+		if have := doc.Components.Schemas[name]; !reflect.DeepEqual(have, want) {
+			t.Errorf("component %s mismatch:\nhave %s\nwant %s", name, toJSON(have), toJSON(want))
+		}
+	}
+}
+
+// Tests that services not set up yet, registered as nil receivers, can still be
+// described.
+func TestDiscoverNilReceiver(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("test", (*DiscoverService)(nil)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	var doc OpenRPCDocument
+	if err := client.Call(&doc, "rpc_discover"); err != nil {
+		t.Fatalf("failed to discover: %v", err)
+	}
+	var names []string
+	for _, m := range doc.Methods {
+		names = append(names, m.Name)
+	}
+	if want := []string{"rpc_discover", "rpc_modules", "test_balance", "test_tree"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("method list mismatch:\nhave %v\nwant %v", names, want)
+	}
+}
+
+func toJSON(v interface{}) string {
+	blob, _ := json.Marshal(v)
+	return string(blob)
+}
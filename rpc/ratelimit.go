@@ -0,0 +1,203 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okcoin/go-okcoin/metrics"
+)
+
+const (
+	// defaultMethodCost is the cost of the methods not configured otherwise.
+	defaultMethodCost = 1
+
+	// bucketSweepInterval is the time between the sweeps of the budgets of the
+	// clients that haven't been active long enough to be fully replenished.
+	bucketSweepInterval = time.Minute
+)
+
+var (
+	errInvalidRate = errors.New("rate limit must be positive")
+
+	rpcLimitedMeter = metrics.NewRegisteredMeter("rpc/limited", nil)
+)
+
+// RateLimitConfig are the request budgets of the clients of an RPC server. Each
+// call charges the cost of its method to the budget of the client, which is
+// replenished over time up to the burst size.
+type RateLimitConfig struct {
+	Rate  float64            // Cost units replenished per second
+	Burst float64            // Maximum budget of a client, the rate if zero
+	Costs map[string]float64 // Costs of methods or whole namespaces, 1 for the ones not listed
+}
+
+// tokenBucket is the budget of a single client.
+type tokenBucket struct {
+	tokens float64   // Cost units available at the time of the last update
+	time   time.Time // Time of the last update
+}
+
+// RateLimiter tracks the budgets of the clients of one or more RPC servers.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	costs map[string]float64
+	now   func() time.Time
+
+	buckets map[string]*tokenBucket
+	swept   time.Time
+	lock    sync.Mutex
+}
+
+// NewRateLimiter creates a rate limiter with the given client budgets.
+func NewRateLimiter(config RateLimitConfig) (*RateLimiter, error) {
+	if config.Rate <= 0 {
+		return nil, errInvalidRate
+	}
+	burst := config.Burst
+	if burst <= 0 {
+		burst = config.Rate
+	}
+	return &RateLimiter{
+		rate:    config.Rate,
+		burst:   burst,
+		costs:   config.Costs,
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+		swept:   time.Now(),
+	}, nil
+}
+
+// cost returns the cost of calling a method, capped to the burst size so that
+// any method is callable with a full budget.
+func (l *RateLimiter) cost(method string) float64 {
+	cost, ok := l.costs[method]
+	if !ok {
+		if i := strings.Index(method, serviceMethodSeparator); i > 0 {
+			cost, ok = l.costs[method[:i]]
+		}
+	}
+	if !ok {
+		cost = defaultMethodCost
+	}
+	return math.Min(cost, l.burst)
+}
+
+// clientID identifies the client whose budget a call is charged to: by its
+// credentials if they are verified, by its IP address otherwise. It is empty if
+// neither is known.
+func clientID(authenticated bool, credentials, remoteAddr string) string {
+	switch {
+	case authenticated && credentials != "":
+		return credentials
+	case remoteAddr != "":
+		if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+			return host
+		}
+		return remoteAddr
+	}
+	return ""
+}
+
+// charge charges the cost of calling a method to the budget of a client. If the
+// budget is insufficient the call is rejected and the time until it is
+// replenished enough is returned.
+func (l *RateLimiter) charge(client, method string) (time.Duration, bool) {
+	wait, ok := l.take(client, l.cost(method))
+	if !ok {
+		rpcLimitedMeter.Mark(1)
+	}
+	return wait, ok
+}
+
+// take charges a cost to the budget of a client. If the budget is insufficient
+// nothing is charged and the time until it is replenished enough is returned.
+func (l *RateLimiter) take(client string, cost float64) (time.Duration, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if now.Sub(l.swept) >= bucketSweepInterval {
+		l.sweep(now)
+	}
+	bucket := l.buckets[client]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: l.burst, time: now}
+		l.buckets[client] = bucket
+	}
+	bucket.tokens = l.replenished(bucket, now)
+	bucket.time = now
+
+	if bucket.tokens < cost {
+		return time.Duration((cost - bucket.tokens) / l.rate * float64(time.Second)), false
+	}
+	bucket.tokens -= cost
+	return 0, true
+}
+
+// replenished returns the budget of a bucket at the given time.
+func (l *RateLimiter) replenished(bucket *tokenBucket, now time.Time) float64 {
+	return math.Min(l.burst, bucket.tokens+now.Sub(bucket.time).Seconds()*l.rate)
+}
+
+// sweep drops the buckets of the clients whose budget is fully replenished, as
+// they are indistinguishable from new ones.
+func (l *RateLimiter) sweep(now time.Time) {
+	for client, bucket := range l.buckets {
+		if l.replenished(bucket, now) >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.swept = now
+}
+
+// SetRateLimiter charges the calls of the clients to their budgets in the given
+// rate limiter, rejecting them if exhausted. It must be set before serving requests.
+func (s *Server) SetRateLimiter(limiter *RateLimiter) {
+	s.limiter = limiter
+}
+
+// limit charges the cost of the requested method to the budget of the client,
+// identified by its credentials if the server verifies them or its IP address
+// otherwise. Clients without a network address, such as IPC ones, are not limited.
+func (s *Server) limit(ctx context.Context, req *serverRequest) Error {
+	if s.limiter == nil {
+		return nil
+	}
+	info := connInfoFromContext(ctx)
+
+	client := clientID(s.auth != nil, info.credentials, info.remoteAddr)
+	if client == "" {
+		return nil
+	}
+	wait, ok := s.limiter.charge(client, req.fullName())
+	if ok {
+		return nil
+	}
+	if info.respHeader != nil {
+		info.respHeader.Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+	return &rateLimitedError{wait}
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Tests that client budgets are charged the configured method costs and are
+// replenished over time.
+func TestRateLimiterBudget(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{
+		Rate:  2,
+		Burst: 10,
+		Costs: map[string]float64{"debug": 6, "debug_cheap": 0.5, "okc_getLogs": 4, "admin": 100},
+	})
+	if err != nil {
+		t.Fatalf("failed to create rate limiter: %v", err)
+	}
+	now := time.Unix(0, 0)
+	limiter.now, limiter.swept = func() time.Time { return now }, now
+
+	costs := map[string]float64{"okc_getLogs": 4, "okc_call": 1, "debug_traceTransaction": 6, "debug_cheap": 0.5, "admin_peers": 10}
+	for method, want := range costs {
+		if have := limiter.cost(method); have != want {
+			t.Errorf("cost mismatch for %s: have %v, want %v", method, have, want)
+		}
+	}
+	// Exhaust the budget of a client, leaving others unaffected
+	if _, ok := limiter.take("a", 6); !ok {
+		t.Fatalf("first call rejected")
+	}
+	if _, ok := limiter.take("a", 4); !ok {
+		t.Fatalf("call within budget rejected")
+	}
+	if wait, ok := limiter.take("a", 1); ok || wait != 500*time.Millisecond {
+		t.Fatalf("call over budget: have allowed %v with wait %v, want wait %v", ok, wait, 500*time.Millisecond)
+	}
+	if _, ok := limiter.take("b", 10); !ok {
+		t.Fatalf("call of another client rejected")
+	}
+	// Ensure the budget is replenished at the rate, up to the burst size
+	now = now.Add(time.Second)
+	if _, ok := limiter.take("a", 2); !ok {
+		t.Fatalf("call within replenished budget rejected")
+	}
+	if _, ok := limiter.take("a", 1); ok {
+		t.Fatalf("call over replenished budget allowed")
+	}
+	now = now.Add(time.Hour)
+	if _, ok := limiter.take("a", 10); !ok {
+		t.Fatalf("call within full budget rejected")
+	}
+	if _, ok := limiter.take("a", 1); ok {
+		t.Fatalf("budget replenished beyond the burst size")
+	}
+	// Ensure idle clients are swept
+	now = now.Add(time.Hour)
+	limiter.take("c", 1)
+	if len(limiter.buckets) != 1 {
+		t.Errorf("bucket count mismatch after sweep: have %d, want 1", len(limiter.buckets))
+	}
+}
+
+// Tests that HTTP clients over budget are rejected with a rate limit error and
+// told when to retry, per remote address.
+func TestRateLimitHTTP(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+
+	limiter, _ := NewRateLimiter(RateLimitConfig{Rate: 0.5, Burst: 2})
+	server.SetRateLimiter(limiter)
+
+	call := func(remoteAddr string) (*httptest.ResponseRecorder, *jsonErrResponse) {
+		body := `{"jsonrpc":"2.0","id":1,"method":"service_noArgsRets","params":[]}`
+		req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(body))
+		req.Header.Set("content-type", contentType)
+		req.RemoteAddr = remoteAddr
+
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+
+		var msg jsonErrResponse
+		if err := json.Unmarshal(resp.Body.Bytes(), &msg); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp, &msg
+	}
+	for i := 0; i < 2; i++ {
+		if _, msg := call("192.0.2.1:1000"); msg.Error.Code != 0 {
+			t.Fatalf("call %d within budget rejected: %v", i, msg.Error.Message)
+		}
+	}
+	resp, msg := call("192.0.2.1:2000")
+	if msg.Error.Code != new(rateLimitedError).ErrorCode() {
+		t.Fatalf("error code mismatch: have %d, want %d", msg.Error.Code, new(rateLimitedError).ErrorCode())
+	}
+	if retry := resp.Header().Get("Retry-After"); retry != "2" {
+		t.Errorf("Retry-After mismatch: have %q, want %q", retry, "2")
+	}
+	if _, msg := call("192.0.2.2:1000"); msg.Error.Code != 0 {
+		t.Errorf("call of another client rejected: %v", msg.Error.Message)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/okcoin/go-okcoin/log"
+)
+
+// ReconnectConfig is the redial policy of clients created by DialReconnecting.
+// Redial attempts are spaced by exponentially growing delays, starting with
+// MinBackoff and doubling up to MaxBackoff.
+type ReconnectConfig struct {
+	MinBackoff time.Duration // Delay after the first failed redial attempt
+	MaxBackoff time.Duration // Upper bound of the delay between attempts
+}
+
+// DefaultReconnectConfig is the redial policy used for unset config fields.
+var DefaultReconnectConfig = ReconnectConfig{
+	MinBackoff: 100 * time.Millisecond,
+	MaxBackoff: 30 * time.Second,
+}
+
+// SubscriptionGap reports the interruption of a subscription by the loss of the
+// client connection. Notifications sent by the server between Lost and Resumed
+// were missed.
+type SubscriptionGap struct {
+	Err     error     // The error that broke the connection
+	Lost    time.Time // When the connection was lost
+	Resumed time.Time // When the subscription was re-established
+}
+
+// DialReconnecting creates a new client for the given URL, just like DialContext,
+// which survives the loss of its connection.
+//
+// When the websocket or IPC connection of the client drops, the calls in flight
+// fail with the connection error, but the client redials in the background with
+// exponential backoff. Calls made in the meantime wait for the new connection,
+// within the bounds of their context. Active subscriptions are re-issued on the
+// new connection with the original arguments, reporting the interruption on
+// their Gaps channel. HTTP clients don't hold a connection, they are returned
+// as created by DialHTTP.
+//
+// The context is used to cancel or time out the initial connection establishment,
+// which is not retried.
+func DialReconnecting(ctx context.Context, rawurl string, config ReconnectConfig) (*Client, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	var connect func(context.Context) (net.Conn, error)
+	switch u.Scheme {
+	case "http", "https":
+		return DialHTTP(rawurl)
+	case "ws", "wss":
+		if connect, err = wsConnectFunc(rawurl, ""); err != nil {
+			return nil, err
+		}
+	case "":
+		connect = func(ctx context.Context) (net.Conn, error) {
+			return newIPCConnection(ctx, rawurl)
+		}
+	default:
+		return nil, fmt.Errorf("no known transport for URL scheme %q", u.Scheme)
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = DefaultReconnectConfig.MinBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultReconnectConfig.MaxBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	return newReconnectingClient(ctx, connect, &config)
+}
+
+// backoffDialer wraps connect into a function retrying failed connection attempts
+// according to the redial policy, until it succeeds, the context is cancelled
+// or the client is closed.
+func (c *Client) backoffDialer(connect func(context.Context) (net.Conn, error)) func(context.Context) (net.Conn, error) {
+	return func(ctx context.Context) (net.Conn, error) {
+		delay := c.redialCfg.MinBackoff
+		for attempt := 1; ; attempt++ {
+			dialctx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+			conn, err := connect(dialctx)
+			cancel()
+			if err == nil {
+				return conn, nil
+			}
+			log.Debug("RPC redial failed", "attempt", attempt, "retry", delay, "err", err)
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-c.didQuit:
+				timer.Stop()
+				return nil, ErrClientQuit
+			}
+			if delay *= 2; delay > c.redialCfg.MaxBackoff {
+				delay = c.redialCfg.MaxBackoff
+			}
+		}
+	}
+}
+
+// redial establishes a new connection in place of the lost one, unless a write
+// has already done so. It is called by dispatch when the read loop of the lost
+// connection fails, so that subscriptions resume without waiting for a call.
+func (c *Client) redial(lost net.Conn) {
+	// Take the write lock through an operation awaiting no responses.
+	select {
+	case c.requestOp <- &requestOp{}:
+	case <-c.didQuit:
+		return
+	}
+	var err error
+	if c.writeConn == lost || c.writeConn == nil {
+		err = c.reconnect(context.Background())
+	}
+	c.sendDone <- err
+}
+
+// suspendSubscriptions sets aside the active subscriptions, along with the ones
+// being resumed, for re-issuing them on the next connection. It is called by
+// dispatch when the connection is lost.
+func (c *Client) suspendSubscriptions(err error) {
+	now := time.Now()
+	for id, sub := range c.subs {
+		delete(c.subs, id)
+		c.suspend(sub, err, now)
+	}
+	for _, op := range c.respWait {
+		if op.resubscribe {
+			c.suspend(op.sub, err, now)
+		}
+	}
+}
+
+func (c *Client) suspend(sub *ClientSubscription, err error, now time.Time) {
+	select {
+	case <-sub.quit:
+		return // Unsubscribed, no need to resume it
+	default:
+	}
+	sub.setID("")
+	if sub.lost.IsZero() {
+		sub.lost, sub.lostErr = now, err
+	}
+	c.suspended = append(c.suspended, sub)
+}
+
+// resubscribe re-issues the subscribe calls of suspended subscriptions on the
+// new connection. The responses are handled by dispatch, resuming the
+// subscriptions.
+func (c *Client) resubscribe(subs []*ClientSubscription) {
+	for i, sub := range subs {
+		select {
+		case <-sub.quit:
+			continue // Unsubscribed while suspended
+		default:
+		}
+		msg := &jsonrpcMessage{Version: "2.0", ID: c.nextID(), Method: sub.namespace + subscribeMethodSuffix, Params: sub.params}
+		op := &requestOp{
+			ids:         []json.RawMessage{msg.ID},
+			resp:        make(chan *jsonrpcMessage),
+			sub:         sub,
+			resubscribe: true,
+		}
+		// A failed write leaves no trace of the request, retry it on the
+		// connection the next write establishes.
+		for {
+			err := c.send(context.Background(), op, msg)
+			if err == nil {
+				break
+			}
+			if err == ErrClientQuit {
+				for _, sub := range subs[i:] {
+					sub.quitWithError(ErrClientQuit, false)
+				}
+				return
+			}
+			log.Debug("RPC resubscribe failed", "namespace", sub.namespace, "err", err)
+		}
+	}
+}
+
+// resumed reports the gap of a subscription re-established on a new connection.
+// It is called by dispatch, the only sender on the gaps channel.
+func (sub *ClientSubscription) resumed() {
+	select {
+	case <-sub.quit:
+		// Unsubscribed while being resumed, cancel it on the server.
+		go sub.requestUnsubscribe()
+		return
+	default:
+	}
+	gap := SubscriptionGap{Err: sub.lostErr, Lost: sub.lost, Resumed: time.Now()}
+	sub.lost, sub.lostErr = time.Time{}, nil
+
+	// Merge an unreceived earlier gap into this one.
+	select {
+	case prev := <-sub.gaps:
+		gap.Err, gap.Lost = prev.Err, prev.Lost
+	default:
+	}
+	sub.gaps <- gap
+}
@@ -0,0 +1,230 @@
+// Copyright 2018 The go-okcoin Authors
+// This file is part of the go-okcoin library.
+//
+// The go-okcoin library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-okcoin library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-okcoin library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TickerService notifies its subscribers of increasing numbers until they
+// unsubscribe.
+type TickerService struct{}
+
+func (s *TickerService) Echo(i int) int {
+	return i
+}
+
+func (s *TickerService) Ticks(ctx context.Context) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	subscription := notifier.CreateSubscription()
+
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for i := 0; ; i++ {
+			select {
+			case <-ticker.C:
+				if err := notifier.Notify(subscription.ID, i); err != nil {
+					return
+				}
+			case <-subscription.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return subscription, nil
+}
+
+func TestReconnectWebsocket(t *testing.T) { testReconnect(t, "ws") }
+func TestReconnectIPC(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("restarting named pipe servers is not supported")
+	}
+	testReconnect(t, "ipc")
+}
+
+// Tests that a reconnecting client resumes its subscriptions and serves calls
+// after its server is killed and restarted on the same endpoint.
+func testReconnect(t *testing.T, transport string) {
+	var endpoint string
+	switch transport {
+	case "ws":
+		endpoint = "127.0.0.1:0"
+	case "ipc":
+		endpoint = fmt.Sprintf("%s/go-okcoin-test-reconnect-%d-%d", os.TempDir(), os.Getpid(), rand.Int63())
+	}
+	startServer := func() (*Server, net.Listener) {
+		srv := newTestServer("test", new(TickerService))
+		var (
+			l   net.Listener
+			err error
+		)
+		switch transport {
+		case "ws":
+			if l, err = net.Listen("tcp", endpoint); err == nil {
+				endpoint = l.Addr().String()
+				go http.Serve(l, srv.WebsocketHandler([]string{"*"}))
+			}
+		case "ipc":
+			if l, err = ipcListen(endpoint); err == nil {
+				go srv.ServeListener(l)
+			}
+		}
+		if err != nil {
+			t.Fatalf("can't listen: %v", err)
+		}
+		return srv, l
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	// Start a server and subscribe to it
+	srv, l := startServer()
+	rawurl := endpoint
+	if transport == "ws" {
+		rawurl = "ws://" + endpoint
+	}
+	client, err := DialReconnecting(ctx, rawurl, ReconnectConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	defer client.Close()
+
+	ticks := make(chan int)
+	sub, err := client.Subscribe(ctx, "test", ticks, "ticks")
+	if err != nil {
+		t.Fatalf("can't subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	receive := func() {
+		select {
+		case <-ticks:
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-ctx.Done():
+			t.Fatalf("no notification received")
+		}
+	}
+	receive()
+
+	// Kill the server, the subscription must survive and calls must wait for the
+	// connection to be re-established.
+	l.Close()
+	srv.Stop()
+
+	lost := time.Now()
+	echo := make(chan error, 1)
+	go func() {
+		var result int
+		time.Sleep(50 * time.Millisecond) // Ensure the call is made while the server is down
+		if err := client.CallContext(ctx, &result, "test_echo", 42); err != nil {
+			echo <- err
+		} else if result != 42 {
+			echo <- fmt.Errorf("echo mismatch: have %d, want 42", result)
+		}
+		close(echo)
+	}()
+	for timeout := time.After(300 * time.Millisecond); ; {
+		select {
+		case <-ticks:
+			continue // Notifications still buffered
+		case err := <-sub.Err():
+			t.Fatalf("subscription ended while the server is down: %v", err)
+		case <-timeout:
+		}
+		break
+	}
+	// Restart the server, the subscription must resume and report the gap
+	srv, l = startServer()
+	defer l.Close()
+	defer srv.Stop()
+
+	select {
+	case gap := <-sub.Gaps():
+		if gap.Err == nil {
+			t.Errorf("gap without connection error")
+		}
+		if gap.Lost.Before(lost.Add(-time.Second)) || gap.Lost.After(gap.Resumed) {
+			t.Errorf("gap times mismatch: lost %v, resumed %v, killed %v", gap.Lost, gap.Resumed, lost)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-ctx.Done():
+		t.Fatalf("no gap reported")
+	}
+	receive()
+
+	if err := <-echo; err != nil {
+		t.Errorf("call during the outage failed: %v", err)
+	}
+	// Unsubscribing must end the subscription cleanly
+	sub.Unsubscribe()
+	if err, ok := <-sub.Err(); ok {
+		t.Errorf("Err returned a value after unsubscribe: %v", err)
+	}
+}
+
+// Tests that the subscriptions of a reconnecting client end without error when
+// the client is closed while its server is down.
+func TestReconnectClose(t *testing.T) {
+	srv := newTestServer("test", new(TickerService))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("can't listen: %v", err)
+	}
+	go http.Serve(l, srv.WebsocketHandler([]string{"*"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := DialReconnecting(ctx, "ws://"+l.Addr().String(), ReconnectConfig{MinBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("can't dial: %v", err)
+	}
+	sub, err := client.Subscribe(ctx, "test", make(chan int, 1000), "ticks")
+	if err != nil {
+		t.Fatalf("can't subscribe: %v", err)
+	}
+	l.Close()
+	srv.Stop()
+	time.Sleep(50 * time.Millisecond)
+
+	client.Close()
+	select {
+	case err := <-sub.Err():
+		if err != nil {
+			t.Errorf("subscription ended with error: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatalf("subscription not ended by Close")
+	}
+}
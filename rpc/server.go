@@ -24,8 +24,10 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/okcoin/go-okcoin/log"
+	"github.com/okcoin/go-okcoin/metrics"
 	"gopkg.in/fatih/set.v0"
 )
 
@@ -88,8 +90,13 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 	if name == "" {
 		return fmt.Errorf("no service name for type %s", svc.typ.String())
 	}
-	if !isExported(reflect.Indirect(rcvrVal).Type().Name()) {
-		return fmt.Errorf("%s is not exported", reflect.Indirect(rcvrVal).Type().Name())
+	// Check the type rather than the value, for nil receivers to be describable
+	rcvrType := svc.typ
+	if rcvrType.Kind() == reflect.Ptr {
+		rcvrType = rcvrType.Elem()
+	}
+	if !isExported(rcvrType.Name()) {
+		return fmt.Errorf("%s is not exported", rcvrType.Name())
 	}
 
 	methods, subscriptions := suitableCallbacks(rcvrVal, svc.typ)
@@ -119,13 +126,30 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// SetAuthenticator restricts the methods clients may call to the ones granted to
+// their credentials by the authenticator. It must be set before serving requests.
+// HTTP and websocket connections without credentials get the anonymous permissions,
+// while local connections served by ServeCodec (IPC, in-process), which cannot carry
+// credentials, get the local permissions of the policy.
+func (s *Server) SetAuthenticator(auth *Authenticator) {
+	s.auth = auth
+}
+
+// SetTimeout sets the deadline of the calls made to the server, canceling their
+// context once it passes. Zero disables the deadline. It must be set before
+// serving requests.
+func (s *Server) SetTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
 // serveRequest will reads requests from the codec, calls the RPC callback and
-// writes the response to the given codec.
+// writes the response to the given codec. The context carries the credentials
+// of the connection, if any.
 //
 // If singleShot is true it will process a single request, otherwise it will handle
 // requests until the codec returns an error when reading a request (in most cases
 // an EOF). It executes requests in parallel when singleShot is false.
-func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecOption) error {
+func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot bool, options CodecOption) error {
 	var pend sync.WaitGroup
 
 	defer func() {
@@ -140,9 +164,13 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 		s.codecsMu.Unlock()
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	if c, ok := codec.(*jsonCodec); ok {
+		c.setMaxMessageSize(s.limits.MaxRequestSize)
+	}
+
 	// if the codec supports notification include a notifier that callbacks can use
 	// to send notification to clients. It is thight to the codec/connection. If the
 	// connection is closed the notifier will stop and cancels all active subscriptions.
@@ -160,13 +188,23 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 	// test if the server is ordered to stop
 	for atomic.LoadInt32(&s.run) == 1 {
 		reqs, batch, err := s.readRequest(codec)
+		if _, ok := err.(*requestTooLargeError); ok {
+			// The request was read in full, reject it but keep serving the connection.
+			// Streams cut off while reading a request fail with a read error below.
+			codec.Write(codec.CreateErrorResponse(nil, err))
+			if singleShot {
+				return nil
+			}
+			continue
+		}
 		if err != nil {
 			// If a parsing error occurred, send an error
 			if err.Error() != "EOF" {
 				log.Debug(fmt.Sprintf("read error %v\n", err))
 				codec.Write(codec.CreateErrorResponse(nil, err))
 			}
-			// Error or end of stream, wait for requests and tear down
+			// Error or end of stream, abort pending requests and tear down
+			cancel()
 			pend.Wait()
 			return nil
 		}
@@ -213,16 +251,19 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes the
 // response back using the given codec. It will block until the codec is closed or the server is
 // stopped. In either case the codec is closed.
+//
+// The codec is assumed to be a local (IPC or in-process) connection, which is not subject to
+// authentication.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	defer codec.Close()
-	s.serveRequest(codec, false, options)
+	s.serveRequest(contextWithLocalConn(context.Background()), codec, false, options)
 }
 
 // ServeSingleRequest reads and processes a single RPC request from the given codec. It will not
 // close the codec unless a non-recoverable error has occurred. Note, this method will return after
 // a single request has been processed!
 func (s *Server) ServeSingleRequest(codec ServerCodec, options CodecOption) {
-	s.serveRequest(codec, true, options)
+	s.serveRequest(contextWithLocalConn(context.Background()), codec, true, options)
 }
 
 // Stop will stop reading new requests, wait for stopPendingRequestTimeout to allow pending requests to finish,
@@ -253,6 +294,15 @@ func (s *Server) createSubscription(ctx context.Context, c ServerCodec, req *ser
 	return reply[0].Interface().(*Subscription).ID, nil
 }
 
+// fullName returns the name of the method a request calls as sent by the clients,
+// the subscription method of the namespace for subscriptions.
+func (req *serverRequest) fullName() string {
+	if req.callb.isSubscribe {
+		return req.svcname + subscribeMethodSuffix
+	}
+	return req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)
+}
+
 // handle executes a request and returns the response from the callback.
 func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverRequest) (interface{}, func()) {
 	if req.err != nil {
@@ -276,6 +326,16 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, &invalidParamsError{"Expected subscription id as first argument"}), nil
 	}
 
+	if err := s.authorize(ctx, req); err != nil {
+		return codec.CreateErrorResponse(&req.id, err), nil
+	}
+	if err := s.limit(ctx, req); err != nil {
+		return codec.CreateErrorResponse(&req.id, err), nil
+	}
+	if metrics.Enabled {
+		defer metrics.GetOrRegisterTimer("rpc/duration/"+req.fullName(), nil).UpdateSince(time.Now())
+	}
+
 	if req.callb.isSubscribe {
 		subid, err := s.createSubscription(ctx, codec, req)
 		if err != nil {
@@ -301,6 +361,11 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
+		if s.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+		}
 		arguments = append(arguments, reflect.ValueOf(ctx))
 	}
 	if len(req.args) > 0 {
@@ -316,8 +381,15 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	if req.callb.errPos >= 0 { // test if method returned an error
 		if !reply[req.callb.errPos].IsNil() {
 			e := reply[req.callb.errPos].Interface().(error)
-			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
-			return res, nil
+
+			var rpcErr Error = &callbackError{e.Error()}
+			if s.timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+				rpcErr = &timeoutError{s.timeout, e.Error()}
+			}
+			if de, ok := e.(DataError); ok {
+				return codec.CreateErrorResponseWithInfo(&req.id, rpcErr, de.ErrorData()), nil
+			}
+			return codec.CreateErrorResponse(&req.id, rpcErr), nil
 		}
 	}
 	return codec.CreateResponse(req.id, reply[0].Interface()), nil
@@ -332,6 +404,7 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 	} else {
 		response, callback = s.handle(ctx, codec, req)
 	}
+	response = s.limitResponses(codec, []*serverRequest{req}, []interface{}{response})[0]
 
 	if err := codec.Write(response); err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
@@ -359,6 +432,7 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 			}
 		}
 	}
+	responses = s.limitResponses(codec, requests, responses)
 
 	if err := codec.Write(responses); err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
@@ -379,6 +453,9 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 	if err != nil {
 		return nil, batch, err
 	}
+	if batch && s.limits.MaxBatchItems > 0 && len(reqs) > s.limits.MaxBatchItems {
+		return nil, batch, &requestTooLargeError{fmt.Sprintf("batch too large (%d > %d items)", len(reqs), s.limits.MaxBatchItems)}
+	}
 
 	requests := make([]*serverRequest, len(reqs))
 
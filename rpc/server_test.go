@@ -20,7 +20,10 @@ import (
 	"context"
 	"encoding/json"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -160,3 +163,65 @@ func TestServerMethodExecution(t *testing.T) {
 func TestServerMethodWithCtx(t *testing.T) {
 	testServerMethodExecution(t, "echoWithCtx")
 }
+
+// partialTestError is an interruption error carrying partial results.
+type partialTestError struct{ err error }
+
+func (e *partialTestError) Error() string          { return e.err.Error() }
+func (e *partialTestError) ErrorData() interface{} { return "partial" }
+
+// TimeoutTestService has a method running until its context is canceled.
+type TimeoutTestService struct{ aborted chan struct{} }
+
+func (s *TimeoutTestService) Block(ctx context.Context) error {
+	<-ctx.Done()
+	close(s.aborted)
+	return &partialTestError{ctx.Err()}
+}
+
+// Tests that calls running past the deadline of the server are aborted and fail
+// with a timeout error carrying their partial results.
+func TestServerTimeout(t *testing.T) {
+	service := &TimeoutTestService{aborted: make(chan struct{})}
+	server := newTestServer("test", service)
+	defer server.Stop()
+	server.SetTimeout(50 * time.Millisecond)
+
+	body := `{"jsonrpc":"2.0","id":1,"method":"test_block","params":[]}`
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+
+	var msg jsonErrResponse
+	if err := json.Unmarshal(resp.Body.Bytes(), &msg); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if msg.Error.Code != new(timeoutError).ErrorCode() {
+		t.Errorf("error code mismatch: have %d, want %d", msg.Error.Code, new(timeoutError).ErrorCode())
+	}
+	if msg.Error.Data != "partial" {
+		t.Errorf("error data mismatch: have %v, want %q", msg.Error.Data, "partial")
+	}
+}
+
+// Tests that calls are aborted when the HTTP client goes away.
+func TestServerHTTPCancel(t *testing.T) {
+	service := &TimeoutTestService{aborted: make(chan struct{})}
+	server := newTestServer("test", service)
+	defer server.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	body := `{"jsonrpc":"2.0","id":1,"method":"test_block","params":[]}`
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set("content-type", contentType)
+
+	go server.ServeHTTP(httptest.NewRecorder(), req)
+	cancel()
+
+	select {
+	case <-service.aborted:
+	case <-time.After(time.Second):
+		t.Fatalf("call not aborted")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
@@ -60,7 +61,10 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 		Handshake: wsHandshakeValidator(allowedOrigins),
 		Handler: func(conn *websocket.Conn) {
 			// Create a custom encode/decode pair to enforce payload size and number encoding
-			conn.MaxPayloadBytes = maxRequestContentLength
+			conn.MaxPayloadBytes = srv.limits.MaxRequestSize
+			if conn.MaxPayloadBytes == 0 {
+				conn.MaxPayloadBytes = math.MaxInt32 // zero selects the websocket package default
+			}
 
 			encoder := func(v interface{}) error {
 				return websocketJSONCodec.Send(conn, v)
@@ -68,11 +72,33 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			codec := NewCodec(conn, encoder, decoder)
+			defer codec.Close()
+
+			ctx := contextWithConnInfo(context.Background(), conn.Request(), nil)
+			srv.serveRequest(ctx, codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
 
+// NewWSHandler returns a handler that serves JSON-RPC to WebSocket connections,
+// enforcing the given origin and virtual host restrictions. Unlike for HTTP, the
+// virtual hosts are not checked if none are given.
+func NewWSHandler(allowedOrigins []string, vhosts []string, srv *Server) http.Handler {
+	handler := srv.WebsocketHandler(allowedOrigins)
+	if len(vhosts) == 0 {
+		return handler
+	}
+	return newVHostHandler(vhosts, handler)
+}
+
+// IsWebsocket reports whether an HTTP request asks for a websocket upgrade, for
+// servers dispatching HTTP and websocket requests arriving on the same port.
+func IsWebsocket(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
 // NewWSServer creates a new websocket RPC server around an API provider.
 //
 // Deprecated: use Server.WebsocketHandler
@@ -124,6 +150,16 @@ func wsHandshakeValidator(allowedOrigins []string) func(*websocket.Config, *http
 // The context is used for the initial connection establishment. It does not
 // affect subsequent interactions with the client.
 func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error) {
+	connect, err := wsConnectFunc(endpoint, origin)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(ctx, connect)
+}
+
+// wsConnectFunc creates the function establishing websocket connections to the
+// given endpoint, defaulting the origin to the local host name.
+func wsConnectFunc(endpoint, origin string) (func(context.Context) (net.Conn, error), error) {
 	if origin == "" {
 		var err error
 		if origin, err = os.Hostname(); err != nil {
@@ -139,10 +175,9 @@ func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error
 	if err != nil {
 		return nil, err
 	}
-
-	return newClient(ctx, func(ctx context.Context) (net.Conn, error) {
+	return func(ctx context.Context) (net.Conn, error) {
 		return wsDialContext(ctx, config)
-	})
+	}, nil
 }
 
 func wsDialContext(ctx context.Context, config *websocket.Config) (*websocket.Conn, error) {